@@ -3,25 +3,125 @@ package config
 import (
 	"blogspot-project/models"
 	"blogspot-project/utils"
+	"blogspot-project/utils/mention"
 	"fmt"
+	"time"
 
 	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
 
 func ConnectDatabase() *gorm.DB {
-	username := utils.GetEnv("DATABASE_USERNAME", "root")
-	password := utils.GetEnv("DATABASE_PASSWORD", "")
-	host := utils.GetEnv("DATABASE_HOST", "127.0.0.1")
-	port := utils.GetEnv("DATABASE_PORT", "3306")
-	database := utils.GetEnv("DATABASE_NAME", "db_blogspot")
-	dsn := fmt.Sprintf("%v:%v@tcp(%v:%v)/%v?charset=utf8mb4&parseTime=True&loc=Local", username, password, host, port, database)
-
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	driver := utils.GetEnv("DATABASE_DRIVER", "mysql")
+	db, err := OpenDatabase(driver, databaseDSN(driver))
 	if err != nil {
 		panic(err.Error())
 	}
-	db.AutoMigrate(&models.User{}, &models.Category{}, &models.Comment{}, &models.Post{},
-		&models.UserLikeComment{}, &models.UserLikePost{})
+	MigrateDatabase(db)
 	return db
 }
+
+// OpenDatabase connects to a mysql, postgres or sqlite database.
+func OpenDatabase(driver, dsn string) (*gorm.DB, error) {
+	var dialector gorm.Dialector
+	switch driver {
+	case "mysql":
+		dialector = mysql.Open(dsn)
+	case "postgres":
+		dialector = postgres.Open(dsn)
+	case "sqlite":
+		dialector = sqlite.Open(dsn)
+	default:
+		return nil, fmt.Errorf("unknown DATABASE_DRIVER %v, expected mysql, postgres or sqlite", driver)
+	}
+	return gorm.Open(dialector, &gorm.Config{TranslateError: true})
+}
+
+// databaseDSN builds the dsn of a driver from the DATABASE_* settings. For
+// sqlite DATABASE_NAME is the path of the database file.
+func databaseDSN(driver string) string {
+	database := utils.GetEnv("DATABASE_NAME", "db_blogspot")
+	host := utils.GetEnv("DATABASE_HOST", "127.0.0.1")
+	password := utils.GetEnv("DATABASE_PASSWORD", "")
+	switch driver {
+	case "postgres":
+		username := utils.GetEnv("DATABASE_USERNAME", "postgres")
+		port := utils.GetEnv("DATABASE_PORT", "5432")
+		sslmode := utils.GetEnv("DATABASE_SSLMODE", "disable")
+		return fmt.Sprintf("host=%v user=%v password=%v dbname=%v port=%v sslmode=%v", host, username, password, database, port, sslmode)
+	case "sqlite":
+		// foreign keys are off in sqlite unless asked for, and concurrent
+		// writers wait for each other instead of failing
+		return utils.GetEnv("DATABASE_NAME", "db_blogspot.db") + "?_foreign_keys=on&_busy_timeout=5000"
+	default:
+		username := utils.GetEnv("DATABASE_USERNAME", "root")
+		port := utils.GetEnv("DATABASE_PORT", "3306")
+		return fmt.Sprintf("%v:%v@tcp(%v:%v)/%v?charset=utf8mb4&parseTime=True&loc=Local", username, password, host, port, database)
+	}
+}
+
+// MigrateDatabase creates or updates the tables and backfills the columns
+// added since data was first written.
+func MigrateDatabase(db *gorm.DB) {
+	// reactions made before the unique index may be doubled, keep the latest
+	if db.Migrator().HasTable(&models.Reaction{}) && !db.Migrator().HasIndex(&models.Reaction{}, "idx_reactions_user_target") {
+		db.Exec(`DELETE FROM reactions WHERE id NOT IN (SELECT id FROM (SELECT MAX(id) AS id FROM reactions GROUP BY user_id, target_type, target_id) AS latest)`)
+	}
+	// the same file uploaded twice before the unique index shares its storage
+	// keys, so the first upload is kept and the others point to it
+	if db.Migrator().HasTable(&models.Media{}) && !db.Migrator().HasIndex(&models.Media{}, "idx_media_user_checksum") {
+		kept := `SELECT MIN(original.id) FROM media original JOIN media copy ON copy.user_id = original.user_id AND copy.checksum = original.checksum WHERE copy.id = `
+		db.Exec(`UPDATE posts SET featured_image_id = (` + kept + `posts.featured_image_id) WHERE featured_image_id IS NOT NULL`)
+		db.Exec(`UPDATE users SET avatar_media_id = (` + kept + `users.avatar_media_id) WHERE avatar_media_id IS NOT NULL`)
+		duplicates := `SELECT id FROM media WHERE id NOT IN (SELECT id FROM (SELECT MIN(id) AS id FROM media GROUP BY user_id, checksum) AS originals)`
+		db.Exec(`DELETE FROM media_variants WHERE media_id IN (` + duplicates + `)`)
+		db.Exec(`DELETE FROM media WHERE id IN (SELECT id FROM (` + duplicates + `) AS copies)`)
+	}
+	db.AutoMigrate(&models.User{}, &models.Category{}, &models.Comment{}, &models.Post{},
+		&models.UserLikeComment{}, &models.UserLikePost{}, &models.Media{}, &models.MediaVariant{}, &models.CommentEdit{},
+		&models.ModerationPolicy{}, &models.SpamToken{}, &models.SpamCorpus{}, &models.Report{},
+		&models.Mention{}, &models.Notification{}, &models.NotificationActor{}, &models.NotificationPreference{},
+		&models.EmailOutbox{}, &models.CategoryFollow{}, &models.UserFollow{}, &models.UserBlock{}, &models.UserMute{}, &models.Reaction{}, &models.ReactionCount{}, &models.Bookmark{}, &models.ReadingList{}, &models.ReadingListItem{},
+		&models.Tag{}, &models.PostTag{})
+
+	// posts created before publishing existed are published, date them by creation
+	db.Model(&models.Post{}).Where("status = ? AND published_at IS NULL", models.POST_STATUS_PUBLISHED).
+		Update("published_at", gorm.Expr("created_at"))
+	// comments created before threading are each the root of their own thread
+	db.Model(&models.Comment{}).Where("thread_id = 0").UpdateColumn("thread_id", gorm.Expr("id"))
+	// content written before mentions were rendered is shown without links
+	db.Model(&models.Post{}).Where("rendered_content IS NULL").UpdateColumn("rendered_content", gorm.Expr("article_content"))
+	var comments []models.Comment
+	db.Where("rendered_content IS NULL").FindInBatches(&comments, 500, func(tx *gorm.DB, batch int) error {
+		for _, comment := range comments {
+			db.Model(&comment).UpdateColumn("rendered_content", mention.Render(comment.CommentContent, nil, true))
+		}
+		return nil
+	})
+	// likes from before reactions become like and dislike reactions, the
+	// latest one counts for users who ended up with several rows
+	db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Exec(`INSERT INTO reactions (user_id, target_type, target_id, post_id, type, created_at, updated_at)
+			SELECT user_id, ?, post_id, post_id, CASE WHEN status = 1 THEN ? ELSE ? END, ?, ? FROM user_like_posts
+			WHERE id IN (SELECT MAX(id) FROM user_like_posts GROUP BY user_id, post_id)
+			AND NOT EXISTS (SELECT 1 FROM reactions r WHERE r.user_id = user_like_posts.user_id AND r.target_type = ? AND r.target_id = user_like_posts.post_id)`,
+			models.TARGET_POST, models.REACTION_LIKE, models.REACTION_DISLIKE, now, now, models.TARGET_POST).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec(`INSERT INTO reactions (user_id, target_type, target_id, post_id, type, created_at, updated_at)
+			SELECT l.user_id, ?, l.comment_id, c.post_id, CASE WHEN l.status = 1 THEN ? ELSE ? END, ?, ?
+			FROM user_like_comments l JOIN comments c ON c.id = l.comment_id
+			WHERE l.id IN (SELECT MAX(id) FROM user_like_comments GROUP BY user_id, comment_id)
+			AND NOT EXISTS (SELECT 1 FROM reactions r WHERE r.user_id = l.user_id AND r.target_type = ? AND r.target_id = l.comment_id)`,
+			models.TARGET_COMMENT, models.REACTION_LIKE, models.REACTION_DISLIKE, now, now, models.TARGET_COMMENT).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("1 = 1").Delete(&models.UserLikePost{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("1 = 1").Delete(&models.UserLikeComment{}).Error
+	})
+}
@@ -0,0 +1,21 @@
+package config
+
+import (
+	"blogspot-project/utils"
+	"blogspot-project/utils/mailer"
+)
+
+func ConnectMailer() mailer.Mailer {
+	switch utils.GetEnv("MAIL_DRIVER", "log") {
+	case "smtp":
+		return mailer.NewSMTPMailer(
+			utils.GetEnv("SMTP_HOST", "127.0.0.1"),
+			utils.GetEnv("SMTP_PORT", "587"),
+			utils.GetEnv("SMTP_USERNAME", ""),
+			utils.GetEnv("SMTP_PASSWORD", ""),
+			utils.GetEnv("MAIL_FROM", "no-reply@localhost"),
+		)
+	default:
+		return mailer.LogMailer{}
+	}
+}
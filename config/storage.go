@@ -0,0 +1,22 @@
+package config
+
+import (
+	"blogspot-project/utils"
+	"blogspot-project/utils/storage"
+)
+
+func ConnectStorage() storage.Storage {
+	switch utils.GetEnv("STORAGE_DRIVER", "local") {
+	case "s3":
+		return storage.NewS3Storage(
+			utils.GetEnv("S3_ENDPOINT", "http://127.0.0.1:9000"),
+			utils.GetEnv("S3_REGION", "us-east-1"),
+			utils.GetEnv("S3_BUCKET", "blogspot"),
+			utils.GetEnv("S3_ACCESS_KEY", ""),
+			utils.GetEnv("S3_SECRET_KEY", ""),
+			utils.GetEnv("S3_PUBLIC_URL", ""),
+		)
+	default:
+		return storage.NewLocalStorage(utils.GetEnv("STORAGE_LOCAL_DIR", "uploads"), utils.SiteURL()+"/uploads")
+	}
+}
@@ -2,22 +2,21 @@ package controllers
 
 import (
 	"blogspot-project/models"
-	"blogspot-project/utils"
+	"blogspot-project/presenters"
+	"blogspot-project/services"
+	"blogspot-project/utils/apperror"
 	"blogspot-project/utils/token"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
-	"golang.org/x/crypto/bcrypt"
-	"gorm.io/gorm"
 )
 
 type RegisterInput struct {
-	Name     string `json:"name" binding:"required"`
-	Username string `json:"username" binding:"required"`
-	Email    string `json:"email" binding:"required"`
+	Name     string `json:"name" binding:"required,max=255"`
+	Username string `json:"username" binding:"required,username"`
+	Email    string `json:"email" binding:"required,email,max=255"`
 	Password string `json:"password" binding:"required"`
-	ImageUrl string `json:"image_url"`
-	Role     uint   `json:"role" binding:"required"`
+	ImageUrl string `json:"image_url" binding:"required,url,max=255"`
 }
 
 type LoginInput struct {
@@ -30,49 +29,58 @@ type UpdatePasswordInput struct {
 	NewPassword string `json:"new_password" binding:"required"`
 }
 
+// UserController serves the accounts and profiles of users.
+type UserController struct {
+	users *services.UserService
+	posts *services.PostService
+}
+
+func NewUserController(users *services.UserService, posts *services.PostService) *UserController {
+	return &UserController{users: users, posts: posts}
+}
+
+// owner presents a user to themselves with their avatar and follow counts.
+func (c *UserController) owner(user models.User) (presenters.OwnerUser, error) {
+	avatars, err := c.users.Avatars(user)
+	if err != nil {
+		return presenters.OwnerUser{}, err
+	}
+	stats, err := c.users.Stats(user.ID)
+	if err != nil {
+		return presenters.OwnerUser{}, err
+	}
+	return presenters.ToOwnerUser(user, avatars, stats), nil
+}
+
 // Register godoc
-// @Summary Register new user or create new user (role 1 for admin, role 2 for non admin).
-// @Description registering a user to get access blog.
+// @Summary Register a new non admin user.
+// @Description registering a user to get access blog. Admin and moderator roles are only granted with PATCH /user/{id}/role.
 // @Tags Auth
 // @Param Body body RegisterInput true "json body to register a user or create new user"
 // @Produce json
 // @Success 200 {object} map[string]interface{}
 // @Router /auth/register [post]
-func RegisterNewUser(ctx *gin.Context) {
-	db := ctx.MustGet("db").(*gorm.DB)
+func (c *UserController) RegisterNewUser(ctx *gin.Context) {
 	var inputRegister RegisterInput
 	if err := ctx.ShouldBindJSON(&inputRegister); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-	if inputRegister.Role == 0 {
-		inputRegister.Role = models.NON_ADMIN_USER_ROLE
-	}
-	if !utils.IsValidEmail(inputRegister.Email) {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid email (make sure email format correct)"})
+		ctx.Error(apperror.Binding(err))
 		return
 	}
-	if !utils.IsValidUrl(inputRegister.ImageUrl) {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid image url (make sure image url format correct)"})
-		return
-	}
-	newUser := models.User{
+	newUser, err := c.users.Register(models.User{
 		Name:     inputRegister.Name,
 		Email:    inputRegister.Email,
 		Username: inputRegister.Username,
 		ImageUrl: inputRegister.ImageUrl,
 		Password: inputRegister.Password,
-		Role:     inputRegister.Role,
-	}
-	_, err := newUser.CreateUser(db)
+	})
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
-	result := map[string]string{
-		"name":     inputRegister.Name,
-		"username": inputRegister.Username,
-		"email":    inputRegister.Email,
+	result, err := c.owner(newUser)
+	if err != nil {
+		ctx.Error(err)
+		return
 	}
 	ctx.JSON(http.StatusOK, gin.H{"message": "Registration success", "user": result})
 }
@@ -85,16 +93,15 @@ func RegisterNewUser(ctx *gin.Context) {
 // @Produce json
 // @Success 200 {object} map[string]interface{}
 // @Router /auth/login [post]
-func LoginUser(ctx *gin.Context) {
-	db := ctx.MustGet("db").(*gorm.DB)
+func (c *UserController) LoginUser(ctx *gin.Context) {
 	var inputLogin LoginInput
 	if err := ctx.ShouldBindJSON(&inputLogin); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ctx.Error(apperror.Binding(err))
 		return
 	}
-	token, err := models.LoginValid(inputLogin.Username, inputLogin.Password, db)
+	token, err := c.users.Login(inputLogin.Username, inputLogin.Password)
 	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		ctx.Error(err)
 		return
 	}
 	ctx.JSON(http.StatusOK, gin.H{"message": "Login success", "token": token})
@@ -109,42 +116,19 @@ func LoginUser(ctx *gin.Context) {
 // @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
 // @Success 200 {object} map[string]interface{}
 // @Router /login/update-password [patch]
-func UpdatePassword(ctx *gin.Context) {
-	db := ctx.MustGet("db").(*gorm.DB)
+func (c *UserController) UpdatePassword(ctx *gin.Context) {
 	id, err := token.ExtractTokenID(ctx)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-	oldUser := models.User{}
-	if err := db.Where("ID = ?", id).Take(&oldUser).Error; err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ctx.Error(apperror.InvalidToken(err))
 		return
 	}
 	updatePasswordInput := UpdatePasswordInput{}
 	if err := ctx.ShouldBindJSON(&updatePasswordInput); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ctx.Error(apperror.Binding(err))
 		return
 	}
-	err = models.VerifyPassword(updatePasswordInput.OldPassword, oldUser.Password)
-	if err != nil && err == bcrypt.ErrMismatchedHashAndPassword {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-	hashedPassword, err := models.PasswordHashing(updatePasswordInput.NewPassword)
-	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-	updatedUser := models.User{
-		Name:     oldUser.Name,
-		Username: oldUser.Username,
-		Email:    oldUser.Email,
-		ImageUrl: oldUser.ImageUrl,
-		Password: hashedPassword,
-	}
-	if err := db.Model(&oldUser).Updates(&updatedUser).Error; err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := c.users.UpdatePassword(id, updatePasswordInput.OldPassword, updatePasswordInput.NewPassword); err != nil {
+		ctx.Error(err)
 		return
 	}
 	ctx.JSON(http.StatusOK, gin.H{"message": "Update Password Success"})
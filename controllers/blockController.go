@@ -0,0 +1,139 @@
+package controllers
+
+import (
+	"blogspot-project/models"
+	"blogspot-project/presenters"
+	"blogspot-project/utils/apperror"
+	"blogspot-project/utils/token"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BlockUser godoc
+// @Summary Block a user.
+// @Description Block a user. They can no longer comment on your posts, reply to your comments, mention you, follow you or see your reactions, and their posts and comments are hidden from you. Follows between you both are removed.
+// @Tags User
+// @Produce json
+// @Param username path string true "Username"
+// @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
+// @Success 200 {object} map[string]interface{}
+// @Router /author/{username}/block [post]
+func (c *FollowController) BlockUser(ctx *gin.Context) {
+	user_id, err := token.ExtractTokenID(ctx)
+	if err != nil {
+		ctx.Error(apperror.InvalidToken(err))
+		return
+	}
+	block, err := c.follows.Block(user_id, ctx.Param("username"))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Success block user", "data": presenters.ToUserBlock(block)})
+}
+
+// UnblockUser godoc
+// @Summary Unblock a user.
+// @Description Unblock a user. Removed follows are not restored.
+// @Tags User
+// @Produce json
+// @Param username path string true "Username"
+// @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
+// @Success 200 {object} map[string]interface{}
+// @Router /author/{username}/block [delete]
+func (c *FollowController) UnblockUser(ctx *gin.Context) {
+	user_id, err := token.ExtractTokenID(ctx)
+	if err != nil {
+		ctx.Error(apperror.InvalidToken(err))
+		return
+	}
+	if err := c.follows.Unblock(user_id, ctx.Param("username")); err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Success unblock user"})
+}
+
+// MuteUser godoc
+// @Summary Mute a user.
+// @Description Mute a user. Their posts and comments are hidden from you and they no longer notify you. They are not told and can still interact with you.
+// @Tags User
+// @Produce json
+// @Param username path string true "Username"
+// @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
+// @Success 200 {object} map[string]interface{}
+// @Router /author/{username}/mute [post]
+func (c *FollowController) MuteUser(ctx *gin.Context) {
+	user_id, err := token.ExtractTokenID(ctx)
+	if err != nil {
+		ctx.Error(apperror.InvalidToken(err))
+		return
+	}
+	mute, err := c.follows.Mute(user_id, ctx.Param("username"))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Success mute user", "data": presenters.ToUserMute(mute)})
+}
+
+// UnmuteUser godoc
+// @Summary Unmute a user.
+// @Description Unmute a user.
+// @Tags User
+// @Produce json
+// @Param username path string true "Username"
+// @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
+// @Success 200 {object} map[string]interface{}
+// @Router /author/{username}/mute [delete]
+func (c *FollowController) UnmuteUser(ctx *gin.Context) {
+	user_id, err := token.ExtractTokenID(ctx)
+	if err != nil {
+		ctx.Error(apperror.InvalidToken(err))
+		return
+	}
+	if err := c.follows.Unmute(user_id, ctx.Param("username")); err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Success unmute user"})
+}
+
+// GetListBlockedUsers godoc
+// @Summary Get blocked users.
+// @Description Get the users the current user blocked, latest first.
+// @Tags User
+// @Produce json
+// @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
+// @Success 200 {object} map[string]interface{}
+// @Router /user/blocks [get]
+func (c *FollowController) GetListBlockedUsers(ctx *gin.Context) {
+	c.listHiddenUsers(ctx, c.follows.Blocked)
+}
+
+// GetListMutedUsers godoc
+// @Summary Get muted users.
+// @Description Get the users the current user muted, latest first.
+// @Tags User
+// @Produce json
+// @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
+// @Success 200 {object} map[string]interface{}
+// @Router /user/mutes [get]
+func (c *FollowController) GetListMutedUsers(ctx *gin.Context) {
+	c.listHiddenUsers(ctx, c.follows.Muted)
+}
+
+func (c *FollowController) listHiddenUsers(ctx *gin.Context, list func(user_id uint) ([]models.User, error)) {
+	user_id, err := token.ExtractTokenID(ctx)
+	if err != nil {
+		ctx.Error(apperror.InvalidToken(err))
+		return
+	}
+	users, err := list(user_id)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	c.respondUsers(ctx, users)
+}
@@ -2,81 +2,85 @@ package controllers
 
 import (
 	"blogspot-project/models"
+	"blogspot-project/presenters"
+	"blogspot-project/services"
 	"blogspot-project/utils"
+	"blogspot-project/utils/apperror"
 	"blogspot-project/utils/token"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 )
 
 type PostInput struct {
-	ArticleTitle       string `binding:"required" json:"article_title"`
-	ArticleDescription string `binding:"required" json:"article_description"`
-	CategoryID         uint   `binding:"required" json:"category_id"`
-	ArticleContent     string `binding:"required" json:"article_content"`
+	ArticleTitle       string    `binding:"required,max=255" json:"article_title"`
+	ArticleDescription string    `binding:"required" json:"article_description"`
+	CategoryID         uint      `binding:"required" json:"category_id"`
+	ArticleContent     string    `binding:"required" json:"article_content"`
+	Status             uint      `json:"status"`
+	FeaturedImageID    *uint     `json:"featured_image_id"`
+	Tags               *[]string `json:"tags"`
 }
 
 type PostUpdate struct {
-	ArticleTitle       string `json:"article_title"`
-	ArticleDescription string `json:"article_description"`
-	CategoryID         uint   `json:"category_id"`
-	ArticleContent     string `json:"article_content"`
+	ArticleTitle       string    `binding:"max=255" json:"article_title"`
+	ArticleDescription string    `json:"article_description"`
+	CategoryID         uint      `json:"category_id"`
+	ArticleContent     string    `json:"article_content"`
+	Status             uint      `json:"status"`
+	FeaturedImageID    *uint     `json:"featured_image_id"`
+	Tags               *[]string `json:"tags"`
+}
+
+// PostController serves the blog posts.
+type PostController struct {
+	posts *services.PostService
+}
+
+func NewPostController(posts *services.PostService) *PostController {
+	return &PostController{posts: posts}
+}
+
+// present returns a post with its details as the user sees them.
+func (c *PostController) present(post models.Post, user_id uint) (presenters.Post, error) {
+	details, err := c.posts.Details([]models.Post{post}, user_id)
+	if err != nil {
+		return presenters.Post{}, err
+	}
+	return presenters.ToPost(post, details), nil
 }
 
 // CreateNewPost godoc
 // @Summary Create Blog Post
-// @Description create new blog post.
+// @Description create new blog post (status 1 for draft, 2 for published, default published). Up to 10 tags can be given by name, new tags are created.
 // @Tags Post
 // @Param Body body PostInput true "json body to create new blog post"
 // @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
 // @Produce json
 // @Success 200 {object} map[string]interface{}
 // @Router /post [post]
-func CreateNewPost(ctx *gin.Context) {
-	db := ctx.MustGet("db").(*gorm.DB)
-	id, err := token.ExtractTokenID(ctx)
+func (c *PostController) CreateNewPost(ctx *gin.Context) {
+	user_id, err := token.ExtractTokenID(ctx)
 	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
-		return
-	}
-	currentUser := models.User{}
-	if err := db.Where("id = ?", id).Take(&currentUser).Error; err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-	if currentUser.Role == models.ADMIN_USER_ROLE {
-		var input PostInput
-		if err := ctx.ShouldBindJSON(&input); err != nil {
-			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
-		}
-		var category models.Category
-		if err := db.Table("categories").Where("id = ?", input.CategoryID).Take(&category).Error; err != nil {
-			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
-		}
-		user_id, err := token.ExtractTokenID(ctx)
-		if err != nil {
-			ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
-			return
-		}
-		newCategory := models.Post{
-			UserID:             user_id,
-			ArticleTitle:       input.ArticleTitle,
-			ArticleDescription: input.ArticleDescription,
-			CategoryID:         category.ID,
-			ArticleContent:     input.ArticleContent,
-		}
-		var createdPost models.Post
-		if err := db.Create(&newCategory).Last(&createdPost).Error; err != nil {
-			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
-		}
-		ctx.JSON(http.StatusOK, gin.H{"message": "Create New blog Success", "data": createdPost})
-		return
-	}
-	ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Only Admin can create new post"})
+		ctx.Error(apperror.InvalidToken(err))
+		return
+	}
+	var input PostInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.Error(apperror.Binding(err))
+		return
+	}
+	createdPost, err := c.posts.Create(user_id, services.PostChanges(input))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	response, err := c.present(createdPost, user_id)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Create New blog Success", "data": response})
 }
 
 // DeletePost godoc
@@ -88,109 +92,87 @@ func CreateNewPost(ctx *gin.Context) {
 // @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
 // @Success 200 {object} map[string]interface{}
 // @Router /post/{id} [delete]
-func DeletePost(ctx *gin.Context) {
-	db := ctx.MustGet("db").(*gorm.DB)
-	id, err := token.ExtractTokenID(ctx)
+func (c *PostController) DeletePost(ctx *gin.Context) {
+	user_id, err := token.ExtractTokenID(ctx)
 	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		ctx.Error(apperror.InvalidToken(err))
 		return
 	}
-	currentUser := models.User{}
-	if err := db.Where("id = ?", id).Take(&currentUser).Error; err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := c.posts.Delete(user_id, utils.GetParamID(ctx, "id")); err != nil {
+		ctx.Error(err)
 		return
 	}
-	if currentUser.Role == models.ADMIN_USER_ROLE {
-		var post models.Post
-		if err := db.Where("id = ?", ctx.Param("id")).Take(&post).Error; err != nil {
-			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
-		}
-		if err := db.Delete(&post).Error; err != nil {
-			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
-		}
-		ctx.JSON(http.StatusOK, gin.H{"message": "Delete blog Success"})
-		return
-	}
-	ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Only Admin can delete post"})
+	ctx.JSON(http.StatusOK, gin.H{"message": "Delete blog Success"})
 }
 
 // UpdatePost godoc
 // @Summary Update existing post.
-// @Description Update existing post without update password that have logged in into blog.
+// @Description Update existing post without update password that have logged in into blog. Tags, when given, replace the tags of the post.
 // @Tags Post
 // @Produce json
 // @Param id path string true "Post id"
+// @Param Body body PostUpdate true "json body to update existing blog post"
 // @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
 // @Success 200 {object} map[string]interface{}
 // @Router /post/{id} [patch]
-func UpdatePost(ctx *gin.Context) {
-	db := ctx.MustGet("db").(*gorm.DB)
+func (c *PostController) UpdatePost(ctx *gin.Context) {
 	user_id, err := token.ExtractTokenID(ctx)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-	currentUser := models.User{}
-	if err := db.Where("id = ?", user_id).Take(&currentUser).Error; err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-	if currentUser.Role == models.ADMIN_USER_ROLE {
-		oldPost := models.Post{}
-		if err := db.Where("id = ? AND user_id = ?", ctx.Param("id"), user_id).Take(&oldPost).Error; err != nil {
-			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
-		}
-		var input PostUpdate
-		if err := ctx.BindJSON(&input); err != nil {
-			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
-		}
-		var category models.Category
-		if err := db.Table("categories").Where("id = ?", input.CategoryID).Take(&category).Error; err != nil {
-			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
-		}
-		updatedPost := models.Post{
-			ArticleTitle:       input.ArticleTitle,
-			ArticleContent:     input.ArticleContent,
-			ArticleDescription: input.ArticleDescription,
-			CategoryID:         input.CategoryID,
-		}
-		if err := db.Model(&oldPost).Updates(&updatedPost).Error; err != nil {
-			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
-		}
-		ctx.JSON(http.StatusOK, gin.H{"message": "Success update blog", "data": updatedPost})
-		return
-	}
-	ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Only Admin can update post"})
+		ctx.Error(apperror.InvalidToken(err))
+		return
+	}
+	var input PostUpdate
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.Error(apperror.Binding(err))
+		return
+	}
+	savedPost, err := c.posts.Update(user_id, utils.GetParamID(ctx, "id"), services.PostChanges(input))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	response, err := c.present(savedPost, user_id)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Success update blog", "data": response})
 }
 
 // GetListBlogs godoc
 // @Summary Get all Blog Post list.
-// @Description Get all categories.
+// @Description Get all published posts, plus the caller's own drafts when a token is sent. Posts of users the caller blocked or muted are left out.
 // @Tags Post
 // @Produce json
-// @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
+// @Param Authorization header string false "Optional authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
+// @Param   current_page      query    int        false        "current page for pagination"
+// @Param   page_size         query    int        false        "page size for pagination"
 // @Param   input_search      query    string     false        "input text for search blog"
 // @Success 200 {object} map[string]interface{}
 // @Router /post [get]
-func GetListBlogs(ctx *gin.Context) {
-	db := ctx.MustGet("db").(*gorm.DB)
+func (c *PostController) GetListBlogs(ctx *gin.Context) {
 	limit, offset, err := utils.GetPagination(ctx)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ctx.Error(err)
+		return
+	}
+	user_id, err := token.ExtractOptionalTokenID(ctx)
+	if err != nil {
+		ctx.Error(apperror.InvalidToken(err))
+		return
+	}
+	blogs, err := c.posts.List(user_id, ctx.Query("input_search"), limit, offset)
+	if err != nil {
+		ctx.Error(err)
 		return
 	}
-	var blogs []models.Post
-	if err := db.Where("article_title LIKE ?", "%"+ctx.Query("input_search")+"%").Limit(limit).Offset(offset).Find(&blogs).Error; err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	details, err := c.posts.Details(blogs, user_id)
+	if err != nil {
+		ctx.Error(err)
 		return
 	}
-	ctx.JSON(http.StatusOK, gin.H{"message": "Get list blog success", "data": blogs})
+	responses := presenters.ToPosts(blogs, details)
+	ctx.JSON(http.StatusOK, gin.H{"message": "Get list blog success", "data": responses})
 }
 
 // GetDetailPost godoc
@@ -199,20 +181,24 @@ func GetListBlogs(ctx *gin.Context) {
 // @Tags Post
 // @Produce json
 // @Param id path string true "Post id"
-// @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
+// @Param Authorization header string false "Optional authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
 // @Success 200 {object} map[string]interface{}
 // @Router /post/{id} [get]
-func GetDetailPost(ctx *gin.Context) {
-	post := models.Post{}
-	db := ctx.MustGet("db").(*gorm.DB)
-	id, err := token.ExtractTokenID(ctx)
+func (c *PostController) GetDetailPost(ctx *gin.Context) {
+	user_id, err := token.ExtractOptionalTokenID(ctx)
 	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		ctx.Error(apperror.InvalidToken(err))
 		return
 	}
-	if err := db.Where("id = ?", id).Take(&post).Error; err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	post, err := c.posts.Find(user_id, utils.GetParamID(ctx, "id"))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	response, err := c.present(post, user_id)
+	if err != nil {
+		ctx.Error(err)
 		return
 	}
-	ctx.JSON(http.StatusOK, gin.H{"message": "Get blog detail success", "data": post})
+	ctx.JSON(http.StatusOK, gin.H{"message": "Get blog detail success", "data": response})
 }
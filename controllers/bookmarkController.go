@@ -0,0 +1,95 @@
+package controllers
+
+import (
+	"blogspot-project/presenters"
+	"blogspot-project/services"
+	"blogspot-project/utils"
+	"blogspot-project/utils/apperror"
+	"blogspot-project/utils/token"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BookmarkController serves the bookmarks of users.
+type BookmarkController struct {
+	bookmarks *services.BookmarkService
+}
+
+func NewBookmarkController(bookmarks *services.BookmarkService) *BookmarkController {
+	return &BookmarkController{bookmarks: bookmarks}
+}
+
+// BookmarkPost godoc
+// @Summary Bookmark a post.
+// @Description Save a post to read later. Bookmarking a post twice keeps the first bookmark.
+// @Tags Bookmark
+// @Produce json
+// @Param id path string true "Post id"
+// @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
+// @Success 200 {object} map[string]interface{}
+// @Router /post/{id}/bookmark [post]
+func (c *BookmarkController) BookmarkPost(ctx *gin.Context) {
+	user_id, err := token.ExtractTokenID(ctx)
+	if err != nil {
+		ctx.Error(apperror.InvalidToken(err))
+		return
+	}
+	bookmark, err := c.bookmarks.Bookmark(user_id, utils.GetParamID(ctx, "id"))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Success bookmark post", "data": presenters.ToBookmark(bookmark)})
+}
+
+// RemoveBookmark godoc
+// @Summary Remove a bookmark.
+// @Description Remove a post from the bookmarks of the current user.
+// @Tags Bookmark
+// @Produce json
+// @Param id path string true "Post id"
+// @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
+// @Success 200 {object} map[string]interface{}
+// @Router /post/{id}/bookmark [delete]
+func (c *BookmarkController) RemoveBookmark(ctx *gin.Context) {
+	user_id, err := token.ExtractTokenID(ctx)
+	if err != nil {
+		ctx.Error(apperror.InvalidToken(err))
+		return
+	}
+	if err := c.bookmarks.RemoveBookmark(user_id, utils.GetParamID(ctx, "id")); err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Success remove bookmark"})
+}
+
+// GetListBookmarks godoc
+// @Summary Get bookmarked posts.
+// @Description Get the posts bookmarked by the current user, latest bookmark first. Bookmarked posts that are unpublished or hidden for now are left out and counted in unavailable_count.
+// @Tags Bookmark
+// @Produce json
+// @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
+// @Param   current_page      query    int        false        "current page for pagination"
+// @Param   page_size         query    int        false        "page size for pagination"
+// @Success 200 {object} map[string]interface{}
+// @Router /bookmarks [get]
+func (c *BookmarkController) GetListBookmarks(ctx *gin.Context) {
+	limit, offset, err := utils.GetPagination(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	user_id, err := token.ExtractTokenID(ctx)
+	if err != nil {
+		ctx.Error(apperror.InvalidToken(err))
+		return
+	}
+	posts, details, unavailable_count, err := c.bookmarks.Bookmarks(user_id, limit, offset)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Get list bookmarks success", "data": presenters.ToPosts(posts, details), "unavailable_count": unavailable_count})
+}
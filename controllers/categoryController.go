@@ -1,15 +1,25 @@
 package controllers
 
 import (
-	"blogspot-project/models"
+	"blogspot-project/presenters"
+	"blogspot-project/services"
+	"blogspot-project/utils"
+	"blogspot-project/utils/apperror"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 )
 
 type CategoryInput struct {
-	Name string `json:"name"`
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+type CategoryController struct {
+	categories *services.CategoryService
+}
+
+func NewCategoryController(categories *services.CategoryService) *CategoryController {
+	return &CategoryController{categories: categories}
 }
 
 // CreateNewCategory godoc
@@ -21,22 +31,18 @@ type CategoryInput struct {
 // @Produce json
 // @Success 200 {object} map[string]interface{}
 // @Router /category [post]
-func CreateNewCategory(ctx *gin.Context) {
-	db := ctx.MustGet("db").(*gorm.DB)
+func (c *CategoryController) CreateNewCategory(ctx *gin.Context) {
 	var input CategoryInput
 	if err := ctx.ShouldBindJSON(&input); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ctx.Error(apperror.Binding(err))
 		return
 	}
-	newCategory := models.Category{
-		Name: input.Name,
-	}
-	var createdCategory models.Category
-	if err := db.Create(&newCategory).Last(&createdCategory).Error; err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	createdCategory, err := c.categories.Create(input.Name)
+	if err != nil {
+		ctx.Error(err)
 		return
 	}
-	ctx.JSON(http.StatusOK, gin.H{"message": "Create New Category Success", "data": createdCategory})
+	ctx.JSON(http.StatusOK, gin.H{"message": "Create New Category Success", "data": presenters.ToCategory(createdCategory)})
 }
 
 // UpdateCategory godoc
@@ -49,27 +55,18 @@ func CreateNewCategory(ctx *gin.Context) {
 // @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
 // @Success 200 {object} map[string]interface{}
 // @Router /category/{id} [patch]
-func UpdateCategory(ctx *gin.Context) {
-	db := ctx.MustGet("db").(*gorm.DB)
-	var oldCategory models.Category
-	if err := db.Where("id = ?", ctx.Param("id")).Take(&oldCategory).Error; err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
+func (c *CategoryController) UpdateCategory(ctx *gin.Context) {
 	var input CategoryInput
 	if err := ctx.ShouldBindJSON(&input); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ctx.Error(apperror.Binding(err))
 		return
 	}
-	var updatedCategoryResult models.Category
-	updatedCategory := models.Category{
-		Name: input.Name,
-	}
-	if err := db.Model(&oldCategory).Updates(&updatedCategory).Find(&updatedCategoryResult).Error; err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	updatedCategory, err := c.categories.Update(utils.GetParamID(ctx, "id"), input.Name)
+	if err != nil {
+		ctx.Error(err)
 		return
 	}
-	ctx.JSON(http.StatusOK, gin.H{"message": "Update Category Success", "data": updatedCategoryResult})
+	ctx.JSON(http.StatusOK, gin.H{"message": "Update Category Success", "data": presenters.ToCategory(updatedCategory)})
 }
 
 // DeleteCategory godoc
@@ -81,35 +78,51 @@ func UpdateCategory(ctx *gin.Context) {
 // @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
 // @Success 200 {object} map[string]interface{}
 // @Router /category/{id} [delete]
-func DeleteCategory(ctx *gin.Context) {
-	db := ctx.MustGet("db").(*gorm.DB)
-	var category models.Category
-	if err := db.Where("id = ?", ctx.Param("id")).Take(&category).Error; err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-	if err := db.Delete(&category).Error; err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+func (c *CategoryController) DeleteCategory(ctx *gin.Context) {
+	if err := c.categories.Delete(utils.GetParamID(ctx, "id")); err != nil {
+		ctx.Error(err)
 		return
 	}
 	ctx.JSON(http.StatusOK, gin.H{"message": "Delete Category Success"})
 }
 
-// GetListUsers godoc
+// GetListCategories godoc
 // @Summary Get all Category list.
 // @Description Get all categories.
 // @Tags Category
 // @Produce json
-// @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
 // @Param   input_search      query    string     false        "input text for search category"
 // @Success 200 {object} map[string]interface{}
 // @Router /category [get]
-func GetListCategories(ctx *gin.Context) {
-	var categories []models.Category
-	db := ctx.MustGet("db").(*gorm.DB)
-	if err := db.Where("name LIKE ?", "%"+ctx.Query("input_search")+"%").Find(&categories).Error; err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+func (c *CategoryController) GetListCategories(ctx *gin.Context) {
+	categories, err := c.categories.List(ctx.Query("input_search"))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Get list category success", "data": presenters.ToCategories(categories)})
+}
+
+// GetDetailCategory godoc
+// @Summary Get category with its posts.
+// @Description Get category detail and the published posts in it.
+// @Tags Category
+// @Produce json
+// @Param id path string true "Category id"
+// @Param   current_page      query    int        false        "current page for pagination"
+// @Param   page_size         query    int        false        "page size for pagination"
+// @Success 200 {object} map[string]interface{}
+// @Router /category/{id} [get]
+func (c *CategoryController) GetDetailCategory(ctx *gin.Context) {
+	limit, offset, err := utils.GetPagination(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	category, posts, details, err := c.categories.Get(utils.GetParamID(ctx, "id"), limit, offset)
+	if err != nil {
+		ctx.Error(err)
 		return
 	}
-	ctx.JSON(http.StatusOK, gin.H{"message": "Get list category success", "data": categories})
+	ctx.JSON(http.StatusOK, gin.H{"message": "Get category detail success", "data": presenters.ToCategory(category), "posts": presenters.ToPosts(posts, details)})
 }
@@ -2,65 +2,71 @@ package controllers
 
 import (
 	"blogspot-project/models"
+	"blogspot-project/presenters"
+	"blogspot-project/services"
 	"blogspot-project/utils"
+	"blogspot-project/utils/apperror"
 	"blogspot-project/utils/token"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 )
 
 type InputComment struct {
 	PostID         uint   `binding:"required" json:"post_id"`
-	CommentContent string `binding:"required" json:"comment_content"`
+	ParentID       *uint  `json:"parent_id"`
+	CommentContent string `binding:"required,max=5000" json:"comment_content"`
 }
 
 type UpdateCommentInput struct {
-	CommentContent string `binding:"required" json:"comment_content"`
+	CommentContent string `binding:"required,max=5000" json:"comment_content"`
+}
+
+// CommentController serves the comments of posts.
+type CommentController struct {
+	comments *services.CommentService
+}
+
+func NewCommentController(comments *services.CommentService) *CommentController {
+	return &CommentController{comments: comments}
 }
 
 // CreateNewComment godoc
 // @Summary Create Comment Blog Post from post id.
-// @Description create new comment blog post based on post id.
+// @Description create new comment blog post based on post id, or a reply when parent_id is set.
+// @Description Replies deeper than COMMENT_MAX_DEPTH are attached next to their parent instead. Users blocked by the author of the post or of the parent comment cannot comment.
 // @Tags Comment
 // @Param Body body InputComment true "json body to create new comment post"
 // @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
 // @Produce json
 // @Success 200 {object} map[string]interface{}
 // @Router /post/comment [post]
-func CreateNewComment(ctx *gin.Context) {
-	db := ctx.MustGet("db").(*gorm.DB)
+func (c *CommentController) CreateNewComment(ctx *gin.Context) {
 	var input InputComment
 	if err := ctx.ShouldBindJSON(&input); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ctx.Error(apperror.Binding(err))
 		return
 	}
 	user_id, err := token.ExtractTokenID(ctx)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ctx.Error(apperror.InvalidToken(err))
 		return
 	}
-	var post models.Post
-	if err := db.Where("id = ?", input.PostID).Take(&post).Error; err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	createdComment, err := c.comments.Create(user_id, input.PostID, input.ParentID, input.CommentContent)
+	if err != nil {
+		ctx.Error(err)
 		return
 	}
-	newComment := models.Comment{
-		UserID:         user_id,
-		PostID:         input.PostID,
-		CommentContent: input.CommentContent,
-	}
-	var createdComment models.Comment
-	if err := db.Create(&newComment).Last(&createdComment).Error; err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if createdComment.ModerationStatus != models.COMMENT_STATUS_APPROVED {
+		ctx.JSON(http.StatusOK, gin.H{"message": "Comment is waiting for moderation", "data": presenters.ToComment(createdComment)})
 		return
 	}
-	ctx.JSON(http.StatusOK, gin.H{"message": "Create New Comment Success", "data": createdComment})
+	ctx.JSON(http.StatusOK, gin.H{"message": "Create New Comment Success", "data": presenters.ToComment(createdComment)})
 }
 
 // UpdateComment godoc
 // @Summary Update existing comment.
-// @Description Update existing comment blog post based on comment id and post id.
+// @Description Update existing comment blog post based on comment id and post id. Only the author can edit a comment, and only within COMMENT_EDIT_WINDOW_MINUTES after posting it. The previous content is kept in the edit history.
 // @Tags Comment
 // @Produce json
 // @Param id path string true "Post id"
@@ -69,34 +75,28 @@ func CreateNewComment(ctx *gin.Context) {
 // @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
 // @Success 200 {object} map[string]interface{}
 // @Router /post/{id}/comment/{comment_id} [patch]
-func UpdateComment(ctx *gin.Context) {
-	db := ctx.MustGet("db").(*gorm.DB)
-	var input UpdateCommentInput
-	if err := ctx.ShouldBindJSON(&input); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+func (c *CommentController) UpdateComment(ctx *gin.Context) {
+	user_id, err := token.ExtractTokenID(ctx)
+	if err != nil {
+		ctx.Error(apperror.InvalidToken(err))
 		return
 	}
-	var oldComment models.Comment
-	if err := db.Where("post_id = ? and id = ?", ctx.Param("id"), ctx.Param("comment_id")).Take(&oldComment).Error; err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var input UpdateCommentInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.Error(apperror.Binding(err))
 		return
 	}
-	updatedCommentInput := models.Comment{
-		UserID:         oldComment.UserID,
-		PostID:         oldComment.PostID,
-		CommentContent: input.CommentContent,
-	}
-	updatedComment := models.Comment{}
-	if err := db.Model(&oldComment).Updates(&updatedCommentInput).Take(&updatedComment).Error; err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	updatedComment, err := c.comments.Update(user_id, utils.GetParamID(ctx, "id"), utils.GetParamID(ctx, "comment_id"), input.CommentContent)
+	if err != nil {
+		ctx.Error(err)
 		return
 	}
-	ctx.JSON(http.StatusOK, gin.H{"message": "Success update comment", "data": updatedComment})
+	ctx.JSON(http.StatusOK, gin.H{"message": "Success update comment", "data": presenters.ToComment(updatedComment)})
 }
 
 // DeleteComment godoc
 // @Summary Delete existing comment blog post.
-// @Description Delete existing comment in blog post by post id.
+// @Description Delete existing comment in blog post by post id. A comment can be deleted by its author, the author of the post, or a moderator. A comment with replies is kept as a "[deleted]" placeholder so the replies are not orphaned.
 // @Tags Comment
 // @Produce json
 // @Param id path string true "Post id"
@@ -104,41 +104,148 @@ func UpdateComment(ctx *gin.Context) {
 // @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
 // @Success 200 {object} map[string]interface{}
 // @Router /post/{id}/comment/{comment_id} [delete]
-func DeleteComment(ctx *gin.Context) {
-	db := ctx.MustGet("db").(*gorm.DB)
-	var comment models.Comment
-	if err := db.Where("post_id = ? and id = ?", ctx.Param("id"), ctx.Param("comment_id")).Take(&comment).Error; err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+func (c *CommentController) DeleteComment(ctx *gin.Context) {
+	user_id, err := token.ExtractTokenID(ctx)
+	if err != nil {
+		ctx.Error(apperror.InvalidToken(err))
 		return
 	}
-	if err := db.Delete(&comment).Error; err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := c.comments.Delete(user_id, utils.GetParamID(ctx, "id"), utils.GetParamID(ctx, "comment_id")); err != nil {
+		ctx.Error(err)
 		return
 	}
 	ctx.JSON(http.StatusOK, gin.H{"message": "Success delete comment"})
 }
 
+// GetCommentHistory godoc
+// @Summary Get the edit history of a comment.
+// @Description Get the previous versions of a comment, newest first. Only moderators and admins can see the edit history.
+// @Tags Comment
+// @Produce json
+// @Param id path string true "Post id"
+// @Param comment_id path string true "Comment id"
+// @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
+// @Success 200 {object} map[string]interface{}
+// @Router /post/{id}/comment/{comment_id}/history [get]
+func (c *CommentController) GetCommentHistory(ctx *gin.Context) {
+	user_id, err := token.ExtractTokenID(ctx)
+	if err != nil {
+		ctx.Error(apperror.InvalidToken(err))
+		return
+	}
+	comment, edits, err := c.comments.History(user_id, utils.GetParamID(ctx, "id"), utils.GetParamID(ctx, "comment_id"))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Get comment history success", "data": gin.H{"comment": presenters.ToComment(comment), "edits": presenters.ToCommentEdits(edits)}})
+}
+
 // GetListComments godoc
 // @Summary Get all comments list.
-// @Description Get all comments based on post id.
+// @Description Get all comments based on post id. Comments of users the caller blocked or muted are left out.
 // @Tags Comment
 // @Produce json
 // @Param id path string true "Post id"
-// @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
+// @Param Authorization header string false "Optional authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
+// @Param   current_page      query    int        false        "current page for pagination"
+// @Param   page_size         query    int        false        "page size for pagination"
 // @Param   input_search      query    string     false        "input text for search comment"
 // @Success 200 {object} map[string]interface{}
 // @Router /post/{id}/comment/ [get]
-func GetListComments(ctx *gin.Context) {
-	var comments []models.Comment
-	db := ctx.MustGet("db").(*gorm.DB)
+func (c *CommentController) GetListComments(ctx *gin.Context) {
+	limit, offset, err := utils.GetPagination(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	user_id, err := token.ExtractOptionalTokenID(ctx)
+	if err != nil {
+		ctx.Error(apperror.InvalidToken(err))
+		return
+	}
+	comments, err := c.comments.List(user_id, utils.GetParamID(ctx, "id"), ctx.Query("input_search"), limit, offset)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	reactions, err := c.comments.Reactions(comments, user_id)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Get list comment success", "data": presenters.ToComments(comments, reactions)})
+}
+
+// GetCommentTree godoc
+// @Summary Get comments of a post as a tree.
+// @Description Get a page of top level comments of a post, each with all of its nested replies. Comments of users the caller blocked or muted are left out with their replies.
+// @Tags Comment
+// @Produce json
+// @Param id path string true "Post id"
+// @Param Authorization header string false "Optional authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
+// @Param   current_page      query    int        false        "current page of top level comments"
+// @Param   page_size         query    int        false        "number of top level comments per page"
+// @Success 200 {object} map[string]interface{}
+// @Router /post/{id}/comment/tree [get]
+func (c *CommentController) GetCommentTree(ctx *gin.Context) {
 	limit, offset, err := utils.GetPagination(ctx)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ctx.Error(err)
+		return
+	}
+	user_id, err := token.ExtractOptionalTokenID(ctx)
+	if err != nil {
+		ctx.Error(apperror.InvalidToken(err))
 		return
 	}
-	if err := db.Where("comment_content LIKE ?", "%"+ctx.Query("input_search")+"%").Limit(limit).Offset(offset).Find(&comments).Error; err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	comments, err := c.comments.Tree(user_id, utils.GetParamID(ctx, "id"), limit, offset)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	reactions, err := c.comments.Reactions(comments, user_id)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Get comment tree success", "data": presenters.ToCommentTree(comments, reactions)})
+}
+
+// GetCommentThread godoc
+// @Summary Get a comment with its direct replies.
+// @Description Get one comment and a page of its direct replies. Use reply_count and this endpoint again to load deeper replies. Replies of users the caller blocked or muted are left out.
+// @Tags Comment
+// @Produce json
+// @Param id path string true "Post id"
+// @Param comment_id path string true "Comment id"
+// @Param Authorization header string false "Optional authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
+// @Param   current_page      query    int        false        "current page for pagination"
+// @Param   page_size         query    int        false        "page size for pagination"
+// @Success 200 {object} map[string]interface{}
+// @Router /post/{id}/comment/{comment_id}/thread [get]
+func (c *CommentController) GetCommentThread(ctx *gin.Context) {
+	limit, offset, err := utils.GetPagination(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	user_id, err := token.ExtractOptionalTokenID(ctx)
+	if err != nil {
+		ctx.Error(apperror.InvalidToken(err))
+		return
+	}
+	comment, replies, err := c.comments.Thread(user_id, utils.GetParamID(ctx, "id"), utils.GetParamID(ctx, "comment_id"), limit, offset)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	comments := append([]models.Comment{comment}, replies...)
+	reactions, err := c.comments.Reactions(comments, user_id)
+	if err != nil {
+		ctx.Error(err)
 		return
 	}
-	ctx.JSON(http.StatusOK, gin.H{"message": "Get list category success", "data": comments})
+	responses := presenters.ToComments(comments, reactions)
+	ctx.JSON(http.StatusOK, gin.H{"message": "Get comment thread success", "data": responses[0], "replies": responses[1:]})
 }
@@ -0,0 +1,60 @@
+package controllers
+
+import (
+	"blogspot-project/utils/apperror"
+	"blogspot-project/utils/mailer"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetUnsubscribe godoc
+// @Summary Check an unsubscribe link.
+// @Description Tell which email list an unsubscribe link is for, so the client can ask for confirmation. Nothing changes, mail scanners open links with GET.
+// @Tags Email
+// @Produce json
+// @Param   token             query    string     true         "unsubscribe token from the email"
+// @Success 200 {object} map[string]interface{}
+// @Router /email/unsubscribe [get]
+func (c *NotificationController) GetUnsubscribe(ctx *gin.Context) {
+	user_id, list, err := c.parseUnsubscribe(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	subscribed, err := c.notifications.Subscribed(user_id, list)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Get unsubscribe success", "data": gin.H{"list": list, "subscribed": subscribed}})
+}
+
+// Unsubscribe godoc
+// @Summary Unsubscribe from an email list.
+// @Description Turn off the email list of an unsubscribe link. Also the target of the one-click List-Unsubscribe-Post header (RFC 8058), so no login is needed.
+// @Tags Email
+// @Produce json
+// @Param   token             query    string     true         "unsubscribe token from the email"
+// @Success 200 {object} map[string]interface{}
+// @Router /email/unsubscribe [post]
+func (c *NotificationController) Unsubscribe(ctx *gin.Context) {
+	user_id, list, err := c.parseUnsubscribe(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	if err := c.notifications.Unsubscribe(user_id, list); err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Success unsubscribe", "data": gin.H{"list": list}})
+}
+
+func (c *NotificationController) parseUnsubscribe(ctx *gin.Context) (uint, string, error) {
+	user_id, list, err := mailer.ParseUnsubscribeToken(ctx.Query("token"))
+	if err != nil || !c.notifications.IsEmailList(list) {
+		return 0, "", apperror.BadRequest(apperror.CODE_BAD_REQUEST, "Invalid unsubscribe token")
+	}
+	return user_id, list, nil
+}
@@ -0,0 +1,156 @@
+package controllers
+
+import (
+	"blogspot-project/services"
+	"blogspot-project/utils"
+	"blogspot-project/utils/feed"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SyndicationController serves the RSS, Atom and JSON feeds of published
+// posts.
+type SyndicationController struct {
+	feeds *services.FeedService
+}
+
+func NewSyndicationController(feeds *services.FeedService) *SyndicationController {
+	return &SyndicationController{feeds: feeds}
+}
+
+// GetSiteFeed godoc
+// @Summary Feed of all published posts.
+// @Description RSS 2.0 (/feed.xml), Atom (/atom.xml) or JSON Feed (/feed.json) of the latest published posts. Narrower feeds exist per category, per author and per tag.
+// @Tags Feed
+// @Produce xml
+// @Produce json
+// @Param   mode      query    string     false        "full or summary content (default from FEED_MODE)"
+// @Success 200 {string} string
+// @Success 304 {string} string
+// @Router /feed.xml [get]
+// @Router /atom.xml [get]
+// @Router /feed.json [get]
+func (c *SyndicationController) GetSiteFeed(ctx *gin.Context) {
+	c.serveFeed(ctx, c.feeds.Site())
+}
+
+// GetCategoryFeed godoc
+// @Summary Feed of published posts in a category.
+// @Description RSS 2.0, Atom or JSON Feed of the latest published posts in a category.
+// @Tags Feed
+// @Produce xml
+// @Produce json
+// @Param id path string true "Category id"
+// @Param   mode      query    string     false        "full or summary content (default from FEED_MODE)"
+// @Success 200 {string} string
+// @Success 304 {string} string
+// @Router /category/{id}/feed.xml [get]
+// @Router /category/{id}/atom.xml [get]
+// @Router /category/{id}/feed.json [get]
+func (c *SyndicationController) GetCategoryFeed(ctx *gin.Context) {
+	category, err := c.feeds.Category(utils.GetParamID(ctx, "id"))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	c.serveFeed(ctx, category)
+}
+
+// GetAuthorFeed godoc
+// @Summary Feed of published posts by an author.
+// @Description RSS 2.0, Atom or JSON Feed of the latest published posts written by an author.
+// @Tags Feed
+// @Produce xml
+// @Produce json
+// @Param username path string true "Author username"
+// @Param   mode      query    string     false        "full or summary content (default from FEED_MODE)"
+// @Success 200 {string} string
+// @Success 304 {string} string
+// @Router /author/{username}/feed.xml [get]
+// @Router /author/{username}/atom.xml [get]
+// @Router /author/{username}/feed.json [get]
+func (c *SyndicationController) GetAuthorFeed(ctx *gin.Context) {
+	author, err := c.feeds.Author(ctx.Param("username"))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	c.serveFeed(ctx, author)
+}
+
+// GetTagFeed godoc
+// @Summary Feed of published posts with a tag.
+// @Description RSS 2.0, Atom or JSON Feed of the latest published posts tagged with a tag.
+// @Tags Feed
+// @Produce xml
+// @Produce json
+// @Param slug path string true "Tag slug"
+// @Param   mode      query    string     false        "full or summary content (default from FEED_MODE)"
+// @Success 200 {string} string
+// @Success 304 {string} string
+// @Router /tag/{slug}/feed.xml [get]
+// @Router /tag/{slug}/atom.xml [get]
+// @Router /tag/{slug}/feed.json [get]
+func (c *SyndicationController) GetTagFeed(ctx *gin.Context) {
+	tag, err := c.feeds.Tag(ctx.Param("slug"))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	c.serveFeed(ctx, tag)
+}
+
+// serveFeed answers conditional requests from the newest post and the post
+// count before loading any post content, so polling readers stay cheap.
+func (c *SyndicationController) serveFeed(ctx *gin.Context, scope services.Feed) {
+	format := feed.FormatFromPath(ctx.FullPath())
+	mode := feed.Mode(ctx.Query("mode"))
+
+	count, lastModified, err := c.feeds.State(scope)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	etag := feed.ETag(lastModified, count, format, mode, scope.Scope)
+	ctx.Header("ETag", etag)
+	ctx.Header("Last-Modified", lastModified.Format(http.TimeFormat))
+	ctx.Header("Cache-Control", "public, max-age=300")
+	if isNotModified(ctx, etag, lastModified) {
+		ctx.Status(http.StatusNotModified)
+		return
+	}
+
+	posts, authors, err := c.feeds.Posts(scope)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	body, err := feed.Render(feed.Build(scope.Title, scope.Link, scope.Description, posts, authors, mode, lastModified), format)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.Data(http.StatusOK, feed.ContentType(format), []byte(body))
+}
+
+// isNotModified compares the ETags of If-None-Match weakly, as RFC 9110
+// asks: a list of tags matches when any of them does, with or without W/.
+func isNotModified(ctx *gin.Context, etag string, lastModified time.Time) bool {
+	if match := ctx.GetHeader("If-None-Match"); match != "" {
+		for _, tag := range strings.Split(match, ",") {
+			tag = strings.TrimSpace(tag)
+			if tag == "*" || strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		}
+		return false
+	}
+	if since := ctx.GetHeader("If-Modified-Since"); since != "" {
+		t, err := http.ParseTime(since)
+		return err == nil && !lastModified.After(t)
+	}
+	return false
+}
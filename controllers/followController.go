@@ -0,0 +1,228 @@
+package controllers
+
+import (
+	"blogspot-project/models"
+	"blogspot-project/presenters"
+	"blogspot-project/services"
+	"blogspot-project/utils"
+	"blogspot-project/utils/apperror"
+	"blogspot-project/utils/token"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FollowController serves the follows, blocks and mutes of users and the
+// personalized feed.
+type FollowController struct {
+	follows *services.FollowService
+	users   *services.UserService
+	posts   *services.PostService
+}
+
+func NewFollowController(follows *services.FollowService, users *services.UserService, posts *services.PostService) *FollowController {
+	return &FollowController{follows: follows, users: users, posts: posts}
+}
+
+// FollowCategory godoc
+// @Summary Follow a category.
+// @Description Follow a category to get its new posts in the personalized feed and the weekly email digest.
+// @Tags Category
+// @Produce json
+// @Param id path string true "Category id"
+// @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
+// @Success 200 {object} map[string]interface{}
+// @Router /category/{id}/follow [post]
+func (c *FollowController) FollowCategory(ctx *gin.Context) {
+	user_id, err := token.ExtractTokenID(ctx)
+	if err != nil {
+		ctx.Error(apperror.InvalidToken(err))
+		return
+	}
+	follow, err := c.follows.FollowCategory(user_id, utils.GetParamID(ctx, "id"))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Success follow category", "data": presenters.ToCategoryFollow(follow)})
+}
+
+// UnfollowCategory godoc
+// @Summary Unfollow a category.
+// @Description Stop following a category.
+// @Tags Category
+// @Produce json
+// @Param id path string true "Category id"
+// @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
+// @Success 200 {object} map[string]interface{}
+// @Router /category/{id}/follow [delete]
+func (c *FollowController) UnfollowCategory(ctx *gin.Context) {
+	user_id, err := token.ExtractTokenID(ctx)
+	if err != nil {
+		ctx.Error(apperror.InvalidToken(err))
+		return
+	}
+	if err := c.follows.UnfollowCategory(user_id, utils.GetParamID(ctx, "id")); err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Success unfollow category"})
+}
+
+// GetListFollowedCategories godoc
+// @Summary Get followed categories.
+// @Description Get the categories the current user follows.
+// @Tags Category
+// @Produce json
+// @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
+// @Success 200 {object} map[string]interface{}
+// @Router /user/following/categories [get]
+func (c *FollowController) GetListFollowedCategories(ctx *gin.Context) {
+	user_id, err := token.ExtractTokenID(ctx)
+	if err != nil {
+		ctx.Error(apperror.InvalidToken(err))
+		return
+	}
+	categories, err := c.follows.FollowedCategories(user_id)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Get list followed categories success", "data": presenters.ToCategories(categories)})
+}
+
+// FollowUser godoc
+// @Summary Follow an author.
+// @Description Follow a user to get their new posts in the personalized feed. The user is notified.
+// @Tags User
+// @Produce json
+// @Param username path string true "Username"
+// @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
+// @Success 200 {object} map[string]interface{}
+// @Router /author/{username}/follow [post]
+func (c *FollowController) FollowUser(ctx *gin.Context) {
+	user_id, err := token.ExtractTokenID(ctx)
+	if err != nil {
+		ctx.Error(apperror.InvalidToken(err))
+		return
+	}
+	follow, err := c.follows.FollowUser(user_id, ctx.Param("username"))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Success follow user", "data": presenters.ToUserFollow(follow)})
+}
+
+// UnfollowUser godoc
+// @Summary Unfollow an author.
+// @Description Stop following a user.
+// @Tags User
+// @Produce json
+// @Param username path string true "Username"
+// @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
+// @Success 200 {object} map[string]interface{}
+// @Router /author/{username}/follow [delete]
+func (c *FollowController) UnfollowUser(ctx *gin.Context) {
+	user_id, err := token.ExtractTokenID(ctx)
+	if err != nil {
+		ctx.Error(apperror.InvalidToken(err))
+		return
+	}
+	if err := c.follows.UnfollowUser(user_id, ctx.Param("username")); err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Success unfollow user"})
+}
+
+// GetListFollowers godoc
+// @Summary Get the followers of a user.
+// @Description Get the users following a user, latest first.
+// @Tags User
+// @Produce json
+// @Param username path string true "Username"
+// @Param   current_page      query    int        false        "current page for pagination"
+// @Param   page_size         query    int        false        "page size for pagination"
+// @Success 200 {object} map[string]interface{}
+// @Router /author/{username}/followers [get]
+func (c *FollowController) GetListFollowers(ctx *gin.Context) {
+	c.listFollows(ctx, c.follows.Followers)
+}
+
+// GetListFollowing godoc
+// @Summary Get the users a user follows.
+// @Description Get the users a user follows, latest first.
+// @Tags User
+// @Produce json
+// @Param username path string true "Username"
+// @Param   current_page      query    int        false        "current page for pagination"
+// @Param   page_size         query    int        false        "page size for pagination"
+// @Success 200 {object} map[string]interface{}
+// @Router /author/{username}/following [get]
+func (c *FollowController) GetListFollowing(ctx *gin.Context) {
+	c.listFollows(ctx, c.follows.Following)
+}
+
+// listFollows lists the users on one side of the follows of the user in the
+// username param.
+func (c *FollowController) listFollows(ctx *gin.Context, list func(username string, limit, offset int) ([]models.User, error)) {
+	limit, offset, err := utils.GetPagination(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	users, err := list(ctx.Param("username"), limit, offset)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	c.respondUsers(ctx, users)
+}
+
+// respondUsers writes the public profiles of users with their avatars.
+func (c *FollowController) respondUsers(ctx *gin.Context, users []models.User) {
+	avatars, err := c.users.Avatars(users...)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	responses := presenters.ToPublicUsers(users, avatars)
+	ctx.JSON(http.StatusOK, gin.H{"message": "Get list users success", "data": responses})
+}
+
+// GetFeed godoc
+// @Summary Get the personalized feed.
+// @Description Get the latest published posts of the authors and categories the current user follows, newest first. Posts of blocked or muted users are left out.
+// @Description The feed is cached for FEED_CACHE_SECONDS, new posts can take that long to show up.
+// @Tags Post
+// @Produce json
+// @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
+// @Param   current_page      query    int        false        "current page for pagination"
+// @Param   page_size         query    int        false        "page size for pagination"
+// @Success 200 {object} map[string]interface{}
+// @Router /feed [get]
+func (c *FollowController) GetFeed(ctx *gin.Context) {
+	limit, offset, err := utils.GetPagination(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	user_id, err := token.ExtractTokenID(ctx)
+	if err != nil {
+		ctx.Error(apperror.InvalidToken(err))
+		return
+	}
+	posts, err := c.follows.Feed(user_id, limit, offset)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	details, err := c.posts.Details(posts, user_id)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	responses := presenters.ToPosts(posts, details)
+	ctx.JSON(http.StatusOK, gin.H{"message": "Get feed success", "data": responses})
+}
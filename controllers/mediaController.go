@@ -0,0 +1,138 @@
+package controllers
+
+import (
+	"blogspot-project/models"
+	"blogspot-project/presenters"
+	"blogspot-project/services"
+	"blogspot-project/utils"
+	"blogspot-project/utils/apperror"
+	"blogspot-project/utils/media"
+	"blogspot-project/utils/token"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MediaController struct {
+	media *services.MediaService
+}
+
+func NewMediaController(items *services.MediaService) *MediaController {
+	return &MediaController{media: items}
+}
+
+// UploadMedia godoc
+// @Summary Upload media file.
+// @Description Upload a file with multipart form field "file". The type is detected from the content, uploading the same file twice returns the existing media.
+// @Description Metadata such as EXIF and GPS is stripped from images, and resized WebP variants are generated, plus square crops when purpose is avatar.
+// @Tags Media
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "file to upload"
+// @Param purpose formData string false "avatar to also generate square avatar crops"
+// @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
+// @Success 200 {object} map[string]interface{}
+// @Router /media [post]
+func (c *MediaController) UploadMedia(ctx *gin.Context) {
+	user_id, err := token.ExtractTokenID(ctx)
+	if err != nil {
+		ctx.Error(apperror.InvalidToken(err))
+		return
+	}
+	max_size := media.MaxUploadSize()
+	// leave room for the multipart boundaries and headers around the file
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, max_size+1<<20)
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		var maxBytesError *http.MaxBytesError
+		if errors.As(err, &maxBytesError) {
+			ctx.Error(apperror.New(http.StatusRequestEntityTooLarge, apperror.CODE_PAYLOAD_TOO_LARGE, fmt.Sprintf("File is larger than %v bytes", max_size)))
+			return
+		}
+		ctx.Error(err)
+		return
+	}
+	if fileHeader.Size > max_size {
+		ctx.Error(apperror.New(http.StatusRequestEntityTooLarge, apperror.CODE_PAYLOAD_TOO_LARGE, fmt.Sprintf("File is larger than %v bytes", max_size)))
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, max_size+1))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	if int64(len(data)) > max_size {
+		ctx.Error(apperror.New(http.StatusRequestEntityTooLarge, apperror.CODE_PAYLOAD_TOO_LARGE, fmt.Sprintf("File is larger than %v bytes", max_size)))
+		return
+	}
+	item, existing, err := c.media.Upload(user_id, fileHeader.Filename, data, ctx.PostForm("purpose") == models.MEDIA_VARIANT_AVATAR)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	if existing {
+		ctx.JSON(http.StatusOK, gin.H{"message": "Media already uploaded", "data": presenters.ToMedia(item)})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Upload media success", "data": presenters.ToMedia(item)})
+}
+
+// GetListMedia godoc
+// @Summary Get media library of current user.
+// @Description Get all media uploaded by the user that have logged in, newest first.
+// @Tags Media
+// @Produce json
+// @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
+// @Param   current_page      query    int        false        "current page for pagination"
+// @Param   page_size         query    int        false        "page size for pagination"
+// @Success 200 {object} map[string]interface{}
+// @Router /media [get]
+func (c *MediaController) GetListMedia(ctx *gin.Context) {
+	user_id, err := token.ExtractTokenID(ctx)
+	if err != nil {
+		ctx.Error(apperror.InvalidToken(err))
+		return
+	}
+	limit, offset, err := utils.GetPagination(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	list, err := c.media.List(user_id, limit, offset)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Get list media success", "data": presenters.ToMediaList(list)})
+}
+
+// DeleteMedia godoc
+// @Summary Delete own media.
+// @Description Delete a media file uploaded by the user that have logged in.
+// @Tags Media
+// @Produce json
+// @Param id path string true "Media id"
+// @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
+// @Success 200 {object} map[string]interface{}
+// @Router /media/{id} [delete]
+func (c *MediaController) DeleteMedia(ctx *gin.Context) {
+	user_id, err := token.ExtractTokenID(ctx)
+	if err != nil {
+		ctx.Error(apperror.InvalidToken(err))
+		return
+	}
+	if err := c.media.Delete(user_id, utils.GetParamID(ctx, "id")); err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Delete media success"})
+}
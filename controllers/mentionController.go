@@ -0,0 +1,40 @@
+package controllers
+
+import (
+	"blogspot-project/presenters"
+	"blogspot-project/utils"
+	"blogspot-project/utils/apperror"
+	"blogspot-project/utils/token"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetListMentions godoc
+// @Summary Get the posts and comments mentioning the current user.
+// @Description Get the mentions of the current user, newest first. Mentions by users the current user blocked or muted are left out.
+// @Tags User
+// @Produce json
+// @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
+// @Param   current_page      query    int        false        "current page for pagination"
+// @Param   page_size         query    int        false        "page size for pagination"
+// @Success 200 {object} map[string]interface{}
+// @Router /user/mentions [get]
+func (c *NotificationController) GetListMentions(ctx *gin.Context) {
+	user_id, err := token.ExtractTokenID(ctx)
+	if err != nil {
+		ctx.Error(apperror.InvalidToken(err))
+		return
+	}
+	limit, offset, err := utils.GetPagination(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	mentions, err := c.notifications.Mentions(user_id, limit, offset)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Get list mentions success", "data": presenters.ToMentions(mentions)})
+}
@@ -0,0 +1,161 @@
+package controllers
+
+import (
+	"blogspot-project/models"
+	"blogspot-project/presenters"
+	"blogspot-project/services"
+	"blogspot-project/utils"
+	"blogspot-project/utils/apperror"
+	"blogspot-project/utils/token"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ModerateCommentsInput struct {
+	CommentIDs []uint `json:"comment_ids" binding:"required"`
+	Action     string `json:"action" binding:"required"`
+}
+
+type ModerationPolicyInput struct {
+	AutoApproveTrusted  *bool    `json:"auto_approve_trusted"`
+	TrustedCommentCount *uint    `json:"trusted_comment_count"`
+	HoldFirstComment    *bool    `json:"hold_first_comment"`
+	HoldLinks           *bool    `json:"hold_links"`
+	SpamThreshold       *float64 `json:"spam_threshold"`
+}
+
+var moderationActions = map[string]uint{
+	"approve": models.COMMENT_STATUS_APPROVED,
+	"spam":    models.COMMENT_STATUS_SPAM,
+	"reject":  models.COMMENT_STATUS_REJECTED,
+}
+
+// GetModerationQueue godoc
+// @Summary Get the comment moderation queue.
+// @Description Get comments by moderation status (1 pending, 2 approved, 3 spam, 4 rejected), oldest first. Only moderators and admins can see the queue.
+// @Tags Moderation
+// @Produce json
+// @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
+// @Param   status            query    int        false        "moderation status, pending by default"
+// @Param   current_page      query    int        false        "current page for pagination"
+// @Param   page_size         query    int        false        "page size for pagination"
+// @Success 200 {object} map[string]interface{}
+// @Router /moderation/comments [get]
+func (c *CommentController) GetModerationQueue(ctx *gin.Context) {
+	user_id, err := token.ExtractTokenID(ctx)
+	if err != nil {
+		ctx.Error(apperror.InvalidToken(err))
+		return
+	}
+	limit, offset, err := utils.GetPagination(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	status := uint(models.COMMENT_STATUS_PENDING)
+	if ctx.Query("status") != "" {
+		parsed, err := strconv.ParseUint(ctx.Query("status"), 10, 32)
+		if err != nil {
+			ctx.Error(apperror.Validation("Invalid moderation status"))
+			return
+		}
+		status = uint(parsed)
+	}
+	comments, err := c.comments.Queue(user_id, status, limit, offset)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Get moderation queue success", "data": presenters.ToModerationComments(comments)})
+}
+
+// ModerateComments godoc
+// @Summary Approve, reject or mark comments as spam.
+// @Description Apply a moderation action (approve, spam or reject) to several comments at once. Approve and spam decisions train the spam classifier.
+// @Tags Moderation
+// @Produce json
+// @Param Body body ModerateCommentsInput true "json body with the comment ids and the action"
+// @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
+// @Success 200 {object} map[string]interface{}
+// @Router /moderation/comments [post]
+func (c *CommentController) ModerateComments(ctx *gin.Context) {
+	user_id, err := token.ExtractTokenID(ctx)
+	if err != nil {
+		ctx.Error(apperror.InvalidToken(err))
+		return
+	}
+	var input ModerateCommentsInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.Error(apperror.Binding(err))
+		return
+	}
+	status, ok := moderationActions[input.Action]
+	if !ok {
+		ctx.Error(apperror.Validation("Action must be approve, spam or reject"))
+		return
+	}
+	comments, err := c.comments.Moderate(user_id, input.CommentIDs, status)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Success moderate comments", "count": len(comments)})
+}
+
+// GetModerationPolicy godoc
+// @Summary Get the comment moderation policy.
+// @Description Get the site wide rules that decide which new comments are held for moderation.
+// @Tags Moderation
+// @Produce json
+// @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
+// @Success 200 {object} map[string]interface{}
+// @Router /moderation/policy [get]
+func (c *CommentController) GetModerationPolicy(ctx *gin.Context) {
+	user_id, err := token.ExtractTokenID(ctx)
+	if err != nil {
+		ctx.Error(apperror.InvalidToken(err))
+		return
+	}
+	policy, err := c.comments.Policy(user_id)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Get moderation policy success", "data": presenters.ToModerationPolicy(policy)})
+}
+
+// UpdateModerationPolicy godoc
+// @Summary Update the comment moderation policy.
+// @Description Update the site wide moderation rules, fields that are left out keep their value. Only admin can change the policy.
+// @Tags Moderation
+// @Produce json
+// @Param Body body ModerationPolicyInput true "json body with the rules to change"
+// @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
+// @Success 200 {object} map[string]interface{}
+// @Router /moderation/policy [patch]
+func (c *CommentController) UpdateModerationPolicy(ctx *gin.Context) {
+	user_id, err := token.ExtractTokenID(ctx)
+	if err != nil {
+		ctx.Error(apperror.InvalidToken(err))
+		return
+	}
+	var input ModerationPolicyInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.Error(apperror.Binding(err))
+		return
+	}
+	policy, err := c.comments.UpdatePolicy(user_id, services.ModerationPolicyChanges{
+		AutoApproveTrusted:  input.AutoApproveTrusted,
+		TrustedCommentCount: input.TrustedCommentCount,
+		HoldFirstComment:    input.HoldFirstComment,
+		HoldLinks:           input.HoldLinks,
+		SpamThreshold:       input.SpamThreshold,
+	})
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Success update moderation policy", "data": presenters.ToModerationPolicy(policy)})
+}
@@ -0,0 +1,206 @@
+package controllers
+
+import (
+	"blogspot-project/presenters"
+	"blogspot-project/services"
+	"blogspot-project/utils"
+	"blogspot-project/utils/apperror"
+	"blogspot-project/utils/notification"
+	"blogspot-project/utils/token"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type NotificationPreferenceInput struct {
+	Mentions   *bool `json:"mentions"`
+	Replies    *bool `json:"replies"`
+	Comments   *bool `json:"comments"`
+	Likes      *bool `json:"likes"`
+	Follows    *bool `json:"follows"`
+	Moderation *bool `json:"moderation"`
+
+	EmailMentions *bool `json:"email_mentions"`
+	EmailReplies  *bool `json:"email_replies"`
+	EmailDigest   *bool `json:"email_digest"`
+}
+
+// NotificationController serves the notifications of users, their
+// preferences, mentions and email unsubscribe links.
+type NotificationController struct {
+	notifications *services.NotificationService
+}
+
+func NewNotificationController(notifications *services.NotificationService) *NotificationController {
+	return &NotificationController{notifications: notifications}
+}
+
+// GetListNotifications godoc
+// @Summary Get the notification inbox.
+// @Description Get the notifications of the current user, most recently updated first. Likes, comments, replies and follows on the same target are grouped while unread.
+// @Tags Notification
+// @Produce json
+// @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
+// @Param   unread            query    bool       false        "only unread notifications"
+// @Param   current_page      query    int        false        "current page for pagination"
+// @Param   page_size         query    int        false        "page size for pagination"
+// @Success 200 {object} map[string]interface{}
+// @Router /notifications [get]
+func (c *NotificationController) GetListNotifications(ctx *gin.Context) {
+	user_id, err := token.ExtractTokenID(ctx)
+	if err != nil {
+		ctx.Error(apperror.InvalidToken(err))
+		return
+	}
+	limit, offset, err := utils.GetPagination(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	notifications, err := c.notifications.List(user_id, ctx.Query("unread") == "true", limit, offset)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	response := []presenters.NotificationDetail{}
+	for _, item := range notifications {
+		actors := presenters.ToPublicUsers(item.Actors, item.Avatars)
+		actor := ""
+		if len(actors) > 0 {
+			actor = actors[0].Name
+		}
+		response = append(response, presenters.NotificationDetail{
+			Notification: presenters.ToNotification(item.Notification),
+			Actors:       actors,
+			Message:      notification.Message(item.Notification, actor),
+		})
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Get list notifications success", "data": response})
+}
+
+// GetUnreadNotificationCount godoc
+// @Summary Get the number of unread notifications.
+// @Description Get how many notifications of the current user are unread.
+// @Tags Notification
+// @Produce json
+// @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
+// @Success 200 {object} map[string]interface{}
+// @Router /notifications/unread-count [get]
+func (c *NotificationController) GetUnreadNotificationCount(ctx *gin.Context) {
+	user_id, err := token.ExtractTokenID(ctx)
+	if err != nil {
+		ctx.Error(apperror.InvalidToken(err))
+		return
+	}
+	count, err := c.notifications.CountUnread(user_id)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Get unread notification count success", "data": gin.H{"unread": count}})
+}
+
+// ReadNotification godoc
+// @Summary Mark a notification as read.
+// @Description Mark one notification of the current user as read.
+// @Tags Notification
+// @Produce json
+// @Param id path string true "Notification id"
+// @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
+// @Success 200 {object} map[string]interface{}
+// @Router /notifications/{id}/read [post]
+func (c *NotificationController) ReadNotification(ctx *gin.Context) {
+	user_id, err := token.ExtractTokenID(ctx)
+	if err != nil {
+		ctx.Error(apperror.InvalidToken(err))
+		return
+	}
+	item, err := c.notifications.Read(user_id, utils.GetParamID(ctx, "id"))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Success read notification", "data": presenters.ToNotification(item)})
+}
+
+// ReadAllNotifications godoc
+// @Summary Mark every notification as read.
+// @Description Mark all unread notifications of the current user as read.
+// @Tags Notification
+// @Produce json
+// @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
+// @Success 200 {object} map[string]interface{}
+// @Router /notifications/read-all [post]
+func (c *NotificationController) ReadAllNotifications(ctx *gin.Context) {
+	user_id, err := token.ExtractTokenID(ctx)
+	if err != nil {
+		ctx.Error(apperror.InvalidToken(err))
+		return
+	}
+	read, err := c.notifications.ReadAll(user_id)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Success read all notifications", "data": gin.H{"read": read}})
+}
+
+// GetNotificationPreference godoc
+// @Summary Get notification preferences.
+// @Description Get which notifications the current user receives.
+// @Tags Notification
+// @Produce json
+// @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
+// @Success 200 {object} map[string]interface{}
+// @Router /notifications/preferences [get]
+func (c *NotificationController) GetNotificationPreference(ctx *gin.Context) {
+	user_id, err := token.ExtractTokenID(ctx)
+	if err != nil {
+		ctx.Error(apperror.InvalidToken(err))
+		return
+	}
+	preference, err := c.notifications.Preference(user_id)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Get notification preferences success", "data": presenters.ToNotificationPreference(preference)})
+}
+
+// UpdateNotificationPreference godoc
+// @Summary Update notification preferences.
+// @Description Turn notification types on or off for the current user, fields that are left out keep their value.
+// @Tags Notification
+// @Produce json
+// @Param Body body NotificationPreferenceInput true "json body with the preferences to change"
+// @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
+// @Success 200 {object} map[string]interface{}
+// @Router /notifications/preferences [patch]
+func (c *NotificationController) UpdateNotificationPreference(ctx *gin.Context) {
+	user_id, err := token.ExtractTokenID(ctx)
+	if err != nil {
+		ctx.Error(apperror.InvalidToken(err))
+		return
+	}
+	var input NotificationPreferenceInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.Error(apperror.Binding(err))
+		return
+	}
+	preference, err := c.notifications.UpdatePreference(user_id, services.NotificationPreferenceChanges{
+		Mentions:      input.Mentions,
+		Replies:       input.Replies,
+		Comments:      input.Comments,
+		Likes:         input.Likes,
+		Follows:       input.Follows,
+		Moderation:    input.Moderation,
+		EmailMentions: input.EmailMentions,
+		EmailReplies:  input.EmailReplies,
+		EmailDigest:   input.EmailDigest,
+	})
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Success update notification preferences", "data": presenters.ToNotificationPreference(preference)})
+}
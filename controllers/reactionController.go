@@ -0,0 +1,217 @@
+package controllers
+
+import (
+	"blogspot-project/models"
+	"blogspot-project/presenters"
+	"blogspot-project/services"
+	"blogspot-project/utils"
+	"blogspot-project/utils/apperror"
+	"blogspot-project/utils/token"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReactionInput struct {
+	Type string `binding:"required" json:"type"`
+}
+
+// LikeController serves the likes and reactions on posts and comments.
+type LikeController struct {
+	likes *services.LikeService
+}
+
+func NewLikeController(likes *services.LikeService) *LikeController {
+	return &LikeController{likes: likes}
+}
+
+// reactors returns the user behind each reaction.
+func (c *LikeController) reactors(reactions []models.Reaction) ([]presenters.Reaction, error) {
+	users, avatars, err := c.likes.Reactors(reactions)
+	if err != nil {
+		return nil, err
+	}
+	return presenters.ToReactions(reactions, users, avatars), nil
+}
+
+// GetListReactionTypes godoc
+// @Summary Get the reaction types.
+// @Description Get the reactions users can leave on posts and comments, set with REACTION_TYPES.
+// @Tags Reaction
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /reactions [get]
+func GetListReactionTypes(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, gin.H{"message": "Get list reaction types success", "data": models.ReactionTypes()})
+}
+
+// ReactToPost godoc
+// @Summary React to a post.
+// @Description Set the reaction of the current user on a post, replacing their previous one.
+// @Tags Reaction
+// @Produce json
+// @Param id path string true "Post id"
+// @Param Body body ReactionInput true "json body with the reaction type"
+// @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
+// @Success 200 {object} map[string]interface{}
+// @Router /post/{id}/reaction [put]
+func (c *LikeController) ReactToPost(ctx *gin.Context) {
+	var input ReactionInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.Error(apperror.Binding(err))
+		return
+	}
+	c.react(ctx, models.TARGET_POST, input.Type, "Success react to blog post")
+}
+
+// RemovePostReaction godoc
+// @Summary Remove a reaction from a post.
+// @Description Remove the reaction of the current user on a post, whatever it was.
+// @Tags Reaction
+// @Produce json
+// @Param id path string true "Post id"
+// @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
+// @Success 200 {object} map[string]interface{}
+// @Router /post/{id}/reaction [delete]
+func (c *LikeController) RemovePostReaction(ctx *gin.Context) {
+	c.react(ctx, models.TARGET_POST, "", "Success remove reaction")
+}
+
+// ReactToComment godoc
+// @Summary React to a comment.
+// @Description Set the reaction of the current user on a comment, replacing their previous one.
+// @Tags Reaction
+// @Produce json
+// @Param id path string true "Comment id"
+// @Param Body body ReactionInput true "json body with the reaction type"
+// @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
+// @Success 200 {object} map[string]interface{}
+// @Router /post/comment/{id}/reaction [put]
+func (c *LikeController) ReactToComment(ctx *gin.Context) {
+	var input ReactionInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.Error(apperror.Binding(err))
+		return
+	}
+	c.react(ctx, models.TARGET_COMMENT, input.Type, "Success react to comment")
+}
+
+// RemoveCommentReaction godoc
+// @Summary Remove a reaction from a comment.
+// @Description Remove the reaction of the current user on a comment, whatever it was.
+// @Tags Reaction
+// @Produce json
+// @Param id path string true "Comment id"
+// @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
+// @Success 200 {object} map[string]interface{}
+// @Router /post/comment/{id}/reaction [delete]
+func (c *LikeController) RemoveCommentReaction(ctx *gin.Context) {
+	c.react(ctx, models.TARGET_COMMENT, "", "Success remove reaction")
+}
+
+// GetListPostReactions godoc
+// @Summary Get the users who reacted to a post.
+// @Description Get the users who reacted to a post, optionally only those with the given reaction. Users who blocked the caller are left out.
+// @Tags Reaction
+// @Produce json
+// @Param id path string true "Post id"
+// @Param   type              query    string     false        "reaction type"
+// @Param   current_page      query    int        false        "current page for pagination"
+// @Param   page_size         query    int        false        "page size for pagination"
+// @Success 200 {object} map[string]interface{}
+// @Router /post/{id}/reactions [get]
+func (c *LikeController) GetListPostReactions(ctx *gin.Context) {
+	c.listReactions(ctx, models.TARGET_POST)
+}
+
+// GetListCommentReactions godoc
+// @Summary Get the users who reacted to a comment.
+// @Description Get the users who reacted to a comment, optionally only those with the given reaction. Users who blocked the caller are left out.
+// @Tags Reaction
+// @Produce json
+// @Param id path string true "Comment id"
+// @Param   type              query    string     false        "reaction type"
+// @Param   current_page      query    int        false        "current page for pagination"
+// @Param   page_size         query    int        false        "page size for pagination"
+// @Success 200 {object} map[string]interface{}
+// @Router /post/comment/{id}/reactions [get]
+func (c *LikeController) GetListCommentReactions(ctx *gin.Context) {
+	c.listReactions(ctx, models.TARGET_COMMENT)
+}
+
+// react sets the reaction of the current user on the target of the id path
+// param, or removes it when reaction is empty, and responds with the new
+// reaction counts.
+func (c *LikeController) react(ctx *gin.Context, target_type, reaction, message string) {
+	user_id, err := token.ExtractTokenID(ctx)
+	if err != nil {
+		ctx.Error(apperror.InvalidToken(err))
+		return
+	}
+	summary, err := c.likes.React(user_id, target_type, utils.GetParamID(ctx, "id"), reaction)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": message, "data": gin.H{"reactions": summary.Counts, "user_reaction": summary.UserReaction}})
+}
+
+// listReactions responds with the users who reacted to the target of the id
+// path param, filtered by the type query param.
+func (c *LikeController) listReactions(ctx *gin.Context, target_type string) {
+	limit, offset, err := utils.GetPagination(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	user_id, err := token.ExtractOptionalTokenID(ctx)
+	if err != nil {
+		ctx.Error(apperror.InvalidToken(err))
+		return
+	}
+	reactions, err := c.likes.List(user_id, target_type, utils.GetParamID(ctx, "id"), ctx.Query("type"), limit, offset)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	data, err := c.reactors(reactions)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Get list reactions success", "data": data})
+}
+
+// reactionUsers returns the users with the given reaction on the target in
+// the id param, for the like and dislike lists. Users who blocked the caller
+// are left out.
+func (c *LikeController) reactionUsers(ctx *gin.Context, target_type, reaction string) ([]presenters.PublicUser, error) {
+	user_id, err := token.ExtractTokenID(ctx)
+	if err != nil {
+		return nil, apperror.InvalidToken(err)
+	}
+	reactions, err := c.likes.Users(user_id, target_type, utils.GetParamID(ctx, "id"), reaction)
+	if err != nil {
+		return nil, err
+	}
+	responses, err := c.reactors(reactions)
+	if err != nil {
+		return nil, err
+	}
+	listOfUsers := []presenters.PublicUser{}
+	for _, item := range responses {
+		listOfUsers = append(listOfUsers, item.PublicUser)
+	}
+	return listOfUsers, nil
+}
+
+// likeStatusReaction maps the status of the like endpoints to a reaction.
+func likeStatusReaction(status string) (string, bool) {
+	switch status {
+	case "1":
+		return models.REACTION_LIKE, true
+	case "0":
+		return models.REACTION_DISLIKE, true
+	}
+	return "", false
+}
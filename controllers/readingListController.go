@@ -0,0 +1,335 @@
+package controllers
+
+import (
+	"blogspot-project/models"
+	"blogspot-project/presenters"
+	"blogspot-project/services"
+	"blogspot-project/utils"
+	"blogspot-project/utils/apperror"
+	"blogspot-project/utils/token"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReadingListInput struct {
+	Name        string `json:"name" binding:"required,max=255"`
+	Description string `json:"description"`
+	IsPublic    bool   `json:"is_public"`
+}
+
+type ReadingListUpdate struct {
+	Name        *string `json:"name" binding:"omitempty,min=1,max=255"`
+	Description *string `json:"description"`
+	IsPublic    *bool   `json:"is_public"`
+}
+
+type ReadingListItemInput struct {
+	PostID   uint   `json:"post_id" binding:"required"`
+	Note     string `json:"note"`
+	Position int    `json:"position"`
+}
+
+type ReadingListItemUpdate struct {
+	Note     *string `json:"note"`
+	Position *int    `json:"position"`
+}
+
+type ReadingListOrderInput struct {
+	PostIDs []uint `json:"post_ids" binding:"required"`
+}
+
+// ReadingListController serves the reading lists of users.
+type ReadingListController struct {
+	bookmarks *services.BookmarkService
+}
+
+func NewReadingListController(bookmarks *services.BookmarkService) *ReadingListController {
+	return &ReadingListController{bookmarks: bookmarks}
+}
+
+// CreateReadingList godoc
+// @Summary Create a reading list.
+// @Description Create a named reading list, private unless is_public is true.
+// @Tags Reading List
+// @Produce json
+// @Param Body body ReadingListInput true "json body to create a reading list"
+// @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
+// @Success 200 {object} map[string]interface{}
+// @Router /reading-lists [post]
+func (c *ReadingListController) CreateReadingList(ctx *gin.Context) {
+	user_id, err := token.ExtractTokenID(ctx)
+	if err != nil {
+		ctx.Error(apperror.InvalidToken(err))
+		return
+	}
+	var input ReadingListInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.Error(apperror.Binding(err))
+		return
+	}
+	list, err := c.bookmarks.CreateList(models.ReadingList{UserID: user_id, Name: input.Name, Description: input.Description, IsPublic: input.IsPublic})
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	details, err := c.bookmarks.ListDetails(user_id, list)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Create reading list success", "data": presenters.ToReadingList(list, details)})
+}
+
+// GetListReadingLists godoc
+// @Summary Get my reading lists.
+// @Description Get the reading lists of the current user, public and private.
+// @Tags Reading List
+// @Produce json
+// @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
+// @Success 200 {object} map[string]interface{}
+// @Router /reading-lists [get]
+func (c *ReadingListController) GetListReadingLists(ctx *gin.Context) {
+	user_id, err := token.ExtractTokenID(ctx)
+	if err != nil {
+		ctx.Error(apperror.InvalidToken(err))
+		return
+	}
+	lists, err := c.bookmarks.Lists(user_id)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	details, err := c.bookmarks.ListDetails(user_id, lists...)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Get list reading lists success", "data": presenters.ToReadingLists(lists, details)})
+}
+
+// GetAuthorReadingLists godoc
+// @Summary Get the public reading lists of a user.
+// @Description Get the public reading lists of a user by username.
+// @Tags Reading List
+// @Produce json
+// @Param username path string true "Username"
+// @Success 200 {object} map[string]interface{}
+// @Router /author/{username}/reading-lists [get]
+func (c *ReadingListController) GetAuthorReadingLists(ctx *gin.Context) {
+	lists, err := c.bookmarks.AuthorLists(ctx.Param("username"))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	details, err := c.bookmarks.ListDetails(0, lists...)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Get list reading lists success", "data": presenters.ToReadingLists(lists, details)})
+}
+
+// GetDetailReadingList godoc
+// @Summary Get a reading list.
+// @Description Get a reading list with its posts in order. Private lists are only shown to their owner.
+// @Description Posts that are unpublished or hidden for now are left out, the owner gets how many in unavailable_count.
+// @Tags Reading List
+// @Produce json
+// @Param id path string true "Reading list id"
+// @Param Authorization header string false "Optional authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
+// @Success 200 {object} map[string]interface{}
+// @Router /reading-lists/{id} [get]
+func (c *ReadingListController) GetDetailReadingList(ctx *gin.Context) {
+	user_id, err := token.ExtractOptionalTokenID(ctx)
+	if err != nil {
+		ctx.Error(apperror.InvalidToken(err))
+		return
+	}
+	content, err := c.bookmarks.List(user_id, utils.GetParamID(ctx, "id"))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	posts := presenters.ToPosts(content.Posts, content.PostDetails)
+	detail := presenters.ReadingListDetail{
+		ReadingList:      presenters.ToReadingList(content.List, content.Details),
+		Items:            []presenters.ReadingListPost{},
+		UnavailableCount: content.UnavailableCount,
+	}
+	for i, item := range content.Items {
+		detail.Items = append(detail.Items, presenters.ReadingListPost{
+			Position: item.Position,
+			Note:     item.Note,
+			AddedAt:  item.CreatedAt,
+			Post:     posts[i],
+		})
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Get reading list detail success", "data": detail})
+}
+
+// UpdateReadingList godoc
+// @Summary Update a reading list.
+// @Description Rename a reading list, change its description or make it public or private. Only given fields are changed.
+// @Tags Reading List
+// @Produce json
+// @Param id path string true "Reading list id"
+// @Param Body body ReadingListUpdate true "json body to update a reading list"
+// @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
+// @Success 200 {object} map[string]interface{}
+// @Router /reading-lists/{id} [patch]
+func (c *ReadingListController) UpdateReadingList(ctx *gin.Context) {
+	user_id, err := token.ExtractTokenID(ctx)
+	if err != nil {
+		ctx.Error(apperror.InvalidToken(err))
+		return
+	}
+	var input ReadingListUpdate
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.Error(apperror.Binding(err))
+		return
+	}
+	list, err := c.bookmarks.UpdateList(user_id, utils.GetParamID(ctx, "id"), services.ReadingListChanges(input))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	details, err := c.bookmarks.ListDetails(list.UserID, list)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Update reading list success", "data": presenters.ToReadingList(list, details)})
+}
+
+// DeleteReadingList godoc
+// @Summary Delete a reading list.
+// @Description Delete a reading list of the current user. The posts themselves are not touched.
+// @Tags Reading List
+// @Produce json
+// @Param id path string true "Reading list id"
+// @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
+// @Success 200 {object} map[string]interface{}
+// @Router /reading-lists/{id} [delete]
+func (c *ReadingListController) DeleteReadingList(ctx *gin.Context) {
+	user_id, err := token.ExtractTokenID(ctx)
+	if err != nil {
+		ctx.Error(apperror.InvalidToken(err))
+		return
+	}
+	if err := c.bookmarks.DeleteList(user_id, utils.GetParamID(ctx, "id")); err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Delete reading list success"})
+}
+
+// AddReadingListPost godoc
+// @Summary Add a post to a reading list.
+// @Description Add a post with an optional note. Without a position the post goes last, otherwise the posts from that position on move down one.
+// @Tags Reading List
+// @Produce json
+// @Param id path string true "Reading list id"
+// @Param Body body ReadingListItemInput true "json body with the post to add"
+// @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
+// @Success 200 {object} map[string]interface{}
+// @Router /reading-lists/{id}/posts [post]
+func (c *ReadingListController) AddReadingListPost(ctx *gin.Context) {
+	user_id, err := token.ExtractTokenID(ctx)
+	if err != nil {
+		ctx.Error(apperror.InvalidToken(err))
+		return
+	}
+	var input ReadingListItemInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.Error(apperror.Binding(err))
+		return
+	}
+	item, err := c.bookmarks.AddPost(user_id, utils.GetParamID(ctx, "id"), input.PostID, input.Note, input.Position)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Add post to reading list success", "data": presenters.ToReadingListItem(item)})
+}
+
+// UpdateReadingListPost godoc
+// @Summary Update a post in a reading list.
+// @Description Change the note of a post in a reading list or move it to another position.
+// @Tags Reading List
+// @Produce json
+// @Param id path string true "Reading list id"
+// @Param post_id path string true "Post id"
+// @Param Body body ReadingListItemUpdate true "json body with the note or position"
+// @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
+// @Success 200 {object} map[string]interface{}
+// @Router /reading-lists/{id}/posts/{post_id} [patch]
+func (c *ReadingListController) UpdateReadingListPost(ctx *gin.Context) {
+	user_id, err := token.ExtractTokenID(ctx)
+	if err != nil {
+		ctx.Error(apperror.InvalidToken(err))
+		return
+	}
+	var input ReadingListItemUpdate
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.Error(apperror.Binding(err))
+		return
+	}
+	item, err := c.bookmarks.UpdatePost(user_id, utils.GetParamID(ctx, "id"), utils.GetParamID(ctx, "post_id"), input.Note, input.Position)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Update reading list post success", "data": presenters.ToReadingListItem(item)})
+}
+
+// RemoveReadingListPost godoc
+// @Summary Remove a post from a reading list.
+// @Description Remove a post from a reading list, the posts after it move up one.
+// @Tags Reading List
+// @Produce json
+// @Param id path string true "Reading list id"
+// @Param post_id path string true "Post id"
+// @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
+// @Success 200 {object} map[string]interface{}
+// @Router /reading-lists/{id}/posts/{post_id} [delete]
+func (c *ReadingListController) RemoveReadingListPost(ctx *gin.Context) {
+	user_id, err := token.ExtractTokenID(ctx)
+	if err != nil {
+		ctx.Error(apperror.InvalidToken(err))
+		return
+	}
+	if err := c.bookmarks.RemovePost(user_id, utils.GetParamID(ctx, "id"), utils.GetParamID(ctx, "post_id")); err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Remove post from reading list success"})
+}
+
+// OrderReadingList godoc
+// @Summary Reorder a reading list.
+// @Description Set the order of a reading list. post_ids must hold every post of the list once, in the new order.
+// @Tags Reading List
+// @Produce json
+// @Param id path string true "Reading list id"
+// @Param Body body ReadingListOrderInput true "json body with the post ids in order"
+// @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
+// @Success 200 {object} map[string]interface{}
+// @Router /reading-lists/{id}/order [put]
+func (c *ReadingListController) OrderReadingList(ctx *gin.Context) {
+	user_id, err := token.ExtractTokenID(ctx)
+	if err != nil {
+		ctx.Error(apperror.InvalidToken(err))
+		return
+	}
+	var input ReadingListOrderInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.Error(apperror.Binding(err))
+		return
+	}
+	if err := c.bookmarks.Order(user_id, utils.GetParamID(ctx, "id"), input.PostIDs); err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Reorder reading list success"})
+}
@@ -0,0 +1,237 @@
+package controllers
+
+import (
+	"blogspot-project/services"
+	"blogspot-project/utils"
+	"blogspot-project/utils/apperror"
+	"blogspot-project/utils/realtime"
+	"blogspot-project/utils/token"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+// REALTIME_MAX_POSTS caps how many posts one connection can follow.
+const REALTIME_MAX_POSTS = 50
+
+// SSE_RETRY_MILLISECONDS is how long browsers wait before reconnecting.
+const SSE_RETRY_MILLISECONDS = 3000
+
+// RealtimeController streams the events of the hub to the connected
+// clients.
+type RealtimeController struct {
+	hub   *realtime.Hub
+	posts *services.PostService
+}
+
+func NewRealtimeController(hub *realtime.Hub, posts *services.PostService) *RealtimeController {
+	return &RealtimeController{hub: hub, posts: posts}
+}
+
+// browsers always send Origin, so pages from other sites are refused unless
+// they are allowed. Clients without one are not browsers and are accepted,
+// as is the api's own host.
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	CheckOrigin: func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			return true
+		}
+		if parsed, err := url.Parse(origin); err == nil && strings.EqualFold(parsed.Host, r.Host) {
+			return true
+		}
+		return utils.AllowedOrigin(origin)
+	},
+}
+
+// GetRealtimeEvents godoc
+// @Summary Stream updates with Server-Sent Events.
+// @Description Stream new comments and like counts of the posts given in posts, and the notifications of the current user. Browsers can pass the token in the token query parameter.
+// @Description Reconnecting clients send the Last-Event-ID header (or last_event_id) to get the events they missed, a reset event means some are gone and the state should be fetched again.
+// @Description Slow clients are disconnected and have to reconnect. A comment line is sent every REALTIME_HEARTBEAT_SECONDS.
+// @Tags Realtime
+// @Produce text/event-stream
+// @Param Authorization header string false "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
+// @Param   token             query    string     false        "jwt token, for clients that cannot set headers"
+// @Param   posts             query    string     false        "comma separated ids of the posts to follow"
+// @Param   last_event_id     query    int        false        "id of the last event received"
+// @Success 200 {string} string
+// @Router /realtime/events [get]
+func (c *RealtimeController) GetRealtimeEvents(ctx *gin.Context) {
+	subscriber, replay, ok := c.subscribeRealtime(ctx, ctx.GetHeader("Last-Event-ID"))
+	if !ok {
+		return
+	}
+	defer subscriber.Close()
+
+	ctx.Header("Content-Type", "text/event-stream")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	ctx.Header("X-Accel-Buffering", "no")
+	ctx.Status(http.StatusOK)
+	fmt.Fprintf(ctx.Writer, "retry: %v\n\n", SSE_RETRY_MILLISECONDS)
+	for _, event := range replay {
+		if err := writeServerSentEvent(ctx, event); err != nil {
+			return
+		}
+	}
+	ctx.Writer.Flush()
+
+	heartbeat := time.NewTicker(realtime.Heartbeat())
+	defer heartbeat.Stop()
+	for {
+		select {
+		case event := <-subscriber.Events():
+			if err := writeServerSentEvent(ctx, event); err != nil {
+				return
+			}
+			ctx.Writer.Flush()
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(ctx.Writer, ": ping\n\n"); err != nil {
+				return
+			}
+			ctx.Writer.Flush()
+		case <-subscriber.Done():
+			return
+		case <-ctx.Request.Context().Done():
+			return
+		}
+	}
+}
+
+// GetRealtimeSocket godoc
+// @Summary Stream updates over a WebSocket.
+// @Description Same events as /realtime/events, sent as JSON messages {id, topic, type, data}. Pass the token in the token query parameter and the id of the last event received in last_event_id when reconnecting.
+// @Description The server pings every REALTIME_HEARTBEAT_SECONDS and closes connections that stop answering or fall behind.
+// @Description Browser pages are only accepted from SITE_URL or one of the comma separated ALLOWED_ORIGINS.
+// @Tags Realtime
+// @Param   token             query    string     true         "jwt token"
+// @Param   posts             query    string     false        "comma separated ids of the posts to follow"
+// @Param   last_event_id     query    int        false        "id of the last event received"
+// @Success 101 {string} string
+// @Router /realtime/ws [get]
+func (c *RealtimeController) GetRealtimeSocket(ctx *gin.Context) {
+	subscriber, replay, ok := c.subscribeRealtime(ctx, "")
+	if !ok {
+		return
+	}
+	defer subscriber.Close()
+
+	conn, err := upgrader.Upgrade(ctx.Writer, ctx.Request, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	heartbeat := realtime.Heartbeat()
+	// the reader only handles pongs and notices when the client goes away
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		conn.SetReadDeadline(time.Now().Add(2 * heartbeat))
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(2 * heartbeat))
+		})
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	for _, event := range replay {
+		if err := writeSocketEvent(conn, event, heartbeat); err != nil {
+			return
+		}
+	}
+	ticker := time.NewTicker(heartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case event := <-subscriber.Events():
+			if err := writeSocketEvent(conn, event, heartbeat); err != nil {
+				return
+			}
+		case <-ticker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(heartbeat)); err != nil {
+				return
+			}
+		case <-subscriber.Done():
+			conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "too slow"), time.Now().Add(time.Second))
+			return
+		case <-closed:
+			return
+		}
+	}
+}
+
+// subscribeRealtime authenticates the connection and subscribes it to the
+// current user and the requested posts. It writes the error response itself
+// when it fails.
+func (c *RealtimeController) subscribeRealtime(ctx *gin.Context, last_event_header string) (*realtime.Subscriber, []realtime.Event, bool) {
+	user_id, err := token.ExtractTokenID(ctx)
+	if err != nil {
+		ctx.Error(apperror.InvalidToken(err))
+		return nil, nil, false
+	}
+	topics := []string{realtime.UserTopic(user_id)}
+	if ctx.Query("posts") != "" {
+		values := strings.Split(ctx.Query("posts"), ",")
+		if len(values) > REALTIME_MAX_POSTS {
+			ctx.Error(apperror.Validation(fmt.Sprintf("Cannot follow more than %v posts", REALTIME_MAX_POSTS)))
+			return nil, nil, false
+		}
+		// ids that are not numbers match no post
+		ids := []uint64{}
+		for _, value := range values {
+			if id, err := strconv.ParseUint(value, 10, 64); err == nil {
+				ids = append(ids, id)
+			}
+		}
+		visible, err := c.posts.VisibleIDs(user_id, ids)
+		if err != nil {
+			ctx.Error(err)
+			return nil, nil, false
+		}
+		for _, id := range visible {
+			topics = append(topics, realtime.PostTopic(id))
+		}
+	}
+	last_event := last_event_header
+	if last_event == "" {
+		last_event = ctx.Query("last_event_id")
+	}
+	var last_event_id uint64 = 0
+	if last_event != "" {
+		last_event_id, err = strconv.ParseUint(last_event, 10, 64)
+		if err != nil {
+			ctx.Error(apperror.BadRequest(apperror.CODE_BAD_REQUEST, "Last-Event-ID must be a number"))
+			return nil, nil, false
+		}
+	}
+	subscriber, replay := c.hub.Subscribe(topics, last_event_id)
+	return subscriber, replay, true
+}
+
+func writeServerSentEvent(ctx *gin.Context, event realtime.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(ctx.Writer, "id: %v\nevent: %v\ndata: %s\n\n", event.ID, event.Type, data)
+	return err
+}
+
+func writeSocketEvent(conn *websocket.Conn, event realtime.Event, timeout time.Duration) error {
+	conn.SetWriteDeadline(time.Now().Add(timeout))
+	return conn.WriteJSON(event)
+}
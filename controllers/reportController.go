@@ -0,0 +1,166 @@
+package controllers
+
+import (
+	"blogspot-project/models"
+	"blogspot-project/presenters"
+	"blogspot-project/services"
+	"blogspot-project/utils"
+	"blogspot-project/utils/apperror"
+	"blogspot-project/utils/token"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReportInput struct {
+	TargetType string `json:"target_type" binding:"required"`
+	TargetID   uint   `json:"target_id" binding:"required"`
+	Reason     string `json:"reason" binding:"required"`
+	Message    string `json:"message"`
+}
+
+type ResolveReportInput struct {
+	Action      string `json:"action" binding:"required"`
+	SuspendDays int    `json:"suspend_days"`
+}
+
+// ReportController serves the reports of readers and their triage by
+// moderators.
+type ReportController struct {
+	reports *services.ReportService
+}
+
+func NewReportController(reports *services.ReportService) *ReportController {
+	return &ReportController{reports: reports}
+}
+
+// CreateReport godoc
+// @Summary Report a post or comment.
+// @Description Flag a post or comment as abusive. target_type is post or comment, reason is one of spam, harassment, hate, violence, sexual, misinformation or other. Each user can report the same content once, and content reaching REPORT_HIDE_THRESHOLD open reports is hidden until a moderator resolves them.
+// @Tags Report
+// @Produce json
+// @Param Body body ReportInput true "json body to report a post or comment"
+// @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
+// @Success 200 {object} map[string]interface{}
+// @Router /report [post]
+func (c *ReportController) CreateReport(ctx *gin.Context) {
+	user_id, err := token.ExtractTokenID(ctx)
+	if err != nil {
+		ctx.Error(apperror.InvalidToken(err))
+		return
+	}
+	var input ReportInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.Error(apperror.Binding(err))
+		return
+	}
+	newReport, err := c.reports.Create(user_id, services.ReportInput{
+		TargetType: input.TargetType,
+		TargetID:   input.TargetID,
+		Reason:     input.Reason,
+		Message:    input.Message,
+	})
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Report success", "data": presenters.ToFiledReport(newReport)})
+}
+
+// GetListReports godoc
+// @Summary Get reports to triage.
+// @Description Get reports by status (1 open, 2 resolved, 3 dismissed), oldest first. Only moderators and admins can see reports.
+// @Tags Moderation
+// @Produce json
+// @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
+// @Param   status            query    int        false        "report status, open by default"
+// @Param   target_type       query    string     false        "only reports on post or comment"
+// @Param   current_page      query    int        false        "current page for pagination"
+// @Param   page_size         query    int        false        "page size for pagination"
+// @Success 200 {object} map[string]interface{}
+// @Router /moderation/reports [get]
+func (c *ReportController) GetListReports(ctx *gin.Context) {
+	user_id, err := token.ExtractTokenID(ctx)
+	if err != nil {
+		ctx.Error(apperror.InvalidToken(err))
+		return
+	}
+	limit, offset, err := utils.GetPagination(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	status := uint(models.REPORT_STATUS_OPEN)
+	if ctx.Query("status") != "" {
+		parsed, err := strconv.ParseUint(ctx.Query("status"), 10, 32)
+		if err != nil {
+			ctx.Error(apperror.Validation("Invalid report status"))
+			return
+		}
+		status = uint(parsed)
+	}
+	reports, err := c.reports.List(user_id, status, ctx.Query("target_type"), limit, offset)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Get list reports success", "data": presenters.ToReports(reports)})
+}
+
+// ResolveReport godoc
+// @Summary Resolve a report.
+// @Description Resolve a report and every other open report on the same content. Actions: dismiss (the content stays, and is shown again if reports hid it), hide, delete, warn (hide and warn the author) or suspend (hide and suspend the author for suspend_days, REPORT_SUSPEND_DAYS by default).
+// @Tags Moderation
+// @Produce json
+// @Param id path string true "Report id"
+// @Param Body body ResolveReportInput true "json body with the action to take"
+// @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
+// @Success 200 {object} map[string]interface{}
+// @Router /moderation/reports/{id}/resolve [post]
+func (c *ReportController) ResolveReport(ctx *gin.Context) {
+	user_id, err := token.ExtractTokenID(ctx)
+	if err != nil {
+		ctx.Error(apperror.InvalidToken(err))
+		return
+	}
+	var input ResolveReportInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.Error(apperror.Binding(err))
+		return
+	}
+	if err := c.reports.Resolve(user_id, utils.GetParamID(ctx, "id"), input.Action, input.SuspendDays); err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Success resolve report"})
+}
+
+// GetUserReportHistory godoc
+// @Summary Get the report history of a user.
+// @Description Get the reports filed by a user and the reports against their content, with their warning count and suspension. Only moderators and admins can see it.
+// @Tags Moderation
+// @Produce json
+// @Param id path string true "User id"
+// @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
+// @Success 200 {object} map[string]interface{}
+// @Router /moderation/users/{id}/reports [get]
+func (c *ReportController) GetUserReportHistory(ctx *gin.Context) {
+	user_id, err := token.ExtractTokenID(ctx)
+	if err != nil {
+		ctx.Error(apperror.InvalidToken(err))
+		return
+	}
+	history, err := c.reports.History(user_id, utils.GetParamID(ctx, "id"))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Get user report history success", "data": gin.H{
+		"user_id":         history.User.ID,
+		"warning_count":   history.User.WarningCount,
+		"suspended_until": history.User.SuspendedUntil,
+		"reports_filed":   presenters.ToReports(history.Filed),
+		"reports_against": presenters.ToReports(history.Against),
+	}})
+}
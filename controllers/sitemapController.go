@@ -0,0 +1,95 @@
+package controllers
+
+import (
+	"blogspot-project/utils"
+	"blogspot-project/utils/apperror"
+	"blogspot-project/utils/sitemap"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SitemapController struct {
+	index *sitemap.Index
+}
+
+func NewSitemapController(index *sitemap.Index) *SitemapController {
+	return &SitemapController{index: index}
+}
+
+// GetSitemap godoc
+// @Summary XML sitemap.
+// @Description Sitemap of published posts, categories and author pages. Turns into a sitemap index pointing to /sitemap/{page}.xml once there are more than 50000 URLs.
+// @Tags SEO
+// @Produce xml
+// @Success 200 {string} string
+// @Router /sitemap.xml [get]
+func (c *SitemapController) GetSitemap(ctx *gin.Context) {
+	c.renderSitemap(ctx, 0)
+}
+
+// GetSitemapPage godoc
+// @Summary XML sitemap page.
+// @Description One page of a sitemap that has been split because it is over 50000 URLs.
+// @Tags SEO
+// @Produce xml
+// @Param page path string true "Sitemap page, for example 1.xml"
+// @Success 200 {string} string
+// @Router /sitemap/{page} [get]
+func (c *SitemapController) GetSitemapPage(ctx *gin.Context) {
+	page, err := strconv.Atoi(strings.TrimSuffix(ctx.Param("page"), ".xml"))
+	if err != nil || page < 1 {
+		ctx.Error(apperror.NotFound(apperror.CODE_SITEMAP_NOT_FOUND, "Sitemap page not found"))
+		return
+	}
+	c.renderSitemap(ctx, page)
+}
+
+func (c *SitemapController) renderSitemap(ctx *gin.Context, page int) {
+	pages, err := c.index.PageCount()
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	if page > 0 && (pages == 1 || page > pages) {
+		ctx.Error(apperror.NotFound(apperror.CODE_SITEMAP_NOT_FOUND, "Sitemap page not found"))
+		return
+	}
+	body, err := c.index.Render(page)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.Data(http.StatusOK, "application/xml; charset=utf-8", body)
+}
+
+// GetRobots godoc
+// @Summary robots.txt
+// @Description Serves the file at ROBOTS_TXT_PATH when set, otherwise rules built from ROBOTS_DISALLOW with a link to the sitemap.
+// @Tags SEO
+// @Produce plain
+// @Success 200 {string} string
+// @Router /robots.txt [get]
+func GetRobots(ctx *gin.Context) {
+	if path := utils.GetEnv("ROBOTS_TXT_PATH", ""); path != "" {
+		body, err := os.ReadFile(path)
+		if err != nil {
+			ctx.Error(err)
+			return
+		}
+		ctx.Data(http.StatusOK, "text/plain; charset=utf-8", body)
+		return
+	}
+	var builder strings.Builder
+	builder.WriteString("User-agent: *\n")
+	for _, path := range strings.Split(utils.GetEnv("ROBOTS_DISALLOW", "/auth/,/login/,/swagger/"), ",") {
+		if path = strings.TrimSpace(path); path != "" {
+			builder.WriteString("Disallow: " + path + "\n")
+		}
+	}
+	builder.WriteString("\nSitemap: " + utils.SiteURL() + "/sitemap.xml\n")
+	ctx.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(builder.String()))
+}
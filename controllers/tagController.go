@@ -0,0 +1,42 @@
+package controllers
+
+import (
+	"blogspot-project/presenters"
+	"blogspot-project/services"
+	"blogspot-project/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TagController struct {
+	tags *services.TagService
+}
+
+func NewTagController(tags *services.TagService) *TagController {
+	return &TagController{tags: tags}
+}
+
+// GetDetailTag godoc
+// @Summary Get tag with its posts.
+// @Description Get tag detail and the published posts tagged with it, newest first.
+// @Tags Tag
+// @Produce json
+// @Param slug path string true "Tag slug"
+// @Param   current_page      query    int        false        "current page for pagination"
+// @Param   page_size         query    int        false        "page size for pagination"
+// @Success 200 {object} map[string]interface{}
+// @Router /tag/{slug} [get]
+func (c *TagController) GetDetailTag(ctx *gin.Context) {
+	limit, offset, err := utils.GetPagination(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	tag, posts, details, err := c.tags.Get(ctx.Param("slug"), limit, offset)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Get tag detail success", "data": presenters.ToTag(tag), "posts": presenters.ToPosts(posts, details)})
+}
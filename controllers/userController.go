@@ -2,19 +2,30 @@ package controllers
 
 import (
 	"blogspot-project/models"
+	"blogspot-project/presenters"
+	"blogspot-project/services"
 	"blogspot-project/utils"
+	"blogspot-project/utils/apperror"
 	"blogspot-project/utils/token"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 )
 
 type UpdateUserInput struct {
-	Name     string `json:"name"`
-	Username string `json:"username"`
-	Email    string `json:"email" binding:"email"`
-	ImageUrl string `json:"image_url"`
+	Name     string `json:"name" binding:"max=255"`
+	Username string `json:"username" binding:"omitempty,username"`
+	Email    string `json:"email" binding:"required,email,max=255"`
+	// not needed when an uploaded avatar is chosen
+	ImageUrl      string `json:"image_url" binding:"required_without=AvatarMediaID,omitempty,url,max=255"`
+	AvatarMediaID *uint  `json:"avatar_media_id"`
+	// left unchanged when not sent
+	Bio         *string              `json:"bio"`
+	SocialLinks *[]models.SocialLink `json:"social_links" binding:"omitempty,dive"`
+}
+
+type UpdateUserRoleInput struct {
+	Role uint `json:"role" binding:"required"`
 }
 
 // GetCurrentUserProfile godoc
@@ -25,19 +36,23 @@ type UpdateUserInput struct {
 // @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
 // @Success 200 {object} map[string]interface{}
 // @Router /user/{id} [get]
-func GetCurrentUserProfile(ctx *gin.Context) {
-	u := models.User{}
-	db := ctx.MustGet("db").(*gorm.DB)
+func (c *UserController) GetCurrentUserProfile(ctx *gin.Context) {
 	id, err := token.ExtractTokenID(ctx)
 	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		ctx.Error(apperror.InvalidToken(err))
+		return
+	}
+	u, err := c.users.Find(id)
+	if err != nil {
+		ctx.Error(err)
 		return
 	}
-	if err := db.Where("id = ?", id).Take(&u).Error; err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	response, err := c.owner(u)
+	if err != nil {
+		ctx.Error(err)
 		return
 	}
-	ctx.JSON(http.StatusOK, gin.H{"message": "Get user profile success", "data": u})
+	ctx.JSON(http.StatusOK, gin.H{"message": "Get user profile success", "data": response})
 }
 
 // GetListUsers godoc
@@ -51,80 +66,56 @@ func GetCurrentUserProfile(ctx *gin.Context) {
 // @Param   input_search      query    string     false        "input text for search category"
 // @Success 200 {object} map[string]interface{}
 // @Router /user [get]
-func GetListUsers(ctx *gin.Context) {
-	var users []models.User
-	db := ctx.MustGet("db").(*gorm.DB)
+func (c *UserController) GetListUsers(ctx *gin.Context) {
 	id, err := token.ExtractTokenID(ctx)
 	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		ctx.Error(apperror.InvalidToken(err))
 		return
 	}
-	currentUser := models.User{}
-	if err := db.Where("id = ?", id).Take(&currentUser).Error; err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	limit, offset, err := utils.GetPagination(ctx)
+	if err != nil {
+		ctx.Error(err)
 		return
 	}
-	if currentUser.Role == models.ADMIN_USER_ROLE {
-		limit, offset, err := utils.GetPagination(ctx)
-		if err != nil {
-			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
-		}
-		if err := db.Where("id != ? AND name LIKE ?", id, "%"+ctx.Query("input_search")+"%").Limit(limit).Offset(offset).Find(&users).Error; err != nil {
-			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
-		}
-		ctx.JSON(http.StatusOK, gin.H{"message": "Get all users success", "data": users})
+	users, err := c.users.List(id, ctx.Query("input_search"), limit, offset)
+	if err != nil {
+		ctx.Error(err)
 		return
 	}
-	ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Only Admin can look list users"})
+	ctx.JSON(http.StatusOK, gin.H{"message": "Get all users success", "data": presenters.ToAdminUsers(users)})
 }
 
 // UpdateCurrentUser godoc
 // @Summary Update current user.
-// @Description Update current user without update password that have logged in into blog.
+// @Description Update current user without update password that have logged in into blog. Bio and social links are only changed when sent, send an empty value to clear them.
 // @Tags User
 // @Produce json
 // @Param Body body UpdateUserInput true "json body to update user profile for current existing user"
 // @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
 // @Success 200 {object} map[string]interface{}
 // @Router /login/update-current-user [patch]
-func UpdateCurrentUser(ctx *gin.Context) {
-	db := ctx.MustGet("db").(*gorm.DB)
+func (c *UserController) UpdateCurrentUser(ctx *gin.Context) {
 	id, err := token.ExtractTokenID(ctx)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-	oldUser := models.User{}
-	if err := db.Where("ID = ?", id).Take(&oldUser).Error; err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ctx.Error(apperror.InvalidToken(err))
 		return
 	}
 	var input UpdateUserInput
-	if err := ctx.BindJSON(&input); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-	if !utils.IsValidEmail(input.Email) {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid email (make sure email format correct)"})
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.Error(apperror.Binding(err))
 		return
 	}
-	if !utils.IsValidUrl(input.ImageUrl) {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid image url (make sure image url format correct)"})
+	savedUser, err := c.users.UpdateProfile(id, services.ProfileUpdate(input))
+	if err != nil {
+		ctx.Error(err)
 		return
 	}
-	updatedUser := models.User{
-		Name:     input.Name,
-		Username: input.Username,
-		Email:    input.Email,
-		ImageUrl: input.ImageUrl,
-	}
-	if err := db.Model(&oldUser).Updates(&updatedUser).Error; err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	response, err := c.owner(savedUser)
+	if err != nil {
+		ctx.Error(err)
 		return
 	}
-	ctx.JSON(http.StatusOK, gin.H{"message": "Success update current user data", "data": updatedUser})
+	ctx.JSON(http.StatusOK, gin.H{"message": "Success update current user data", "data": response})
 }
 
 // DeleteUser godoc
@@ -136,30 +127,108 @@ func UpdateCurrentUser(ctx *gin.Context) {
 // @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
 // @Success 200 {object} map[string]interface{}
 // @Router /user/{id} [delete]
-func DeleteUser(ctx *gin.Context) {
-	db := ctx.MustGet("db").(*gorm.DB)
+func (c *UserController) DeleteUser(ctx *gin.Context) {
 	id, err := token.ExtractTokenID(ctx)
 	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		ctx.Error(apperror.InvalidToken(err))
+		return
+	}
+	if err := c.users.Delete(id, utils.GetParamID(ctx, "id")); err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Delete User Success"})
+}
+
+// UpdateUserRole godoc
+// @Summary Change the role of a user.
+// @Description Change the role of a user (1 admin, 2 regular user, 3 moderator). Only admin can change roles.
+// @Tags User
+// @Produce json
+// @Param id path string true "User id"
+// @Param Body body UpdateUserRoleInput true "json body with the new role"
+// @Param Authorization header string true "Authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
+// @Success 200 {object} map[string]interface{}
+// @Router /user/{id}/role [patch]
+func (c *UserController) UpdateUserRole(ctx *gin.Context) {
+	id, err := token.ExtractTokenID(ctx)
+	if err != nil {
+		ctx.Error(apperror.InvalidToken(err))
+		return
+	}
+	var input UpdateUserRoleInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.Error(apperror.Binding(err))
+		return
+	}
+	user, err := c.users.UpdateRole(id, utils.GetParamID(ctx, "id"), input.Role)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "Success update user role", "data": presenters.ToAdminUser(user)})
+}
+
+// GetAuthorProfile godoc
+// @Summary Get public author profile.
+// @Description Get public profile of an author by username with bio, avatar, social links and counts, without private data like email or role.
+// @Tags User
+// @Produce json
+// @Param username path string true "Author username"
+// @Success 200 {object} map[string]interface{}
+// @Router /author/{username} [get]
+func (c *UserController) GetAuthorProfile(ctx *gin.Context) {
+	user, err := c.users.FindByUsername(ctx.Param("username"))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	avatars, err := c.users.Avatars(user)
+	if err != nil {
+		ctx.Error(err)
 		return
 	}
-	currentUser := models.User{}
-	if err := db.Where("id = ?", id).Take(&currentUser).Error; err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	stats, err := c.users.Stats(user.ID)
+	if err != nil {
+		ctx.Error(err)
 		return
 	}
-	if currentUser.Role == models.ADMIN_USER_ROLE {
-		user := models.User{}
-		if err := db.Where("id = ?", ctx.Param("id")).Take(&user).Error; err != nil {
-			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
-		}
-		if err := db.Delete(&user).Error; err != nil {
-			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
-		}
-		ctx.JSON(http.StatusOK, gin.H{"message": "Delete User Success"})
+	response := presenters.ToProfile(user, avatars, stats)
+	ctx.JSON(http.StatusOK, gin.H{"message": "Get author profile success", "data": response})
+}
+
+// GetAuthorPosts godoc
+// @Summary Get the published posts of an author.
+// @Description Get the published posts of an author by username, newest first.
+// @Tags User
+// @Produce json
+// @Param username path string true "Author username"
+// @Param Authorization header string false "Optional authorization. How to input in swagger : 'Bearer <insert_your_token_here>'"
+// @Param   current_page      query    int        false        "current page for pagination"
+// @Param   page_size         query    int        false        "page size for pagination"
+// @Success 200 {object} map[string]interface{}
+// @Router /author/{username}/posts [get]
+func (c *UserController) GetAuthorPosts(ctx *gin.Context) {
+	limit, offset, err := utils.GetPagination(ctx)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	user_id, err := token.ExtractOptionalTokenID(ctx)
+	if err != nil {
+		ctx.Error(apperror.InvalidToken(err))
+		return
+	}
+	posts, err := c.posts.ListByAuthor(ctx.Param("username"), limit, offset)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	details, err := c.posts.Details(posts, user_id)
+	if err != nil {
+		ctx.Error(err)
 		return
 	}
-	ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Only Admin can delete user"})
+	responses := presenters.ToPosts(posts, details)
+	ctx.JSON(http.StatusOK, gin.H{"message": "Get author posts success", "data": responses})
 }
@@ -16,6 +16,41 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/atom.xml": {
+            "get": {
+                "description": "RSS 2.0 (/feed.xml), Atom (/atom.xml) or JSON Feed (/feed.json) of the latest published posts. Narrower feeds exist per category, per author and per tag.",
+                "produces": [
+                    "text/xml",
+                    "application/json"
+                ],
+                "tags": [
+                    "Feed"
+                ],
+                "summary": "Feed of all published posts.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "full or summary content (default from FEED_MODE)",
+                        "name": "mode",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "304": {
+                        "description": "Not Modified",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
         "/auth/login": {
             "post": {
                 "description": "login into blog to get access all blog list and CRUD blogs",
@@ -50,14 +85,14 @@ const docTemplate = `{
         },
         "/auth/register": {
             "post": {
-                "description": "registering a user to get access blog.",
+                "description": "registering a user to get access blog. Admin and moderator roles are only granted with PATCH /user/{id}/role.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Auth"
                 ],
-                "summary": "Register new user or create new user (role 1 for admin, role 2 for non admin).",
+                "summary": "Register a new non admin user.",
                 "parameters": [
                     {
                         "description": "json body to register a user or create new user",
@@ -80,29 +115,23 @@ const docTemplate = `{
                 }
             }
         },
-        "/category": {
+        "/author/{username}": {
             "get": {
-                "description": "Get all categories.",
+                "description": "Get public profile of an author by username with bio, avatar, social links and counts, without private data like email or role.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Category"
+                    "User"
                 ],
-                "summary": "Get all Category list.",
+                "summary": "Get public author profile.",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
-                        "name": "Authorization",
-                        "in": "header",
+                        "description": "Author username",
+                        "name": "username",
+                        "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "input text for search category",
-                        "name": "input_search",
-                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -114,60 +143,65 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "post": {
-                "description": "create new category for post.",
+            }
+        },
+        "/author/{username}/atom.xml": {
+            "get": {
+                "description": "RSS 2.0, Atom or JSON Feed of the latest published posts written by an author.",
                 "produces": [
+                    "text/xml",
                     "application/json"
                 ],
                 "tags": [
-                    "Category"
+                    "Feed"
                 ],
-                "summary": "Create Category",
+                "summary": "Feed of published posts by an author.",
                 "parameters": [
                     {
-                        "description": "json body to create new category",
-                        "name": "Body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/controllers.CategoryInput"
-                        }
+                        "type": "string",
+                        "description": "Author username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
                     },
                     {
                         "type": "string",
-                        "description": "Authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
-                        "name": "Authorization",
-                        "in": "header",
-                        "required": true
+                        "description": "full or summary content (default from FEED_MODE)",
+                        "name": "mode",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "type": "string"
+                        }
+                    },
+                    "304": {
+                        "description": "Not Modified",
+                        "schema": {
+                            "type": "string"
                         }
                     }
                 }
             }
         },
-        "/category/{id}": {
-            "delete": {
-                "description": "Delete existing category by id.",
+        "/author/{username}/block": {
+            "post": {
+                "description": "Block a user. They can no longer comment on your posts, reply to your comments, mention you, follow you or see your reactions, and their posts and comments are hidden from you. Follows between you both are removed.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Category"
+                    "User"
                 ],
-                "summary": "Delete existing category.",
+                "summary": "Block a user.",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Category id",
-                        "name": "id",
+                        "description": "Username",
+                        "name": "username",
                         "in": "path",
                         "required": true
                     },
@@ -189,32 +223,23 @@ const docTemplate = `{
                     }
                 }
             },
-            "patch": {
-                "description": "Update existing category based on category id.",
+            "delete": {
+                "description": "Unblock a user. Removed follows are not restored.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Category"
+                    "User"
                 ],
-                "summary": "Update Category.",
+                "summary": "Unblock a user.",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Category id",
-                        "name": "id",
+                        "description": "Username",
+                        "name": "username",
                         "in": "path",
                         "required": true
                     },
-                    {
-                        "description": "the body to update existing category",
-                        "name": "Body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/controllers.CategoryInput"
-                        }
-                    },
                     {
                         "type": "string",
                         "description": "Authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
@@ -234,107 +259,114 @@ const docTemplate = `{
                 }
             }
         },
-        "/login/update-current-user": {
-            "patch": {
-                "description": "Update current user without update password that have logged in into blog.",
+        "/author/{username}/feed.json": {
+            "get": {
+                "description": "RSS 2.0, Atom or JSON Feed of the latest published posts written by an author.",
                 "produces": [
+                    "text/xml",
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Feed"
                 ],
-                "summary": "Update current user.",
+                "summary": "Feed of published posts by an author.",
                 "parameters": [
                     {
-                        "description": "json body to update user profile for current existing user",
-                        "name": "Body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/controllers.UpdateUserInput"
-                        }
+                        "type": "string",
+                        "description": "Author username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
                     },
                     {
                         "type": "string",
-                        "description": "Authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
-                        "name": "Authorization",
-                        "in": "header",
-                        "required": true
+                        "description": "full or summary content (default from FEED_MODE)",
+                        "name": "mode",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "type": "string"
+                        }
+                    },
+                    "304": {
+                        "description": "Not Modified",
+                        "schema": {
+                            "type": "string"
                         }
                     }
                 }
             }
         },
-        "/login/update-password": {
-            "patch": {
-                "description": "Ability user to change their password.",
+        "/author/{username}/feed.xml": {
+            "get": {
+                "description": "RSS 2.0, Atom or JSON Feed of the latest published posts written by an author.",
                 "produces": [
+                    "text/xml",
                     "application/json"
                 ],
                 "tags": [
-                    "Auth"
+                    "Feed"
                 ],
-                "summary": "Update password for current user.",
+                "summary": "Feed of published posts by an author.",
                 "parameters": [
                     {
-                        "description": "json body to update password for current existing user",
-                        "name": "Body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/controllers.UpdatePasswordInput"
-                        }
+                        "type": "string",
+                        "description": "Author username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
                     },
                     {
                         "type": "string",
-                        "description": "Authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
-                        "name": "Authorization",
-                        "in": "header",
-                        "required": true
+                        "description": "full or summary content (default from FEED_MODE)",
+                        "name": "mode",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "type": "string"
+                        }
+                    },
+                    "304": {
+                        "description": "Not Modified",
+                        "schema": {
+                            "type": "string"
                         }
                     }
                 }
             }
         },
-        "/post": {
-            "get": {
-                "description": "Get all categories.",
+        "/author/{username}/follow": {
+            "post": {
+                "description": "Follow a user to get their new posts in the personalized feed. The user is notified.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Post"
+                    "User"
                 ],
-                "summary": "Get all Blog Post list.",
+                "summary": "Follow an author.",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
-                        "name": "Authorization",
-                        "in": "header",
+                        "description": "Username",
+                        "name": "username",
+                        "in": "path",
                         "required": true
                     },
                     {
                         "type": "string",
-                        "description": "input text for search blog",
-                        "name": "input_search",
-                        "in": "query"
+                        "description": "Authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -347,24 +379,22 @@ const docTemplate = `{
                     }
                 }
             },
-            "post": {
-                "description": "create new blog post.",
+            "delete": {
+                "description": "Stop following a user.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Post"
+                    "User"
                 ],
-                "summary": "Create Blog Post",
+                "summary": "Unfollow an author.",
                 "parameters": [
                     {
-                        "description": "json body to create new blog post",
-                        "name": "Body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/controllers.PostInput"
-                        }
+                        "type": "string",
+                        "description": "Username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
                     },
                     {
                         "type": "string",
@@ -385,32 +415,35 @@ const docTemplate = `{
                 }
             }
         },
-        "/post/comment": {
-            "post": {
-                "description": "create new comment blog post based on post id.",
+        "/author/{username}/followers": {
+            "get": {
+                "description": "Get the users following a user, latest first.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Comment"
+                    "User"
                 ],
-                "summary": "Create Comment Blog Post from post id.",
+                "summary": "Get the followers of a user.",
                 "parameters": [
-                    {
-                        "description": "json body to create new comment post",
-                        "name": "Body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/controllers.InputComment"
-                        }
-                    },
                     {
                         "type": "string",
-                        "description": "Authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
-                        "name": "Authorization",
-                        "in": "header",
+                        "description": "Username",
+                        "name": "username",
+                        "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "current page for pagination",
+                        "name": "current_page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page size for pagination",
+                        "name": "page_size",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -424,37 +457,35 @@ const docTemplate = `{
                 }
             }
         },
-        "/post/comment/{id}/like/{status}": {
-            "post": {
-                "description": "like comment in existing post.",
+        "/author/{username}/following": {
+            "get": {
+                "description": "Get the users a user follows, latest first.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Like"
+                    "User"
                 ],
-                "summary": "Like comment",
+                "summary": "Get the users a user follows.",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Comment id",
-                        "name": "id",
+                        "description": "Username",
+                        "name": "username",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "type": "string",
-                        "description": "status 0/1 (dislike or like)",
-                        "name": "status",
-                        "in": "path",
-                        "required": true
+                        "type": "integer",
+                        "description": "current page for pagination",
+                        "name": "current_page",
+                        "in": "query"
                     },
                     {
-                        "type": "string",
-                        "description": "Authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
-                        "name": "Authorization",
-                        "in": "header",
-                        "required": true
+                        "type": "integer",
+                        "description": "page size for pagination",
+                        "name": "page_size",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -468,21 +499,21 @@ const docTemplate = `{
                 }
             }
         },
-        "/post/comment/{id}/user-dislikes": {
-            "get": {
-                "description": "Get all users who dislikes comment in blog post based on id.",
+        "/author/{username}/mute": {
+            "post": {
+                "description": "Mute a user. Their posts and comments are hidden from you and they no longer notify you. They are not told and can still interact with you.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Like"
+                    "User"
                 ],
-                "summary": "Get all User dislike comment blog.",
+                "summary": "Mute a user.",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Comment id",
-                        "name": "id",
+                        "description": "Username",
+                        "name": "username",
                         "in": "path",
                         "required": true
                     },
@@ -503,23 +534,21 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/post/comment/{id}/user-likes": {
-            "get": {
-                "description": "Get all users who likes comment in blog post based on id.",
+            },
+            "delete": {
+                "description": "Unmute a user.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Like"
+                    "User"
                 ],
-                "summary": "Get all User likes based on comment blog post id.",
+                "summary": "Unmute a user.",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Comment id",
-                        "name": "id",
+                        "description": "Username",
+                        "name": "username",
                         "in": "path",
                         "required": true
                     },
@@ -542,30 +571,41 @@ const docTemplate = `{
                 }
             }
         },
-        "/post/{id}": {
+        "/author/{username}/posts": {
             "get": {
-                "description": "Get post detail based on post id",
+                "description": "Get the published posts of an author by username, newest first.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Post"
+                    "User"
                 ],
-                "summary": "Get detail post by id",
+                "summary": "Get the published posts of an author.",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Post id",
-                        "name": "id",
+                        "description": "Author username",
+                        "name": "username",
                         "in": "path",
                         "required": true
                     },
                     {
                         "type": "string",
-                        "description": "Authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
+                        "description": "Optional authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
                         "name": "Authorization",
-                        "in": "header",
-                        "required": true
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "current page for pagination",
+                        "name": "current_page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page size for pagination",
+                        "name": "page_size",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -577,30 +617,67 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Delete existing blog post by id.",
+            }
+        },
+        "/author/{username}/reading-lists": {
+            "get": {
+                "description": "Get the public reading lists of a user by username.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Post"
+                    "Reading List"
                 ],
-                "summary": "Delete existing blog post.",
+                "summary": "Get the public reading lists of a user.",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Post id",
-                        "name": "id",
+                        "description": "Username",
+                        "name": "username",
                         "in": "path",
                         "required": true
-                    },
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/bookmarks": {
+            "get": {
+                "description": "Get the posts bookmarked by the current user, latest bookmark first. Bookmarked posts that are unpublished or hidden for now are left out and counted in unavailable_count.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Bookmark"
+                ],
+                "summary": "Get bookmarked posts.",
+                "parameters": [
                     {
                         "type": "string",
                         "description": "Authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
                         "name": "Authorization",
                         "in": "header",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "current page for pagination",
+                        "name": "current_page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page size for pagination",
+                        "name": "page_size",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -612,23 +689,54 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "patch": {
-                "description": "Update existing post without update password that have logged in into blog.",
+            }
+        },
+        "/category": {
+            "get": {
+                "description": "Get all categories.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Post"
+                    "Category"
                 ],
-                "summary": "Update existing post.",
+                "summary": "Get all Category list.",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Post id",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "input text for search category",
+                        "name": "input_search",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "create new category for post.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Category"
+                ],
+                "summary": "Create Category",
+                "parameters": [
+                    {
+                        "description": "json body to create new category",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.CategoryInput"
+                        }
                     },
                     {
                         "type": "string",
@@ -649,35 +757,34 @@ const docTemplate = `{
                 }
             }
         },
-        "/post/{id}/comment/": {
+        "/category/{id}": {
             "get": {
-                "description": "Get all comments based on post id.",
+                "description": "Get category detail and the published posts in it.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Comment"
+                    "Category"
                 ],
-                "summary": "Get all comments list.",
+                "summary": "Get category with its posts.",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Post id",
+                        "description": "Category id",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "type": "string",
-                        "description": "Authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
-                        "name": "Authorization",
-                        "in": "header",
-                        "required": true
+                        "type": "integer",
+                        "description": "current page for pagination",
+                        "name": "current_page",
+                        "in": "query"
                     },
                     {
-                        "type": "string",
-                        "description": "input text for search comment",
-                        "name": "input_search",
+                        "type": "integer",
+                        "description": "page size for pagination",
+                        "name": "page_size",
                         "in": "query"
                     }
                 ],
@@ -690,33 +797,24 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/post/{id}/comment/{comment_id}": {
+            },
             "delete": {
-                "description": "Delete existing comment in blog post by post id.",
+                "description": "Delete existing category by id.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Comment"
+                    "Category"
                 ],
-                "summary": "Delete existing comment blog post.",
+                "summary": "Delete existing category.",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Post id",
+                        "description": "Category id",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
-                    {
-                        "type": "string",
-                        "description": "Comment id",
-                        "name": "comment_id",
-                        "in": "path",
-                        "required": true
-                    },
                     {
                         "type": "string",
                         "description": "Authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
@@ -736,36 +834,29 @@ const docTemplate = `{
                 }
             },
             "patch": {
-                "description": "Update existing comment blog post based on comment id and post id.",
+                "description": "Update existing category based on category id.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Comment"
+                    "Category"
                 ],
-                "summary": "Update existing comment.",
+                "summary": "Update Category.",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Post id",
+                        "description": "Category id",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "type": "string",
-                        "description": "Comment id",
-                        "name": "comment_id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "json body to update existing comment in existing post",
+                        "description": "the body to update existing category",
                         "name": "Body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/controllers.UpdateCommentInput"
+                            "$ref": "#/definitions/controllers.CategoryInput"
                         }
                     },
                     {
@@ -787,101 +878,146 @@ const docTemplate = `{
                 }
             }
         },
-        "/post/{id}/like/{status}": {
-            "post": {
-                "description": "like existing post",
+        "/category/{id}/atom.xml": {
+            "get": {
+                "description": "RSS 2.0, Atom or JSON Feed of the latest published posts in a category.",
                 "produces": [
+                    "text/xml",
                     "application/json"
                 ],
                 "tags": [
-                    "Like"
+                    "Feed"
                 ],
-                "summary": "Like post",
+                "summary": "Feed of published posts in a category.",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Post id",
+                        "description": "Category id",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
                         "type": "string",
-                        "description": "status 0/1 (dislike or like)",
-                        "name": "status",
+                        "description": "full or summary content (default from FEED_MODE)",
+                        "name": "mode",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "304": {
+                        "description": "Not Modified",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/category/{id}/feed.json": {
+            "get": {
+                "description": "RSS 2.0, Atom or JSON Feed of the latest published posts in a category.",
+                "produces": [
+                    "text/xml",
+                    "application/json"
+                ],
+                "tags": [
+                    "Feed"
+                ],
+                "summary": "Feed of published posts in a category.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Category id",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
                         "type": "string",
-                        "description": "Authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
-                        "name": "Authorization",
-                        "in": "header",
-                        "required": true
+                        "description": "full or summary content (default from FEED_MODE)",
+                        "name": "mode",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "type": "string"
+                        }
+                    },
+                    "304": {
+                        "description": "Not Modified",
+                        "schema": {
+                            "type": "string"
                         }
                     }
                 }
             }
         },
-        "/post/{id}/user-dislikes": {
+        "/category/{id}/feed.xml": {
             "get": {
-                "description": "Get all users who dislikes in blog post based on id.",
+                "description": "RSS 2.0, Atom or JSON Feed of the latest published posts in a category.",
                 "produces": [
+                    "text/xml",
                     "application/json"
                 ],
                 "tags": [
-                    "Like"
+                    "Feed"
                 ],
-                "summary": "Get all User dislike blog.",
+                "summary": "Feed of published posts in a category.",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Post id",
+                        "description": "Category id",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
                         "type": "string",
-                        "description": "Authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
-                        "name": "Authorization",
-                        "in": "header",
-                        "required": true
+                        "description": "full or summary content (default from FEED_MODE)",
+                        "name": "mode",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "type": "string"
+                        }
+                    },
+                    "304": {
+                        "description": "Not Modified",
+                        "schema": {
+                            "type": "string"
                         }
                     }
                 }
             }
         },
-        "/post/{id}/user-likes": {
-            "get": {
-                "description": "Get all users who likes comment in blog post based on id.",
+        "/category/{id}/follow": {
+            "post": {
+                "description": "Follow a category to get its new posts in the personalized feed and the weekly email digest.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Like"
+                    "Category"
                 ],
-                "summary": "Get all User like based on blog post id.",
+                "summary": "Follow a category.",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Post id",
+                        "description": "Category id",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -903,24 +1039,2889 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/user": {
-            "get": {
-                "description": "Get all users that have been registered except current user.",
+            },
+            "delete": {
+                "description": "Stop following a category.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "User"
+                    "Category"
                 ],
-                "summary": "Get all User list.",
+                "summary": "Unfollow a category.",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
-                        "name": "Authorization",
-                        "in": "header",
+                        "description": "Category id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/email/unsubscribe": {
+            "get": {
+                "description": "Tell which email list an unsubscribe link is for, so the client can ask for confirmation. Nothing changes, mail scanners open links with GET.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Email"
+                ],
+                "summary": "Check an unsubscribe link.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "unsubscribe token from the email",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Turn off the email list of an unsubscribe link. Also the target of the one-click List-Unsubscribe-Post header (RFC 8058), so no login is needed.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Email"
+                ],
+                "summary": "Unsubscribe from an email list.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "unsubscribe token from the email",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/feed": {
+            "get": {
+                "description": "Get the latest published posts of the authors and categories the current user follows, newest first. Posts of blocked or muted users are left out.\nThe feed is cached for FEED_CACHE_SECONDS, new posts can take that long to show up.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Post"
+                ],
+                "summary": "Get the personalized feed.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "current page for pagination",
+                        "name": "current_page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page size for pagination",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/feed.json": {
+            "get": {
+                "description": "RSS 2.0 (/feed.xml), Atom (/atom.xml) or JSON Feed (/feed.json) of the latest published posts. Narrower feeds exist per category, per author and per tag.",
+                "produces": [
+                    "text/xml",
+                    "application/json"
+                ],
+                "tags": [
+                    "Feed"
+                ],
+                "summary": "Feed of all published posts.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "full or summary content (default from FEED_MODE)",
+                        "name": "mode",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "304": {
+                        "description": "Not Modified",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/feed.xml": {
+            "get": {
+                "description": "RSS 2.0 (/feed.xml), Atom (/atom.xml) or JSON Feed (/feed.json) of the latest published posts. Narrower feeds exist per category, per author and per tag.",
+                "produces": [
+                    "text/xml",
+                    "application/json"
+                ],
+                "tags": [
+                    "Feed"
+                ],
+                "summary": "Feed of all published posts.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "full or summary content (default from FEED_MODE)",
+                        "name": "mode",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "304": {
+                        "description": "Not Modified",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/login/update-current-user": {
+            "patch": {
+                "description": "Update current user without update password that have logged in into blog. Bio and social links are only changed when sent, send an empty value to clear them.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Update current user.",
+                "parameters": [
+                    {
+                        "description": "json body to update user profile for current existing user",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.UpdateUserInput"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/login/update-password": {
+            "patch": {
+                "description": "Ability user to change their password.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Update password for current user.",
+                "parameters": [
+                    {
+                        "description": "json body to update password for current existing user",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.UpdatePasswordInput"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/media": {
+            "get": {
+                "description": "Get all media uploaded by the user that have logged in, newest first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Media"
+                ],
+                "summary": "Get media library of current user.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "current page for pagination",
+                        "name": "current_page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page size for pagination",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Upload a file with multipart form field \"file\". The type is detected from the content, uploading the same file twice returns the existing media.\nMetadata such as EXIF and GPS is stripped from images, and resized WebP variants are generated, plus square crops when purpose is avatar.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Media"
+                ],
+                "summary": "Upload media file.",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "file to upload",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "avatar to also generate square avatar crops",
+                        "name": "purpose",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/media/{id}": {
+            "delete": {
+                "description": "Delete a media file uploaded by the user that have logged in.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Media"
+                ],
+                "summary": "Delete own media.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Media id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/moderation/comments": {
+            "get": {
+                "description": "Get comments by moderation status (1 pending, 2 approved, 3 spam, 4 rejected), oldest first. Only moderators and admins can see the queue.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Moderation"
+                ],
+                "summary": "Get the comment moderation queue.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "moderation status, pending by default",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "current page for pagination",
+                        "name": "current_page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page size for pagination",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Apply a moderation action (approve, spam or reject) to several comments at once. Approve and spam decisions train the spam classifier.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Moderation"
+                ],
+                "summary": "Approve, reject or mark comments as spam.",
+                "parameters": [
+                    {
+                        "description": "json body with the comment ids and the action",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ModerateCommentsInput"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/moderation/policy": {
+            "get": {
+                "description": "Get the site wide rules that decide which new comments are held for moderation.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Moderation"
+                ],
+                "summary": "Get the comment moderation policy.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Update the site wide moderation rules, fields that are left out keep their value. Only admin can change the policy.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Moderation"
+                ],
+                "summary": "Update the comment moderation policy.",
+                "parameters": [
+                    {
+                        "description": "json body with the rules to change",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ModerationPolicyInput"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/moderation/reports": {
+            "get": {
+                "description": "Get reports by status (1 open, 2 resolved, 3 dismissed), oldest first. Only moderators and admins can see reports.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Moderation"
+                ],
+                "summary": "Get reports to triage.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "report status, open by default",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "only reports on post or comment",
+                        "name": "target_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "current page for pagination",
+                        "name": "current_page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page size for pagination",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/moderation/reports/{id}/resolve": {
+            "post": {
+                "description": "Resolve a report and every other open report on the same content. Actions: dismiss (the content stays, and is shown again if reports hid it), hide, delete, warn (hide and warn the author) or suspend (hide and suspend the author for suspend_days, REPORT_SUSPEND_DAYS by default).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Moderation"
+                ],
+                "summary": "Resolve a report.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Report id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "json body with the action to take",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ResolveReportInput"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/moderation/users/{id}/reports": {
+            "get": {
+                "description": "Get the reports filed by a user and the reports against their content, with their warning count and suspension. Only moderators and admins can see it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Moderation"
+                ],
+                "summary": "Get the report history of a user.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/notifications": {
+            "get": {
+                "description": "Get the notifications of the current user, most recently updated first. Likes, comments, replies and follows on the same target are grouped while unread.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Notification"
+                ],
+                "summary": "Get the notification inbox.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "only unread notifications",
+                        "name": "unread",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "current page for pagination",
+                        "name": "current_page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page size for pagination",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/notifications/preferences": {
+            "get": {
+                "description": "Get which notifications the current user receives.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Notification"
+                ],
+                "summary": "Get notification preferences.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Turn notification types on or off for the current user, fields that are left out keep their value.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Notification"
+                ],
+                "summary": "Update notification preferences.",
+                "parameters": [
+                    {
+                        "description": "json body with the preferences to change",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.NotificationPreferenceInput"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/notifications/read-all": {
+            "post": {
+                "description": "Mark all unread notifications of the current user as read.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Notification"
+                ],
+                "summary": "Mark every notification as read.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/notifications/unread-count": {
+            "get": {
+                "description": "Get how many notifications of the current user are unread.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Notification"
+                ],
+                "summary": "Get the number of unread notifications.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/notifications/{id}/read": {
+            "post": {
+                "description": "Mark one notification of the current user as read.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Notification"
+                ],
+                "summary": "Mark a notification as read.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Notification id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/post": {
+            "get": {
+                "description": "Get all published posts, plus the caller's own drafts when a token is sent. Posts of users the caller blocked or muted are left out.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Post"
+                ],
+                "summary": "Get all Blog Post list.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Optional authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
+                        "name": "Authorization",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "current page for pagination",
+                        "name": "current_page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page size for pagination",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "input text for search blog",
+                        "name": "input_search",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "create new blog post (status 1 for draft, 2 for published, default published). Up to 10 tags can be given by name, new tags are created.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Post"
+                ],
+                "summary": "Create Blog Post",
+                "parameters": [
+                    {
+                        "description": "json body to create new blog post",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.PostInput"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/post/comment": {
+            "post": {
+                "description": "create new comment blog post based on post id, or a reply when parent_id is set.\nReplies deeper than COMMENT_MAX_DEPTH are attached next to their parent instead. Users blocked by the author of the post or of the parent comment cannot comment.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Comment"
+                ],
+                "summary": "Create Comment Blog Post from post id.",
+                "parameters": [
+                    {
+                        "description": "json body to create new comment post",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.InputComment"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/post/comment/{id}/like/{status}": {
+            "post": {
+                "description": "like comment in existing post, the same as reacting with like or dislike.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Like"
+                ],
+                "summary": "Like comment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comment id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "status 0/1 (dislike or like)",
+                        "name": "status",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/post/comment/{id}/reaction": {
+            "put": {
+                "description": "Set the reaction of the current user on a comment, replacing their previous one.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reaction"
+                ],
+                "summary": "React to a comment.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comment id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "json body with the reaction type",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ReactionInput"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Remove the reaction of the current user on a comment, whatever it was.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reaction"
+                ],
+                "summary": "Remove a reaction from a comment.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comment id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/post/comment/{id}/reactions": {
+            "get": {
+                "description": "Get the users who reacted to a comment, optionally only those with the given reaction. Users who blocked the caller are left out.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reaction"
+                ],
+                "summary": "Get the users who reacted to a comment.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comment id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "reaction type",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "current page for pagination",
+                        "name": "current_page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page size for pagination",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/post/comment/{id}/user-dislikes": {
+            "get": {
+                "description": "Get all users who dislikes comment in blog post based on id.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Like"
+                ],
+                "summary": "Get all User dislike comment blog.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comment id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/post/comment/{id}/user-likes": {
+            "get": {
+                "description": "Get all users who likes comment in blog post based on id.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Like"
+                ],
+                "summary": "Get all User likes based on comment blog post id.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comment id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/post/{id}": {
+            "get": {
+                "description": "Get post detail based on post id",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Post"
+                ],
+                "summary": "Get detail post by id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Post id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Optional authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
+                        "name": "Authorization",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete existing blog post by id.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Post"
+                ],
+                "summary": "Delete existing blog post.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Post id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Update existing post without update password that have logged in into blog. Tags, when given, replace the tags of the post.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Post"
+                ],
+                "summary": "Update existing post.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Post id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "json body to update existing blog post",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.PostUpdate"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/post/{id}/bookmark": {
+            "post": {
+                "description": "Save a post to read later. Bookmarking a post twice keeps the first bookmark.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Bookmark"
+                ],
+                "summary": "Bookmark a post.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Post id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Remove a post from the bookmarks of the current user.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Bookmark"
+                ],
+                "summary": "Remove a bookmark.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Post id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/post/{id}/comment/": {
+            "get": {
+                "description": "Get all comments based on post id. Comments of users the caller blocked or muted are left out.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Comment"
+                ],
+                "summary": "Get all comments list.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Post id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Optional authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
+                        "name": "Authorization",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "current page for pagination",
+                        "name": "current_page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page size for pagination",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "input text for search comment",
+                        "name": "input_search",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/post/{id}/comment/tree": {
+            "get": {
+                "description": "Get a page of top level comments of a post, each with all of its nested replies. Comments of users the caller blocked or muted are left out with their replies.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Comment"
+                ],
+                "summary": "Get comments of a post as a tree.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Post id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Optional authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
+                        "name": "Authorization",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "current page of top level comments",
+                        "name": "current_page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "number of top level comments per page",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/post/{id}/comment/{comment_id}": {
+            "delete": {
+                "description": "Delete existing comment in blog post by post id. A comment can be deleted by its author, the author of the post, or a moderator. A comment with replies is kept as a \"[deleted]\" placeholder so the replies are not orphaned.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Comment"
+                ],
+                "summary": "Delete existing comment blog post.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Post id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comment id",
+                        "name": "comment_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Update existing comment blog post based on comment id and post id. Only the author can edit a comment, and only within COMMENT_EDIT_WINDOW_MINUTES after posting it. The previous content is kept in the edit history.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Comment"
+                ],
+                "summary": "Update existing comment.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Post id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comment id",
+                        "name": "comment_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "json body to update existing comment in existing post",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.UpdateCommentInput"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/post/{id}/comment/{comment_id}/history": {
+            "get": {
+                "description": "Get the previous versions of a comment, newest first. Only moderators and admins can see the edit history.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Comment"
+                ],
+                "summary": "Get the edit history of a comment.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Post id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comment id",
+                        "name": "comment_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/post/{id}/comment/{comment_id}/thread": {
+            "get": {
+                "description": "Get one comment and a page of its direct replies. Use reply_count and this endpoint again to load deeper replies. Replies of users the caller blocked or muted are left out.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Comment"
+                ],
+                "summary": "Get a comment with its direct replies.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Post id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comment id",
+                        "name": "comment_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Optional authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
+                        "name": "Authorization",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "current page for pagination",
+                        "name": "current_page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page size for pagination",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/post/{id}/like/{status}": {
+            "post": {
+                "description": "like existing post, the same as reacting with like or dislike.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Like"
+                ],
+                "summary": "Like post",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Post id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "status 0/1 (dislike or like)",
+                        "name": "status",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/post/{id}/reaction": {
+            "put": {
+                "description": "Set the reaction of the current user on a post, replacing their previous one.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reaction"
+                ],
+                "summary": "React to a post.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Post id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "json body with the reaction type",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ReactionInput"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Remove the reaction of the current user on a post, whatever it was.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reaction"
+                ],
+                "summary": "Remove a reaction from a post.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Post id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/post/{id}/reactions": {
+            "get": {
+                "description": "Get the users who reacted to a post, optionally only those with the given reaction. Users who blocked the caller are left out.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reaction"
+                ],
+                "summary": "Get the users who reacted to a post.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Post id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "reaction type",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "current page for pagination",
+                        "name": "current_page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page size for pagination",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/post/{id}/user-dislikes": {
+            "get": {
+                "description": "Get all users who dislikes in blog post based on id.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Like"
+                ],
+                "summary": "Get all User dislike blog.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Post id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/post/{id}/user-likes": {
+            "get": {
+                "description": "Get all users who likes comment in blog post based on id.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Like"
+                ],
+                "summary": "Get all User like based on blog post id.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Post id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/reactions": {
+            "get": {
+                "description": "Get the reactions users can leave on posts and comments, set with REACTION_TYPES.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reaction"
+                ],
+                "summary": "Get the reaction types.",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/reading-lists": {
+            "get": {
+                "description": "Get the reading lists of the current user, public and private.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reading List"
+                ],
+                "summary": "Get my reading lists.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a named reading list, private unless is_public is true.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reading List"
+                ],
+                "summary": "Create a reading list.",
+                "parameters": [
+                    {
+                        "description": "json body to create a reading list",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ReadingListInput"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/reading-lists/{id}": {
+            "get": {
+                "description": "Get a reading list with its posts in order. Private lists are only shown to their owner.\nPosts that are unpublished or hidden for now are left out, the owner gets how many in unavailable_count.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reading List"
+                ],
+                "summary": "Get a reading list.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Reading list id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Optional authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
+                        "name": "Authorization",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a reading list of the current user. The posts themselves are not touched.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reading List"
+                ],
+                "summary": "Delete a reading list.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Reading list id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Rename a reading list, change its description or make it public or private. Only given fields are changed.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reading List"
+                ],
+                "summary": "Update a reading list.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Reading list id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "json body to update a reading list",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ReadingListUpdate"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/reading-lists/{id}/order": {
+            "put": {
+                "description": "Set the order of a reading list. post_ids must hold every post of the list once, in the new order.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reading List"
+                ],
+                "summary": "Reorder a reading list.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Reading list id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "json body with the post ids in order",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ReadingListOrderInput"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/reading-lists/{id}/posts": {
+            "post": {
+                "description": "Add a post with an optional note. Without a position the post goes last, otherwise the posts from that position on move down one.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reading List"
+                ],
+                "summary": "Add a post to a reading list.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Reading list id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "json body with the post to add",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ReadingListItemInput"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/reading-lists/{id}/posts/{post_id}": {
+            "delete": {
+                "description": "Remove a post from a reading list, the posts after it move up one.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reading List"
+                ],
+                "summary": "Remove a post from a reading list.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Reading list id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Post id",
+                        "name": "post_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Change the note of a post in a reading list or move it to another position.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reading List"
+                ],
+                "summary": "Update a post in a reading list.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Reading list id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Post id",
+                        "name": "post_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "json body with the note or position",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ReadingListItemUpdate"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/realtime/events": {
+            "get": {
+                "description": "Stream new comments and like counts of the posts given in posts, and the notifications of the current user. Browsers can pass the token in the token query parameter.\nReconnecting clients send the Last-Event-ID header (or last_event_id) to get the events they missed, a reset event means some are gone and the state should be fetched again.\nSlow clients are disconnected and have to reconnect. A comment line is sent every REALTIME_HEARTBEAT_SECONDS.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "Realtime"
+                ],
+                "summary": "Stream updates with Server-Sent Events.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
+                        "name": "Authorization",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "jwt token, for clients that cannot set headers",
+                        "name": "token",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "comma separated ids of the posts to follow",
+                        "name": "posts",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "id of the last event received",
+                        "name": "last_event_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/realtime/ws": {
+            "get": {
+                "description": "Same events as /realtime/events, sent as JSON messages {id, topic, type, data}. Pass the token in the token query parameter and the id of the last event received in last_event_id when reconnecting.\nThe server pings every REALTIME_HEARTBEAT_SECONDS and closes connections that stop answering or fall behind.\nBrowser pages are only accepted from SITE_URL or one of the comma separated ALLOWED_ORIGINS.",
+                "tags": [
+                    "Realtime"
+                ],
+                "summary": "Stream updates over a WebSocket.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "jwt token",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "comma separated ids of the posts to follow",
+                        "name": "posts",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "id of the last event received",
+                        "name": "last_event_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "101": {
+                        "description": "Switching Protocols",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/report": {
+            "post": {
+                "description": "Flag a post or comment as abusive. target_type is post or comment, reason is one of spam, harassment, hate, violence, sexual, misinformation or other. Each user can report the same content once, and content reaching REPORT_HIDE_THRESHOLD open reports is hidden until a moderator resolves them.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Report"
+                ],
+                "summary": "Report a post or comment.",
+                "parameters": [
+                    {
+                        "description": "json body to report a post or comment",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.ReportInput"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/robots.txt": {
+            "get": {
+                "description": "Serves the file at ROBOTS_TXT_PATH when set, otherwise rules built from ROBOTS_DISALLOW with a link to the sitemap.",
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "SEO"
+                ],
+                "summary": "robots.txt",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/sitemap.xml": {
+            "get": {
+                "description": "Sitemap of published posts, categories and author pages. Turns into a sitemap index pointing to /sitemap/{page}.xml once there are more than 50000 URLs.",
+                "produces": [
+                    "text/xml"
+                ],
+                "tags": [
+                    "SEO"
+                ],
+                "summary": "XML sitemap.",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/sitemap/{page}": {
+            "get": {
+                "description": "One page of a sitemap that has been split because it is over 50000 URLs.",
+                "produces": [
+                    "text/xml"
+                ],
+                "tags": [
+                    "SEO"
+                ],
+                "summary": "XML sitemap page.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Sitemap page, for example 1.xml",
+                        "name": "page",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/tag/{slug}": {
+            "get": {
+                "description": "Get tag detail and the published posts tagged with it, newest first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tag"
+                ],
+                "summary": "Get tag with its posts.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tag slug",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "current page for pagination",
+                        "name": "current_page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page size for pagination",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/tag/{slug}/atom.xml": {
+            "get": {
+                "description": "RSS 2.0, Atom or JSON Feed of the latest published posts tagged with a tag.",
+                "produces": [
+                    "text/xml",
+                    "application/json"
+                ],
+                "tags": [
+                    "Feed"
+                ],
+                "summary": "Feed of published posts with a tag.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tag slug",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "full or summary content (default from FEED_MODE)",
+                        "name": "mode",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "304": {
+                        "description": "Not Modified",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/tag/{slug}/feed.json": {
+            "get": {
+                "description": "RSS 2.0, Atom or JSON Feed of the latest published posts tagged with a tag.",
+                "produces": [
+                    "text/xml",
+                    "application/json"
+                ],
+                "tags": [
+                    "Feed"
+                ],
+                "summary": "Feed of published posts with a tag.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tag slug",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "full or summary content (default from FEED_MODE)",
+                        "name": "mode",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "304": {
+                        "description": "Not Modified",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/tag/{slug}/feed.xml": {
+            "get": {
+                "description": "RSS 2.0, Atom or JSON Feed of the latest published posts tagged with a tag.",
+                "produces": [
+                    "text/xml",
+                    "application/json"
+                ],
+                "tags": [
+                    "Feed"
+                ],
+                "summary": "Feed of published posts with a tag.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tag slug",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "full or summary content (default from FEED_MODE)",
+                        "name": "mode",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "304": {
+                        "description": "Not Modified",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/user": {
+            "get": {
+                "description": "Get all users that have been registered except current user.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Get all User list.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "current page for pagination",
+                        "name": "current_page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page size for pagination",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "input text for search category",
+                        "name": "input_search",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/user/blocks": {
+            "get": {
+                "description": "Get the users the current user blocked, latest first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Get blocked users.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/user/following/categories": {
+            "get": {
+                "description": "Get the categories the current user follows.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Category"
+                ],
+                "summary": "Get followed categories.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/user/mentions": {
+            "get": {
+                "description": "Get the mentions of the current user, newest first. Mentions by users the current user blocked or muted are left out.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Get the posts and comments mentioning the current user.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
+                        "name": "Authorization",
+                        "in": "header",
                         "required": true
                     },
                     {
@@ -934,12 +3935,36 @@ const docTemplate = `{
                         "description": "page size for pagination",
                         "name": "page_size",
                         "in": "query"
-                    },
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/user/mutes": {
+            "get": {
+                "description": "Get the users the current user muted, latest first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Get muted users.",
+                "parameters": [
                     {
                         "type": "string",
-                        "description": "input text for search category",
-                        "name": "input_search",
-                        "in": "query"
+                        "description": "Authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -1017,14 +4042,64 @@ const docTemplate = `{
                     }
                 }
             }
+        },
+        "/user/{id}/role": {
+            "patch": {
+                "description": "Change the role of a user (1 admin, 2 regular user, 3 moderator). Only admin can change roles.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Change the role of a user.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "json body with the new role",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/controllers.UpdateUserRoleInput"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Authorization. How to input in swagger : 'Bearer \u003cinsert_your_token_here\u003e'",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
         }
     },
     "definitions": {
         "controllers.CategoryInput": {
             "type": "object",
+            "required": [
+                "name"
+            ],
             "properties": {
                 "name": {
-                    "type": "string"
+                    "type": "string",
+                    "maxLength": 100
                 }
             }
         },
@@ -1036,7 +4111,11 @@ const docTemplate = `{
             ],
             "properties": {
                 "comment_content": {
-                    "type": "string"
+                    "type": "string",
+                    "maxLength": 5000
+                },
+                "parent_id": {
+                    "type": "integer"
                 },
                 "post_id": {
                     "type": "integer"
@@ -1058,6 +4137,76 @@ const docTemplate = `{
                 }
             }
         },
+        "controllers.ModerateCommentsInput": {
+            "type": "object",
+            "required": [
+                "action",
+                "comment_ids"
+            ],
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "comment_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "controllers.ModerationPolicyInput": {
+            "type": "object",
+            "properties": {
+                "auto_approve_trusted": {
+                    "type": "boolean"
+                },
+                "hold_first_comment": {
+                    "type": "boolean"
+                },
+                "hold_links": {
+                    "type": "boolean"
+                },
+                "spam_threshold": {
+                    "type": "number"
+                },
+                "trusted_comment_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "controllers.NotificationPreferenceInput": {
+            "type": "object",
+            "properties": {
+                "comments": {
+                    "type": "boolean"
+                },
+                "email_digest": {
+                    "type": "boolean"
+                },
+                "email_mentions": {
+                    "type": "boolean"
+                },
+                "email_replies": {
+                    "type": "boolean"
+                },
+                "follows": {
+                    "type": "boolean"
+                },
+                "likes": {
+                    "type": "boolean"
+                },
+                "mentions": {
+                    "type": "boolean"
+                },
+                "moderation": {
+                    "type": "boolean"
+                },
+                "replies": {
+                    "type": "boolean"
+                }
+            }
+        },
         "controllers.PostInput": {
             "type": "object",
             "required": [
@@ -1074,10 +4223,140 @@ const docTemplate = `{
                     "type": "string"
                 },
                 "article_title": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "category_id": {
+                    "type": "integer"
+                },
+                "featured_image_id": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "integer"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "controllers.PostUpdate": {
+            "type": "object",
+            "properties": {
+                "article_content": {
+                    "type": "string"
+                },
+                "article_description": {
                     "type": "string"
                 },
+                "article_title": {
+                    "type": "string",
+                    "maxLength": 255
+                },
                 "category_id": {
                     "type": "integer"
+                },
+                "featured_image_id": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "integer"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "controllers.ReactionInput": {
+            "type": "object",
+            "required": [
+                "type"
+            ],
+            "properties": {
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.ReadingListInput": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "is_public": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 255
+                }
+            }
+        },
+        "controllers.ReadingListItemInput": {
+            "type": "object",
+            "required": [
+                "post_id"
+            ],
+            "properties": {
+                "note": {
+                    "type": "string"
+                },
+                "position": {
+                    "type": "integer"
+                },
+                "post_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "controllers.ReadingListItemUpdate": {
+            "type": "object",
+            "properties": {
+                "note": {
+                    "type": "string"
+                },
+                "position": {
+                    "type": "integer"
+                }
+            }
+        },
+        "controllers.ReadingListOrderInput": {
+            "type": "object",
+            "required": [
+                "post_ids"
+            ],
+            "properties": {
+                "post_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "controllers.ReadingListUpdate": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "is_public": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "minLength": 1
                 }
             }
         },
@@ -1085,29 +4364,65 @@ const docTemplate = `{
             "type": "object",
             "required": [
                 "email",
+                "image_url",
                 "name",
                 "password",
-                "role",
                 "username"
             ],
             "properties": {
                 "email": {
-                    "type": "string"
+                    "type": "string",
+                    "maxLength": 255
                 },
                 "image_url": {
-                    "type": "string"
+                    "type": "string",
+                    "maxLength": 255
                 },
                 "name": {
-                    "type": "string"
+                    "type": "string",
+                    "maxLength": 255
                 },
                 "password": {
                     "type": "string"
                 },
-                "role": {
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.ReportInput": {
+            "type": "object",
+            "required": [
+                "reason",
+                "target_id",
+                "target_type"
+            ],
+            "properties": {
+                "message": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "target_id": {
                     "type": "integer"
                 },
-                "username": {
+                "target_type": {
+                    "type": "string"
+                }
+            }
+        },
+        "controllers.ResolveReportInput": {
+            "type": "object",
+            "required": [
+                "action"
+            ],
+            "properties": {
+                "action": {
                     "type": "string"
+                },
+                "suspend_days": {
+                    "type": "integer"
                 }
             }
         },
@@ -1118,7 +4433,8 @@ const docTemplate = `{
             ],
             "properties": {
                 "comment_content": {
-                    "type": "string"
+                    "type": "string",
+                    "maxLength": 5000
                 }
             }
         },
@@ -1139,20 +4455,68 @@ const docTemplate = `{
         },
         "controllers.UpdateUserInput": {
             "type": "object",
+            "required": [
+                "email"
+            ],
             "properties": {
-                "email": {
+                "avatar_media_id": {
+                    "type": "integer"
+                },
+                "bio": {
+                    "description": "left unchanged when not sent",
                     "type": "string"
                 },
+                "email": {
+                    "type": "string",
+                    "maxLength": 255
+                },
                 "image_url": {
-                    "type": "string"
+                    "description": "not needed when an uploaded avatar is chosen",
+                    "type": "string",
+                    "maxLength": 255
                 },
                 "name": {
-                    "type": "string"
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "social_links": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SocialLink"
+                    }
                 },
                 "username": {
                     "type": "string"
                 }
             }
+        },
+        "controllers.UpdateUserRoleInput": {
+            "type": "object",
+            "required": [
+                "role"
+            ],
+            "properties": {
+                "role": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.SocialLink": {
+            "type": "object",
+            "required": [
+                "label",
+                "url"
+            ],
+            "properties": {
+                "label": {
+                    "type": "string",
+                    "maxLength": 50
+                },
+                "url": {
+                    "type": "string",
+                    "maxLength": 255
+                }
+            }
         }
     }
 }`
@@ -4,6 +4,8 @@ import (
 	"blogspot-project/config"
 	"blogspot-project/docs"
 	"blogspot-project/routes"
+	"blogspot-project/utils/counters"
+	"blogspot-project/utils/mailer"
 	"log"
 
 	"github.com/joho/godotenv"
@@ -27,7 +29,12 @@ func main() {
 	}
 	defer sqlDb.Close()
 
+	// emails are sent in the background from the outbox
+	go mailer.Run(db, config.ConnectMailer())
+	// like counters drifted by earlier bugs or manual edits are repaired
+	go counters.Run(db)
+
 	// route setup
-	r := routes.SetupRouter(db)
+	r := routes.SetupRouter(db, config.ConnectStorage())
 	r.Run()
 }
@@ -1,17 +1,47 @@
 package middlewares
 
 import (
+	"blogspot-project/utils/apperror"
 	"blogspot-project/utils/token"
-	"net/http"
 
 	"github.com/gin-gonic/gin"
 )
 
+// ErrorHandler writes the last error a handler added with ctx.Error as a
+// problem, unless the handler already answered.
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		if len(c.Errors) > 0 && !c.Writer.Written() {
+			apperror.Respond(c, c.Errors.Last().Err)
+		}
+	}
+}
+
 func JwtAuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		err := token.TokenValid(c)
 		if err != nil {
-			c.String(http.StatusUnauthorized, err.Error())
+			c.Error(apperror.InvalidToken(err))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// OptionalJwtAuthMiddleware lets anonymous requests through but still rejects
+// a token that is present and invalid, so clients notice an expired session
+// instead of silently losing personalized data.
+func OptionalJwtAuthMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token.GetTokenString(c) == "" {
+			c.Next()
+			return
+		}
+		err := token.TokenValid(c)
+		if err != nil {
+			c.Error(apperror.InvalidToken(err))
 			c.Abort()
 			return
 		}
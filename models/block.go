@@ -0,0 +1,75 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// UserBlock is a user blocking another one. Blocked users cannot comment on
+// the posts or reply to the comments of the blocker, mention or follow them,
+// or see their reactions, and the blocker no longer sees their content.
+type UserBlock struct {
+	UserID    uint      `json:"user_id" gorm:"primary_key;autoIncrement:false"`
+	BlockedID uint      `json:"blocked_id" gorm:"primary_key;autoIncrement:false;index"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// UserMute is a user muting another one. The posts and comments of muted
+// users are left out of the views of the muting user and they no longer
+// notify them, without the muted user noticing.
+type UserMute struct {
+	UserID    uint      `json:"user_id" gorm:"primary_key;autoIncrement:false"`
+	MutedID   uint      `json:"muted_id" gorm:"primary_key;autoIncrement:false;index"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// HasBlocked is true when the user blocked the other one.
+func HasBlocked(db *gorm.DB, user_id, other_id uint) (bool, error) {
+	var count int64 = 0
+	err := db.Model(&UserBlock{}).Where("user_id = ? AND blocked_id = ?", user_id, other_id).Count(&count).Error
+	return count > 0, err
+}
+
+// HasBlockBetween is true when either user blocked the other one.
+func HasBlockBetween(db *gorm.DB, user_id, other_id uint) (bool, error) {
+	var count int64 = 0
+	err := db.Model(&UserBlock{}).Where("(user_id = ? AND blocked_id = ?) OR (user_id = ? AND blocked_id = ?)", user_id, other_id, other_id, user_id).Count(&count).Error
+	return count > 0, err
+}
+
+// HasHidden is true when the user blocked or muted the other one.
+func HasHidden(db *gorm.DB, user_id, other_id uint) (bool, error) {
+	blocked, err := HasBlocked(db, user_id, other_id)
+	if err != nil || blocked {
+		return blocked, err
+	}
+	var count int64 = 0
+	err = db.Model(&UserMute{}).Where("user_id = ? AND muted_id = ?", user_id, other_id).Count(&count).Error
+	return count > 0, err
+}
+
+// WithoutHiddenUsers leaves out the rows whose column holds a user the given
+// user blocked or muted. Anonymous users (user_id 0) hide nobody.
+func WithoutHiddenUsers(column string, user_id uint) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if user_id == 0 {
+			return db
+		}
+		blocked := db.Session(&gorm.Session{NewDB: true}).Model(&UserBlock{}).Select("blocked_id").Where("user_id = ?", user_id)
+		muted := db.Session(&gorm.Session{NewDB: true}).Model(&UserMute{}).Select("muted_id").Where("user_id = ?", user_id)
+		return db.Where(column+" NOT IN (?) AND "+column+" NOT IN (?)", blocked, muted)
+	}
+}
+
+// WithoutBlockers leaves out the rows whose column holds a user who blocked
+// the given user.
+func WithoutBlockers(column string, user_id uint) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if user_id == 0 {
+			return db
+		}
+		blockers := db.Session(&gorm.Session{NewDB: true}).Model(&UserBlock{}).Select("user_id").Where("blocked_id = ?", user_id)
+		return db.Where(column+" NOT IN (?)", blockers)
+	}
+}
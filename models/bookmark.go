@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// Bookmark is a post saved by a user to read later. Bookmarks of posts that
+// get unpublished or hidden are kept, they show again when the post is back.
+type Bookmark struct {
+	UserID    uint      `json:"user_id" gorm:"primary_key;autoIncrement:false"`
+	PostID    uint      `json:"post_id" gorm:"primary_key;autoIncrement:false;index"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ReadingList is a named collection of posts. Only its owner sees it unless
+// it is public.
+type ReadingList struct {
+	ID          uint      `json:"id" gorm:"primary_key"`
+	UserID      uint      `json:"user_id" gorm:"not null;index"`
+	Name        string    `json:"name" gorm:"size:255;not null"`
+	Description string    `json:"description" gorm:"text"`
+	IsPublic    bool      `json:"is_public" gorm:"not null;default:false"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// ReadingListItem is a post in a reading list. Positions start at 1 and have
+// no gaps.
+type ReadingListItem struct {
+	ReadingListID uint      `json:"reading_list_id" gorm:"primary_key;autoIncrement:false"`
+	PostID        uint      `json:"post_id" gorm:"primary_key;autoIncrement:false;index"`
+	Position      int       `json:"position" gorm:"not null"`
+	Note          string    `json:"note" gorm:"size:1000"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// ReadingListDetails is what a reading list response needs besides the list:
+// the owners by user id with their avatars by media id, and the number of
+// posts the caller can read by list id.
+type ReadingListDetails struct {
+	Owners     map[uint]User
+	Avatars    map[uint]Media
+	PostCounts map[uint]int64
+}
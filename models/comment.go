@@ -1,17 +1,68 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"blogspot-project/utils"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type Comment struct {
 	gorm.Model
-	UserID              uint   `json:"user_id" gorm:"not null"`
-	PostID              uint   `json:"post_id" gorm:"not null"`
-	CommentContent      string `json:"comment_content" gorm:"text;not null"`
-	CommentLikeCount    uint   `json:"comment_like_count" gorm:"not null;default:0"`
-	CommentDislikeCount uint   `json:"comment_dislike_count" gorm:"not null;default:0"`
+	UserID              uint       `json:"user_id" gorm:"not null"`
+	PostID              uint       `json:"post_id" gorm:"not null"`
+	CommentContent      string     `json:"comment_content" gorm:"text;not null"`
+	RenderedContent     string     `json:"rendered_content" gorm:"text"`
+	CommentLikeCount    uint       `json:"comment_like_count" gorm:"not null;default:0"`
+	CommentDislikeCount uint       `json:"comment_dislike_count" gorm:"not null;default:0"`
+	ParentID            *uint      `json:"parent_id" gorm:"index"`
+	ThreadID            uint       `json:"thread_id" gorm:"not null;default:0;index"`
+	Depth               uint       `json:"depth" gorm:"not null;default:0"`
+	ReplyCount          uint       `json:"reply_count" gorm:"not null;default:0"`
+	IsDeleted           bool       `json:"is_deleted" gorm:"not null;default:false"`
+	EditedAt            *time.Time `json:"edited_at"`
+	ModerationStatus    uint       `json:"moderation_status" gorm:"not null;default:2;index"`
+	SpamScore           float64    `json:"-" gorm:"not null;default:0"`
+	SpamLabel           uint       `json:"-" gorm:"not null;default:0"`
 
 	// relationship
 	User            User              `json:"-"`
 	Post            Post              `json:"-"`
 	UserLikeComment []UserLikeComment `json:"-"`
+	Edits           []CommentEdit     `json:"-"`
+}
+
+// CommentEdit keeps the content a comment had before an edit. The history is
+// only shown to moderators.
+type CommentEdit struct {
+	ID              uint      `json:"id" gorm:"primary_key"`
+	CommentID       uint      `json:"comment_id" gorm:"not null;index"`
+	EditorID        uint      `json:"editor_id" gorm:"not null"`
+	PreviousContent string    `json:"previous_content" gorm:"text;not null"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// DELETED_COMMENT_CONTENT replaces the content of a deleted comment that
+// still has replies, so the thread below it stays readable.
+const DELETED_COMMENT_CONTENT = "[deleted]"
+
+// CommentMaxDepth is how deep replies may nest (COMMENT_MAX_DEPTH), top
+// level comments have depth 0.
+func CommentMaxDepth() uint {
+	depth, err := strconv.Atoi(utils.GetEnv("COMMENT_MAX_DEPTH", "5"))
+	if err != nil || depth < 0 {
+		return 5
+	}
+	return uint(depth)
+}
+
+// CommentEditWindow is how long after posting the author may still edit a
+// comment (COMMENT_EDIT_WINDOW_MINUTES), 0 means there is no limit.
+func CommentEditWindow() time.Duration {
+	minutes, err := strconv.Atoi(utils.GetEnv("COMMENT_EDIT_WINDOW_MINUTES", "15"))
+	if err != nil || minutes < 0 {
+		return 15 * time.Minute
+	}
+	return time.Duration(minutes) * time.Minute
 }
@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+const EMAIL_STATUS_PENDING = 1
+const EMAIL_STATUS_SENT = 2
+const EMAIL_STATUS_FAILED = 3
+
+// Email lists a user can unsubscribe from.
+const EMAIL_LIST_REPLIES = "replies"
+const EMAIL_LIST_MENTIONS = "mentions"
+const EMAIL_LIST_DIGEST = "digest"
+
+// EmailOutbox is an email waiting to be sent, written in the same
+// transaction as whatever caused it so nothing is lost when the mail server
+// is down. Failed sends are retried until MAIL_MAX_ATTEMPTS.
+type EmailOutbox struct {
+	ID            uint       `json:"id" gorm:"primary_key"`
+	UserID        uint       `json:"user_id" gorm:"not null;index"`
+	List          string     `json:"list" gorm:"size:16"`
+	ToAddress     string     `json:"to_address" gorm:"not null"`
+	Subject       string     `json:"subject" gorm:"not null"`
+	TextBody      string     `json:"-" gorm:"type:text"`
+	HTMLBody      string     `json:"-" gorm:"type:text"`
+	Headers       string     `json:"-" gorm:"type:text"`
+	Status        uint       `json:"status" gorm:"not null;default:1;index:idx_email_outbox_due"`
+	Attempts      uint       `json:"attempts" gorm:"not null;default:0"`
+	NextAttemptAt time.Time  `json:"next_attempt_at" gorm:"index:idx_email_outbox_due"`
+	LastError     string     `json:"last_error" gorm:"type:text"`
+	SentAt        *time.Time `json:"sent_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+func (EmailOutbox) TableName() string {
+	return "email_outbox"
+}
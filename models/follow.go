@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// CategoryFollow is a user following a category, used for the weekly digest
+// and the personalized feed.
+type CategoryFollow struct {
+	UserID     uint      `json:"user_id" gorm:"primary_key;autoIncrement:false"`
+	CategoryID uint      `json:"category_id" gorm:"primary_key;autoIncrement:false;index"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// UserFollow is a user following an author, whose posts then show in the
+// personalized feed of the follower.
+type UserFollow struct {
+	FollowerID  uint      `json:"follower_id" gorm:"primary_key;autoIncrement:false"`
+	FollowingID uint      `json:"following_id" gorm:"primary_key;autoIncrement:false;index"`
+	CreatedAt   time.Time `json:"created_at"`
+}
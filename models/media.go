@@ -0,0 +1,77 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+type Media struct {
+	gorm.Model
+	UserID     uint   `json:"user_id" gorm:"not null;uniqueIndex:idx_media_user_checksum"`
+	FileName   string `json:"file_name" gorm:"size:255;not null"`
+	MimeType   string `json:"mime_type" gorm:"size:100;not null"`
+	Size       int64  `json:"size" gorm:"not null"`
+	Width      int    `json:"width" gorm:"not null;default:0"`
+	Height     int    `json:"height" gorm:"not null;default:0"`
+	Checksum   string `json:"checksum" gorm:"size:64;not null;uniqueIndex:idx_media_user_checksum"`
+	StorageKey string `json:"-" gorm:"size:255;not null"`
+	Url        string `json:"url" gorm:"size:500;not null"`
+
+	// Relationship
+	User     User           `json:"-"`
+	Variants []MediaVariant `json:"variants" gorm:"constraint:OnDelete:CASCADE;"`
+}
+
+// MediaVariant is a processed derivative of an uploaded image, a resized
+// copy or a square avatar crop, always re-encoded without metadata.
+type MediaVariant struct {
+	ID         uint   `json:"id" gorm:"primary_key"`
+	MediaID    uint   `json:"media_id" gorm:"not null;index"`
+	Kind       string `json:"kind" gorm:"size:20;not null"`
+	Width      int    `json:"width" gorm:"not null"`
+	Height     int    `json:"height" gorm:"not null"`
+	MimeType   string `json:"mime_type" gorm:"size:100;not null"`
+	Size       int64  `json:"size" gorm:"not null"`
+	StorageKey string `json:"-" gorm:"size:255;not null"`
+	Url        string `json:"url" gorm:"size:500;not null"`
+}
+
+const MEDIA_VARIANT_RESIZED = "resized"
+const MEDIA_VARIANT_AVATAR = "avatar"
+
+type ImageResponse struct {
+	MediaID uint   `json:"media_id"`
+	Url     string `json:"url"`
+	Width   int    `json:"width"`
+	Height  int    `json:"height"`
+	Srcset  string `json:"srcset"`
+}
+
+// Image describes the media for an <img> tag, with a srcset built from the
+// variants of the given kind.
+func (m Media) Image(kind string) ImageResponse {
+	variants := []MediaVariant{}
+	for _, variant := range m.Variants {
+		if variant.Kind == kind {
+			variants = append(variants, variant)
+		}
+	}
+	sort.Slice(variants, func(a, b int) bool { return variants[a].Width < variants[b].Width })
+	response := ImageResponse{MediaID: m.ID, Url: m.Url, Width: m.Width, Height: m.Height}
+	candidates := []string{}
+	for _, variant := range variants {
+		candidates = append(candidates, fmt.Sprintf("%v %vw", variant.Url, variant.Width))
+	}
+	if kind == MEDIA_VARIANT_AVATAR && len(variants) > 0 {
+		// avatars are cropped, so the original is not a valid candidate
+		largest := variants[len(variants)-1]
+		response.Url, response.Width, response.Height = largest.Url, largest.Width, largest.Height
+	} else if m.Width > 0 {
+		candidates = append(candidates, fmt.Sprintf("%v %vw", m.Url, m.Width))
+	}
+	response.Srcset = strings.Join(candidates, ", ")
+	return response
+}
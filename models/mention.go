@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// Mention records that a post or comment mentions a user. Notified is set
+// once the mentioned user has been told, which waits until the content is
+// visible (published post, approved comment).
+type Mention struct {
+	ID          uint      `json:"id" gorm:"primary_key"`
+	UserID      uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_mentions_user_target"`
+	MentionerID uint      `json:"mentioner_id" gorm:"not null"`
+	TargetType  string    `json:"target_type" gorm:"size:16;not null;uniqueIndex:idx_mentions_user_target;index:idx_mentions_target"`
+	TargetID    uint      `json:"target_id" gorm:"not null;uniqueIndex:idx_mentions_user_target;index:idx_mentions_target"`
+	PostID      uint      `json:"post_id" gorm:"not null"`
+	Notified    bool      `json:"-" gorm:"not null;default:false"`
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -0,0 +1,80 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const COMMENT_STATUS_PENDING = 1
+const COMMENT_STATUS_APPROVED = 2
+const COMMENT_STATUS_SPAM = 3
+const COMMENT_STATUS_REJECTED = 4
+
+func IsValidCommentStatus(status uint) bool {
+	return status >= COMMENT_STATUS_PENDING && status <= COMMENT_STATUS_REJECTED
+}
+
+// Labels a comment was trained with, so a changed decision can be unlearned
+// before the new one is learned.
+const SPAM_LABEL_NONE = 0
+const SPAM_LABEL_HAM = 1
+const SPAM_LABEL_SPAM = 2
+
+// ModerationPolicy holds the site wide rules deciding whether a new comment
+// goes live right away. There is a single row, see GetModerationPolicy.
+type ModerationPolicy struct {
+	ID                  uint      `json:"-" gorm:"primary_key"`
+	AutoApproveTrusted  bool      `json:"auto_approve_trusted" gorm:"not null;default:true"`
+	TrustedCommentCount uint      `json:"trusted_comment_count" gorm:"not null;default:3"`
+	HoldFirstComment    bool      `json:"hold_first_comment" gorm:"not null;default:true"`
+	HoldLinks           bool      `json:"hold_links" gorm:"not null;default:true"`
+	SpamThreshold       float64   `json:"spam_threshold" gorm:"not null;default:0.9"`
+	UpdatedAt           time.Time `json:"updated_at"`
+}
+
+// SpamToken counts in how many spam and ham comments a token appeared.
+type SpamToken struct {
+	Token     string `gorm:"primary_key;size:64"`
+	SpamCount uint   `gorm:"not null;default:0"`
+	HamCount  uint   `gorm:"not null;default:0"`
+}
+
+// SpamCorpus is the single row of totals the classifier needs next to the
+// token counts.
+type SpamCorpus struct {
+	ID         uint `gorm:"primary_key"`
+	SpamDocs   uint `gorm:"not null;default:0"`
+	HamDocs    uint `gorm:"not null;default:0"`
+	SpamTokens uint `gorm:"not null;default:0"`
+	HamTokens  uint `gorm:"not null;default:0"`
+}
+
+func GetModerationPolicy(db *gorm.DB) (ModerationPolicy, error) {
+	policy := ModerationPolicy{
+		ID:                  1,
+		AutoApproveTrusted:  true,
+		TrustedCommentCount: 3,
+		HoldFirstComment:    true,
+		HoldLinks:           true,
+		SpamThreshold:       0.9,
+	}
+	err := db.Where(ModerationPolicy{ID: 1}).Attrs(policy).FirstOrCreate(&policy).Error
+	return policy, err
+}
+
+// ApprovedComments limits a query to comments anonymous readers may see.
+func ApprovedComments(db *gorm.DB) *gorm.DB {
+	return db.Where("comments.moderation_status = ?", COMMENT_STATUS_APPROVED)
+}
+
+// VisibleComments limits a query to approved comments plus the held ones of
+// the given user, so authors can see that their comment awaits moderation.
+func VisibleComments(user_id uint) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if user_id == 0 {
+			return ApprovedComments(db)
+		}
+		return db.Where("(comments.moderation_status = ? OR comments.user_id = ?)", COMMENT_STATUS_APPROVED, user_id)
+	}
+}
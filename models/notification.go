@@ -0,0 +1,104 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const NOTIFICATION_MENTION = "mention"
+const NOTIFICATION_REPLY = "reply"
+const NOTIFICATION_COMMENT = "comment"
+const NOTIFICATION_LIKE = "like"
+const NOTIFICATION_FOLLOW = "follow"
+const NOTIFICATION_MODERATION = "moderation"
+
+// Notification is one entry of a user's inbox. Likes, comments, replies and
+// follows on the same target are aggregated into a single entry while it is
+// unread, with the people behind it in NotificationActor.
+type Notification struct {
+	ID         uint       `json:"id" gorm:"primary_key"`
+	UserID     uint       `json:"user_id" gorm:"not null;index:idx_notifications_user_read"`
+	ActorID    uint       `json:"actor_id" gorm:"not null"`
+	ActorCount uint       `json:"actor_count" gorm:"not null;default:1"`
+	Type       string     `json:"type" gorm:"size:32;not null"`
+	TargetType string     `json:"target_type" gorm:"size:16"`
+	TargetID   uint       `json:"target_id"`
+	PostID     uint       `json:"post_id"`
+	Action     string     `json:"action" gorm:"size:16"`
+	ReadAt     *time.Time `json:"read_at" gorm:"index:idx_notifications_user_read"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+type NotificationActor struct {
+	NotificationID uint      `gorm:"primary_key;autoIncrement:false"`
+	ActorID        uint      `gorm:"primary_key;autoIncrement:false"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// NotificationPreference holds which notifications a user wants, in the
+// inbox and by email. Users without a row get the defaults.
+type NotificationPreference struct {
+	UserID        uint       `json:"-" gorm:"primary_key;autoIncrement:false"`
+	Mentions      bool       `json:"mentions" gorm:"not null;default:true"`
+	Replies       bool       `json:"replies" gorm:"not null;default:true"`
+	Comments      bool       `json:"comments" gorm:"not null;default:true"`
+	Likes         bool       `json:"likes" gorm:"not null;default:true"`
+	Follows       bool       `json:"follows" gorm:"not null;default:true"`
+	Moderation    bool       `json:"moderation" gorm:"not null;default:true"`
+	EmailMentions bool       `json:"email_mentions" gorm:"not null;default:true"`
+	EmailReplies  bool       `json:"email_replies" gorm:"not null;default:true"`
+	EmailDigest   bool       `json:"email_digest" gorm:"not null;default:true"`
+	DigestSentAt  *time.Time `json:"digest_sent_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+func DefaultNotificationPreference(user_id uint) NotificationPreference {
+	return NotificationPreference{
+		UserID: user_id, Mentions: true, Replies: true, Comments: true, Likes: true, Follows: true, Moderation: true,
+		EmailMentions: true, EmailReplies: true, EmailDigest: true,
+	}
+}
+
+func GetNotificationPreference(db *gorm.DB, user_id uint) (NotificationPreference, error) {
+	preference := NotificationPreference{}
+	err := db.Where("user_id = ?", user_id).Take(&preference).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return DefaultNotificationPreference(user_id), nil
+	}
+	return preference, err
+}
+
+// Allows reports whether the user wants notifications of the given type.
+func (p NotificationPreference) Allows(notification_type string) bool {
+	switch notification_type {
+	case NOTIFICATION_MENTION:
+		return p.Mentions
+	case NOTIFICATION_REPLY:
+		return p.Replies
+	case NOTIFICATION_COMMENT:
+		return p.Comments
+	case NOTIFICATION_LIKE:
+		return p.Likes
+	case NOTIFICATION_FOLLOW:
+		return p.Follows
+	case NOTIFICATION_MODERATION:
+		return p.Moderation
+	}
+	return true
+}
+
+// AllowsEmail reports whether the user wants emails of the given list.
+func (p NotificationPreference) AllowsEmail(list string) bool {
+	switch list {
+	case EMAIL_LIST_MENTIONS:
+		return p.EmailMentions
+	case EMAIL_LIST_REPLIES:
+		return p.EmailReplies
+	case EMAIL_LIST_DIGEST:
+		return p.EmailDigest
+	}
+	return false
+}
@@ -1,18 +1,25 @@
 package models
 
 import (
+	"time"
+
 	"gorm.io/gorm"
 )
 
 type Post struct {
 	gorm.Model
-	UserID             uint   `gorm:"not null;" json:"user_id"`
-	ArticleTitle       string `gorm:"size:255;not null;" json:"article_title"`
-	ArticleDescription string `gorm:"text;" json:"article_description"`
-	CategoryID         uint   `json:"category_id"`
-	ArticleContent     string `gorm:"text;" json:"article_content"`
-	PostLikeCount      uint   `json:"post_like_count" gorm:"not null;default:0"`
-	PostDislikeCount   uint   `json:"post_dislike_count" gorm:"not null;default:0"`
+	UserID             uint       `gorm:"not null;" json:"user_id"`
+	ArticleTitle       string     `gorm:"size:255;not null;" json:"article_title"`
+	ArticleDescription string     `gorm:"text;" json:"article_description"`
+	CategoryID         uint       `json:"category_id"`
+	ArticleContent     string     `gorm:"text;" json:"article_content"`
+	RenderedContent    string     `gorm:"text;" json:"rendered_content"`
+	PostLikeCount      uint       `json:"post_like_count" gorm:"not null;default:0"`
+	PostDislikeCount   uint       `json:"post_dislike_count" gorm:"not null;default:0"`
+	Status             uint       `json:"status" gorm:"not null;default:2"`
+	PublishedAt        *time.Time `json:"published_at" gorm:"index"`
+	FeaturedImageID    *uint      `json:"featured_image_id"`
+	IsHidden           bool       `json:"is_hidden" gorm:"not null;default:false"`
 
 	// Relationship
 	User         User           `json:"-"`
@@ -20,3 +27,37 @@ type Post struct {
 	Category     Category       `json:"-"`
 	UserLikePost []UserLikePost `json:"-"`
 }
+
+const POST_STATUS_DRAFT = 1
+const POST_STATUS_PUBLISHED = 2
+
+func IsValidPostStatus(status uint) bool {
+	return status == POST_STATUS_DRAFT || status == POST_STATUS_PUBLISHED
+}
+
+// PublishedPosts limits a query to posts that anonymous readers may see,
+// leaving out posts hidden by moderators or by reports.
+func PublishedPosts(db *gorm.DB) *gorm.DB {
+	return db.Where("posts.status = ? AND posts.is_hidden = ?", POST_STATUS_PUBLISHED, false)
+}
+
+// VisiblePosts limits a query to published posts plus the drafts and hidden
+// posts of the given user, so authors can still read their own work.
+func VisiblePosts(user_id uint) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if user_id == 0 {
+			return PublishedPosts(db)
+		}
+		return db.Where("((posts.status = ? AND posts.is_hidden = ?) OR posts.user_id = ?)", POST_STATUS_PUBLISHED, false, user_id)
+	}
+}
+
+// PostDetails is what a post response needs besides the post: the featured
+// images by media id, and the tags, the reactions and the caller's bookmark
+// by post id.
+type PostDetails struct {
+	Images     map[uint]Media
+	Tags       map[uint][]Tag
+	Reactions  map[uint]ReactionSummary
+	Bookmarked map[uint]bool
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"blogspot-project/utils"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const REACTION_LIKE = "like"
+const REACTION_DISLIKE = "dislike"
+
+// DEFAULT_REACTION_TYPES is the reaction set used when REACTION_TYPES is not
+// set.
+const DEFAULT_REACTION_TYPES = "like,dislike,love,laugh,insightful"
+
+var reactionTypePattern = regexp.MustCompile(`^[a-z_]{1,32}$`)
+
+// Reaction is the one reaction a user has on a post or a comment, the
+// unique index makes sure of that even under concurrent requests. It
+// replaces UserLikePost and UserLikeComment, like and dislike are reactions
+// like any other.
+type Reaction struct {
+	ID         uint      `json:"id" gorm:"primary_key"`
+	UserID     uint      `json:"user_id" gorm:"not null;uniqueIndex:idx_reactions_user_target"`
+	TargetType string    `json:"target_type" gorm:"size:16;not null;uniqueIndex:idx_reactions_user_target;index:idx_reactions_target"`
+	TargetID   uint      `json:"target_id" gorm:"not null;uniqueIndex:idx_reactions_user_target;index:idx_reactions_target"`
+	PostID     uint      `json:"post_id" gorm:"not null"`
+	Type       string    `json:"type" gorm:"size:32;not null;index:idx_reactions_target"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// ReactionCount is the number of reactions of one type on a target. It is
+// kept in step with Reaction in the same transaction, and repaired by the
+// counters job if it ever drifts.
+type ReactionCount struct {
+	TargetType string `json:"target_type" gorm:"primary_key;size:16"`
+	TargetID   uint   `json:"target_id" gorm:"primary_key;autoIncrement:false"`
+	Type       string `json:"type" gorm:"primary_key;size:32"`
+	Total      int64  `json:"total" gorm:"not null;default:0"`
+}
+
+// ReactionTypes is the configured reaction set (REACTION_TYPES, comma
+// separated). Like and dislike are always part of it, the like endpoints
+// depend on them.
+func ReactionTypes() []string {
+	types := []string{REACTION_LIKE, REACTION_DISLIKE}
+	for _, name := range strings.Split(utils.GetEnv("REACTION_TYPES", DEFAULT_REACTION_TYPES), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !reactionTypePattern.MatchString(name) || name == REACTION_LIKE || name == REACTION_DISLIKE {
+			continue
+		}
+		types = append(types, name)
+	}
+	return types
+}
+
+func IsValidReaction(reaction string) bool {
+	for _, name := range ReactionTypes() {
+		if name == reaction {
+			return true
+		}
+	}
+	return false
+}
+
+// ReactionSummary is the reactions on one post or comment: the count of
+// every reaction type and the caller's own reaction.
+type ReactionSummary struct {
+	Counts       map[string]int64
+	UserReaction *string
+}
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"blogspot-project/utils"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const REPORT_STATUS_OPEN = 1
+const REPORT_STATUS_RESOLVED = 2
+const REPORT_STATUS_DISMISSED = 3
+
+// Actions a moderator can take when resolving a report.
+const REPORT_ACTION_DISMISS = "dismiss"
+const REPORT_ACTION_HIDE = "hide"
+const REPORT_ACTION_DELETE = "delete"
+const REPORT_ACTION_WARN = "warn"
+const REPORT_ACTION_SUSPEND = "suspend"
+
+var REPORT_REASONS = []string{"spam", "harassment", "hate", "violence", "sexual", "misinformation", "other"}
+
+// Report is a reader flagging a post or comment. A user can report the same
+// target only once.
+type Report struct {
+	gorm.Model
+	ReporterID   uint       `json:"reporter_id" gorm:"not null;uniqueIndex:idx_reports_reporter_target"`
+	TargetType   string     `json:"target_type" gorm:"size:16;not null;uniqueIndex:idx_reports_reporter_target;index:idx_reports_target"`
+	TargetID     uint       `json:"target_id" gorm:"not null;uniqueIndex:idx_reports_reporter_target;index:idx_reports_target"`
+	TargetUserID uint       `json:"target_user_id" gorm:"not null;index"`
+	Reason       string     `json:"reason" gorm:"size:32;not null"`
+	Message      string     `json:"message" gorm:"text"`
+	Status       uint       `json:"status" gorm:"not null;default:1;index"`
+	Action       string     `json:"action" gorm:"size:16"`
+	ResolvedByID *uint      `json:"resolved_by_id"`
+	ResolvedAt   *time.Time `json:"resolved_at"`
+	// HeldByReport marks the report that reached REPORT_HIDE_THRESHOLD and
+	// hid its target, dismissing the reports only undoes that hold
+	HeldByReport bool `json:"held_by_report" gorm:"not null;default:false"`
+
+	// relationship
+	Reporter User `json:"-"`
+}
+
+func IsValidReportReason(reason string) bool {
+	for _, item := range REPORT_REASONS {
+		if item == reason {
+			return true
+		}
+	}
+	return false
+}
+
+func IsValidReportTarget(target_type string) bool {
+	return target_type == TARGET_POST || target_type == TARGET_COMMENT
+}
+
+// ReportHideThreshold is how many open reports hide a post or comment until
+// a moderator looks at it (REPORT_HIDE_THRESHOLD), 0 turns auto hiding off.
+func ReportHideThreshold() int64 {
+	threshold, err := strconv.Atoi(utils.GetEnv("REPORT_HIDE_THRESHOLD", "5"))
+	if err != nil || threshold < 0 {
+		return 5
+	}
+	return int64(threshold)
+}
+
+// ReportSuspendDays is the default length of a suspension
+// (REPORT_SUSPEND_DAYS).
+func ReportSuspendDays() int {
+	days, err := strconv.Atoi(utils.GetEnv("REPORT_SUSPEND_DAYS", "7"))
+	if err != nil || days <= 0 {
+		return 7
+	}
+	return days
+}
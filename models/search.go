@@ -0,0 +1,21 @@
+package models
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// likeEscaper escapes the LIKE wildcards typed in a search. ! is used as the
+// escape character since backslashes are read differently by each database.
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
+// Contains limits a query to the rows whose column contains search, ignoring
+// case. LIKE already ignores case on MySQL and SQLite but not on PostgreSQL,
+// so both sides are lowered. Searches are not full-text: no database uses
+// an index for them or ranks the matches.
+func Contains(column, search string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("LOWER("+column+") LIKE ? ESCAPE '!'", "%"+likeEscaper.Replace(strings.ToLower(search))+"%")
+	}
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Tag labels posts across categories, posts of a tag have their own feeds.
+type Tag struct {
+	ID   uint   `json:"id" gorm:"primary_key"`
+	Name string `json:"name" gorm:"size:50;not null"`
+	Slug string `json:"slug" gorm:"size:60;not null;uniqueIndex"`
+}
+
+// PostTag is a tag of a post.
+type PostTag struct {
+	PostID uint `json:"post_id" gorm:"primary_key;autoIncrement:false"`
+	TagID  uint `json:"tag_id" gorm:"primary_key;autoIncrement:false;index"`
+}
+
+const POST_MAX_TAGS = 10
+const TAG_NAME_MAX_LENGTH = 50
+
+// TagSlug is the url form of a tag name: lower case letters and digits, with
+// single dashes in place of anything else. Names with the same slug are the
+// same tag.
+func TagSlug(name string) string {
+	var builder strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && builder.Len() > 0 {
+				builder.WriteRune('-')
+			}
+			builder.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	return builder.String()
+}
@@ -0,0 +1,6 @@
+package models
+
+// Kinds of content that mentions, reports and notifications point at.
+const TARGET_POST = "post"
+const TARGET_COMMENT = "comment"
+const TARGET_USER = "user"
@@ -4,6 +4,7 @@ import (
 	"blogspot-project/utils/token"
 	"html"
 	"strings"
+	"time"
 
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
@@ -17,6 +18,15 @@ type User struct {
 	Password string `gorm:"size:255;not null" json:"-"`
 	ImageUrl string `gorm:"size:255;" json:"image_url"`
 	Role     uint   `gorm:"not null;default:2" json:"role"`
+	// AvatarMediaID points to an uploaded image, ImageUrl then holds its
+	// largest avatar crop so clients reading only image_url keep working.
+	AvatarMediaID *uint `json:"avatar_media_id"`
+	// set by moderators resolving reports, see IsSuspended
+	WarningCount   uint       `gorm:"not null;default:0" json:"warning_count"`
+	SuspendedUntil *time.Time `json:"suspended_until"`
+	// shown on the public profile
+	Bio         string       `gorm:"type:text" json:"bio"`
+	SocialLinks []SocialLink `gorm:"type:text;serializer:json" json:"social_links"`
 
 	// Relationship
 	Posts               []Post            `json:"-"`
@@ -25,15 +35,37 @@ type User struct {
 	UserListLikeComment []UserLikeComment `json:"-"`
 }
 
-type UserResponse struct {
-	Name     string `gorm:"size:255;not null;unique" json:"name"`
-	Username string `gorm:"size:255;not null;unique" json:"username"`
-	Email    string `gorm:"size:255;not null;unique" json:"email"`
-	ImageUrl string `gorm:"size:255;" json:"image_url"`
+// SocialLink is a link to another site of the user, like a website or a
+// social network account.
+type SocialLink struct {
+	Label string `json:"label" binding:"required,max=50"`
+	Url   string `json:"url" binding:"required,url,max=255"`
 }
 
+// PROFILE_BIO_MAX_LENGTH and PROFILE_MAX_SOCIAL_LINKS bound what a user can
+// put on their public profile.
+const PROFILE_BIO_MAX_LENGTH = 500
+const PROFILE_MAX_SOCIAL_LINKS = 10
+
 const ADMIN_USER_ROLE = 1
 const NON_ADMIN_USER_ROLE = 2
+const MODERATOR_USER_ROLE = 3
+
+func IsValidRole(role uint) bool {
+	return role == ADMIN_USER_ROLE || role == NON_ADMIN_USER_ROLE || role == MODERATOR_USER_ROLE
+}
+
+// IsSuspended is true while a suspension is running. Suspended users can
+// still log in and read, but cannot write posts, comments or reports.
+func (u User) IsSuspended() bool {
+	return u.SuspendedUntil != nil && u.SuspendedUntil.After(time.Now())
+}
+
+// IsModerator is true for moderators and admins, who may moderate any
+// user's content.
+func (u User) IsModerator() bool {
+	return u.Role == ADMIN_USER_ROLE || u.Role == MODERATOR_USER_ROLE
+}
 
 func VerifyPassword(password, hashedPassword string) error {
 	return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
@@ -74,3 +106,10 @@ func LoginValid(username, password string, db *gorm.DB) (string, error) {
 	}
 	return token, nil
 }
+
+// UserStats is the number of published posts and follows of a user.
+type UserStats struct {
+	PostCount      int64
+	FollowerCount  int64
+	FollowingCount int64
+}
@@ -1,5 +1,7 @@
 package models
 
+// UserLikeComment is the like table from before reactions. Its rows are moved to
+// Reaction when the database is connected.
 type UserLikeComment struct {
 	ID        uint `json:"id" gorm:"primary_key;not null;"`
 	UserID    uint `json:"user_id" gorm:"not null;"`
@@ -1,5 +1,7 @@
 package models
 
+// UserLikePost is the like table from before reactions. Its rows are moved to
+// Reaction when the database is connected.
 type UserLikePost struct {
 	ID     uint `json:"id" gorm:"primary_key;not null;"`
 	UserID uint `json:"user_id" gorm:"not null;"`
@@ -0,0 +1,24 @@
+package presenters
+
+import (
+	"blogspot-project/models"
+	"time"
+)
+
+type UserBlock struct {
+	BlockedID uint      `json:"blocked_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type UserMute struct {
+	MutedID   uint      `json:"muted_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func ToUserBlock(block models.UserBlock) UserBlock {
+	return UserBlock{BlockedID: block.BlockedID, CreatedAt: block.CreatedAt}
+}
+
+func ToUserMute(mute models.UserMute) UserMute {
+	return UserMute{MutedID: mute.MutedID, CreatedAt: mute.CreatedAt}
+}
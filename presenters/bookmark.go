@@ -0,0 +1,85 @@
+package presenters
+
+import (
+	"blogspot-project/models"
+	"time"
+)
+
+type Bookmark struct {
+	PostID    uint      `json:"post_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ReadingList struct {
+	ID          uint       `json:"id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	IsPublic    bool       `json:"is_public"`
+	Owner       PublicUser `json:"owner"`
+	PostCount   int64      `json:"post_count"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// ReadingListItem is where a post sits in a reading list, returned when the
+// owner adds or moves a post.
+type ReadingListItem struct {
+	ReadingListID uint      `json:"reading_list_id"`
+	PostID        uint      `json:"post_id"`
+	Position      int       `json:"position"`
+	Note          string    `json:"note"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// ReadingListPost is a post of a reading list detail.
+type ReadingListPost struct {
+	Position int       `json:"position"`
+	Note     string    `json:"note"`
+	AddedAt  time.Time `json:"added_at"`
+	Post     Post      `json:"post"`
+}
+
+// ReadingListDetail lists the posts the caller can read. Its owner also gets
+// how many posts of the list are unpublished or hidden for now.
+type ReadingListDetail struct {
+	ReadingList
+	Items            []ReadingListPost `json:"items"`
+	UnavailableCount int64             `json:"unavailable_count"`
+}
+
+func ToBookmark(bookmark models.Bookmark) Bookmark {
+	return Bookmark{PostID: bookmark.PostID, CreatedAt: bookmark.CreatedAt}
+}
+
+// ToReadingLists adds the owner and the number of posts the caller can read
+// from details to each list.
+func ToReadingLists(lists []models.ReadingList, details models.ReadingListDetails) []ReadingList {
+	responses := make([]ReadingList, len(lists))
+	for i, list := range lists {
+		responses[i] = ToReadingList(list, details)
+	}
+	return responses
+}
+
+func ToReadingList(list models.ReadingList, details models.ReadingListDetails) ReadingList {
+	return ReadingList{
+		ID:          list.ID,
+		Name:        list.Name,
+		Description: list.Description,
+		IsPublic:    list.IsPublic,
+		Owner:       ToPublicUser(details.Owners[list.UserID], details.Avatars),
+		PostCount:   details.PostCounts[list.ID],
+		CreatedAt:   list.CreatedAt,
+		UpdatedAt:   list.UpdatedAt,
+	}
+}
+
+func ToReadingListItem(item models.ReadingListItem) ReadingListItem {
+	return ReadingListItem{
+		ReadingListID: item.ReadingListID,
+		PostID:        item.PostID,
+		Position:      item.Position,
+		Note:          item.Note,
+		CreatedAt:     item.CreatedAt,
+	}
+}
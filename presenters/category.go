@@ -0,0 +1,20 @@
+package presenters
+
+import "blogspot-project/models"
+
+type Category struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
+func ToCategory(category models.Category) Category {
+	return Category{ID: category.ID, Name: category.Name}
+}
+
+func ToCategories(categories []models.Category) []Category {
+	responses := make([]Category, len(categories))
+	for i, category := range categories {
+		responses[i] = ToCategory(category)
+	}
+	return responses
+}
@@ -0,0 +1,121 @@
+package presenters
+
+import (
+	"blogspot-project/models"
+	"time"
+)
+
+// Comment is a comment as readers see it. The spam data is only in
+// ModerationComment.
+type Comment struct {
+	ID                  uint             `json:"ID"`
+	CreatedAt           time.Time        `json:"CreatedAt"`
+	UpdatedAt           time.Time        `json:"UpdatedAt"`
+	UserID              uint             `json:"user_id"`
+	PostID              uint             `json:"post_id"`
+	CommentContent      string           `json:"comment_content"`
+	RenderedContent     string           `json:"rendered_content"`
+	CommentLikeCount    uint             `json:"comment_like_count"`
+	CommentDislikeCount uint             `json:"comment_dislike_count"`
+	ParentID            *uint            `json:"parent_id"`
+	ThreadID            uint             `json:"thread_id"`
+	Depth               uint             `json:"depth"`
+	ReplyCount          uint             `json:"reply_count"`
+	IsDeleted           bool             `json:"is_deleted"`
+	EditedAt            *time.Time       `json:"edited_at"`
+	ModerationStatus    uint             `json:"moderation_status"`
+	Reactions           map[string]int64 `json:"reactions,omitempty"`
+	UserReaction        *string          `json:"user_reaction,omitempty"`
+}
+
+type CommentNode struct {
+	Comment
+	Replies []*CommentNode `json:"replies"`
+}
+
+// ModerationComment is a comment in the moderation queue.
+type ModerationComment struct {
+	Comment
+	SpamScore float64 `json:"spam_score"`
+}
+
+// CommentEdit is a previous version of a comment, only shown to moderators.
+type CommentEdit struct {
+	ID              uint      `json:"id"`
+	EditorID        uint      `json:"editor_id"`
+	PreviousContent string    `json:"previous_content"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// ToComment returns a comment without its reactions, for a comment that was
+// just written or is pushed to live readers.
+func ToComment(comment models.Comment) Comment {
+	return Comment{
+		ID:                  comment.ID,
+		CreatedAt:           comment.CreatedAt,
+		UpdatedAt:           comment.UpdatedAt,
+		UserID:              comment.UserID,
+		PostID:              comment.PostID,
+		CommentContent:      comment.CommentContent,
+		RenderedContent:     comment.RenderedContent,
+		CommentLikeCount:    comment.CommentLikeCount,
+		CommentDislikeCount: comment.CommentDislikeCount,
+		ParentID:            comment.ParentID,
+		ThreadID:            comment.ThreadID,
+		Depth:               comment.Depth,
+		ReplyCount:          comment.ReplyCount,
+		IsDeleted:           comment.IsDeleted,
+		EditedAt:            comment.EditedAt,
+		ModerationStatus:    comment.ModerationStatus,
+	}
+}
+
+// ToComments returns the comments with their reactions and the caller's own
+// reaction on them, found in reactions by comment id.
+func ToComments(comments []models.Comment, reactions map[uint]models.ReactionSummary) []Comment {
+	responses := make([]Comment, len(comments))
+	for i, comment := range comments {
+		responses[i] = ToComment(comment)
+		responses[i].Reactions = reactions[comment.ID].Counts
+		responses[i].UserReaction = reactions[comment.ID].UserReaction
+	}
+	return responses
+}
+
+// ToCommentTree nests comments under their parents, keeping the order of
+// the given slice. Comments whose parent is not in the slice become roots.
+func ToCommentTree(comments []models.Comment, reactions map[uint]models.ReactionSummary) []*CommentNode {
+	responses := ToComments(comments, reactions)
+	nodes := make(map[uint]*CommentNode, len(responses))
+	for _, comment := range responses {
+		nodes[comment.ID] = &CommentNode{Comment: comment, Replies: []*CommentNode{}}
+	}
+	roots := []*CommentNode{}
+	for _, comment := range responses {
+		node := nodes[comment.ID]
+		if comment.ParentID != nil {
+			if parent, ok := nodes[*comment.ParentID]; ok {
+				parent.Replies = append(parent.Replies, node)
+				continue
+			}
+		}
+		roots = append(roots, node)
+	}
+	return roots
+}
+
+func ToModerationComments(comments []models.Comment) []ModerationComment {
+	responses := make([]ModerationComment, len(comments))
+	for i, comment := range comments {
+		responses[i] = ModerationComment{Comment: ToComment(comment), SpamScore: comment.SpamScore}
+	}
+	return responses
+}
+
+func ToCommentEdits(edits []models.CommentEdit) []CommentEdit {
+	responses := make([]CommentEdit, len(edits))
+	for i, edit := range edits {
+		responses[i] = CommentEdit{ID: edit.ID, EditorID: edit.EditorID, PreviousContent: edit.PreviousContent, CreatedAt: edit.CreatedAt}
+	}
+	return responses
+}
@@ -0,0 +1,25 @@
+package presenters
+
+import (
+	"blogspot-project/models"
+	"time"
+)
+
+type CategoryFollow struct {
+	CategoryID uint      `json:"category_id"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type UserFollow struct {
+	FollowerID  uint      `json:"follower_id"`
+	FollowingID uint      `json:"following_id"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func ToCategoryFollow(follow models.CategoryFollow) CategoryFollow {
+	return CategoryFollow{CategoryID: follow.CategoryID, CreatedAt: follow.CreatedAt}
+}
+
+func ToUserFollow(follow models.UserFollow) UserFollow {
+	return UserFollow{FollowerID: follow.FollowerID, FollowingID: follow.FollowingID, CreatedAt: follow.CreatedAt}
+}
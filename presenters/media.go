@@ -0,0 +1,64 @@
+package presenters
+
+import (
+	"blogspot-project/models"
+	"time"
+)
+
+// Media is an upload in the library of its owner. Storage keys and checksums
+// stay on the server.
+type Media struct {
+	ID        uint           `json:"ID"`
+	CreatedAt time.Time      `json:"CreatedAt"`
+	FileName  string         `json:"file_name"`
+	MimeType  string         `json:"mime_type"`
+	Size      int64          `json:"size"`
+	Width     int            `json:"width"`
+	Height    int            `json:"height"`
+	Url       string         `json:"url"`
+	Variants  []MediaVariant `json:"variants"`
+}
+
+type MediaVariant struct {
+	ID       uint   `json:"id"`
+	Kind     string `json:"kind"`
+	Width    int    `json:"width"`
+	Height   int    `json:"height"`
+	MimeType string `json:"mime_type"`
+	Size     int64  `json:"size"`
+	Url      string `json:"url"`
+}
+
+func ToMedia(item models.Media) Media {
+	response := Media{
+		ID:        item.ID,
+		CreatedAt: item.CreatedAt,
+		FileName:  item.FileName,
+		MimeType:  item.MimeType,
+		Size:      item.Size,
+		Width:     item.Width,
+		Height:    item.Height,
+		Url:       item.Url,
+		Variants:  []MediaVariant{},
+	}
+	for _, variant := range item.Variants {
+		response.Variants = append(response.Variants, MediaVariant{
+			ID:       variant.ID,
+			Kind:     variant.Kind,
+			Width:    variant.Width,
+			Height:   variant.Height,
+			MimeType: variant.MimeType,
+			Size:     variant.Size,
+			Url:      variant.Url,
+		})
+	}
+	return response
+}
+
+func ToMediaList(list []models.Media) []Media {
+	responses := make([]Media, len(list))
+	for i, item := range list {
+		responses[i] = ToMedia(item)
+	}
+	return responses
+}
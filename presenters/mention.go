@@ -0,0 +1,31 @@
+package presenters
+
+import (
+	"blogspot-project/models"
+	"time"
+)
+
+// Mention is a post or comment mentioning the caller.
+type Mention struct {
+	ID          uint      `json:"id"`
+	MentionerID uint      `json:"mentioner_id"`
+	TargetType  string    `json:"target_type"`
+	TargetID    uint      `json:"target_id"`
+	PostID      uint      `json:"post_id"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func ToMentions(mentions []models.Mention) []Mention {
+	responses := make([]Mention, len(mentions))
+	for i, mention := range mentions {
+		responses[i] = Mention{
+			ID:          mention.ID,
+			MentionerID: mention.MentionerID,
+			TargetType:  mention.TargetType,
+			TargetID:    mention.TargetID,
+			PostID:      mention.PostID,
+			CreatedAt:   mention.CreatedAt,
+		}
+	}
+	return responses
+}
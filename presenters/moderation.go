@@ -0,0 +1,26 @@
+package presenters
+
+import (
+	"blogspot-project/models"
+	"time"
+)
+
+type ModerationPolicy struct {
+	AutoApproveTrusted  bool      `json:"auto_approve_trusted"`
+	TrustedCommentCount uint      `json:"trusted_comment_count"`
+	HoldFirstComment    bool      `json:"hold_first_comment"`
+	HoldLinks           bool      `json:"hold_links"`
+	SpamThreshold       float64   `json:"spam_threshold"`
+	UpdatedAt           time.Time `json:"updated_at"`
+}
+
+func ToModerationPolicy(policy models.ModerationPolicy) ModerationPolicy {
+	return ModerationPolicy{
+		AutoApproveTrusted:  policy.AutoApproveTrusted,
+		TrustedCommentCount: policy.TrustedCommentCount,
+		HoldFirstComment:    policy.HoldFirstComment,
+		HoldLinks:           policy.HoldLinks,
+		SpamThreshold:       policy.SpamThreshold,
+		UpdatedAt:           policy.UpdatedAt,
+	}
+}
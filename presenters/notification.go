@@ -0,0 +1,73 @@
+package presenters
+
+import (
+	"blogspot-project/models"
+	"time"
+)
+
+// Notification is an inbox entry of the caller, as pushed to live clients.
+type Notification struct {
+	ID         uint       `json:"id"`
+	Type       string     `json:"type"`
+	TargetType string     `json:"target_type"`
+	TargetID   uint       `json:"target_id"`
+	PostID     uint       `json:"post_id"`
+	Action     string     `json:"action"`
+	ActorID    uint       `json:"actor_id"`
+	ActorCount uint       `json:"actor_count"`
+	ReadAt     *time.Time `json:"read_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// NotificationDetail is an inbox entry with the latest people behind it and
+// a message to show.
+type NotificationDetail struct {
+	Notification
+	Actors  []PublicUser `json:"actors"`
+	Message string       `json:"message"`
+}
+
+type NotificationPreference struct {
+	Mentions      bool      `json:"mentions"`
+	Replies       bool      `json:"replies"`
+	Comments      bool      `json:"comments"`
+	Likes         bool      `json:"likes"`
+	Follows       bool      `json:"follows"`
+	Moderation    bool      `json:"moderation"`
+	EmailMentions bool      `json:"email_mentions"`
+	EmailReplies  bool      `json:"email_replies"`
+	EmailDigest   bool      `json:"email_digest"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+func ToNotification(item models.Notification) Notification {
+	return Notification{
+		ID:         item.ID,
+		Type:       item.Type,
+		TargetType: item.TargetType,
+		TargetID:   item.TargetID,
+		PostID:     item.PostID,
+		Action:     item.Action,
+		ActorID:    item.ActorID,
+		ActorCount: item.ActorCount,
+		ReadAt:     item.ReadAt,
+		CreatedAt:  item.CreatedAt,
+		UpdatedAt:  item.UpdatedAt,
+	}
+}
+
+func ToNotificationPreference(preference models.NotificationPreference) NotificationPreference {
+	return NotificationPreference{
+		Mentions:      preference.Mentions,
+		Replies:       preference.Replies,
+		Comments:      preference.Comments,
+		Likes:         preference.Likes,
+		Follows:       preference.Follows,
+		Moderation:    preference.Moderation,
+		EmailMentions: preference.EmailMentions,
+		EmailReplies:  preference.EmailReplies,
+		EmailDigest:   preference.EmailDigest,
+		UpdatedAt:     preference.UpdatedAt,
+	}
+}
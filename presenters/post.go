@@ -0,0 +1,87 @@
+package presenters
+
+import (
+	"blogspot-project/models"
+	"time"
+)
+
+// Post is a post with the reactions and the caller's own state on it. The
+// keys of the embedded gorm.Model fields stay as clients already read them.
+type Post struct {
+	ID                 uint                  `json:"ID"`
+	CreatedAt          time.Time             `json:"CreatedAt"`
+	UpdatedAt          time.Time             `json:"UpdatedAt"`
+	UserID             uint                  `json:"user_id"`
+	ArticleTitle       string                `json:"article_title"`
+	ArticleDescription string                `json:"article_description"`
+	CategoryID         uint                  `json:"category_id"`
+	ArticleContent     string                `json:"article_content"`
+	RenderedContent    string                `json:"rendered_content"`
+	PostLikeCount      uint                  `json:"post_like_count"`
+	PostDislikeCount   uint                  `json:"post_dislike_count"`
+	Status             uint                  `json:"status"`
+	PublishedAt        *time.Time            `json:"published_at"`
+	FeaturedImageID    *uint                 `json:"featured_image_id"`
+	FeaturedImage      *models.ImageResponse `json:"featured_image"`
+	Tags               []Tag                 `json:"tags"`
+	IsHidden           bool                  `json:"is_hidden"`
+	UserLikeStatus     *uint                 `json:"user_like_status"`
+	Reactions          map[string]int64      `json:"reactions"`
+	UserReaction       *string               `json:"user_reaction"`
+	IsBookmarked       bool                  `json:"is_bookmarked"`
+}
+
+// ToPosts attaches the featured image, the tags, the reaction counts and the caller's
+// reaction and bookmark from details to each post. Posts missing from the
+// details get no reaction and like status and no bookmark.
+func ToPosts(posts []models.Post, details models.PostDetails) []Post {
+	responses := make([]Post, len(posts))
+	for i, post := range posts {
+		responses[i] = ToPost(post, details)
+	}
+	return responses
+}
+
+func ToPost(post models.Post, details models.PostDetails) Post {
+	response := Post{
+		ID:                 post.ID,
+		CreatedAt:          post.CreatedAt,
+		UpdatedAt:          post.UpdatedAt,
+		UserID:             post.UserID,
+		ArticleTitle:       post.ArticleTitle,
+		ArticleDescription: post.ArticleDescription,
+		CategoryID:         post.CategoryID,
+		ArticleContent:     post.ArticleContent,
+		RenderedContent:    post.RenderedContent,
+		PostLikeCount:      post.PostLikeCount,
+		PostDislikeCount:   post.PostDislikeCount,
+		Status:             post.Status,
+		PublishedAt:        post.PublishedAt,
+		FeaturedImageID:    post.FeaturedImageID,
+		IsHidden:           post.IsHidden,
+		Tags:               ToTags(details.Tags[post.ID]),
+		IsBookmarked:       details.Bookmarked[post.ID],
+	}
+	if post.FeaturedImageID != nil {
+		if item, ok := details.Images[*post.FeaturedImageID]; ok {
+			image := item.Image(models.MEDIA_VARIANT_RESIZED)
+			response.FeaturedImage = &image
+		}
+	}
+	summary := details.Reactions[post.ID]
+	response.Reactions = summary.Counts
+	response.UserReaction = summary.UserReaction
+	if summary.UserReaction == nil {
+		return response
+	}
+	// like status of the like endpoints, other reactions have none
+	switch *summary.UserReaction {
+	case models.REACTION_LIKE:
+		status := uint(1)
+		response.UserLikeStatus = &status
+	case models.REACTION_DISLIKE:
+		status := uint(0)
+		response.UserLikeStatus = &status
+	}
+	return response
+}
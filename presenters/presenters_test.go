@@ -0,0 +1,147 @@
+package presenters
+
+import (
+	"blogspot-project/models"
+	"encoding/json"
+	"testing"
+)
+
+func jsonKeys(t *testing.T, value interface{}) map[string]interface{} {
+	t.Helper()
+	data, err := json.Marshal(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := map[string]interface{}{}
+	if err := json.Unmarshal(data, &keys); err != nil {
+		t.Fatal(err)
+	}
+	return keys
+}
+
+func assertKeys(t *testing.T, keys map[string]interface{}, shown []string, hidden []string) {
+	t.Helper()
+	for _, key := range shown {
+		if _, ok := keys[key]; !ok {
+			t.Errorf("expected key %q in %v", key, keys)
+		}
+	}
+	for _, key := range hidden {
+		if _, ok := keys[key]; ok {
+			t.Errorf("key %q must not be exposed", key)
+		}
+	}
+}
+
+func testUser() models.User {
+	user := models.User{
+		Name:     "Jane",
+		Username: "jane",
+		Email:    "jane@example.com",
+		Password: "hashed",
+		Role:     models.MODERATOR_USER_ROLE,
+		Bio:      "writer",
+	}
+	user.ID = 7
+	return user
+}
+
+func TestPublicUserHidesAccountData(t *testing.T) {
+	users := ToPublicUsers([]models.User{testUser()}, nil)
+	assertKeys(t, jsonKeys(t, users[0]),
+		[]string{"name", "username", "image_url", "avatar"},
+		[]string{"email", "password", "role", "warning_count", "suspended_until", "ID"})
+}
+
+func TestProfileHidesAccountData(t *testing.T) {
+	user := testUser()
+	media_id := uint(3)
+	user.AvatarMediaID = &media_id
+	avatars := map[uint]models.Media{3: {Url: "/media/7/a.png"}}
+	profile := ToProfile(user, avatars, models.UserStats{PostCount: 2, FollowerCount: 5, FollowingCount: 1})
+	keys := jsonKeys(t, profile)
+	assertKeys(t, keys,
+		[]string{"name", "bio", "avatar", "post_count", "follower_count", "following_count"},
+		[]string{"email", "password", "role", "ID"})
+	if profile.Avatar == nil || profile.Avatar.Url != "/media/7/a.png" {
+		t.Errorf("expected the avatar from avatars, got %v", profile.Avatar)
+	}
+	if keys["post_count"] != 2.0 || keys["follower_count"] != 5.0 || keys["following_count"] != 1.0 {
+		t.Errorf("expected the counts of the stats, got %v", keys)
+	}
+}
+
+func TestPostDetails(t *testing.T) {
+	post := models.Post{ArticleTitle: "Hello"}
+	post.ID = 4
+	media_id := uint(9)
+	post.FeaturedImageID = &media_id
+	like := models.REACTION_LIKE
+	details := models.PostDetails{
+		Images:     map[uint]models.Media{9: {Url: "/media/1/b.png"}},
+		Reactions:  map[uint]models.ReactionSummary{4: {Counts: map[string]int64{models.REACTION_LIKE: 1}, UserReaction: &like}},
+		Bookmarked: map[uint]bool{4: true},
+	}
+	response := ToPost(post, details)
+	if response.FeaturedImage == nil || response.FeaturedImage.Url != "/media/1/b.png" {
+		t.Errorf("expected the featured image, got %v", response.FeaturedImage)
+	}
+	if !response.IsBookmarked || response.Reactions[models.REACTION_LIKE] != 1 {
+		t.Errorf("expected the bookmark and reactions of the details, got %+v", response)
+	}
+	if response.UserLikeStatus == nil || *response.UserLikeStatus != 1 {
+		t.Errorf("expected like status 1, got %v", response.UserLikeStatus)
+	}
+	other := ToPosts([]models.Post{{ArticleTitle: "Other"}}, details)[0]
+	if other.IsBookmarked || other.UserReaction != nil || other.FeaturedImage != nil {
+		t.Errorf("expected nothing for a post missing from the details, got %+v", other)
+	}
+}
+
+func TestAdminUserShowsAccountData(t *testing.T) {
+	assertKeys(t, jsonKeys(t, ToAdminUser(testUser())),
+		[]string{"ID", "email", "role", "warning_count", "suspended_until"},
+		[]string{"password", "bio", "DeletedAt"})
+}
+
+func TestCommentHidesSpamData(t *testing.T) {
+	comment := models.Comment{CommentContent: "hello", SpamScore: 0.7, SpamLabel: models.SPAM_LABEL_SPAM}
+	assertKeys(t, jsonKeys(t, ToComment(comment)),
+		[]string{"ID", "comment_content", "moderation_status"},
+		[]string{"spam_score", "spam_label", "DeletedAt", "reactions"})
+	queued := ToModerationComments([]models.Comment{comment})
+	keys := jsonKeys(t, queued[0])
+	assertKeys(t, keys, []string{"spam_score", "comment_content"}, []string{"spam_label"})
+	if keys["spam_score"] != 0.7 {
+		t.Errorf("expected spam score 0.7, got %v", keys["spam_score"])
+	}
+}
+
+func TestMediaHidesStorage(t *testing.T) {
+	item := models.Media{
+		FileName:   "a.png",
+		Checksum:   "abc",
+		StorageKey: "7/abc.png",
+		Url:        "/media/7/abc.png",
+		Variants:   []models.MediaVariant{{Kind: models.MEDIA_VARIANT_RESIZED, StorageKey: "7/abc-320.png", Url: "/media/7/abc-320.png"}},
+	}
+	keys := jsonKeys(t, ToMedia(item))
+	assertKeys(t, keys, []string{"file_name", "url", "variants"}, []string{"checksum", "storage_key", "user_id", "DeletedAt"})
+	variant := keys["variants"].([]interface{})[0].(map[string]interface{})
+	assertKeys(t, variant, []string{"kind", "url"}, []string{"storage_key", "media_id"})
+}
+
+func TestReportViews(t *testing.T) {
+	report := models.Report{ReporterID: 3, TargetUserID: 9, Reason: "spam"}
+	assertKeys(t, jsonKeys(t, ToFiledReport(report)),
+		[]string{"reason", "status", "target_type"},
+		[]string{"target_user_id", "reporter_id", "resolved_by_id", "held_by_report"})
+	assertKeys(t, jsonKeys(t, ToReports([]models.Report{report})[0]),
+		[]string{"reason", "reporter_id", "target_user_id", "resolved_by_id", "held_by_report"},
+		[]string{"DeletedAt"})
+}
+
+func TestNotificationHidesRecipient(t *testing.T) {
+	item := models.Notification{UserID: 4, ActorID: 5, Type: models.NOTIFICATION_LIKE}
+	assertKeys(t, jsonKeys(t, ToNotification(item)), []string{"type", "actor_id", "read_at"}, []string{"user_id"})
+}
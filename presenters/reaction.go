@@ -0,0 +1,23 @@
+package presenters
+
+import (
+	"blogspot-project/models"
+)
+
+// Reaction is a user who reacted, in the reaction lists of a post or comment.
+type Reaction struct {
+	Type string `json:"type"`
+	PublicUser
+}
+
+// ToReactions returns the user behind each reaction from users by id,
+// reactions of users who are gone are left out.
+func ToReactions(reactions []models.Reaction, users map[uint]models.User, avatars map[uint]models.Media) []Reaction {
+	responses := []Reaction{}
+	for _, reaction := range reactions {
+		if user, ok := users[reaction.UserID]; ok {
+			responses = append(responses, Reaction{Type: reaction.Type, PublicUser: ToPublicUser(user, avatars)})
+		}
+	}
+	return responses
+}
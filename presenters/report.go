@@ -0,0 +1,58 @@
+package presenters
+
+import (
+	"blogspot-project/models"
+	"time"
+)
+
+// FiledReport is a report as seen by the reader who filed it.
+type FiledReport struct {
+	ID         uint      `json:"ID"`
+	CreatedAt  time.Time `json:"CreatedAt"`
+	TargetType string    `json:"target_type"`
+	TargetID   uint      `json:"target_id"`
+	Reason     string    `json:"reason"`
+	Message    string    `json:"message"`
+	Status     uint      `json:"status"`
+}
+
+// Report is a report as moderators triage it.
+type Report struct {
+	FiledReport
+	UpdatedAt    time.Time  `json:"UpdatedAt"`
+	ReporterID   uint       `json:"reporter_id"`
+	TargetUserID uint       `json:"target_user_id"`
+	Action       string     `json:"action"`
+	ResolvedByID *uint      `json:"resolved_by_id"`
+	ResolvedAt   *time.Time `json:"resolved_at"`
+	HeldByReport bool       `json:"held_by_report"`
+}
+
+func ToFiledReport(report models.Report) FiledReport {
+	return FiledReport{
+		ID:         report.ID,
+		CreatedAt:  report.CreatedAt,
+		TargetType: report.TargetType,
+		TargetID:   report.TargetID,
+		Reason:     report.Reason,
+		Message:    report.Message,
+		Status:     report.Status,
+	}
+}
+
+func ToReports(reports []models.Report) []Report {
+	responses := make([]Report, len(reports))
+	for i, report := range reports {
+		responses[i] = Report{
+			FiledReport:  ToFiledReport(report),
+			UpdatedAt:    report.UpdatedAt,
+			ReporterID:   report.ReporterID,
+			TargetUserID: report.TargetUserID,
+			Action:       report.Action,
+			ResolvedByID: report.ResolvedByID,
+			ResolvedAt:   report.ResolvedAt,
+			HeldByReport: report.HeldByReport,
+		}
+	}
+	return responses
+}
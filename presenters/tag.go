@@ -0,0 +1,21 @@
+package presenters
+
+import "blogspot-project/models"
+
+type Tag struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+}
+
+func ToTag(tag models.Tag) Tag {
+	return Tag{ID: tag.ID, Name: tag.Name, Slug: tag.Slug}
+}
+
+func ToTags(tags []models.Tag) []Tag {
+	responses := make([]Tag, len(tags))
+	for i, tag := range tags {
+		responses[i] = ToTag(tag)
+	}
+	return responses
+}
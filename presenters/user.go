@@ -0,0 +1,149 @@
+package presenters
+
+import (
+	"blogspot-project/models"
+	"time"
+)
+
+// PublicUser is what anyone may see of a user in lists like reactions,
+// followers or notification actors.
+type PublicUser struct {
+	Name     string                `json:"name"`
+	Username string                `json:"username"`
+	ImageUrl string                `json:"image_url"`
+	Avatar   *models.ImageResponse `json:"avatar"`
+}
+
+// Profile is the public profile page of an author. It must never hold the
+// email, the role or moderation data.
+type Profile struct {
+	PublicUser
+	Bio            string              `json:"bio"`
+	SocialLinks    []models.SocialLink `json:"social_links"`
+	PostCount      int64               `json:"post_count"`
+	FollowerCount  int64               `json:"follower_count"`
+	FollowingCount int64               `json:"following_count"`
+	JoinedAt       time.Time           `json:"joined_at"`
+}
+
+// OwnerUser is a user as seen by themselves, with their email and account
+// state.
+type OwnerUser struct {
+	ID             uint                  `json:"ID"`
+	Name           string                `json:"name"`
+	Username       string                `json:"username"`
+	Email          string                `json:"email"`
+	ImageUrl       string                `json:"image_url"`
+	AvatarMediaID  *uint                 `json:"avatar_media_id"`
+	Avatar         *models.ImageResponse `json:"avatar"`
+	Bio            string                `json:"bio"`
+	SocialLinks    []models.SocialLink   `json:"social_links"`
+	Role           uint                  `json:"role"`
+	WarningCount   uint                  `json:"warning_count"`
+	SuspendedUntil *time.Time            `json:"suspended_until"`
+	FollowerCount  int64                 `json:"follower_count"`
+	FollowingCount int64                 `json:"following_count"`
+	CreatedAt      time.Time             `json:"CreatedAt"`
+	UpdatedAt      time.Time             `json:"UpdatedAt"`
+}
+
+// AdminUser is a user in the admin user list, the account data without the
+// profile.
+type AdminUser struct {
+	ID             uint       `json:"ID"`
+	Name           string     `json:"name"`
+	Username       string     `json:"username"`
+	Email          string     `json:"email"`
+	ImageUrl       string     `json:"image_url"`
+	Role           uint       `json:"role"`
+	WarningCount   uint       `json:"warning_count"`
+	SuspendedUntil *time.Time `json:"suspended_until"`
+	CreatedAt      time.Time  `json:"CreatedAt"`
+	UpdatedAt      time.Time  `json:"UpdatedAt"`
+}
+
+// ToPublicUsers returns the public part of each user, with the avatar found
+// in avatars by media id.
+func ToPublicUsers(users []models.User, avatars map[uint]models.Media) []PublicUser {
+	responses := make([]PublicUser, len(users))
+	for i, user := range users {
+		responses[i] = ToPublicUser(user, avatars)
+	}
+	return responses
+}
+
+func ToPublicUser(user models.User, avatars map[uint]models.Media) PublicUser {
+	response := PublicUser{Name: user.Name, Username: user.Username, ImageUrl: user.ImageUrl}
+	if user.AvatarMediaID != nil {
+		if item, ok := avatars[*user.AvatarMediaID]; ok {
+			image := item.Image(models.MEDIA_VARIANT_AVATAR)
+			response.Avatar = &image
+		}
+	}
+	return response
+}
+
+// ToProfile returns the public profile of an author with the number of
+// published posts and follows.
+func ToProfile(user models.User, avatars map[uint]models.Media, stats models.UserStats) Profile {
+	return Profile{
+		PublicUser:     ToPublicUser(user, avatars),
+		Bio:            user.Bio,
+		SocialLinks:    socialLinks(user),
+		PostCount:      stats.PostCount,
+		FollowerCount:  stats.FollowerCount,
+		FollowingCount: stats.FollowingCount,
+		JoinedAt:       user.CreatedAt,
+	}
+}
+
+func ToOwnerUser(user models.User, avatars map[uint]models.Media, stats models.UserStats) OwnerUser {
+	return OwnerUser{
+		ID:             user.ID,
+		Name:           user.Name,
+		Username:       user.Username,
+		Email:          user.Email,
+		ImageUrl:       user.ImageUrl,
+		AvatarMediaID:  user.AvatarMediaID,
+		Avatar:         ToPublicUser(user, avatars).Avatar,
+		Bio:            user.Bio,
+		SocialLinks:    socialLinks(user),
+		Role:           user.Role,
+		WarningCount:   user.WarningCount,
+		SuspendedUntil: user.SuspendedUntil,
+		FollowerCount:  stats.FollowerCount,
+		FollowingCount: stats.FollowingCount,
+		CreatedAt:      user.CreatedAt,
+		UpdatedAt:      user.UpdatedAt,
+	}
+}
+
+func ToAdminUser(user models.User) AdminUser {
+	return AdminUser{
+		ID:             user.ID,
+		Name:           user.Name,
+		Username:       user.Username,
+		Email:          user.Email,
+		ImageUrl:       user.ImageUrl,
+		Role:           user.Role,
+		WarningCount:   user.WarningCount,
+		SuspendedUntil: user.SuspendedUntil,
+		CreatedAt:      user.CreatedAt,
+		UpdatedAt:      user.UpdatedAt,
+	}
+}
+
+func ToAdminUsers(users []models.User) []AdminUser {
+	responses := make([]AdminUser, len(users))
+	for i, user := range users {
+		responses[i] = ToAdminUser(user)
+	}
+	return responses
+}
+
+func socialLinks(user models.User) []models.SocialLink {
+	if user.SocialLinks == nil {
+		return []models.SocialLink{}
+	}
+	return user.SocialLinks
+}
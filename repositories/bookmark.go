@@ -0,0 +1,313 @@
+package repositories
+
+import (
+	"blogspot-project/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// BookmarkRepository holds the bookmarks and reading lists of users.
+type BookmarkRepository interface {
+	// Bookmark saves a bookmark of a post, keeping the first one when the
+	// post is already bookmarked.
+	Bookmark(user_id, post_id uint) (models.Bookmark, error)
+	RemoveBookmark(user_id, post_id uint) error
+	// ListBookmarked returns the bookmarked posts the user can read, latest
+	// bookmark first.
+	ListBookmarked(user_id uint, limit, offset int) ([]models.Post, error)
+	// CountBookmarks returns how many bookmarked posts the user can read and
+	// how many bookmarks they have.
+	CountBookmarks(user_id uint) (visible, total int64, err error)
+
+	FindList(id uint) (models.ReadingList, error)
+	FindOwnedList(id, user_id uint) (models.ReadingList, error)
+	// ListLists returns the reading lists of a user, last updated first
+	ListLists(user_id uint, public_only bool) ([]models.ReadingList, error)
+	// ListDetails loads what the responses of the lists show to the user:
+	// the owners with their avatars, and how many posts of each list the
+	// user can read.
+	ListDetails(lists []models.ReadingList, user_id uint) (models.ReadingListDetails, error)
+	CreateList(list *models.ReadingList) error
+	UpdateList(list *models.ReadingList, changes map[string]interface{}) error
+	// DeleteList removes a list with its items, the posts are not touched
+	DeleteList(list models.ReadingList) error
+
+	// ListItems returns in order the items of a list whose posts the user
+	// can read, with those posts in the same order.
+	ListItems(list_id, user_id uint) ([]models.ReadingListItem, []models.Post, error)
+	FindItem(list_id, post_id uint) (models.ReadingListItem, error)
+	CountItems(list_id uint) (int64, error)
+	ItemPostIDs(list_id uint) ([]uint, error)
+	// AddItem inserts the item at its position, moving the items from that
+	// position on down one.
+	AddItem(list models.ReadingList, item *models.ReadingListItem) error
+	// UpdateItem saves the note of an item and moves it to position.
+	UpdateItem(list models.ReadingList, item models.ReadingListItem, position int) (models.ReadingListItem, error)
+	// RemoveItem removes a post from a list, the items after it move up one
+	RemoveItem(list models.ReadingList, post_id uint) error
+	// Order sets the positions of the items in the order of post_ids
+	Order(list models.ReadingList, post_ids []uint) error
+}
+
+type gormBookmarkRepository struct {
+	db *gorm.DB
+}
+
+func NewBookmarkRepository(db *gorm.DB) BookmarkRepository {
+	return &gormBookmarkRepository{db: db}
+}
+
+func (r *gormBookmarkRepository) Bookmark(user_id, post_id uint) (models.Bookmark, error) {
+	bookmark := models.Bookmark{}
+	err := r.db.Where(models.Bookmark{UserID: user_id, PostID: post_id}).FirstOrCreate(&bookmark).Error
+	return bookmark, err
+}
+
+func (r *gormBookmarkRepository) RemoveBookmark(user_id, post_id uint) error {
+	return r.db.Where("user_id = ? AND post_id = ?", user_id, post_id).Delete(&models.Bookmark{}).Error
+}
+
+func (r *gormBookmarkRepository) bookmarked(user_id uint) *gorm.DB {
+	return r.db.Scopes(models.VisiblePosts(user_id)).
+		Joins("JOIN bookmarks ON bookmarks.post_id = posts.id AND bookmarks.user_id = ?", user_id)
+}
+
+func (r *gormBookmarkRepository) ListBookmarked(user_id uint, limit, offset int) ([]models.Post, error) {
+	var posts []models.Post
+	err := r.bookmarked(user_id).Order("bookmarks.created_at desc").Limit(limit).Offset(offset).Find(&posts).Error
+	return posts, err
+}
+
+func (r *gormBookmarkRepository) CountBookmarks(user_id uint) (visible, total int64, err error) {
+	if err := r.bookmarked(user_id).Model(&models.Post{}).Count(&visible).Error; err != nil {
+		return 0, 0, err
+	}
+	if err := r.db.Model(&models.Bookmark{}).Where("user_id = ?", user_id).Count(&total).Error; err != nil {
+		return 0, 0, err
+	}
+	return visible, total, nil
+}
+
+func (r *gormBookmarkRepository) FindList(id uint) (models.ReadingList, error) {
+	list := models.ReadingList{}
+	err := r.db.Where("id = ?", id).Take(&list).Error
+	return list, err
+}
+
+func (r *gormBookmarkRepository) FindOwnedList(id, user_id uint) (models.ReadingList, error) {
+	list := models.ReadingList{}
+	err := r.db.Where("id = ? AND user_id = ?", id, user_id).Take(&list).Error
+	return list, err
+}
+
+func (r *gormBookmarkRepository) ListLists(user_id uint, public_only bool) ([]models.ReadingList, error) {
+	query := r.db.Where("user_id = ?", user_id)
+	if public_only {
+		query = query.Where("is_public = ?", true)
+	}
+	var lists []models.ReadingList
+	err := query.Order("updated_at desc").Find(&lists).Error
+	return lists, err
+}
+
+func (r *gormBookmarkRepository) ListDetails(lists []models.ReadingList, user_id uint) (models.ReadingListDetails, error) {
+	details := models.ReadingListDetails{Owners: map[uint]models.User{}, PostCounts: map[uint]int64{}}
+	owner_ids := []uint{}
+	for _, list := range lists {
+		owner_ids = append(owner_ids, list.UserID)
+		var post_count int64 = 0
+		if err := r.db.Model(&models.ReadingListItem{}).
+			Joins("JOIN posts ON posts.id = reading_list_items.post_id AND posts.deleted_at IS NULL").
+			Scopes(models.VisiblePosts(user_id)).
+			Where("reading_list_items.reading_list_id = ?", list.ID).Count(&post_count).Error; err != nil {
+			return models.ReadingListDetails{}, err
+		}
+		details.PostCounts[list.ID] = post_count
+	}
+	owners := []models.User{}
+	if len(owner_ids) > 0 {
+		if err := r.db.Where("id IN ?", owner_ids).Find(&owners).Error; err != nil {
+			return models.ReadingListDetails{}, err
+		}
+	}
+	for _, owner := range owners {
+		details.Owners[owner.ID] = owner
+	}
+	avatars, err := avatarsOf(r.db, owners)
+	if err != nil {
+		return models.ReadingListDetails{}, err
+	}
+	details.Avatars = avatars
+	return details, nil
+}
+
+func (r *gormBookmarkRepository) CreateList(list *models.ReadingList) error {
+	return r.db.Create(list).Error
+}
+
+func (r *gormBookmarkRepository) UpdateList(list *models.ReadingList, changes map[string]interface{}) error {
+	return r.db.Model(list).Updates(changes).Error
+}
+
+func (r *gormBookmarkRepository) DeleteList(list models.ReadingList) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("reading_list_id = ?", list.ID).Delete(&models.ReadingListItem{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&list).Error
+	})
+}
+
+func (r *gormBookmarkRepository) ListItems(list_id, user_id uint) ([]models.ReadingListItem, []models.Post, error) {
+	var items []models.ReadingListItem
+	if err := r.db.Joins("JOIN posts ON posts.id = reading_list_items.post_id AND posts.deleted_at IS NULL").
+		Scopes(models.VisiblePosts(user_id)).
+		Where("reading_list_items.reading_list_id = ?", list_id).
+		Order("reading_list_items.position").Find(&items).Error; err != nil {
+		return nil, nil, err
+	}
+	post_ids := make([]uint, len(items))
+	for i, item := range items {
+		post_ids[i] = item.PostID
+	}
+	var posts []models.Post
+	if err := r.db.Where("id IN ?", post_ids).Find(&posts).Error; err != nil {
+		return nil, nil, err
+	}
+	posts_by_id := map[uint]models.Post{}
+	for _, post := range posts {
+		posts_by_id[post.ID] = post
+	}
+	ordered := make([]models.Post, len(items))
+	for i, item := range items {
+		ordered[i] = posts_by_id[item.PostID]
+	}
+	return items, ordered, nil
+}
+
+func (r *gormBookmarkRepository) FindItem(list_id, post_id uint) (models.ReadingListItem, error) {
+	item := models.ReadingListItem{}
+	err := r.db.Where("reading_list_id = ? AND post_id = ?", list_id, post_id).Take(&item).Error
+	return item, err
+}
+
+func (r *gormBookmarkRepository) CountItems(list_id uint) (int64, error) {
+	var item_count int64 = 0
+	err := r.db.Model(&models.ReadingListItem{}).Where("reading_list_id = ?", list_id).Count(&item_count).Error
+	return item_count, err
+}
+
+func (r *gormBookmarkRepository) ItemPostIDs(list_id uint) ([]uint, error) {
+	var post_ids []uint
+	err := r.db.Model(&models.ReadingListItem{}).Where("reading_list_id = ?", list_id).Pluck("post_id", &post_ids).Error
+	return post_ids, err
+}
+
+func (r *gormBookmarkRepository) AddItem(list models.ReadingList, item *models.ReadingListItem) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.ReadingListItem{}).Where("reading_list_id = ? AND position >= ?", list.ID, item.Position).
+			Update("position", gorm.Expr("position + 1")).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(item).Error; err != nil {
+			return err
+		}
+		return touchReadingList(tx, list)
+	})
+}
+
+func (r *gormBookmarkRepository) UpdateItem(list models.ReadingList, item models.ReadingListItem, position int) (models.ReadingListItem, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := moveReadingListItem(tx, item, position); err != nil {
+			return err
+		}
+		item.Position = position
+		if err := tx.Model(&item).Updates(map[string]interface{}{"note": item.Note, "position": item.Position}).Error; err != nil {
+			return err
+		}
+		return touchReadingList(tx, list)
+	})
+	return item, err
+}
+
+func (r *gormBookmarkRepository) RemoveItem(list models.ReadingList, post_id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var item models.ReadingListItem
+		if err := tx.Where("reading_list_id = ? AND post_id = ?", list.ID, post_id).Take(&item).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&item).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.ReadingListItem{}).Where("reading_list_id = ? AND position > ?", list.ID, item.Position).
+			Update("position", gorm.Expr("position - 1")).Error; err != nil {
+			return err
+		}
+		return touchReadingList(tx, list)
+	})
+}
+
+func (r *gormBookmarkRepository) Order(list models.ReadingList, post_ids []uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for i, post_id := range post_ids {
+			if err := tx.Model(&models.ReadingListItem{}).Where("reading_list_id = ? AND post_id = ?", list.ID, post_id).
+				Update("position", i+1).Error; err != nil {
+				return err
+			}
+		}
+		return touchReadingList(tx, list)
+	})
+}
+
+// bookmarkedPostIDs returns which of the posts the user bookmarked.
+func bookmarkedPostIDs(db *gorm.DB, user_id uint, post_ids []uint) (map[uint]bool, error) {
+	bookmarked := map[uint]bool{}
+	if user_id == 0 || len(post_ids) == 0 {
+		return bookmarked, nil
+	}
+	var ids []uint
+	if err := db.Model(&models.Bookmark{}).Where("user_id = ? AND post_id IN ?", user_id, post_ids).Pluck("post_id", &ids).Error; err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		bookmarked[id] = true
+	}
+	return bookmarked, nil
+}
+
+// deletePostCollections removes a deleted post from every bookmark and
+// reading list, closing the gap it leaves in the list order.
+func deletePostCollections(tx *gorm.DB, post_id uint) error {
+	var items []models.ReadingListItem
+	if err := tx.Where("post_id = ?", post_id).Find(&items).Error; err != nil {
+		return err
+	}
+	for _, item := range items {
+		if err := tx.Delete(&item).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.ReadingListItem{}).Where("reading_list_id = ? AND position > ?", item.ReadingListID, item.Position).
+			Update("position", gorm.Expr("position - 1")).Error; err != nil {
+			return err
+		}
+	}
+	return tx.Where("post_id = ?", post_id).Delete(&models.Bookmark{}).Error
+}
+
+// moveReadingListItem shifts the posts between the old and new position of
+// the item so positions stay without gaps. The item itself is not saved.
+func moveReadingListItem(tx *gorm.DB, item models.ReadingListItem, position int) error {
+	query := tx.Model(&models.ReadingListItem{}).Where("reading_list_id = ? AND post_id <> ?", item.ReadingListID, item.PostID)
+	if position < item.Position {
+		return query.Where("position >= ? AND position < ?", position, item.Position).Update("position", gorm.Expr("position + 1")).Error
+	}
+	if position > item.Position {
+		return query.Where("position > ? AND position <= ?", item.Position, position).Update("position", gorm.Expr("position - 1")).Error
+	}
+	return nil
+}
+
+func touchReadingList(tx *gorm.DB, list models.ReadingList) error {
+	return tx.Model(&list).Update("updated_at", time.Now()).Error
+}
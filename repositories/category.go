@@ -0,0 +1,57 @@
+package repositories
+
+import (
+	"blogspot-project/models"
+
+	"gorm.io/gorm"
+)
+
+type CategoryRepository interface {
+	FindByID(id uint) (models.Category, error)
+	// List searches categories by name
+	List(search string) ([]models.Category, error)
+	Create(category *models.Category) error
+	Update(category models.Category, changes models.Category) (models.Category, error)
+	// Delete removes the category with its follows
+	Delete(category models.Category) error
+}
+
+type gormCategoryRepository struct {
+	db *gorm.DB
+}
+
+func NewCategoryRepository(db *gorm.DB) CategoryRepository {
+	return &gormCategoryRepository{db: db}
+}
+
+func (r *gormCategoryRepository) FindByID(id uint) (models.Category, error) {
+	category := models.Category{}
+	err := r.db.Where("id = ?", id).Take(&category).Error
+	return category, err
+}
+
+func (r *gormCategoryRepository) List(search string) ([]models.Category, error) {
+	var categories []models.Category
+	err := r.db.Scopes(models.Contains("name", search)).Order("id").Find(&categories).Error
+	return categories, err
+}
+
+func (r *gormCategoryRepository) Create(category *models.Category) error {
+	return r.db.Create(category).Error
+}
+
+func (r *gormCategoryRepository) Update(category models.Category, changes models.Category) (models.Category, error) {
+	if err := r.db.Model(&category).Updates(&changes).Error; err != nil {
+		return models.Category{}, err
+	}
+	return r.FindByID(category.ID)
+}
+
+func (r *gormCategoryRepository) Delete(category models.Category) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("category_id = ?", category.ID).Delete(&models.CategoryFollow{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&category).Error
+	})
+}
@@ -0,0 +1,283 @@
+package repositories
+
+import (
+	"blogspot-project/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type CommentRepository interface {
+	FindByID(id uint) (models.Comment, error)
+	FindInPost(post_id, id uint) (models.Comment, error)
+	// FindVisible loads a comment the user can read that is not deleted, see
+	// models.VisibleComments
+	FindVisible(id, user_id uint) (models.Comment, error)
+	FindVisibleInPost(post_id, id, user_id uint) (models.Comment, error)
+	// FindManyByID loads the comments with the given ids that are not
+	// deleted
+	FindManyByID(ids []uint) ([]models.Comment, error)
+	// List, ListRoots, ListThreadReplies and ListReplies only return the
+	// comments the user can read, leaving out those of users they blocked
+	// or muted.
+	List(post_id, user_id uint, search string, limit, offset int) ([]models.Comment, error)
+	ListRoots(post_id, user_id uint, limit, offset int) ([]models.Comment, error)
+	ListThreadReplies(thread_ids []uint, user_id uint) ([]models.Comment, error)
+	ListReplies(parent_id, user_id uint, limit, offset int) ([]models.Comment, error)
+	// ListEdits returns the previous versions of a comment, newest first
+	ListEdits(comment_id uint) ([]models.CommentEdit, error)
+	// Reactions returns the reaction summary of each comment for the user
+	Reactions(ids []uint, user_id uint) (map[uint]models.ReactionSummary, error)
+	// ListByStatus returns the comments in a moderation status, oldest
+	// first
+	ListByStatus(status uint, limit, offset int) ([]models.Comment, error)
+	ModerationPolicy() (models.ModerationPolicy, error)
+	// UpdateModerationPolicy writes the given columns of changes, even when
+	// they are empty, and returns the saved policy.
+	UpdateModerationPolicy(changes models.ModerationPolicy, columns []string) (models.ModerationPolicy, error)
+	CountApproved(user_id uint) (int64, error)
+	// Create saves a comment with its mentions. An approved comment counts
+	// as a reply of its parent.
+	Create(comment *models.Comment) error
+	// Edit replaces the content of a comment, keeping the previous one in
+	// the edit history, and moves it to status.
+	Edit(comment models.Comment, editor_id uint, content string, status uint, score float64) (models.Comment, error)
+	// SetStatus moves a comment to a moderation status and spam label,
+	// keeping the reply count of its parent in line.
+	SetStatus(comment models.Comment, status, label uint) error
+	Remove(comment models.Comment) error
+}
+
+type gormCommentRepository struct {
+	db *gorm.DB
+}
+
+func NewCommentRepository(db *gorm.DB) CommentRepository {
+	return &gormCommentRepository{db: db}
+}
+
+func (r *gormCommentRepository) FindByID(id uint) (models.Comment, error) {
+	comment := models.Comment{}
+	err := r.db.Where("id = ?", id).Take(&comment).Error
+	return comment, err
+}
+
+func (r *gormCommentRepository) FindInPost(post_id, id uint) (models.Comment, error) {
+	comment := models.Comment{}
+	err := r.db.Where("post_id = ? AND id = ?", post_id, id).Take(&comment).Error
+	return comment, err
+}
+
+func (r *gormCommentRepository) FindVisible(id, user_id uint) (models.Comment, error) {
+	comment := models.Comment{}
+	err := r.db.Scopes(models.VisibleComments(user_id)).Where("id = ? AND is_deleted = ?", id, false).Take(&comment).Error
+	return comment, err
+}
+
+func (r *gormCommentRepository) FindVisibleInPost(post_id, id, user_id uint) (models.Comment, error) {
+	comment := models.Comment{}
+	err := r.db.Scopes(models.VisibleComments(user_id)).Where("post_id = ? AND id = ?", post_id, id).Take(&comment).Error
+	return comment, err
+}
+
+func (r *gormCommentRepository) FindManyByID(ids []uint) ([]models.Comment, error) {
+	var comments []models.Comment
+	err := r.db.Where("id IN ? AND is_deleted = ?", ids, false).Find(&comments).Error
+	return comments, err
+}
+
+func (r *gormCommentRepository) visible(user_id uint) *gorm.DB {
+	return r.db.Scopes(models.VisibleComments(user_id), models.WithoutHiddenUsers("comments.user_id", user_id))
+}
+
+func (r *gormCommentRepository) List(post_id, user_id uint, search string, limit, offset int) ([]models.Comment, error) {
+	var comments []models.Comment
+	err := r.visible(user_id).Scopes(models.Contains("comment_content", search)).Where("post_id = ?", post_id).Order("id").Limit(limit).Offset(offset).Find(&comments).Error
+	return comments, err
+}
+
+func (r *gormCommentRepository) ListRoots(post_id, user_id uint, limit, offset int) ([]models.Comment, error) {
+	var comments []models.Comment
+	err := r.visible(user_id).Where("post_id = ? AND parent_id IS NULL", post_id).Order("id").Limit(limit).Offset(offset).Find(&comments).Error
+	return comments, err
+}
+
+func (r *gormCommentRepository) ListThreadReplies(thread_ids []uint, user_id uint) ([]models.Comment, error) {
+	var comments []models.Comment
+	err := r.visible(user_id).Where("thread_id IN ? AND parent_id IS NOT NULL", thread_ids).Order("id").Find(&comments).Error
+	return comments, err
+}
+
+func (r *gormCommentRepository) ListReplies(parent_id, user_id uint, limit, offset int) ([]models.Comment, error) {
+	var comments []models.Comment
+	err := r.visible(user_id).Where("parent_id = ?", parent_id).Order("id").Limit(limit).Offset(offset).Find(&comments).Error
+	return comments, err
+}
+
+func (r *gormCommentRepository) ListEdits(comment_id uint) ([]models.CommentEdit, error) {
+	var edits []models.CommentEdit
+	err := r.db.Where("comment_id = ?", comment_id).Order("id desc").Find(&edits).Error
+	return edits, err
+}
+
+func (r *gormCommentRepository) Reactions(ids []uint, user_id uint) (map[uint]models.ReactionSummary, error) {
+	return reactionSummaries(r.db, models.TARGET_COMMENT, ids, user_id)
+}
+
+func (r *gormCommentRepository) ListByStatus(status uint, limit, offset int) ([]models.Comment, error) {
+	var comments []models.Comment
+	err := r.db.Where("moderation_status = ?", status).Order("id").Limit(limit).Offset(offset).Find(&comments).Error
+	return comments, err
+}
+
+func (r *gormCommentRepository) ModerationPolicy() (models.ModerationPolicy, error) {
+	return models.GetModerationPolicy(r.db)
+}
+
+func (r *gormCommentRepository) UpdateModerationPolicy(changes models.ModerationPolicy, columns []string) (models.ModerationPolicy, error) {
+	policy, err := r.ModerationPolicy()
+	if err != nil {
+		return models.ModerationPolicy{}, err
+	}
+	if len(columns) > 0 {
+		if err := r.db.Model(&policy).Select(columns).Updates(&changes).Error; err != nil {
+			return models.ModerationPolicy{}, err
+		}
+	}
+	return r.ModerationPolicy()
+}
+
+func (r *gormCommentRepository) CountApproved(user_id uint) (int64, error) {
+	var approved int64 = 0
+	err := r.db.Model(&models.Comment{}).Where("user_id = ? AND moderation_status = ? AND is_deleted = ?", user_id, models.COMMENT_STATUS_APPROVED, false).Count(&approved).Error
+	return approved, err
+}
+
+func (r *gormCommentRepository) Create(comment *models.Comment) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(comment).Error; err != nil {
+			return err
+		}
+		rendered, err := syncMentions(tx, comment.UserID, models.TARGET_COMMENT, comment.ID, comment.PostID, comment.CommentContent, true)
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(comment).UpdateColumn("rendered_content", rendered).Error; err != nil {
+			return err
+		}
+		if comment.ParentID == nil {
+			comment.ThreadID = comment.ID
+			if err := tx.Model(comment).UpdateColumn("thread_id", comment.ID).Error; err != nil {
+				return err
+			}
+		}
+		if comment.ModerationStatus != models.COMMENT_STATUS_APPROVED || comment.ParentID == nil {
+			return nil
+		}
+		return tx.Model(&models.Comment{}).Where("id = ?", *comment.ParentID).UpdateColumn("reply_count", gorm.Expr("reply_count + 1")).Error
+	})
+	if err != nil {
+		return err
+	}
+	return r.db.Where("id = ?", comment.ID).Take(comment).Error
+}
+
+func (r *gormCommentRepository) Edit(comment models.Comment, editor_id uint, content string, status uint, score float64) (models.Comment, error) {
+	updatedComment := models.Comment{}
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		edit := models.CommentEdit{
+			CommentID:       comment.ID,
+			EditorID:        editor_id,
+			PreviousContent: comment.CommentContent,
+		}
+		if err := tx.Create(&edit).Error; err != nil {
+			return err
+		}
+		rendered, err := syncMentions(tx, comment.UserID, models.TARGET_COMMENT, comment.ID, comment.PostID, content, true)
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(&comment).Updates(map[string]interface{}{
+			"comment_content":  content,
+			"rendered_content": rendered,
+			"edited_at":        time.Now(),
+			"spam_score":       score,
+		}).Error; err != nil {
+			return err
+		}
+		if comment.ModerationStatus != status {
+			if err := setCommentStatus(tx, comment, status, comment.SpamLabel); err != nil {
+				return err
+			}
+		}
+		return tx.Where("id = ?", comment.ID).Take(&updatedComment).Error
+	})
+	return updatedComment, err
+}
+
+func (r *gormCommentRepository) SetStatus(comment models.Comment, status, label uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return setCommentStatus(tx, comment, status, label)
+	})
+}
+
+func (r *gormCommentRepository) Remove(comment models.Comment) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return removeComment(tx, comment)
+	})
+}
+
+// removeComment deletes a comment. While it still has replies it is kept as
+// a tombstone instead, and a tombstone whose last reply goes is removed too.
+func removeComment(tx *gorm.DB, comment models.Comment) error {
+	var replies int64 = 0
+	if err := tx.Model(&models.Comment{}).Where("parent_id = ?", comment.ID).Count(&replies).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("target_type = ? AND target_id = ?", models.TARGET_COMMENT, comment.ID).Delete(&models.Mention{}).Error; err != nil {
+		return err
+	}
+	if replies > 0 {
+		return tx.Model(&comment).Updates(map[string]interface{}{"comment_content": models.DELETED_COMMENT_CONTENT, "rendered_content": models.DELETED_COMMENT_CONTENT, "is_deleted": true}).Error
+	}
+	if err := tx.Delete(&comment).Error; err != nil {
+		return err
+	}
+	if comment.ParentID == nil {
+		return nil
+	}
+	// reply_count only counts approved replies
+	if comment.ModerationStatus == models.COMMENT_STATUS_APPROVED {
+		if err := tx.Model(&models.Comment{}).Where("id = ? AND reply_count > 0", *comment.ParentID).UpdateColumn("reply_count", gorm.Expr("reply_count - 1")).Error; err != nil {
+			return err
+		}
+	}
+	var parent models.Comment
+	if err := tx.Where("id = ?", *comment.ParentID).Take(&parent).Error; err != nil {
+		return err
+	}
+	if parent.IsDeleted {
+		return removeComment(tx, parent)
+	}
+	return nil
+}
+
+// setCommentStatus moves a comment to a new moderation status and spam
+// label, keeping the reply count of its parent in line with the approved
+// replies.
+func setCommentStatus(tx *gorm.DB, comment models.Comment, status, label uint) error {
+	previous := comment.ModerationStatus
+	if err := tx.Model(&comment).Updates(map[string]interface{}{"moderation_status": status, "spam_label": label}).Error; err != nil {
+		return err
+	}
+	if comment.ParentID == nil {
+		return nil
+	}
+	if previous != models.COMMENT_STATUS_APPROVED && status == models.COMMENT_STATUS_APPROVED {
+		return tx.Model(&models.Comment{}).Where("id = ?", *comment.ParentID).UpdateColumn("reply_count", gorm.Expr("reply_count + 1")).Error
+	}
+	if previous == models.COMMENT_STATUS_APPROVED && status != models.COMMENT_STATUS_APPROVED {
+		return tx.Model(&models.Comment{}).Where("id = ? AND reply_count > 0", *comment.ParentID).UpdateColumn("reply_count", gorm.Expr("reply_count - 1")).Error
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package repositories
+
+import (
+	"blogspot-project/models"
+
+	"gorm.io/gorm"
+)
+
+// FollowRepository keeps who follows, blocks and mutes whom, and the
+// categories users follow.
+type FollowRepository interface {
+	FollowCategory(user_id, category_id uint) (models.CategoryFollow, error)
+	UnfollowCategory(user_id, category_id uint) error
+	// FollowedCategories returns the categories a user follows by name
+	FollowedCategories(user_id uint) ([]models.Category, error)
+	// FollowUser is not created when the user already followed them
+	FollowUser(follower_id, following_id uint) (follow models.UserFollow, created bool, err error)
+	UnfollowUser(follower_id, following_id uint) error
+	// Followers and Following list the users on the other side of the
+	// follows of a user, latest first
+	Followers(user_id uint, limit, offset int) ([]models.User, error)
+	Following(user_id uint, limit, offset int) ([]models.User, error)
+	// Block blocks a user and removes the follows between them both
+	Block(user_id, blocked_id uint) (models.UserBlock, error)
+	Unblock(user_id, blocked_id uint) error
+	Mute(user_id, muted_id uint) (models.UserMute, error)
+	Unmute(user_id, muted_id uint) error
+	// Blocked and Muted list the users a user blocked or muted, latest first
+	Blocked(user_id uint) ([]models.User, error)
+	Muted(user_id uint) ([]models.User, error)
+	// HasBlockBetween is true when either user blocked the other one
+	HasBlockBetween(user_id, other_id uint) (bool, error)
+}
+
+type gormFollowRepository struct {
+	db *gorm.DB
+}
+
+func NewFollowRepository(db *gorm.DB) FollowRepository {
+	return &gormFollowRepository{db: db}
+}
+
+func (r *gormFollowRepository) FollowCategory(user_id, category_id uint) (models.CategoryFollow, error) {
+	follow := models.CategoryFollow{}
+	err := r.db.Where(models.CategoryFollow{UserID: user_id, CategoryID: category_id}).FirstOrCreate(&follow).Error
+	return follow, err
+}
+
+func (r *gormFollowRepository) UnfollowCategory(user_id, category_id uint) error {
+	return r.db.Where("user_id = ? AND category_id = ?", user_id, category_id).Delete(&models.CategoryFollow{}).Error
+}
+
+func (r *gormFollowRepository) FollowedCategories(user_id uint) ([]models.Category, error) {
+	var categories []models.Category
+	err := r.db.Joins("JOIN category_follows ON category_follows.category_id = categories.id AND category_follows.user_id = ?", user_id).
+		Order("categories.name").Find(&categories).Error
+	return categories, err
+}
+
+func (r *gormFollowRepository) FollowUser(follower_id, following_id uint) (models.UserFollow, bool, error) {
+	follow := models.UserFollow{}
+	result := r.db.Where(models.UserFollow{FollowerID: follower_id, FollowingID: following_id}).FirstOrCreate(&follow)
+	return follow, result.RowsAffected > 0, result.Error
+}
+
+func (r *gormFollowRepository) UnfollowUser(follower_id, following_id uint) error {
+	return r.db.Where("follower_id = ? AND following_id = ?", follower_id, following_id).Delete(&models.UserFollow{}).Error
+}
+
+func (r *gormFollowRepository) Followers(user_id uint, limit, offset int) ([]models.User, error) {
+	return r.follows(user_id, "following_id", "follower_id", limit, offset)
+}
+
+func (r *gormFollowRepository) Following(user_id uint, limit, offset int) ([]models.User, error) {
+	return r.follows(user_id, "follower_id", "following_id", limit, offset)
+}
+
+// follows lists the users on the other side of the follows of a user,
+// user_column being the side of that user.
+func (r *gormFollowRepository) follows(user_id uint, user_column, other_column string, limit, offset int) ([]models.User, error) {
+	var users []models.User
+	err := r.db.Joins("JOIN user_follows ON user_follows."+other_column+" = users.id AND user_follows."+user_column+" = ?", user_id).
+		Order("user_follows.created_at desc").Limit(limit).Offset(offset).Find(&users).Error
+	return users, err
+}
+
+func (r *gormFollowRepository) Block(user_id, blocked_id uint) (models.UserBlock, error) {
+	block := models.UserBlock{}
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where(models.UserBlock{UserID: user_id, BlockedID: blocked_id}).FirstOrCreate(&block).Error; err != nil {
+			return err
+		}
+		return tx.Where("(follower_id = ? AND following_id = ?) OR (follower_id = ? AND following_id = ?)", user_id, blocked_id, blocked_id, user_id).
+			Delete(&models.UserFollow{}).Error
+	})
+	return block, err
+}
+
+func (r *gormFollowRepository) Unblock(user_id, blocked_id uint) error {
+	return r.db.Where("user_id = ? AND blocked_id = ?", user_id, blocked_id).Delete(&models.UserBlock{}).Error
+}
+
+func (r *gormFollowRepository) Mute(user_id, muted_id uint) (models.UserMute, error) {
+	mute := models.UserMute{}
+	err := r.db.Where(models.UserMute{UserID: user_id, MutedID: muted_id}).FirstOrCreate(&mute).Error
+	return mute, err
+}
+
+func (r *gormFollowRepository) Unmute(user_id, muted_id uint) error {
+	return r.db.Where("user_id = ? AND muted_id = ?", user_id, muted_id).Delete(&models.UserMute{}).Error
+}
+
+func (r *gormFollowRepository) Blocked(user_id uint) ([]models.User, error) {
+	return r.hidden(user_id, "user_blocks", "blocked_id")
+}
+
+func (r *gormFollowRepository) Muted(user_id uint) ([]models.User, error) {
+	return r.hidden(user_id, "user_mutes", "muted_id")
+}
+
+func (r *gormFollowRepository) hidden(user_id uint, table, column string) ([]models.User, error) {
+	var users []models.User
+	err := r.db.Joins("JOIN "+table+" ON "+table+"."+column+" = users.id AND "+table+".user_id = ?", user_id).
+		Order(table + ".created_at desc").Find(&users).Error
+	return users, err
+}
+
+func (r *gormFollowRepository) HasBlockBetween(user_id, other_id uint) (bool, error) {
+	return models.HasBlockBetween(r.db, user_id, other_id)
+}
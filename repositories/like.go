@@ -0,0 +1,197 @@
+package repositories
+
+import (
+	"blogspot-project/models"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ReactionTarget is the post or comment a reaction is on.
+type ReactionTarget struct {
+	TargetType string
+	TargetID   uint
+	PostID     uint
+	OwnerID    uint
+}
+
+type LikeRepository interface {
+	// SetReaction stores the reaction of a user on a target, an empty
+	// reaction removes it, and reports whether anything changed.
+	SetReaction(user_id uint, target ReactionTarget, reaction string) (bool, error)
+	Summary(target ReactionTarget, user_id uint) (models.ReactionSummary, error)
+	// List returns the reactions on a target, newest first, optionally only
+	// of one type. Reactions of users who blocked user_id are left out. A
+	// limit of -1 returns them all.
+	List(target_type string, target_id uint, reaction string, user_id uint, limit, offset int) ([]models.Reaction, error)
+}
+
+type gormLikeRepository struct {
+	db *gorm.DB
+}
+
+func NewLikeRepository(db *gorm.DB) LikeRepository {
+	return &gormLikeRepository{db: db}
+}
+
+func (r *gormLikeRepository) SetReaction(user_id uint, target ReactionTarget, reaction string) (bool, error) {
+	changed := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		changed, err = setReaction(tx, user_id, target, reaction)
+		return err
+	})
+	return changed, err
+}
+
+func (r *gormLikeRepository) Summary(target ReactionTarget, user_id uint) (models.ReactionSummary, error) {
+	summaries, err := reactionSummaries(r.db, target.TargetType, []uint{target.TargetID}, user_id)
+	if err != nil {
+		return models.ReactionSummary{}, err
+	}
+	return summaries[target.TargetID], nil
+}
+
+func (r *gormLikeRepository) List(target_type string, target_id uint, reaction string, user_id uint, limit, offset int) ([]models.Reaction, error) {
+	query := r.db.Scopes(models.WithoutBlockers("user_id", user_id)).Where("target_type = ? AND target_id = ?", target_type, target_id)
+	if reaction != "" {
+		query = query.Where("type = ?", reaction)
+	}
+	var reactions []models.Reaction
+	err := query.Order("id desc").Limit(limit).Offset(offset).Find(&reactions).Error
+	return reactions, err
+}
+
+// setReaction moves the counters by the difference of the new reaction.
+// The row is locked so concurrent requests of the same user are applied one
+// after the other.
+func setReaction(tx *gorm.DB, user_id uint, target ReactionTarget, reaction string) (bool, error) {
+	var existing models.Reaction
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("user_id = ? AND target_type = ? AND target_id = ?", user_id, target.TargetType, target.TargetID).Take(&existing).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, err
+	}
+	previous := ""
+	if err == nil {
+		previous = existing.Type
+	}
+	if previous == reaction {
+		return false, nil
+	}
+	switch {
+	case reaction == "":
+		result := tx.Where("id = ?", existing.ID).Delete(&models.Reaction{})
+		if result.Error != nil {
+			return false, result.Error
+		}
+		if result.RowsAffected == 0 {
+			return false, nil
+		}
+	case previous != "":
+		if err := tx.Model(&existing).Update("type", reaction).Error; err != nil {
+			return false, err
+		}
+	default:
+		// a concurrent request of the same user may have inserted first, the
+		// unique index turns this insert into a no-op and that row is updated
+		result := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "target_type"}, {Name: "target_id"}},
+			DoNothing: true,
+		}).Create(&models.Reaction{
+			UserID:     user_id,
+			TargetType: target.TargetType,
+			TargetID:   target.TargetID,
+			PostID:     target.PostID,
+			Type:       reaction,
+		})
+		if result.Error != nil {
+			return false, result.Error
+		}
+		if result.RowsAffected == 0 {
+			return setReaction(tx, user_id, target, reaction)
+		}
+	}
+	if err := addReactionCount(tx, target, previous, -1); err != nil {
+		return false, err
+	}
+	if err := addReactionCount(tx, target, reaction, 1); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// addReactionCount moves the count of one reaction type on a target by
+// delta, along with the like and dislike counters of posts and comments
+// that clients read.
+func addReactionCount(tx *gorm.DB, target ReactionTarget, reaction string, delta int) error {
+	if reaction == "" {
+		return nil
+	}
+	if err := tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "target_type"}, {Name: "target_id"}, {Name: "type"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"total": gorm.Expr("reaction_counts.total + ?", delta)}),
+	}).Create(&models.ReactionCount{TargetType: target.TargetType, TargetID: target.TargetID, Type: reaction, Total: int64(delta)}).Error; err != nil {
+		return err
+	}
+	column := ""
+	switch reaction {
+	case models.REACTION_LIKE:
+		column = "like_count"
+	case models.REACTION_DISLIKE:
+		column = "dislike_count"
+	default:
+		return nil
+	}
+	var model interface{} = &models.Post{}
+	if target.TargetType == models.TARGET_POST {
+		column = "post_" + column
+	} else {
+		column = "comment_" + column
+		model = &models.Comment{}
+	}
+	value := gorm.Expr(column+" + ?", delta)
+	if delta < 0 {
+		// counters are unsigned, a drifted one stays at 0 until reconciled
+		value = gorm.Expr("CASE WHEN "+column+" >= ? THEN "+column+" - ? ELSE 0 END", -delta, -delta)
+	}
+	return tx.Model(model).Where("id = ?", target.TargetID).UpdateColumn(column, value).Error
+}
+
+// reactionSummaries returns the reaction summary of each target. Anonymous
+// callers (user_id 0) get no user reaction.
+func reactionSummaries(db *gorm.DB, target_type string, ids []uint, user_id uint) (map[uint]models.ReactionSummary, error) {
+	summaries := map[uint]models.ReactionSummary{}
+	for _, id := range ids {
+		counts := map[string]int64{}
+		for _, name := range models.ReactionTypes() {
+			counts[name] = 0
+		}
+		summaries[id] = models.ReactionSummary{Counts: counts}
+	}
+	if len(ids) == 0 {
+		return summaries, nil
+	}
+	var rows []models.ReactionCount
+	if err := db.Where("target_type = ? AND target_id IN ?", target_type, ids).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		summaries[row.TargetID].Counts[row.Type] = row.Total
+	}
+	if user_id == 0 {
+		return summaries, nil
+	}
+	var own []models.Reaction
+	if err := db.Where("user_id = ? AND target_type = ? AND target_id IN ?", user_id, target_type, ids).Find(&own).Error; err != nil {
+		return nil, err
+	}
+	for _, reaction := range own {
+		reaction := reaction
+		summary := summaries[reaction.TargetID]
+		summary.UserReaction = &reaction.Type
+		summaries[reaction.TargetID] = summary
+	}
+	return summaries, nil
+}
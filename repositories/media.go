@@ -0,0 +1,101 @@
+package repositories
+
+import (
+	"blogspot-project/models"
+
+	"gorm.io/gorm"
+)
+
+// mediaByID loads media with their variants keyed by id.
+func mediaByID(db *gorm.DB, ids []uint) (map[uint]models.Media, error) {
+	result := map[uint]models.Media{}
+	if len(ids) == 0 {
+		return result, nil
+	}
+	var list []models.Media
+	if err := db.Preload("Variants").Where("id IN ?", ids).Find(&list).Error; err != nil {
+		return nil, err
+	}
+	for _, item := range list {
+		result[item.ID] = item
+	}
+	return result, nil
+}
+
+// findOwnedImage loads an image uploaded by the given user, for fields like
+// a featured image or an avatar that may only point to the caller's own
+// media.
+func findOwnedImage(db *gorm.DB, media_id, user_id uint) (models.Media, error) {
+	item := models.Media{}
+	err := db.Preload("Variants").Where("id = ? AND user_id = ? AND width > 0", media_id, user_id).Take(&item).Error
+	return item, err
+}
+
+type MediaRepository interface {
+	// FindByChecksum loads the media a user already uploaded with the same
+	// content
+	FindByChecksum(user_id uint, checksum string) (models.Media, error)
+	// List returns the media of a user, newest first
+	List(user_id uint, limit, offset int) ([]models.Media, error)
+	// FindOwned loads a media of the user with its variants
+	FindOwned(id, user_id uint) (models.Media, error)
+	FindOwnedImage(media_id, user_id uint) (models.Media, error)
+	Create(item *models.Media) error
+	CreateVariant(variant *models.MediaVariant) error
+	// Delete removes a media with its variants and clears the posts and
+	// users that still point to it
+	Delete(item models.Media) error
+}
+
+type gormMediaRepository struct {
+	db *gorm.DB
+}
+
+func NewMediaRepository(db *gorm.DB) MediaRepository {
+	return &gormMediaRepository{db: db}
+}
+
+func (r *gormMediaRepository) FindByChecksum(user_id uint, checksum string) (models.Media, error) {
+	item := models.Media{}
+	err := r.db.Preload("Variants").Where("user_id = ? AND checksum = ?", user_id, checksum).Take(&item).Error
+	return item, err
+}
+
+func (r *gormMediaRepository) List(user_id uint, limit, offset int) ([]models.Media, error) {
+	var list []models.Media
+	err := r.db.Preload("Variants").Where("user_id = ?", user_id).Order("id DESC").Limit(limit).Offset(offset).Find(&list).Error
+	return list, err
+}
+
+func (r *gormMediaRepository) FindOwned(id, user_id uint) (models.Media, error) {
+	item := models.Media{}
+	err := r.db.Preload("Variants").Where("id = ? AND user_id = ?", id, user_id).Take(&item).Error
+	return item, err
+}
+
+func (r *gormMediaRepository) FindOwnedImage(media_id, user_id uint) (models.Media, error) {
+	return findOwnedImage(r.db, media_id, user_id)
+}
+
+func (r *gormMediaRepository) Create(item *models.Media) error {
+	return r.db.Create(item).Error
+}
+
+func (r *gormMediaRepository) CreateVariant(variant *models.MediaVariant) error {
+	return r.db.Create(variant).Error
+}
+
+func (r *gormMediaRepository) Delete(item models.Media) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Post{}).Where("featured_image_id = ?", item.ID).Update("featured_image_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.User{}).Where("avatar_media_id = ?", item.ID).Updates(map[string]interface{}{"avatar_media_id": nil, "image_url": ""}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("media_id = ?", item.ID).Delete(&models.MediaVariant{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&item).Error
+	})
+}
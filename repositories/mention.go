@@ -0,0 +1,86 @@
+package repositories
+
+import (
+	"blogspot-project/models"
+	"blogspot-project/utils/mention"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// syncMentions brings the mention records of a post or comment in line with
+// its content and returns the content with mentions rendered as links.
+// Nobody is notified here, see notification.Notifier.Mentions.
+func syncMentions(tx *gorm.DB, author_id uint, target_type string, target_id, post_id uint, content string, escape bool) (string, error) {
+	names := mention.Parse(content)
+	for i, name := range names {
+		names[i] = strings.ToLower(name)
+	}
+	var users []models.User
+	if len(names) > 0 {
+		if err := tx.Where("LOWER(username) IN ?", names).Find(&users).Error; err != nil {
+			return "", err
+		}
+	}
+	// users who blocked the author are left as plain text
+	var blockers []uint
+	if len(users) > 0 {
+		if err := tx.Model(&models.UserBlock{}).Where("blocked_id = ?", author_id).Pluck("user_id", &blockers).Error; err != nil {
+			return "", err
+		}
+	}
+	blocked := map[uint]bool{}
+	for _, id := range blockers {
+		blocked[id] = true
+	}
+	known := map[string]string{}
+	mentioned := map[uint]bool{}
+	for _, user := range users {
+		if blocked[user.ID] {
+			continue
+		}
+		known[strings.ToLower(user.Username)] = user.Username
+		if user.ID != author_id {
+			mentioned[user.ID] = true
+		}
+	}
+	var existing []models.Mention
+	if err := tx.Where("target_type = ? AND target_id = ?", target_type, target_id).Find(&existing).Error; err != nil {
+		return "", err
+	}
+	for _, item := range existing {
+		if mentioned[item.UserID] {
+			delete(mentioned, item.UserID)
+			continue
+		}
+		if err := tx.Delete(&item).Error; err != nil {
+			return "", err
+		}
+	}
+	for user_id := range mentioned {
+		newMention := models.Mention{
+			UserID:      user_id,
+			MentionerID: author_id,
+			TargetType:  target_type,
+			TargetID:    target_id,
+			PostID:      post_id,
+		}
+		if err := tx.Create(&newMention).Error; err != nil {
+			return "", err
+		}
+	}
+	return mention.Render(content, known, escape), nil
+}
+
+// updatePostMentions renders the mentions of a saved post.
+func updatePostMentions(db *gorm.DB, post models.Post) (models.Post, error) {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		rendered, err := syncMentions(tx, post.UserID, models.TARGET_POST, post.ID, post.ID, post.ArticleContent, false)
+		if err != nil {
+			return err
+		}
+		post.RenderedContent = rendered
+		return tx.Model(&post).UpdateColumn("rendered_content", rendered).Error
+	})
+	return post, err
+}
@@ -0,0 +1,121 @@
+package repositories
+
+import (
+	"blogspot-project/models"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type NotificationRepository interface {
+	// List returns the notifications of a user, latest activity first
+	List(user_id uint, unread bool, limit, offset int) ([]models.Notification, error)
+	// Actors returns up to limit of the latest people behind a notification,
+	// most recent first
+	Actors(notification_id uint, limit int) ([]models.User, error)
+	CountUnread(user_id uint) (int64, error)
+	// FindOwned loads a notification of the user
+	FindOwned(id, user_id uint) (models.Notification, error)
+	MarkRead(item models.Notification) (models.Notification, error)
+	// MarkAllRead returns how many notifications were unread
+	MarkAllRead(user_id uint) (int64, error)
+	// Preference is the default one until the user changes it
+	Preference(user_id uint) (models.NotificationPreference, error)
+	// UpdatePreference writes the given columns of changes, even when they
+	// are false, and returns the saved preference.
+	UpdatePreference(user_id uint, changes models.NotificationPreference, columns []string) (models.NotificationPreference, error)
+	// Mentions returns the notified mentions of a user, newest first,
+	// leaving out those by users they blocked or muted
+	Mentions(user_id uint, limit, offset int) ([]models.Mention, error)
+}
+
+type gormNotificationRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationRepository(db *gorm.DB) NotificationRepository {
+	return &gormNotificationRepository{db: db}
+}
+
+func (r *gormNotificationRepository) List(user_id uint, unread bool, limit, offset int) ([]models.Notification, error) {
+	query := r.db.Where("user_id = ?", user_id)
+	if unread {
+		query = query.Where("read_at IS NULL")
+	}
+	var notifications []models.Notification
+	err := query.Order("updated_at desc, id desc").Limit(limit).Offset(offset).Find(&notifications).Error
+	return notifications, err
+}
+
+func (r *gormNotificationRepository) Actors(notification_id uint, limit int) ([]models.User, error) {
+	var actors []models.NotificationActor
+	if err := r.db.Where("notification_id = ?", notification_id).Order("created_at desc").Limit(limit).Find(&actors).Error; err != nil {
+		return nil, err
+	}
+	users := []models.User{}
+	for _, actor := range actors {
+		var user models.User
+		if err := r.db.Where("id = ?", actor.ActorID).Take(&user).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, nil
+}
+
+func (r *gormNotificationRepository) CountUnread(user_id uint) (int64, error) {
+	var count int64 = 0
+	err := r.db.Model(&models.Notification{}).Where("user_id = ? AND read_at IS NULL", user_id).Count(&count).Error
+	return count, err
+}
+
+func (r *gormNotificationRepository) FindOwned(id, user_id uint) (models.Notification, error) {
+	item := models.Notification{}
+	err := r.db.Where("id = ? AND user_id = ?", id, user_id).Take(&item).Error
+	return item, err
+}
+
+func (r *gormNotificationRepository) MarkRead(item models.Notification) (models.Notification, error) {
+	if item.ReadAt != nil {
+		return item, nil
+	}
+	now := time.Now()
+	if err := r.db.Model(&item).UpdateColumn("read_at", now).Error; err != nil {
+		return models.Notification{}, err
+	}
+	item.ReadAt = &now
+	return item, nil
+}
+
+func (r *gormNotificationRepository) MarkAllRead(user_id uint) (int64, error) {
+	result := r.db.Model(&models.Notification{}).Where("user_id = ? AND read_at IS NULL", user_id).UpdateColumn("read_at", time.Now())
+	return result.RowsAffected, result.Error
+}
+
+func (r *gormNotificationRepository) Preference(user_id uint) (models.NotificationPreference, error) {
+	return models.GetNotificationPreference(r.db, user_id)
+}
+
+func (r *gormNotificationRepository) UpdatePreference(user_id uint, changes models.NotificationPreference, columns []string) (models.NotificationPreference, error) {
+	preference := models.NotificationPreference{}
+	if err := r.db.Where(models.NotificationPreference{UserID: user_id}).Attrs(models.DefaultNotificationPreference(user_id)).FirstOrCreate(&preference).Error; err != nil {
+		return models.NotificationPreference{}, err
+	}
+	if len(columns) > 0 {
+		if err := r.db.Model(&preference).Select(columns).Updates(&changes).Error; err != nil {
+			return models.NotificationPreference{}, err
+		}
+	}
+	return r.Preference(user_id)
+}
+
+func (r *gormNotificationRepository) Mentions(user_id uint, limit, offset int) ([]models.Mention, error) {
+	var mentions []models.Mention
+	err := r.db.Scopes(models.WithoutHiddenUsers("mentioner_id", user_id)).Where("user_id = ? AND notified = ?", user_id, true).
+		Order("id desc").Limit(limit).Offset(offset).Find(&mentions).Error
+	return mentions, err
+}
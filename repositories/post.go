@@ -0,0 +1,250 @@
+package repositories
+
+import (
+	"blogspot-project/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// FeedFilter narrows the published posts of a syndication feed to a
+// category, an author or a tag, zero fields are not filtered on.
+type FeedFilter struct {
+	CategoryID uint
+	UserID     uint
+	TagID      uint
+}
+
+type PostRepository interface {
+	FindByID(id uint) (models.Post, error)
+	// FindVisible loads a post the user can read, see models.VisiblePosts
+	FindVisible(id, user_id uint) (models.Post, error)
+	FindByAuthor(id, user_id uint) (models.Post, error)
+	// List searches by title the posts the user can read, leaving out the
+	// posts of users they blocked or muted.
+	List(user_id uint, search string, limit, offset int) ([]models.Post, error)
+	ListPublishedByAuthor(user_id uint, limit, offset int) ([]models.Post, error)
+	ListPublishedInCategory(category_id uint, limit, offset int) ([]models.Post, error)
+	ListPublishedWithTag(tag_id uint, limit, offset int) ([]models.Post, error)
+	// ListPublishedByID loads the published posts with the given ids in
+	// their order, leaving out the posts of users the user blocked or muted
+	ListPublishedByID(ids []uint, user_id uint) ([]models.Post, error)
+	// FeedState counts the published posts of a feed and returns when the
+	// newest of them changed, the unix epoch when there are none
+	FeedState(filter FeedFilter) (count int64, last_modified time.Time, err error)
+	// ListFeed returns the latest published posts of a feed
+	ListFeed(filter FeedFilter, limit int) ([]models.Post, error)
+	// VisibleIDs returns which of the posts the user can read
+	VisibleIDs(ids []uint64, user_id uint) ([]uint, error)
+	// Details loads what the responses of the posts show to the user: the
+	// featured images, the tags, and the reactions and bookmarks of the user.
+	// Anonymous users (user_id 0) get no reaction and no bookmark.
+	Details(posts []models.Post, user_id uint) (models.PostDetails, error)
+	// Create and Update render the mentions of the saved post.
+	Create(post *models.Post) error
+	Update(post models.Post, changes models.Post) (models.Post, error)
+	// SetHidden hides a post from everyone but its author, or shows it
+	// again
+	SetHidden(post models.Post, hidden bool) (models.Post, error)
+	// SetTags replaces the tags of a post by the tags with the given names,
+	// which must have distinct slugs
+	SetTags(post models.Post, names []string) error
+	// Delete removes the post with its mentions, bookmarks and reading list
+	// entries.
+	Delete(post models.Post) error
+}
+
+type gormPostRepository struct {
+	db *gorm.DB
+}
+
+func NewPostRepository(db *gorm.DB) PostRepository {
+	return &gormPostRepository{db: db}
+}
+
+func (r *gormPostRepository) FindByID(id uint) (models.Post, error) {
+	post := models.Post{}
+	err := r.db.Where("id = ?", id).Take(&post).Error
+	return post, err
+}
+
+func (r *gormPostRepository) FindVisible(id, user_id uint) (models.Post, error) {
+	post := models.Post{}
+	err := r.db.Scopes(models.VisiblePosts(user_id)).Where("id = ?", id).Take(&post).Error
+	return post, err
+}
+
+func (r *gormPostRepository) FindByAuthor(id, user_id uint) (models.Post, error) {
+	post := models.Post{}
+	err := r.db.Where("id = ? AND user_id = ?", id, user_id).Take(&post).Error
+	return post, err
+}
+
+func (r *gormPostRepository) List(user_id uint, search string, limit, offset int) ([]models.Post, error) {
+	var posts []models.Post
+	err := r.db.Scopes(models.VisiblePosts(user_id), models.WithoutHiddenUsers("posts.user_id", user_id), models.Contains("article_title", search)).
+		Order("posts.id").Limit(limit).Offset(offset).Find(&posts).Error
+	return posts, err
+}
+
+func (r *gormPostRepository) ListPublishedByAuthor(user_id uint, limit, offset int) ([]models.Post, error) {
+	var posts []models.Post
+	err := r.db.Scopes(models.PublishedPosts).Where("user_id = ?", user_id).
+		Order("published_at desc").Order("id desc").Limit(limit).Offset(offset).Find(&posts).Error
+	return posts, err
+}
+
+func (r *gormPostRepository) ListPublishedInCategory(category_id uint, limit, offset int) ([]models.Post, error) {
+	var posts []models.Post
+	err := r.db.Scopes(models.PublishedPosts).Where("category_id = ?", category_id).Order("published_at DESC").Limit(limit).Offset(offset).Find(&posts).Error
+	return posts, err
+}
+
+func (r *gormPostRepository) ListPublishedWithTag(tag_id uint, limit, offset int) ([]models.Post, error) {
+	var posts []models.Post
+	err := r.db.Scopes(models.PublishedPosts).Where("id IN (SELECT post_id FROM post_tags WHERE tag_id = ?)", tag_id).
+		Order("published_at DESC").Limit(limit).Offset(offset).Find(&posts).Error
+	return posts, err
+}
+
+func (r *gormPostRepository) ListPublishedByID(ids []uint, user_id uint) ([]models.Post, error) {
+	var posts []models.Post
+	if err := r.db.Scopes(models.PublishedPosts, models.WithoutHiddenUsers("posts.user_id", user_id)).Where("id IN ?", ids).Find(&posts).Error; err != nil {
+		return nil, err
+	}
+	posts_by_id := map[uint]models.Post{}
+	for _, post := range posts {
+		posts_by_id[post.ID] = post
+	}
+	ordered := []models.Post{}
+	for _, id := range ids {
+		if post, ok := posts_by_id[id]; ok {
+			ordered = append(ordered, post)
+		}
+	}
+	return ordered, nil
+}
+
+func (f FeedFilter) scope(db *gorm.DB) *gorm.DB {
+	db = models.PublishedPosts(db)
+	if f.CategoryID != 0 {
+		db = db.Where("posts.category_id = ?", f.CategoryID)
+	}
+	if f.UserID != 0 {
+		db = db.Where("posts.user_id = ?", f.UserID)
+	}
+	if f.TagID != 0 {
+		db = db.Where("posts.id IN (SELECT post_id FROM post_tags WHERE tag_id = ?)", f.TagID)
+	}
+	return db
+}
+
+func (r *gormPostRepository) FeedState(filter FeedFilter) (int64, time.Time, error) {
+	var count int64 = 0
+	if err := r.db.Model(&models.Post{}).Scopes(filter.scope).Count(&count).Error; err != nil {
+		return 0, time.Time{}, err
+	}
+	if count == 0 {
+		return 0, time.Unix(0, 0).UTC(), nil
+	}
+	latest := models.Post{}
+	if err := r.db.Select("updated_at").Scopes(filter.scope).Order("updated_at DESC").Take(&latest).Error; err != nil {
+		return 0, time.Time{}, err
+	}
+	return count, latest.UpdatedAt, nil
+}
+
+func (r *gormPostRepository) ListFeed(filter FeedFilter, limit int) ([]models.Post, error) {
+	var posts []models.Post
+	err := r.db.Scopes(filter.scope).Order("published_at DESC").Limit(limit).Find(&posts).Error
+	return posts, err
+}
+
+func (r *gormPostRepository) VisibleIDs(ids []uint64, user_id uint) ([]uint, error) {
+	var posts []models.Post
+	if err := r.db.Scopes(models.VisiblePosts(user_id)).Select("id").Where("id IN ?", ids).Find(&posts).Error; err != nil {
+		return nil, err
+	}
+	result := []uint{}
+	for _, post := range posts {
+		result = append(result, post.ID)
+	}
+	return result, nil
+}
+
+func (r *gormPostRepository) Details(posts []models.Post, user_id uint) (models.PostDetails, error) {
+	ids := make([]uint, len(posts))
+	media_ids := []uint{}
+	for i, post := range posts {
+		ids[i] = post.ID
+		if post.FeaturedImageID != nil {
+			media_ids = append(media_ids, *post.FeaturedImageID)
+		}
+	}
+	images, err := mediaByID(r.db, media_ids)
+	if err != nil {
+		return models.PostDetails{}, err
+	}
+	tags, err := tagsByPost(r.db, ids)
+	if err != nil {
+		return models.PostDetails{}, err
+	}
+	reactions, err := reactionSummaries(r.db, models.TARGET_POST, ids, user_id)
+	if err != nil {
+		return models.PostDetails{}, err
+	}
+	bookmarked, err := bookmarkedPostIDs(r.db, user_id, ids)
+	if err != nil {
+		return models.PostDetails{}, err
+	}
+	return models.PostDetails{Images: images, Tags: tags, Reactions: reactions, Bookmarked: bookmarked}, nil
+}
+
+func (r *gormPostRepository) Create(post *models.Post) error {
+	if err := r.db.Create(post).Error; err != nil {
+		return err
+	}
+	saved, err := r.FindByID(post.ID)
+	if err != nil {
+		return err
+	}
+	*post, err = updatePostMentions(r.db, saved)
+	return err
+}
+
+func (r *gormPostRepository) Update(post models.Post, changes models.Post) (models.Post, error) {
+	if err := r.db.Model(&post).Updates(&changes).Error; err != nil {
+		return models.Post{}, err
+	}
+	saved, err := r.FindByID(post.ID)
+	if err != nil {
+		return models.Post{}, err
+	}
+	return updatePostMentions(r.db, saved)
+}
+
+func (r *gormPostRepository) SetHidden(post models.Post, hidden bool) (models.Post, error) {
+	if err := r.db.Model(&post).Update("is_hidden", hidden).Error; err != nil {
+		return models.Post{}, err
+	}
+	return post, nil
+}
+
+func (r *gormPostRepository) SetTags(post models.Post, names []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return setPostTags(tx, post.ID, names)
+	})
+}
+
+func (r *gormPostRepository) Delete(post models.Post) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&post).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("target_type = ? AND target_id = ?", models.TARGET_POST, post.ID).Delete(&models.Mention{}).Error; err != nil {
+			return err
+		}
+		// bookmarks and reading lists only keep posts that can come back
+		return deletePostCollections(tx, post.ID)
+	})
+}
@@ -0,0 +1,94 @@
+package repositories
+
+import (
+	"blogspot-project/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ReportRepository interface {
+	FindByID(id uint) (models.Report, error)
+	// HasReported is true when the user already reported the target
+	HasReported(reporter_id uint, target_type string, target_id uint) (bool, error)
+	// List returns the reports with a status, oldest first, only those on
+	// target_type when it is set
+	List(status uint, target_type string, limit, offset int) ([]models.Report, error)
+	// ListOpen returns the open reports on a target
+	ListOpen(target_type string, target_id uint) ([]models.Report, error)
+	// ListFiledBy and ListAgainst return the reports filed by a user and
+	// those against their content, newest first
+	ListFiledBy(user_id uint) ([]models.Report, error)
+	ListAgainst(user_id uint) ([]models.Report, error)
+	Create(report *models.Report) error
+	// Hold records that the report hid its target
+	Hold(report *models.Report) error
+	// Resolve closes every open report on the target with the moderator's
+	// action
+	Resolve(target_type string, target_id uint, status uint, action string, moderator_id uint) error
+}
+
+type gormReportRepository struct {
+	db *gorm.DB
+}
+
+func NewReportRepository(db *gorm.DB) ReportRepository {
+	return &gormReportRepository{db: db}
+}
+
+func (r *gormReportRepository) FindByID(id uint) (models.Report, error) {
+	report := models.Report{}
+	err := r.db.Where("id = ?", id).Take(&report).Error
+	return report, err
+}
+
+func (r *gormReportRepository) HasReported(reporter_id uint, target_type string, target_id uint) (bool, error) {
+	var count int64 = 0
+	err := r.db.Model(&models.Report{}).Where("reporter_id = ? AND target_type = ? AND target_id = ?", reporter_id, target_type, target_id).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *gormReportRepository) List(status uint, target_type string, limit, offset int) ([]models.Report, error) {
+	query := r.db.Where("status = ?", status)
+	if target_type != "" {
+		query = query.Where("target_type = ?", target_type)
+	}
+	var reports []models.Report
+	err := query.Order("id").Limit(limit).Offset(offset).Find(&reports).Error
+	return reports, err
+}
+
+func (r *gormReportRepository) open(target_type string, target_id uint) *gorm.DB {
+	return r.db.Model(&models.Report{}).Where("target_type = ? AND target_id = ? AND status = ?", target_type, target_id, models.REPORT_STATUS_OPEN)
+}
+
+func (r *gormReportRepository) ListOpen(target_type string, target_id uint) ([]models.Report, error) {
+	var reports []models.Report
+	err := r.open(target_type, target_id).Order("id").Find(&reports).Error
+	return reports, err
+}
+
+func (r *gormReportRepository) ListFiledBy(user_id uint) ([]models.Report, error) {
+	var reports []models.Report
+	err := r.db.Where("reporter_id = ?", user_id).Order("id desc").Find(&reports).Error
+	return reports, err
+}
+
+func (r *gormReportRepository) ListAgainst(user_id uint) ([]models.Report, error) {
+	var reports []models.Report
+	err := r.db.Where("target_user_id = ?", user_id).Order("id desc").Find(&reports).Error
+	return reports, err
+}
+
+func (r *gormReportRepository) Create(report *models.Report) error {
+	return r.db.Create(report).Error
+}
+
+func (r *gormReportRepository) Hold(report *models.Report) error {
+	return r.db.Model(report).Update("held_by_report", true).Error
+}
+
+func (r *gormReportRepository) Resolve(target_type string, target_id uint, status uint, action string, moderator_id uint) error {
+	return r.open(target_type, target_id).
+		Updates(map[string]interface{}{"status": status, "action": action, "resolved_by_id": moderator_id, "resolved_at": time.Now()}).Error
+}
@@ -0,0 +1,64 @@
+package repositories
+
+import (
+	"blogspot-project/models"
+
+	"gorm.io/gorm"
+)
+
+type TagRepository interface {
+	FindBySlug(slug string) (models.Tag, error)
+}
+
+type gormTagRepository struct {
+	db *gorm.DB
+}
+
+func NewTagRepository(db *gorm.DB) TagRepository {
+	return &gormTagRepository{db: db}
+}
+
+func (r *gormTagRepository) FindBySlug(slug string) (models.Tag, error) {
+	tag := models.Tag{}
+	err := r.db.Where("slug = ?", slug).Take(&tag).Error
+	return tag, err
+}
+
+// tagsByPost loads the tags of the posts by post id, by name.
+func tagsByPost(db *gorm.DB, post_ids []uint) (map[uint][]models.Tag, error) {
+	result := map[uint][]models.Tag{}
+	if len(post_ids) == 0 {
+		return result, nil
+	}
+	var rows []struct {
+		models.Tag
+		PostID uint
+	}
+	err := db.Model(&models.Tag{}).Select("tags.*, post_tags.post_id").Joins("JOIN post_tags ON post_tags.tag_id = tags.id").
+		Where("post_tags.post_id IN ?", post_ids).Order("tags.name").Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		result[row.PostID] = append(result[row.PostID], row.Tag)
+	}
+	return result, nil
+}
+
+// setPostTags replaces the tags of a post, creating the tags that do not
+// exist yet. Names are expected to have distinct slugs.
+func setPostTags(tx *gorm.DB, post_id uint, names []string) error {
+	if err := tx.Where("post_id = ?", post_id).Delete(&models.PostTag{}).Error; err != nil {
+		return err
+	}
+	for _, name := range names {
+		tag := models.Tag{}
+		if err := tx.Where(models.Tag{Slug: models.TagSlug(name)}).Attrs(models.Tag{Name: name}).FirstOrCreate(&tag).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&models.PostTag{PostID: post_id, TagID: tag.ID}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
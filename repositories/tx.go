@@ -0,0 +1,74 @@
+package repositories
+
+import "gorm.io/gorm"
+
+// Tx holds repositories that all work inside one database transaction, see
+// UnitOfWork.
+type Tx struct {
+	Users    UserRepository
+	Posts    PostRepository
+	Comments CommentRepository
+	Reports  ReportRepository
+	Follows  FollowRepository
+
+	db          *gorm.DB
+	afterCommit []func()
+}
+
+func newTx(db *gorm.DB) *Tx {
+	return &Tx{
+		Users:    NewUserRepository(db),
+		Posts:    NewPostRepository(db),
+		Comments: NewCommentRepository(db),
+		Reports:  NewReportRepository(db),
+		Follows:  NewFollowRepository(db),
+		db:       db,
+	}
+}
+
+// DB is the transaction itself, for writers outside the repositories like
+// notification.Notifier.WithTx.
+func (tx *Tx) DB() *gorm.DB {
+	return tx.db
+}
+
+// AfterCommit keeps fn until the transaction commits, for changes that live
+// outside the database like the sitemap or events pushed to clients. They
+// are dropped when it rolls back.
+func (tx *Tx) AfterCommit(fn func()) {
+	tx.afterCommit = append(tx.afterCommit, fn)
+}
+
+// Committed runs the AfterCommit funcs in the order they were added.
+func (tx *Tx) Committed() {
+	for _, fn := range tx.afterCommit {
+		fn()
+	}
+	tx.afterCommit = nil
+}
+
+// UnitOfWork runs work in one transaction, committed when work returns nil
+// and rolled back otherwise.
+type UnitOfWork interface {
+	Do(work func(tx *Tx) error) error
+}
+
+type gormUnitOfWork struct {
+	db *gorm.DB
+}
+
+func NewUnitOfWork(db *gorm.DB) UnitOfWork {
+	return &gormUnitOfWork{db: db}
+}
+
+func (u *gormUnitOfWork) Do(work func(tx *Tx) error) error {
+	var unit *Tx
+	if err := u.db.Transaction(func(db *gorm.DB) error {
+		unit = newTx(db)
+		return work(unit)
+	}); err != nil {
+		return err
+	}
+	unit.Committed()
+	return nil
+}
@@ -0,0 +1,156 @@
+package repositories
+
+import (
+	"blogspot-project/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type UserRepository interface {
+	FindByID(id uint) (models.User, error)
+	FindByUsername(username string) (models.User, error)
+	FindManyByID(ids []uint) ([]models.User, error)
+	// Authors loads the authors of the posts keyed by user id
+	Authors(posts []models.Post) (map[uint]models.User, error)
+	// List searches users by name, leaving out the user exclude_id
+	List(exclude_id uint, search string, limit, offset int) ([]models.User, error)
+	// Create hashes the password of the user before saving it
+	Create(user *models.User) error
+	// Update writes the fields of changes that are set, and the given columns
+	// even when they are empty, and returns the saved user.
+	Update(user models.User, changes models.User, columns []string) (models.User, error)
+	UpdateRole(user *models.User, role uint) error
+	// Warn adds a warning to the user's count
+	Warn(user_id uint) error
+	Suspend(user_id uint, until time.Time) error
+	Delete(user models.User) error
+	FindOwnedImage(media_id, user_id uint) (models.Media, error)
+	// Avatars loads the avatar media of the users keyed by media id
+	Avatars(users []models.User) (map[uint]models.Media, error)
+	// Stats counts the published posts and the follows of a user
+	Stats(user_id uint) (models.UserStats, error)
+	// HasBlocked is true when user_id blocked other_id
+	HasBlocked(user_id, other_id uint) (bool, error)
+}
+
+type gormUserRepository struct {
+	db *gorm.DB
+}
+
+func NewUserRepository(db *gorm.DB) UserRepository {
+	return &gormUserRepository{db: db}
+}
+
+func (r *gormUserRepository) FindByID(id uint) (models.User, error) {
+	user := models.User{}
+	err := r.db.Where("id = ?", id).Take(&user).Error
+	return user, err
+}
+
+func (r *gormUserRepository) FindByUsername(username string) (models.User, error) {
+	user := models.User{}
+	err := r.db.Where("username = ?", username).Take(&user).Error
+	return user, err
+}
+
+func (r *gormUserRepository) FindManyByID(ids []uint) ([]models.User, error) {
+	var users []models.User
+	if len(ids) == 0 {
+		return users, nil
+	}
+	err := r.db.Where("id IN ?", ids).Find(&users).Error
+	return users, err
+}
+
+func (r *gormUserRepository) Authors(posts []models.Post) (map[uint]models.User, error) {
+	ids := make([]uint, 0, len(posts))
+	for _, post := range posts {
+		ids = append(ids, post.UserID)
+	}
+	users, err := r.FindManyByID(ids)
+	if err != nil {
+		return nil, err
+	}
+	result := map[uint]models.User{}
+	for _, user := range users {
+		result[user.ID] = user
+	}
+	return result, nil
+}
+
+func (r *gormUserRepository) List(exclude_id uint, search string, limit, offset int) ([]models.User, error) {
+	var users []models.User
+	err := r.db.Scopes(models.Contains("name", search)).Where("id != ?", exclude_id).Order("id").Limit(limit).Offset(offset).Find(&users).Error
+	return users, err
+}
+
+func (r *gormUserRepository) Create(user *models.User) error {
+	_, err := user.CreateUser(r.db)
+	return err
+}
+
+func (r *gormUserRepository) Update(user models.User, changes models.User, columns []string) (models.User, error) {
+	if err := r.db.Model(&user).Updates(&changes).Error; err != nil {
+		return models.User{}, err
+	}
+	if len(columns) > 0 {
+		if err := r.db.Model(&user).Select(columns).Updates(&changes).Error; err != nil {
+			return models.User{}, err
+		}
+	}
+	return r.FindByID(user.ID)
+}
+
+func (r *gormUserRepository) UpdateRole(user *models.User, role uint) error {
+	return r.db.Model(user).Update("role", role).Error
+}
+
+func (r *gormUserRepository) Warn(user_id uint) error {
+	return r.db.Model(&models.User{}).Where("id = ?", user_id).UpdateColumn("warning_count", gorm.Expr("warning_count + 1")).Error
+}
+
+func (r *gormUserRepository) Suspend(user_id uint, until time.Time) error {
+	return r.db.Model(&models.User{}).Where("id = ?", user_id).Update("suspended_until", until).Error
+}
+
+func (r *gormUserRepository) Delete(user models.User) error {
+	return r.db.Delete(&user).Error
+}
+
+func (r *gormUserRepository) FindOwnedImage(media_id, user_id uint) (models.Media, error) {
+	return findOwnedImage(r.db, media_id, user_id)
+}
+
+func (r *gormUserRepository) Avatars(users []models.User) (map[uint]models.Media, error) {
+	return avatarsOf(r.db, users)
+}
+
+func (r *gormUserRepository) Stats(user_id uint) (models.UserStats, error) {
+	stats := models.UserStats{}
+	if err := r.db.Model(&models.Post{}).Scopes(models.PublishedPosts).Where("user_id = ?", user_id).Count(&stats.PostCount).Error; err != nil {
+		return models.UserStats{}, err
+	}
+	if err := r.db.Model(&models.UserFollow{}).Where("following_id = ?", user_id).Count(&stats.FollowerCount).Error; err != nil {
+		return models.UserStats{}, err
+	}
+	if err := r.db.Model(&models.UserFollow{}).Where("follower_id = ?", user_id).Count(&stats.FollowingCount).Error; err != nil {
+		return models.UserStats{}, err
+	}
+	return stats, nil
+}
+
+func (r *gormUserRepository) HasBlocked(user_id, other_id uint) (bool, error) {
+	return models.HasBlocked(r.db, user_id, other_id)
+}
+
+// avatarsOf loads the avatar media of the users keyed by media id.
+func avatarsOf(db *gorm.DB, users []models.User) (map[uint]models.Media, error) {
+	ids := []uint{}
+	for _, user := range users {
+		if user.AvatarMediaID != nil {
+			ids = append(ids, *user.AvatarMediaID)
+		}
+	}
+	return mediaByID(db, ids)
+}
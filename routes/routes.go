@@ -3,6 +3,17 @@ package routes
 import (
 	"blogspot-project/controllers"
 	"blogspot-project/middlewares"
+	"blogspot-project/models"
+	"blogspot-project/presenters"
+	"blogspot-project/repositories"
+	"blogspot-project/services"
+	"blogspot-project/utils/notification"
+	"blogspot-project/utils/realtime"
+	"blogspot-project/utils/sitemap"
+	"blogspot-project/utils/spam"
+	"blogspot-project/utils/storage"
+	"blogspot-project/utils/timeline"
+	"blogspot-project/utils/validation"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -11,60 +22,251 @@ import (
 	ginSwagger "github.com/swaggo/gin-swagger" // gin-swagger middleware
 )
 
-func SetupRouter(db *gorm.DB) *gin.Engine {
+func SetupRouter(db *gorm.DB, store storage.Storage) *gin.Engine {
 	r := gin.Default()
+	validation.Register()
 
-	r.Use(func(c *gin.Context) {
-		c.Set("db", db)
+	sitemapIndex := sitemap.NewIndex(db)
+	timelineCache := timeline.NewCache(db)
+
+	// notifications are pushed to the connected clients of their user, and
+	// approved comments to the readers of their post
+	hub := realtime.NewHub()
+	notifier := notification.NewNotifier(db, func(item models.Notification) {
+		hub.Publish(realtime.UserTopic(item.UserID), realtime.EVENT_NOTIFICATION, presenters.ToNotification(item))
 	})
+	publishComment := func(comment models.Comment) {
+		hub.Publish(realtime.PostTopic(comment.PostID), realtime.EVENT_COMMENT, presenters.ToComment(comment))
+	}
+
+	userRepository := repositories.NewUserRepository(db)
+	postRepository := repositories.NewPostRepository(db)
+	commentRepository := repositories.NewCommentRepository(db)
+	categoryRepository := repositories.NewCategoryRepository(db)
+	likeRepository := repositories.NewLikeRepository(db)
+	bookmarkRepository := repositories.NewBookmarkRepository(db)
+	reportRepository := repositories.NewReportRepository(db)
+	followRepository := repositories.NewFollowRepository(db)
+	notificationRepository := repositories.NewNotificationRepository(db)
+	mediaRepository := repositories.NewMediaRepository(db)
+	tagRepository := repositories.NewTagRepository(db)
+	unitOfWork := repositories.NewUnitOfWork(db)
+
+	mediaService := services.NewMediaService(mediaRepository, store)
+	userService := services.NewUserService(userRepository, sitemapIndex, mediaService.PrepareAvatar)
+	postService := services.NewPostService(postRepository, userRepository, categoryRepository, sitemapIndex, notifier)
+	commentService := services.NewCommentService(commentRepository, postRepository, userRepository, unitOfWork, services.NotifierInTx(notifier), spam.NewClassifier(db), publishComment)
+	categoryService := services.NewCategoryService(categoryRepository, postRepository, sitemapIndex)
+	tagService := services.NewTagService(tagRepository, postRepository)
+	likeService := services.NewLikeService(likeRepository, postRepository, commentRepository, userRepository, hub, notifier)
+	bookmarkService := services.NewBookmarkService(bookmarkRepository, postRepository, userRepository)
+	followService := services.NewFollowService(followRepository, userRepository, categoryRepository, postRepository, unitOfWork, timelineCache, services.NotifierInTx(notifier))
+	notificationService := services.NewNotificationService(notificationRepository, userRepository)
+	feedService := services.NewFeedService(postRepository, userRepository, categoryRepository, tagRepository)
+	reportService := services.NewReportService(reportRepository, postRepository, commentRepository, userRepository, unitOfWork, sitemapIndex, services.NotifierInTx(notifier))
+
+	userController := controllers.NewUserController(userService, postService)
+	postController := controllers.NewPostController(postService)
+	commentController := controllers.NewCommentController(commentService)
+	categoryController := controllers.NewCategoryController(categoryService)
+	tagController := controllers.NewTagController(tagService)
+	likeController := controllers.NewLikeController(likeService)
+	bookmarkController := controllers.NewBookmarkController(bookmarkService)
+	readingListController := controllers.NewReadingListController(bookmarkService)
+	reportController := controllers.NewReportController(reportService)
+	followController := controllers.NewFollowController(followService, userService, postService)
+	notificationController := controllers.NewNotificationController(notificationService)
+	mediaController := controllers.NewMediaController(mediaService)
+	syndicationController := controllers.NewSyndicationController(feedService)
+	sitemapController := controllers.NewSitemapController(sitemapIndex)
+	realtimeController := controllers.NewRealtimeController(hub, postService)
+
+	r.Use(middlewares.ErrorHandler())
+
+	// uploads on the local disk are served by the api itself
+	if local, ok := store.(*storage.LocalStorage); ok {
+		r.Static("/uploads", local.Dir)
+	}
+
+	// syndication feeds of published posts, for the whole site, a category,
+	// an author or a tag
+	r.GET("/feed.xml", syndicationController.GetSiteFeed)
+	r.GET("/atom.xml", syndicationController.GetSiteFeed)
+	r.GET("/feed.json", syndicationController.GetSiteFeed)
+
+	r.GET("/sitemap.xml", sitemapController.GetSitemap)
+	r.GET("/sitemap/:page", sitemapController.GetSitemapPage)
+	r.GET("/robots.txt", controllers.GetRobots)
+
+	r.GET("/reactions", controllers.GetListReactionTypes)
 
 	AuthRoute := r.Group("/auth")
-	AuthRoute.POST("/login", controllers.LoginUser)
-	AuthRoute.POST("/register", controllers.RegisterNewUser)
+	AuthRoute.POST("/login", userController.LoginUser)
+	AuthRoute.POST("/register", userController.RegisterNewUser)
 
 	updateUserMiddlewareRoute := r.Group("/login")
 	updateUserMiddlewareRoute.Use(middlewares.JwtAuthMiddleware())
-	updateUserMiddlewareRoute.PATCH("/update-password", controllers.UpdatePassword)
-	updateUserMiddlewareRoute.PATCH("/update-current-user", controllers.UpdateCurrentUser)
+	updateUserMiddlewareRoute.PATCH("/update-password", userController.UpdatePassword)
+	updateUserMiddlewareRoute.PATCH("/update-current-user", userController.UpdateCurrentUser)
+
+	AuthorRoute := r.Group("/author")
+	AuthorRoute.GET("/:username", userController.GetAuthorProfile)
+	AuthorRoute.GET("/:username/posts", middlewares.OptionalJwtAuthMiddleware(), userController.GetAuthorPosts)
+	AuthorRoute.GET("/:username/feed.xml", syndicationController.GetAuthorFeed)
+	AuthorRoute.GET("/:username/atom.xml", syndicationController.GetAuthorFeed)
+	AuthorRoute.GET("/:username/feed.json", syndicationController.GetAuthorFeed)
+	AuthorRoute.GET("/:username/reading-lists", readingListController.GetAuthorReadingLists)
+	AuthorRoute.GET("/:username/followers", followController.GetListFollowers)
+	AuthorRoute.GET("/:username/following", followController.GetListFollowing)
+
+	AuthorUserRoute := r.Group("/author")
+	AuthorUserRoute.Use(middlewares.JwtAuthMiddleware())
+	AuthorUserRoute.POST("/:username/follow", followController.FollowUser)
+	AuthorUserRoute.DELETE("/:username/follow", followController.UnfollowUser)
+	AuthorUserRoute.POST("/:username/block", followController.BlockUser)
+	AuthorUserRoute.DELETE("/:username/block", followController.UnblockUser)
+	AuthorUserRoute.POST("/:username/mute", followController.MuteUser)
+	AuthorUserRoute.DELETE("/:username/mute", followController.UnmuteUser)
+
+	FeedRoute := r.Group("/feed")
+	FeedRoute.Use(middlewares.JwtAuthMiddleware())
+	FeedRoute.GET("", followController.GetFeed)
 
 	UserRoute := r.Group("/user")
 	UserRoute.Use(middlewares.JwtAuthMiddleware())
-	UserRoute.GET("/", controllers.GetListUsers)
-	UserRoute.GET("/profile", controllers.GetCurrentUserProfile)
-	UserRoute.DELETE("/:id", controllers.DeleteUser)
+	UserRoute.GET("/", userController.GetListUsers)
+	UserRoute.GET("/profile", userController.GetCurrentUserProfile)
+	UserRoute.GET("/mentions", notificationController.GetListMentions)
+	UserRoute.GET("/following/categories", followController.GetListFollowedCategories)
+	UserRoute.GET("/blocks", followController.GetListBlockedUsers)
+	UserRoute.GET("/mutes", followController.GetListMutedUsers)
+	UserRoute.DELETE("/:id", userController.DeleteUser)
+	UserRoute.PATCH("/:id/role", userController.UpdateUserRole)
+
+	PublicCategoryRoute := r.Group("/category")
+	PublicCategoryRoute.GET("/", categoryController.GetListCategories)
+	PublicCategoryRoute.GET("/:id", categoryController.GetDetailCategory)
+	PublicCategoryRoute.GET("/:id/feed.xml", syndicationController.GetCategoryFeed)
+	PublicCategoryRoute.GET("/:id/atom.xml", syndicationController.GetCategoryFeed)
+	PublicCategoryRoute.GET("/:id/feed.json", syndicationController.GetCategoryFeed)
+
+	TagRoute := r.Group("/tag")
+	TagRoute.GET("/:slug", tagController.GetDetailTag)
+	TagRoute.GET("/:slug/feed.xml", syndicationController.GetTagFeed)
+	TagRoute.GET("/:slug/atom.xml", syndicationController.GetTagFeed)
+	TagRoute.GET("/:slug/feed.json", syndicationController.GetTagFeed)
 
 	CategoryRoute := r.Group("/category")
 	CategoryRoute.Use(middlewares.JwtAuthMiddleware())
-	CategoryRoute.POST("/", controllers.CreateNewCategory)
-	CategoryRoute.PATCH("/:id", controllers.UpdateCategory)
-	CategoryRoute.DELETE("/:id", controllers.DeleteCategory)
-	CategoryRoute.GET("/", controllers.GetListCategories)
+	CategoryRoute.POST("/", categoryController.CreateNewCategory)
+	CategoryRoute.PATCH("/:id", categoryController.UpdateCategory)
+	CategoryRoute.DELETE("/:id", categoryController.DeleteCategory)
+	CategoryRoute.POST("/:id/follow", followController.FollowCategory)
+	CategoryRoute.DELETE("/:id/follow", followController.UnfollowCategory)
+
+	// read only post routes are public, a token only personalizes the response
+	PublicPostRoute := r.Group("/post")
+	PublicPostRoute.Use(middlewares.OptionalJwtAuthMiddleware())
+	PublicPostRoute.GET("/", postController.GetListBlogs)
+	PublicPostRoute.GET("/:id", postController.GetDetailPost)
+	PublicPostRoute.GET("/:id/comment", commentController.GetListComments)
+	PublicPostRoute.GET("/:id/comment/tree", commentController.GetCommentTree)
+	PublicPostRoute.GET("/:id/comment/:comment_id/thread", commentController.GetCommentThread)
+	PublicPostRoute.GET("/:id/reactions", likeController.GetListPostReactions)
+	PublicPostRoute.GET("/comment/:id/reactions", likeController.GetListCommentReactions)
 
 	PostRoute := r.Group("/post")
 	PostRoute.Use(middlewares.JwtAuthMiddleware())
 
 	//posts api section
-	PostRoute.POST("/", controllers.CreateNewPost)
-	PostRoute.GET("/", controllers.GetListBlogs)
-	PostRoute.DELETE("/:id", controllers.DeletePost)
-	PostRoute.PATCH("/:id", controllers.UpdatePost)
-	PostRoute.GET("/:id", controllers.GetDetailPost)
+	PostRoute.POST("/", postController.CreateNewPost)
+	PostRoute.DELETE("/:id", postController.DeletePost)
+	PostRoute.PATCH("/:id", postController.UpdatePost)
 
 	//comments api section
-	PostRoute.POST("/comment", controllers.CreateNewComment)
-	PostRoute.PATCH("/:id/comment/:comment_id", controllers.UpdateComment)
-	PostRoute.DELETE("/:id/comment/:comment_id", controllers.DeleteComment)
-	PostRoute.GET("/:id/comment", controllers.GetListComments)
+	PostRoute.POST("/comment", commentController.CreateNewComment)
+	PostRoute.PATCH("/:id/comment/:comment_id", commentController.UpdateComment)
+	PostRoute.DELETE("/:id/comment/:comment_id", commentController.DeleteComment)
+	PostRoute.GET("/:id/comment/:comment_id/history", commentController.GetCommentHistory)
 
 	//user like post api section
-	PostRoute.POST("/:id/like/:status", controllers.LikePostController)
-	PostRoute.GET("/:id/user-likes/", controllers.GetListUserLikePost)
-	PostRoute.GET("/:id/user-dislikes/", controllers.GetListUserDislikePost)
+	PostRoute.POST("/:id/like/:status", likeController.LikePostController)
+	PostRoute.GET("/:id/user-likes/", likeController.GetListUserLikePost)
+	PostRoute.GET("/:id/user-dislikes/", likeController.GetListUserDislikePost)
+
+	//reactions api section
+	PostRoute.PUT("/:id/reaction", likeController.ReactToPost)
+	PostRoute.DELETE("/:id/reaction", likeController.RemovePostReaction)
+	PostRoute.PUT("/comment/:id/reaction", likeController.ReactToComment)
+	PostRoute.DELETE("/comment/:id/reaction", likeController.RemoveCommentReaction)
+
+	//bookmarks api section
+	PostRoute.POST("/:id/bookmark", bookmarkController.BookmarkPost)
+	PostRoute.DELETE("/:id/bookmark", bookmarkController.RemoveBookmark)
 
 	//user like comment post api section
-	PostRoute.POST("/comment/:id/like/:status", controllers.LikeCommentController)
-	PostRoute.GET("/comment/:id/user-likes/", controllers.GetListUserLikeComment)
-	PostRoute.GET("/comment/:id/user-dislikes/", controllers.GetListUserDislikeComment)
+	PostRoute.POST("/comment/:id/like/:status", likeController.LikeCommentController)
+	PostRoute.GET("/comment/:id/user-likes/", likeController.GetListUserLikeComment)
+	PostRoute.GET("/comment/:id/user-dislikes/", likeController.GetListUserDislikeComment)
+
+	BookmarkRoute := r.Group("/bookmarks")
+	BookmarkRoute.Use(middlewares.JwtAuthMiddleware())
+	BookmarkRoute.GET("", bookmarkController.GetListBookmarks)
+
+	// public reading lists can be read without a login
+	PublicReadingListRoute := r.Group("/reading-lists")
+	PublicReadingListRoute.Use(middlewares.OptionalJwtAuthMiddleware())
+	PublicReadingListRoute.GET("/:id", readingListController.GetDetailReadingList)
+
+	ReadingListRoute := r.Group("/reading-lists")
+	ReadingListRoute.Use(middlewares.JwtAuthMiddleware())
+	ReadingListRoute.GET("", readingListController.GetListReadingLists)
+	ReadingListRoute.POST("", readingListController.CreateReadingList)
+	ReadingListRoute.PATCH("/:id", readingListController.UpdateReadingList)
+	ReadingListRoute.DELETE("/:id", readingListController.DeleteReadingList)
+	ReadingListRoute.PUT("/:id/order", readingListController.OrderReadingList)
+	ReadingListRoute.POST("/:id/posts", readingListController.AddReadingListPost)
+	ReadingListRoute.PATCH("/:id/posts/:post_id", readingListController.UpdateReadingListPost)
+	ReadingListRoute.DELETE("/:id/posts/:post_id", readingListController.RemoveReadingListPost)
+
+	// unsubscribe links from emails work without a login
+	EmailRoute := r.Group("/email")
+	EmailRoute.GET("/unsubscribe", notificationController.GetUnsubscribe)
+	EmailRoute.POST("/unsubscribe", notificationController.Unsubscribe)
+
+	RealtimeRoute := r.Group("/realtime")
+	RealtimeRoute.Use(middlewares.JwtAuthMiddleware())
+	RealtimeRoute.GET("/events", realtimeController.GetRealtimeEvents)
+	RealtimeRoute.GET("/ws", realtimeController.GetRealtimeSocket)
+
+	NotificationRoute := r.Group("/notifications")
+	NotificationRoute.Use(middlewares.JwtAuthMiddleware())
+	NotificationRoute.GET("", notificationController.GetListNotifications)
+	NotificationRoute.GET("/unread-count", notificationController.GetUnreadNotificationCount)
+	NotificationRoute.POST("/read-all", notificationController.ReadAllNotifications)
+	NotificationRoute.POST("/:id/read", notificationController.ReadNotification)
+	NotificationRoute.GET("/preferences", notificationController.GetNotificationPreference)
+	NotificationRoute.PATCH("/preferences", notificationController.UpdateNotificationPreference)
+
+	ModerationRoute := r.Group("/moderation")
+	ModerationRoute.Use(middlewares.JwtAuthMiddleware())
+	ModerationRoute.GET("/comments", commentController.GetModerationQueue)
+	ModerationRoute.POST("/comments", commentController.ModerateComments)
+	ModerationRoute.GET("/policy", commentController.GetModerationPolicy)
+	ModerationRoute.PATCH("/policy", commentController.UpdateModerationPolicy)
+	ModerationRoute.GET("/reports", reportController.GetListReports)
+	ModerationRoute.POST("/reports/:id/resolve", reportController.ResolveReport)
+	ModerationRoute.GET("/users/:id/reports", reportController.GetUserReportHistory)
+
+	ReportRoute := r.Group("/report")
+	ReportRoute.Use(middlewares.JwtAuthMiddleware())
+	ReportRoute.POST("/", reportController.CreateReport)
+
+	MediaRoute := r.Group("/media")
+	MediaRoute.Use(middlewares.JwtAuthMiddleware())
+	MediaRoute.POST("/", mediaController.UploadMedia)
+	MediaRoute.GET("/", mediaController.GetListMedia)
+	MediaRoute.DELETE("/:id", mediaController.DeleteMedia)
 
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
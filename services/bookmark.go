@@ -0,0 +1,262 @@
+package services
+
+import (
+	"blogspot-project/models"
+	"blogspot-project/repositories"
+	"blogspot-project/utils/apperror"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// ReadingListChanges holds the changes of an owner to a reading list, nil
+// fields are left unchanged.
+type ReadingListChanges struct {
+	Name        *string
+	Description *string
+	IsPublic    *bool
+}
+
+// ReadingListContent is a reading list with the posts the caller can read in
+// it, in order. UnavailableCount is only counted for the owner.
+type ReadingListContent struct {
+	List             models.ReadingList
+	Details          models.ReadingListDetails
+	Items            []models.ReadingListItem
+	Posts            []models.Post
+	PostDetails      models.PostDetails
+	UnavailableCount int64
+}
+
+type BookmarkService struct {
+	bookmarks repositories.BookmarkRepository
+	posts     repositories.PostRepository
+	users     repositories.UserRepository
+}
+
+func NewBookmarkService(bookmarks repositories.BookmarkRepository, posts repositories.PostRepository, users repositories.UserRepository) *BookmarkService {
+	return &BookmarkService{bookmarks: bookmarks, posts: posts, users: users}
+}
+
+// Bookmark saves a post the user can read to their bookmarks.
+func (s *BookmarkService) Bookmark(user_id, post_id uint) (models.Bookmark, error) {
+	post, err := s.posts.FindVisible(post_id, user_id)
+	if err != nil {
+		return models.Bookmark{}, apperror.Lookup(err, apperror.CODE_POST_NOT_FOUND, "Post not found")
+	}
+	return s.bookmarks.Bookmark(user_id, post.ID)
+}
+
+func (s *BookmarkService) RemoveBookmark(user_id, post_id uint) error {
+	return s.bookmarks.RemoveBookmark(user_id, post_id)
+}
+
+// Bookmarks returns a page of the bookmarked posts the user can read with
+// their details, and how many bookmarked posts are unpublished or hidden for
+// now.
+func (s *BookmarkService) Bookmarks(user_id uint, limit, offset int) ([]models.Post, models.PostDetails, int64, error) {
+	posts, err := s.bookmarks.ListBookmarked(user_id, limit, offset)
+	if err != nil {
+		return nil, models.PostDetails{}, 0, err
+	}
+	visible, total, err := s.bookmarks.CountBookmarks(user_id)
+	if err != nil {
+		return nil, models.PostDetails{}, 0, err
+	}
+	details, err := s.posts.Details(posts, user_id)
+	if err != nil {
+		return nil, models.PostDetails{}, 0, err
+	}
+	return posts, details, total - visible, nil
+}
+
+// owned loads a reading list of the user.
+func (s *BookmarkService) owned(user_id, id uint) (models.ReadingList, error) {
+	list, err := s.bookmarks.FindOwnedList(id, user_id)
+	if err != nil {
+		return models.ReadingList{}, apperror.NotFound(apperror.CODE_READING_LIST_NOT_FOUND, "Reading list not found")
+	}
+	return list, nil
+}
+
+// ListDetails loads the owners and post counts shown with the lists to the
+// user.
+func (s *BookmarkService) ListDetails(user_id uint, lists ...models.ReadingList) (models.ReadingListDetails, error) {
+	return s.bookmarks.ListDetails(lists, user_id)
+}
+
+func (s *BookmarkService) CreateList(list models.ReadingList) (models.ReadingList, error) {
+	if err := s.bookmarks.CreateList(&list); err != nil {
+		return models.ReadingList{}, err
+	}
+	return list, nil
+}
+
+// Lists returns all the reading lists of the user, public and private.
+func (s *BookmarkService) Lists(user_id uint) ([]models.ReadingList, error) {
+	return s.bookmarks.ListLists(user_id, false)
+}
+
+// AuthorLists returns the public reading lists of the user with the given
+// username.
+func (s *BookmarkService) AuthorLists(username string) ([]models.ReadingList, error) {
+	user, err := s.users.FindByUsername(username)
+	if err != nil {
+		return nil, apperror.Lookup(err, apperror.CODE_USER_NOT_FOUND, "User not found")
+	}
+	return s.bookmarks.ListLists(user.ID, true)
+}
+
+// List loads a reading list with the posts the user can read in it. Private
+// lists are only found by their owner.
+func (s *BookmarkService) List(user_id, id uint) (ReadingListContent, error) {
+	list, err := s.bookmarks.FindList(id)
+	if err != nil {
+		return ReadingListContent{}, apperror.Lookup(err, apperror.CODE_READING_LIST_NOT_FOUND, "Reading list not found")
+	}
+	if !list.IsPublic && list.UserID != user_id {
+		return ReadingListContent{}, apperror.NotFound(apperror.CODE_READING_LIST_NOT_FOUND, "Reading list not found")
+	}
+	content := ReadingListContent{List: list}
+	if content.Items, content.Posts, err = s.bookmarks.ListItems(list.ID, user_id); err != nil {
+		return ReadingListContent{}, err
+	}
+	if content.PostDetails, err = s.posts.Details(content.Posts, user_id); err != nil {
+		return ReadingListContent{}, err
+	}
+	if content.Details, err = s.ListDetails(user_id, list); err != nil {
+		return ReadingListContent{}, err
+	}
+	if list.UserID == user_id {
+		item_count, err := s.bookmarks.CountItems(list.ID)
+		if err != nil {
+			return ReadingListContent{}, err
+		}
+		content.UnavailableCount = item_count - int64(len(content.Items))
+	}
+	return content, nil
+}
+
+func (s *BookmarkService) UpdateList(user_id, id uint, input ReadingListChanges) (models.ReadingList, error) {
+	list, err := s.owned(user_id, id)
+	if err != nil {
+		return models.ReadingList{}, err
+	}
+	changes := map[string]interface{}{}
+	if input.Name != nil {
+		changes["name"] = *input.Name
+	}
+	if input.Description != nil {
+		changes["description"] = *input.Description
+	}
+	if input.IsPublic != nil {
+		changes["is_public"] = *input.IsPublic
+	}
+	if len(changes) > 0 {
+		if err := s.bookmarks.UpdateList(&list, changes); err != nil {
+			return models.ReadingList{}, err
+		}
+	}
+	return list, nil
+}
+
+func (s *BookmarkService) DeleteList(user_id, id uint) error {
+	list, err := s.owned(user_id, id)
+	if err != nil {
+		return err
+	}
+	return s.bookmarks.DeleteList(list)
+}
+
+// AddPost adds a post the owner can read to their list. Without a valid
+// position the post goes last.
+func (s *BookmarkService) AddPost(user_id, id, post_id uint, note string, position int) (models.ReadingListItem, error) {
+	list, err := s.owned(user_id, id)
+	if err != nil {
+		return models.ReadingListItem{}, err
+	}
+	post, err := s.posts.FindVisible(post_id, list.UserID)
+	if err != nil {
+		return models.ReadingListItem{}, apperror.Lookup(err, apperror.CODE_POST_NOT_FOUND, "Post not found")
+	}
+	if _, err := s.bookmarks.FindItem(list.ID, post.ID); err == nil {
+		return models.ReadingListItem{}, apperror.Conflict(apperror.CODE_ALREADY_IN_READING_LIST, "Post is already in this reading list")
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.ReadingListItem{}, err
+	}
+	item_count, err := s.bookmarks.CountItems(list.ID)
+	if err != nil {
+		return models.ReadingListItem{}, err
+	}
+	item := models.ReadingListItem{ReadingListID: list.ID, PostID: post.ID, Note: note, Position: int(item_count) + 1}
+	if position > 0 && position <= int(item_count) {
+		item.Position = position
+	}
+	if err := s.bookmarks.AddItem(list, &item); err != nil {
+		return models.ReadingListItem{}, err
+	}
+	return item, nil
+}
+
+// UpdatePost changes the note of a post in a list of the user or moves it to
+// another position.
+func (s *BookmarkService) UpdatePost(user_id, id, post_id uint, note *string, position *int) (models.ReadingListItem, error) {
+	list, err := s.owned(user_id, id)
+	if err != nil {
+		return models.ReadingListItem{}, err
+	}
+	item, err := s.bookmarks.FindItem(list.ID, post_id)
+	if err != nil {
+		return models.ReadingListItem{}, err
+	}
+	if note != nil {
+		item.Note = *note
+	}
+	moved := item.Position
+	if position != nil {
+		item_count, err := s.bookmarks.CountItems(list.ID)
+		if err != nil {
+			return models.ReadingListItem{}, err
+		}
+		if *position < 1 || *position > int(item_count) {
+			return models.ReadingListItem{}, apperror.Validation("Position must be between 1 and the number of posts in the list")
+		}
+		moved = *position
+	}
+	return s.bookmarks.UpdateItem(list, item, moved)
+}
+
+func (s *BookmarkService) RemovePost(user_id, id, post_id uint) error {
+	list, err := s.owned(user_id, id)
+	if err != nil {
+		return err
+	}
+	return s.bookmarks.RemoveItem(list, post_id)
+}
+
+// Order sets the order of a list of the user, post_ids must hold every post
+// of the list once.
+func (s *BookmarkService) Order(user_id, id uint, post_ids []uint) error {
+	list, err := s.owned(user_id, id)
+	if err != nil {
+		return err
+	}
+	current, err := s.bookmarks.ItemPostIDs(list.ID)
+	if err != nil {
+		return err
+	}
+	in_list := map[uint]bool{}
+	for _, post_id := range current {
+		in_list[post_id] = true
+	}
+	if len(post_ids) != len(current) {
+		return apperror.Validation("post_ids must hold every post of the reading list once")
+	}
+	for _, post_id := range post_ids {
+		if !in_list[post_id] {
+			return apperror.Validation("post_ids must hold every post of the reading list once")
+		}
+		delete(in_list, post_id)
+	}
+	return s.bookmarks.Order(list, post_ids)
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"blogspot-project/models"
+	"blogspot-project/repositories"
+	"blogspot-project/utils/apperror"
+)
+
+type CategoryService struct {
+	categories repositories.CategoryRepository
+	posts      repositories.PostRepository
+	sitemap    SitemapUpdater
+}
+
+func NewCategoryService(categories repositories.CategoryRepository, posts repositories.PostRepository, index SitemapUpdater) *CategoryService {
+	return &CategoryService{categories: categories, posts: posts, sitemap: index}
+}
+
+func (s *CategoryService) find(id uint) (models.Category, error) {
+	category, err := s.categories.FindByID(id)
+	if err != nil {
+		return models.Category{}, apperror.Lookup(err, apperror.CODE_CATEGORY_NOT_FOUND, "Category not found")
+	}
+	return category, nil
+}
+
+func (s *CategoryService) Create(name string) (models.Category, error) {
+	category := models.Category{Name: name}
+	if err := s.categories.Create(&category); err != nil {
+		return models.Category{}, err
+	}
+	s.sitemap.CategoryChanged(category)
+	return category, nil
+}
+
+func (s *CategoryService) Update(id uint, name string) (models.Category, error) {
+	category, err := s.find(id)
+	if err != nil {
+		return models.Category{}, err
+	}
+	savedCategory, err := s.categories.Update(category, models.Category{Name: name})
+	if err != nil {
+		return models.Category{}, err
+	}
+	s.sitemap.CategoryChanged(savedCategory)
+	return savedCategory, nil
+}
+
+func (s *CategoryService) Delete(id uint) error {
+	category, err := s.find(id)
+	if err != nil {
+		return err
+	}
+	if err := s.categories.Delete(category); err != nil {
+		return err
+	}
+	s.sitemap.CategoryDeleted(category.ID)
+	return nil
+}
+
+func (s *CategoryService) List(search string) ([]models.Category, error) {
+	return s.categories.List(search)
+}
+
+// Get loads a category with a page of its published posts, newest first,
+// and their details as anonymous readers see them.
+func (s *CategoryService) Get(id uint, limit, offset int) (models.Category, []models.Post, models.PostDetails, error) {
+	category, err := s.find(id)
+	if err != nil {
+		return models.Category{}, nil, models.PostDetails{}, err
+	}
+	posts, err := s.posts.ListPublishedInCategory(category.ID, limit, offset)
+	if err != nil {
+		return models.Category{}, nil, models.PostDetails{}, err
+	}
+	details, err := s.posts.Details(posts, 0)
+	if err != nil {
+		return models.Category{}, nil, models.PostDetails{}, err
+	}
+	return category, posts, details, nil
+}
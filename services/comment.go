@@ -0,0 +1,509 @@
+package services
+
+import (
+	"blogspot-project/models"
+	"blogspot-project/repositories"
+	"blogspot-project/utils/apperror"
+	"blogspot-project/utils/notification"
+	"blogspot-project/utils/spam"
+	"errors"
+	"net/http"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ModerationPolicyChanges holds the changes of an admin to the moderation
+// policy, nil fields are left unchanged.
+type ModerationPolicyChanges struct {
+	AutoApproveTrusted  *bool
+	TrustedCommentCount *uint
+	HoldFirstComment    *bool
+	HoldLinks           *bool
+	SpamThreshold       *float64
+}
+
+type CommentService struct {
+	comments repositories.CommentRepository
+	posts    repositories.PostRepository
+	users    repositories.UserRepository
+	unit     repositories.UnitOfWork
+	notifier TxNotifier
+	spam     SpamScorer
+	// listener gets every comment once it is approved, to push it to the
+	// readers of its post
+	listener func(models.Comment)
+}
+
+func NewCommentService(comments repositories.CommentRepository, posts repositories.PostRepository, users repositories.UserRepository, unit repositories.UnitOfWork, notifier TxNotifier, classifier SpamScorer, listener func(models.Comment)) *CommentService {
+	return &CommentService{comments: comments, posts: posts, users: users, unit: unit, notifier: notifier, spam: classifier, listener: listener}
+}
+
+func (s *CommentService) user(user_id uint) (models.User, error) {
+	user, err := s.users.FindByID(user_id)
+	if err != nil {
+		return models.User{}, apperror.Lookup(err, apperror.CODE_USER_NOT_FOUND, "User not found")
+	}
+	return user, nil
+}
+
+// commenter loads a user who is about to write, rejecting suspended ones.
+func (s *CommentService) commenter(user_id uint) (models.User, error) {
+	user, err := s.user(user_id)
+	if err != nil {
+		return models.User{}, err
+	}
+	if user.IsSuspended() {
+		return models.User{}, apperror.New(http.StatusForbidden, apperror.CODE_ACCOUNT_SUSPENDED, "Your account is suspended")
+	}
+	return user, nil
+}
+
+func (s *CommentService) post(user_id, post_id uint) (models.Post, error) {
+	post, err := s.posts.FindVisible(post_id, user_id)
+	if err != nil {
+		return models.Post{}, apperror.Lookup(err, apperror.CODE_POST_NOT_FOUND, "Post not found")
+	}
+	return post, nil
+}
+
+func (s *CommentService) find(post_id, id uint) (models.Comment, error) {
+	comment, err := s.comments.FindInPost(post_id, id)
+	if err != nil {
+		return models.Comment{}, apperror.Lookup(err, apperror.CODE_COMMENT_NOT_FOUND, "Comment not found")
+	}
+	return comment, nil
+}
+
+// moderate decides the moderation status of a comment written by user. The
+// spam score is returned too so moderators can sort the queue.
+func (s *CommentService) moderate(user models.User, content string) (uint, float64, error) {
+	if user.IsModerator() {
+		return models.COMMENT_STATUS_APPROVED, 0, nil
+	}
+	policy, err := s.comments.ModerationPolicy()
+	if err != nil {
+		return 0, 0, err
+	}
+	score, trained, err := s.spam.Score(content)
+	if err != nil {
+		return 0, 0, err
+	}
+	if trained && score >= policy.SpamThreshold {
+		return models.COMMENT_STATUS_SPAM, score, nil
+	}
+	approved, err := s.comments.CountApproved(user.ID)
+	if err != nil {
+		return 0, 0, err
+	}
+	if policy.AutoApproveTrusted && policy.TrustedCommentCount > 0 && approved >= int64(policy.TrustedCommentCount) {
+		return models.COMMENT_STATUS_APPROVED, score, nil
+	}
+	if policy.HoldFirstComment && approved == 0 {
+		return models.COMMENT_STATUS_PENDING, score, nil
+	}
+	if policy.HoldLinks && spam.CountLinks(content) > 0 {
+		return models.COMMENT_STATUS_PENDING, score, nil
+	}
+	return models.COMMENT_STATUS_APPROVED, score, nil
+}
+
+// Create writes a comment on a post, or a reply when parent_id is set.
+// Replies past the max depth are attached next to their parent instead. An
+// approved comment is published to the readers of the post and notifies the
+// people it concerns.
+func (s *CommentService) Create(user_id, post_id uint, parent_id *uint, content string) (models.Comment, error) {
+	post, err := s.post(user_id, post_id)
+	if err != nil {
+		return models.Comment{}, err
+	}
+	user, err := s.commenter(user_id)
+	if err != nil {
+		return models.Comment{}, err
+	}
+	blocked, err := s.users.HasBlocked(post.UserID, user_id)
+	if err != nil {
+		return models.Comment{}, err
+	}
+	if blocked {
+		return models.Comment{}, apperror.Forbidden("You cannot comment on this post")
+	}
+	comment := models.Comment{
+		UserID:         user_id,
+		PostID:         post.ID,
+		CommentContent: content,
+	}
+	if parent_id != nil {
+		parent, err := s.find(post.ID, *parent_id)
+		if err != nil {
+			return models.Comment{}, err
+		}
+		if parent.IsDeleted {
+			return models.Comment{}, apperror.Conflict(apperror.CODE_COMMENT_DELETED, "Cannot reply to a deleted comment")
+		}
+		if parent.ModerationStatus != models.COMMENT_STATUS_APPROVED {
+			return models.Comment{}, apperror.Conflict(apperror.CODE_COMMENT_NOT_APPROVED, "Cannot reply to a comment that is not approved")
+		}
+		blocked, err := s.users.HasBlocked(parent.UserID, user_id)
+		if err != nil {
+			return models.Comment{}, err
+		}
+		if blocked {
+			return models.Comment{}, apperror.Forbidden("You cannot reply to this comment")
+		}
+		// past the max depth the reply becomes a sibling of its parent
+		if parent.Depth+1 > models.CommentMaxDepth() && parent.ParentID != nil {
+			grandparent, err := s.comments.FindByID(*parent.ParentID)
+			if err != nil {
+				return models.Comment{}, apperror.Lookup(err, apperror.CODE_COMMENT_NOT_FOUND, "Comment not found")
+			}
+			parent = grandparent
+		}
+		comment.ParentID = &parent.ID
+		comment.ThreadID = parent.ThreadID
+		comment.Depth = parent.Depth + 1
+	}
+	comment.ModerationStatus, comment.SpamScore, err = s.moderate(user, content)
+	if err != nil {
+		return models.Comment{}, err
+	}
+	// the notifications are written with the comment, so a failure leaves
+	// neither behind
+	err = s.unit.Do(func(tx *repositories.Tx) error {
+		if err := tx.Comments.Create(&comment); err != nil {
+			return apperror.Lookup(err, apperror.CODE_COMMENT_NOT_FOUND, "Comment not found")
+		}
+		if comment.ModerationStatus != models.COMMENT_STATUS_APPROVED {
+			return nil
+		}
+		return s.published(tx, comment)
+	})
+	if err != nil {
+		return models.Comment{}, err
+	}
+	return comment, nil
+}
+
+// published tells the people an approved comment concerns about it, and
+// hands it to the listener once the transaction commits.
+func (s *CommentService) published(tx *repositories.Tx, comment models.Comment) error {
+	if err := s.notifier(tx).CommentPublished(comment); err != nil {
+		return err
+	}
+	if s.listener != nil {
+		tx.AfterCommit(func() { s.listener(comment) })
+	}
+	return nil
+}
+
+// Update changes the content of a comment by its author within the edit
+// window. The previous content is kept in the edit history and the new one
+// is moderated again.
+func (s *CommentService) Update(user_id, post_id, id uint, content string) (models.Comment, error) {
+	comment, err := s.find(post_id, id)
+	if err != nil {
+		return models.Comment{}, err
+	}
+	if comment.IsDeleted {
+		return models.Comment{}, apperror.Conflict(apperror.CODE_COMMENT_DELETED, "Cannot update a deleted comment")
+	}
+	if comment.UserID != user_id {
+		return models.Comment{}, apperror.Forbidden("Only the author can edit this comment")
+	}
+	window := models.CommentEditWindow()
+	if window > 0 && time.Since(comment.CreatedAt) > window {
+		return models.Comment{}, apperror.Forbidden("The time to edit this comment has passed")
+	}
+	if content == comment.CommentContent {
+		return comment, nil
+	}
+	user, err := s.commenter(user_id)
+	if err != nil {
+		return models.Comment{}, err
+	}
+	// an edit can sneak in links or spam, so the new content is checked again
+	status, score, err := s.moderate(user, content)
+	if err != nil {
+		return models.Comment{}, err
+	}
+	// comments waiting for a moderator stay in the queue
+	if comment.ModerationStatus != models.COMMENT_STATUS_APPROVED {
+		status = comment.ModerationStatus
+	}
+	updatedComment := models.Comment{}
+	err = s.unit.Do(func(tx *repositories.Tx) error {
+		var err error
+		updatedComment, err = tx.Comments.Edit(comment, user_id, content, status, score)
+		if err != nil {
+			return apperror.Lookup(err, apperror.CODE_COMMENT_NOT_FOUND, "Comment not found")
+		}
+		// users mentioned in the new content hear about it
+		if status != models.COMMENT_STATUS_APPROVED {
+			return nil
+		}
+		return s.notifier(tx).Mentions(models.TARGET_COMMENT, comment.ID)
+	})
+	if err != nil {
+		return models.Comment{}, err
+	}
+	return updatedComment, nil
+}
+
+// Delete removes a comment for its author, the author of the post or a
+// moderator.
+func (s *CommentService) Delete(user_id, post_id, id uint) error {
+	comment, err := s.find(post_id, id)
+	if err != nil {
+		return err
+	}
+	if comment.UserID != user_id {
+		post, err := s.posts.FindByID(comment.PostID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if post.UserID != user_id {
+			user, err := s.user(user_id)
+			if err != nil {
+				return err
+			}
+			if !user.IsModerator() {
+				return apperror.Forbidden("Only the comment author, the post author or a moderator can delete this comment")
+			}
+		}
+	}
+	return s.comments.Remove(comment)
+}
+
+// Moderate moves comments to a moderation status for a moderator and
+// returns those that were found. The decision trains the spam classifier:
+// approve as ham, spam as spam, and reject takes back whatever the comment
+// was trained as before. Authors hear about approvals and rejections, and
+// newly approved comments are published like new ones.
+func (s *CommentService) Moderate(user_id uint, ids []uint, status uint) ([]models.Comment, error) {
+	if _, err := s.moderator(user_id, "Only moderators can moderate comments"); err != nil {
+		return nil, err
+	}
+	comments, err := s.comments.FindManyByID(ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, comment := range comments {
+		label, err := s.train(comment, status)
+		if err != nil {
+			return nil, err
+		}
+		err = s.unit.Do(func(tx *repositories.Tx) error {
+			if err := tx.Comments.SetStatus(comment, status, label); err != nil {
+				return err
+			}
+			if comment.ModerationStatus == status {
+				return nil
+			}
+			action := ""
+			switch status {
+			case models.COMMENT_STATUS_APPROVED:
+				action = notification.ACTION_APPROVED
+			case models.COMMENT_STATUS_REJECTED:
+				action = notification.ACTION_REJECTED
+			}
+			if action != "" {
+				if err := s.notifier(tx).Moderation(comment.UserID, user_id, action, models.TARGET_COMMENT, comment.ID, comment.PostID); err != nil {
+					return err
+				}
+			}
+			if status != models.COMMENT_STATUS_APPROVED {
+				return nil
+			}
+			comment.ModerationStatus = status
+			return s.published(tx, comment)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return comments, nil
+}
+
+// moderator loads the user acting as a moderator, forbidden with the given
+// detail otherwise.
+func (s *CommentService) moderator(user_id uint, detail string) (models.User, error) {
+	user, err := s.user(user_id)
+	if err != nil {
+		return models.User{}, err
+	}
+	if !user.IsModerator() {
+		return models.User{}, apperror.Forbidden(detail)
+	}
+	return user, nil
+}
+
+// Queue returns the comments in a moderation status to moderators, oldest
+// first.
+func (s *CommentService) Queue(user_id, status uint, limit, offset int) ([]models.Comment, error) {
+	if _, err := s.moderator(user_id, "Only moderators can see the moderation queue"); err != nil {
+		return nil, err
+	}
+	if !models.IsValidCommentStatus(status) {
+		return nil, apperror.Validation("Invalid moderation status")
+	}
+	return s.comments.ListByStatus(status, limit, offset)
+}
+
+func (s *CommentService) Policy(user_id uint) (models.ModerationPolicy, error) {
+	if _, err := s.moderator(user_id, "Only moderators can see the moderation policy"); err != nil {
+		return models.ModerationPolicy{}, err
+	}
+	return s.comments.ModerationPolicy()
+}
+
+// UpdatePolicy changes the moderation policy, which only admins can do.
+func (s *CommentService) UpdatePolicy(user_id uint, input ModerationPolicyChanges) (models.ModerationPolicy, error) {
+	user, err := s.user(user_id)
+	if err != nil {
+		return models.ModerationPolicy{}, err
+	}
+	if user.Role != models.ADMIN_USER_ROLE {
+		return models.ModerationPolicy{}, apperror.Forbidden("Only Admin can change the moderation policy")
+	}
+	changes := models.ModerationPolicy{}
+	columns := []string{}
+	if input.AutoApproveTrusted != nil {
+		changes.AutoApproveTrusted = *input.AutoApproveTrusted
+		columns = append(columns, "auto_approve_trusted")
+	}
+	if input.TrustedCommentCount != nil {
+		changes.TrustedCommentCount = *input.TrustedCommentCount
+		columns = append(columns, "trusted_comment_count")
+	}
+	if input.HoldFirstComment != nil {
+		changes.HoldFirstComment = *input.HoldFirstComment
+		columns = append(columns, "hold_first_comment")
+	}
+	if input.HoldLinks != nil {
+		changes.HoldLinks = *input.HoldLinks
+		columns = append(columns, "hold_links")
+	}
+	if input.SpamThreshold != nil {
+		if *input.SpamThreshold <= 0 || *input.SpamThreshold > 1 {
+			return models.ModerationPolicy{}, apperror.Validation("Spam threshold must be between 0 and 1")
+		}
+		changes.SpamThreshold = *input.SpamThreshold
+		columns = append(columns, "spam_threshold")
+	}
+	return s.comments.UpdateModerationPolicy(changes, columns)
+}
+
+// train teaches the spam classifier the moderator's decision on a comment
+// and returns the label it is now trained as.
+func (s *CommentService) train(comment models.Comment, status uint) (uint, error) {
+	label := uint(models.SPAM_LABEL_NONE)
+	switch status {
+	case models.COMMENT_STATUS_APPROVED:
+		label = models.SPAM_LABEL_HAM
+	case models.COMMENT_STATUS_SPAM:
+		label = models.SPAM_LABEL_SPAM
+	}
+	if label == comment.SpamLabel {
+		return label, nil
+	}
+	if comment.SpamLabel != models.SPAM_LABEL_NONE {
+		if err := s.spam.Unlearn(comment.CommentContent, comment.SpamLabel == models.SPAM_LABEL_SPAM); err != nil {
+			return 0, err
+		}
+	}
+	if label != models.SPAM_LABEL_NONE {
+		if err := s.spam.Learn(comment.CommentContent, label == models.SPAM_LABEL_SPAM); err != nil {
+			return 0, err
+		}
+	}
+	return label, nil
+}
+
+// History returns a comment with its previous versions, for moderators.
+func (s *CommentService) History(user_id, post_id, id uint) (models.Comment, []models.CommentEdit, error) {
+	user, err := s.user(user_id)
+	if err != nil {
+		return models.Comment{}, nil, err
+	}
+	if !user.IsModerator() {
+		return models.Comment{}, nil, apperror.Forbidden("Only moderators can see the edit history")
+	}
+	comment, err := s.find(post_id, id)
+	if err != nil {
+		return models.Comment{}, nil, err
+	}
+	edits, err := s.comments.ListEdits(comment.ID)
+	if err != nil {
+		return models.Comment{}, nil, err
+	}
+	return comment, edits, nil
+}
+
+// Reactions loads the reaction summary of each comment for the user.
+func (s *CommentService) Reactions(comments []models.Comment, user_id uint) (map[uint]models.ReactionSummary, error) {
+	ids := make([]uint, len(comments))
+	for i, comment := range comments {
+		ids[i] = comment.ID
+	}
+	return s.comments.Reactions(ids, user_id)
+}
+
+// List searches the comments of a post the user can read.
+func (s *CommentService) List(user_id, post_id uint, search string, limit, offset int) ([]models.Comment, error) {
+	post, err := s.post(user_id, post_id)
+	if err != nil {
+		return nil, err
+	}
+	return s.comments.List(post.ID, user_id, search, limit, offset)
+}
+
+// Tree returns a page of top level comments of a post followed by all of
+// their replies. Replies under a hidden comment are hidden with it.
+func (s *CommentService) Tree(user_id, post_id uint, limit, offset int) ([]models.Comment, error) {
+	post, err := s.post(user_id, post_id)
+	if err != nil {
+		return nil, err
+	}
+	roots, err := s.comments.ListRoots(post.ID, user_id, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	if len(roots) == 0 {
+		return roots, nil
+	}
+	thread_ids := make([]uint, len(roots))
+	shown := map[uint]bool{}
+	for i, root := range roots {
+		thread_ids[i] = root.ID
+		shown[root.ID] = true
+	}
+	replies, err := s.comments.ListThreadReplies(thread_ids, user_id)
+	if err != nil {
+		return nil, err
+	}
+	comments := roots
+	for _, reply := range replies {
+		if shown[*reply.ParentID] {
+			shown[reply.ID] = true
+			comments = append(comments, reply)
+		}
+	}
+	return comments, nil
+}
+
+// Thread returns a comment with a page of its direct replies.
+func (s *CommentService) Thread(user_id, post_id, id uint, limit, offset int) (models.Comment, []models.Comment, error) {
+	post, err := s.post(user_id, post_id)
+	if err != nil {
+		return models.Comment{}, nil, err
+	}
+	comment, err := s.comments.FindVisibleInPost(post.ID, id, user_id)
+	if err != nil {
+		return models.Comment{}, nil, apperror.Lookup(err, apperror.CODE_COMMENT_NOT_FOUND, "Comment not found")
+	}
+	replies, err := s.comments.ListReplies(comment.ID, user_id, limit, offset)
+	if err != nil {
+		return models.Comment{}, nil, err
+	}
+	return comment, replies, nil
+}
@@ -0,0 +1,212 @@
+package services
+
+import (
+	"blogspot-project/models"
+	"blogspot-project/repositories"
+	"blogspot-project/utils/apperror"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+type commentFixture struct {
+	unit      *fakeUnit
+	comments  *fakeComments
+	users     *fakeUsers
+	notifier  *fakeNotifier
+	spam      *fakeSpam
+	published []uint
+	service   *CommentService
+}
+
+// newCommentFixture has an admin (1) who wrote post 10, a member (2) and a
+// moderator (3).
+func newCommentFixture(comments ...models.Comment) *commentFixture {
+	f := &commentFixture{
+		comments: newFakeComments(comments...),
+		users:    newFakeUsers(testUser(1, models.ADMIN_USER_ROLE), testUser(2, models.NON_ADMIN_USER_ROLE), testUser(3, models.MODERATOR_USER_ROLE)),
+		notifier: &fakeNotifier{},
+		spam:     &fakeSpam{},
+	}
+	posts := newFakePosts(testPost(10, 1, models.POST_STATUS_PUBLISHED), testPost(11, 1, models.POST_STATUS_DRAFT))
+	f.unit = &fakeUnit{tx: repositories.Tx{Users: f.users, Posts: posts, Comments: f.comments}}
+	notifier := func(tx *repositories.Tx) Notifier {
+		return f.notifier
+	}
+	f.service = NewCommentService(f.comments, posts, f.users, f.unit, notifier, f.spam, func(comment models.Comment) {
+		f.published = append(f.published, comment.ID)
+	})
+	return f
+}
+
+func TestCommentServiceModerationPolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		user_id  uint
+		content  string
+		policy   models.ModerationPolicy
+		approved int64
+		spam     fakeSpam
+		status   uint
+	}{
+		{"no rules", 2, "hello", models.ModerationPolicy{SpamThreshold: 0.9}, 0, fakeSpam{}, models.COMMENT_STATUS_APPROVED},
+		{"moderators skip the rules", 3, "http://spam.example", models.ModerationPolicy{HoldFirstComment: true, HoldLinks: true}, 0, fakeSpam{score: 1, trained: true}, models.COMMENT_STATUS_APPROVED},
+		{"spam score over the threshold", 2, "buy now", models.ModerationPolicy{SpamThreshold: 0.9}, 0, fakeSpam{score: 0.95, trained: true}, models.COMMENT_STATUS_SPAM},
+		{"spam score under the threshold", 2, "buy now", models.ModerationPolicy{SpamThreshold: 0.9}, 0, fakeSpam{score: 0.5, trained: true}, models.COMMENT_STATUS_APPROVED},
+		{"untrained scores are ignored", 2, "buy now", models.ModerationPolicy{SpamThreshold: 0.9}, 0, fakeSpam{score: 0.99}, models.COMMENT_STATUS_APPROVED},
+		{"first comment held", 2, "hello", models.ModerationPolicy{HoldFirstComment: true, SpamThreshold: 0.9}, 0, fakeSpam{}, models.COMMENT_STATUS_PENDING},
+		{"later comment not held", 2, "hello", models.ModerationPolicy{HoldFirstComment: true, SpamThreshold: 0.9}, 1, fakeSpam{}, models.COMMENT_STATUS_APPROVED},
+		{"links held", 2, "see www.example.com", models.ModerationPolicy{HoldLinks: true, SpamThreshold: 0.9}, 1, fakeSpam{}, models.COMMENT_STATUS_PENDING},
+		{"trusted commenters skip the holds", 2, "see www.example.com", models.ModerationPolicy{AutoApproveTrusted: true, TrustedCommentCount: 3, HoldLinks: true, SpamThreshold: 0.9}, 3, fakeSpam{}, models.COMMENT_STATUS_APPROVED},
+		{"trusted commenters still caught as spam", 2, "buy now", models.ModerationPolicy{AutoApproveTrusted: true, TrustedCommentCount: 3, SpamThreshold: 0.9}, 3, fakeSpam{score: 0.95, trained: true}, models.COMMENT_STATUS_SPAM},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f := newCommentFixture()
+			f.comments.policy = test.policy
+			f.comments.approved[test.user_id] = test.approved
+			*f.spam = test.spam
+			comment, err := f.service.Create(test.user_id, 10, nil, test.content)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if comment.ModerationStatus != test.status {
+				t.Errorf("expected status %v, got %v", test.status, comment.ModerationStatus)
+			}
+			// only approved comments reach the readers and notify anyone
+			approved := test.status == models.COMMENT_STATUS_APPROVED
+			if (len(f.published) > 0) != approved || (len(f.notifier.sent) > 0) != approved {
+				t.Errorf("expected published and notified %v, got %v and %v", approved, f.published, f.notifier.sent)
+			}
+		})
+	}
+}
+
+func TestCommentServiceCreateReply(t *testing.T) {
+	parent := testComment(20, 10, 1, models.COMMENT_STATUS_APPROVED)
+	pending := testComment(21, 10, 1, models.COMMENT_STATUS_PENDING)
+	deleted := testComment(22, 10, 1, models.COMMENT_STATUS_APPROVED)
+	deleted.IsDeleted = true
+	// 24 is as deep as replies go, so replying to it answers its parent 23
+	deep := testComment(23, 10, 1, models.COMMENT_STATUS_APPROVED)
+	deep.Depth = models.CommentMaxDepth() - 1
+	deepest := testComment(24, 10, 1, models.COMMENT_STATUS_APPROVED)
+	deepest.ParentID, deepest.ThreadID, deepest.Depth = &deep.ID, deep.ThreadID, models.CommentMaxDepth()
+	other_post := testComment(25, 11, 1, models.COMMENT_STATUS_APPROVED)
+	tests := []struct {
+		name      string
+		post_id   uint
+		parent_id uint
+		blocked   bool
+		code      string
+		attached  uint
+		depth     uint
+	}{
+		{"reply", 10, 20, false, "", 20, 1},
+		{"reply past the max depth", 10, 24, false, "", 23, models.CommentMaxDepth()},
+		{"parent not approved", 10, 21, false, apperror.CODE_COMMENT_NOT_APPROVED, 0, 0},
+		{"parent deleted", 10, 22, false, apperror.CODE_COMMENT_DELETED, 0, 0},
+		{"parent in another post", 10, 25, false, apperror.CODE_COMMENT_NOT_FOUND, 0, 0},
+		{"blocked by the post author", 10, 20, true, apperror.CODE_FORBIDDEN, 0, 0},
+		{"draft post", 11, 25, false, apperror.CODE_POST_NOT_FOUND, 0, 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f := newCommentFixture(parent, pending, deleted, deep, deepest, other_post)
+			f.users.blocks[[2]uint{1, 2}] = test.blocked
+			f.comments.policy.SpamThreshold = 0.9
+			comment, err := f.service.Create(2, test.post_id, &test.parent_id, "reply")
+			if code := errorCode(err); code != test.code {
+				t.Fatalf("expected error code %q, got %q (%v)", test.code, code, err)
+			}
+			if test.code != "" {
+				if len(f.comments.comments) != 6 {
+					t.Errorf("a rejected reply must not be saved")
+				}
+				return
+			}
+			if comment.ParentID == nil || *comment.ParentID != test.attached || comment.Depth != test.depth {
+				t.Errorf("expected the reply under %v at depth %v, got parent %v depth %v", test.attached, test.depth, comment.ParentID, comment.Depth)
+			}
+			if comment.ThreadID != parent.ThreadID && comment.ThreadID != deep.ThreadID {
+				t.Errorf("expected the reply in the thread of its parent, got %v", comment.ThreadID)
+			}
+			if !reflect.DeepEqual(f.notifier.sent, []string{"comment 101"}) {
+				t.Errorf("expected the reply to notify, got %v", f.notifier.sent)
+			}
+		})
+	}
+}
+
+func TestCommentServiceCreateNotifies(t *testing.T) {
+	f := newCommentFixture()
+	f.comments.policy.SpamThreshold = 0.9
+	comment, err := f.service.Create(2, 10, nil, "hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(f.notifier.sent, []string{fmt.Sprintf("comment %v", comment.ID)}) || !reflect.DeepEqual(f.published, []uint{comment.ID}) {
+		t.Errorf("expected the comment notified and published, got %v and %v", f.notifier.sent, f.published)
+	}
+
+	// a failed notification rolls the comment back and publishes nothing
+	f = newCommentFixture()
+	f.comments.policy.SpamThreshold = 0.9
+	f.notifier.err = errors.New("notifications are down")
+	if _, err := f.service.Create(2, 10, nil, "hello"); err != f.notifier.err {
+		t.Fatalf("expected the notification error, got %v", err)
+	}
+	if f.unit.rolled_back != 1 || len(f.published) != 0 {
+		t.Errorf("expected the comment rolled back and not published, got %v rollbacks and %v", f.unit.rolled_back, f.published)
+	}
+}
+
+func TestCommentServiceModerate(t *testing.T) {
+	tests := []struct {
+		name      string
+		user_id   uint
+		comment   models.Comment
+		label     uint
+		status    uint
+		code      string
+		learned   []string
+		notified  []string
+		published bool
+	}{
+		{"approve pending", 3, testComment(20, 10, 2, models.COMMENT_STATUS_PENDING), models.SPAM_LABEL_NONE, models.COMMENT_STATUS_APPROVED, "",
+			[]string{"learn comment false"}, []string{"approved 2 comment 20", "comment 20"}, true},
+		{"spam pending", 3, testComment(20, 10, 2, models.COMMENT_STATUS_PENDING), models.SPAM_LABEL_NONE, models.COMMENT_STATUS_SPAM, "",
+			[]string{"learn comment true"}, nil, false},
+		{"approve what was trained as spam", 1, testComment(20, 10, 2, models.COMMENT_STATUS_SPAM), models.SPAM_LABEL_SPAM, models.COMMENT_STATUS_APPROVED, "",
+			[]string{"unlearn comment true", "learn comment false"}, []string{"approved 2 comment 20", "comment 20"}, true},
+		{"reject takes back the training", 3, testComment(20, 10, 2, models.COMMENT_STATUS_APPROVED), models.SPAM_LABEL_HAM, models.COMMENT_STATUS_REJECTED, "",
+			[]string{"unlearn comment false"}, []string{"rejected 2 comment 20"}, false},
+		{"same decision again", 3, testComment(20, 10, 2, models.COMMENT_STATUS_APPROVED), models.SPAM_LABEL_HAM, models.COMMENT_STATUS_APPROVED, "",
+			nil, nil, false},
+		{"member", 2, testComment(20, 10, 2, models.COMMENT_STATUS_PENDING), models.SPAM_LABEL_NONE, models.COMMENT_STATUS_APPROVED, apperror.CODE_FORBIDDEN,
+			nil, nil, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.comment.SpamLabel = test.label
+			f := newCommentFixture(test.comment)
+			_, err := f.service.Moderate(test.user_id, []uint{test.comment.ID, 99}, test.status)
+			if code := errorCode(err); code != test.code {
+				t.Fatalf("expected error code %q, got %q (%v)", test.code, code, err)
+			}
+			if !reflect.DeepEqual(f.spam.learned, test.learned) {
+				t.Errorf("expected training %v, got %v", test.learned, f.spam.learned)
+			}
+			if !reflect.DeepEqual(f.notifier.sent, test.notified) {
+				t.Errorf("expected notifications %v, got %v", test.notified, f.notifier.sent)
+			}
+			if (len(f.published) > 0) != test.published {
+				t.Errorf("expected published %v, got %v", test.published, f.published)
+			}
+			if test.code == "" && f.comments.comments[test.comment.ID].ModerationStatus != test.status {
+				t.Errorf("expected status %v, got %v", test.status, f.comments.comments[test.comment.ID].ModerationStatus)
+			}
+		})
+	}
+}
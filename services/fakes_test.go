@@ -0,0 +1,478 @@
+package services
+
+import (
+	"blogspot-project/models"
+	"blogspot-project/repositories"
+	"blogspot-project/utils/apperror"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// The fakes keep their rows in maps. They embed the repository interface
+// they stand for, so a method the tests do not expect panics.
+
+type fakeUsers struct {
+	repositories.UserRepository
+	users  map[uint]models.User
+	images map[uint]models.Media
+	blocks map[[2]uint]bool
+}
+
+func newFakeUsers(users ...models.User) *fakeUsers {
+	f := &fakeUsers{users: map[uint]models.User{}, images: map[uint]models.Media{}, blocks: map[[2]uint]bool{}}
+	for _, user := range users {
+		f.users[user.ID] = user
+	}
+	return f
+}
+
+func (f *fakeUsers) FindByID(id uint) (models.User, error) {
+	user, ok := f.users[id]
+	if !ok {
+		return models.User{}, gorm.ErrRecordNotFound
+	}
+	return user, nil
+}
+
+func (f *fakeUsers) FindByUsername(username string) (models.User, error) {
+	for _, user := range f.users {
+		if user.Username == username {
+			return user, nil
+		}
+	}
+	return models.User{}, gorm.ErrRecordNotFound
+}
+
+func (f *fakeUsers) FindOwnedImage(media_id, user_id uint) (models.Media, error) {
+	item, ok := f.images[media_id]
+	if !ok || item.UserID != user_id {
+		return models.Media{}, gorm.ErrRecordNotFound
+	}
+	return item, nil
+}
+
+func (f *fakeUsers) Warn(user_id uint) error {
+	user := f.users[user_id]
+	user.WarningCount++
+	f.users[user_id] = user
+	return nil
+}
+
+func (f *fakeUsers) Suspend(user_id uint, until time.Time) error {
+	user := f.users[user_id]
+	user.SuspendedUntil = &until
+	f.users[user_id] = user
+	return nil
+}
+
+func (f *fakeUsers) HasBlocked(user_id, other_id uint) (bool, error) {
+	return f.blocks[[2]uint{user_id, other_id}], nil
+}
+
+type fakePosts struct {
+	repositories.PostRepository
+	posts   map[uint]models.Post
+	tags    map[uint][]string
+	next_id uint
+}
+
+func newFakePosts(posts ...models.Post) *fakePosts {
+	f := &fakePosts{posts: map[uint]models.Post{}, tags: map[uint][]string{}, next_id: 100}
+	for _, post := range posts {
+		f.posts[post.ID] = post
+	}
+	return f
+}
+
+func (f *fakePosts) FindByID(id uint) (models.Post, error) {
+	post, ok := f.posts[id]
+	if !ok {
+		return models.Post{}, gorm.ErrRecordNotFound
+	}
+	return post, nil
+}
+
+func (f *fakePosts) FindVisible(id, user_id uint) (models.Post, error) {
+	post, err := f.FindByID(id)
+	if err != nil {
+		return models.Post{}, err
+	}
+	if (post.Status != models.POST_STATUS_PUBLISHED || post.IsHidden) && post.UserID != user_id {
+		return models.Post{}, gorm.ErrRecordNotFound
+	}
+	return post, nil
+}
+
+func (f *fakePosts) FindByAuthor(id, user_id uint) (models.Post, error) {
+	post, err := f.FindByID(id)
+	if err != nil || post.UserID != user_id {
+		return models.Post{}, gorm.ErrRecordNotFound
+	}
+	return post, nil
+}
+
+func (f *fakePosts) Create(post *models.Post) error {
+	f.next_id++
+	post.ID = f.next_id
+	f.posts[post.ID] = *post
+	return nil
+}
+
+// Update writes the fields of changes that are set, like gorm does with a
+// struct.
+func (f *fakePosts) Update(post models.Post, changes models.Post) (models.Post, error) {
+	saved := f.posts[post.ID]
+	if changes.ArticleTitle != "" {
+		saved.ArticleTitle = changes.ArticleTitle
+	}
+	if changes.ArticleContent != "" {
+		saved.ArticleContent = changes.ArticleContent
+	}
+	if changes.CategoryID != 0 {
+		saved.CategoryID = changes.CategoryID
+	}
+	if changes.Status != 0 {
+		saved.Status = changes.Status
+	}
+	if changes.PublishedAt != nil {
+		saved.PublishedAt = changes.PublishedAt
+	}
+	f.posts[post.ID] = saved
+	return saved, nil
+}
+
+func (f *fakePosts) SetHidden(post models.Post, hidden bool) (models.Post, error) {
+	post.IsHidden = hidden
+	f.posts[post.ID] = post
+	return post, nil
+}
+
+func (f *fakePosts) SetTags(post models.Post, names []string) error {
+	f.tags[post.ID] = names
+	return nil
+}
+
+func (f *fakePosts) Delete(post models.Post) error {
+	delete(f.posts, post.ID)
+	return nil
+}
+
+type fakeCategories struct {
+	repositories.CategoryRepository
+	categories map[uint]models.Category
+}
+
+func (f *fakeCategories) FindByID(id uint) (models.Category, error) {
+	category, ok := f.categories[id]
+	if !ok {
+		return models.Category{}, gorm.ErrRecordNotFound
+	}
+	return category, nil
+}
+
+type fakeComments struct {
+	repositories.CommentRepository
+	comments map[uint]models.Comment
+	policy   models.ModerationPolicy
+	approved map[uint]int64
+	next_id  uint
+}
+
+func newFakeComments(comments ...models.Comment) *fakeComments {
+	f := &fakeComments{comments: map[uint]models.Comment{}, approved: map[uint]int64{}, next_id: 100}
+	for _, comment := range comments {
+		f.comments[comment.ID] = comment
+	}
+	return f
+}
+
+func (f *fakeComments) FindByID(id uint) (models.Comment, error) {
+	comment, ok := f.comments[id]
+	if !ok {
+		return models.Comment{}, gorm.ErrRecordNotFound
+	}
+	return comment, nil
+}
+
+func (f *fakeComments) FindVisible(id, user_id uint) (models.Comment, error) {
+	comment, err := f.FindByID(id)
+	if err != nil || comment.IsDeleted || (comment.ModerationStatus != models.COMMENT_STATUS_APPROVED && comment.UserID != user_id) {
+		return models.Comment{}, gorm.ErrRecordNotFound
+	}
+	return comment, nil
+}
+
+func (f *fakeComments) FindInPost(post_id, id uint) (models.Comment, error) {
+	comment, err := f.FindByID(id)
+	if err != nil || comment.PostID != post_id {
+		return models.Comment{}, gorm.ErrRecordNotFound
+	}
+	return comment, nil
+}
+
+func (f *fakeComments) FindManyByID(ids []uint) ([]models.Comment, error) {
+	comments := []models.Comment{}
+	for _, id := range ids {
+		if comment, ok := f.comments[id]; ok && !comment.IsDeleted {
+			comments = append(comments, comment)
+		}
+	}
+	return comments, nil
+}
+
+func (f *fakeComments) ModerationPolicy() (models.ModerationPolicy, error) {
+	return f.policy, nil
+}
+
+func (f *fakeComments) CountApproved(user_id uint) (int64, error) {
+	return f.approved[user_id], nil
+}
+
+func (f *fakeComments) Create(comment *models.Comment) error {
+	f.next_id++
+	comment.ID = f.next_id
+	if comment.ParentID == nil {
+		comment.ThreadID = comment.ID
+	}
+	f.comments[comment.ID] = *comment
+	return nil
+}
+
+func (f *fakeComments) SetStatus(comment models.Comment, status, label uint) error {
+	comment.ModerationStatus, comment.SpamLabel = status, label
+	f.comments[comment.ID] = comment
+	return nil
+}
+
+func (f *fakeComments) Remove(comment models.Comment) error {
+	delete(f.comments, comment.ID)
+	return nil
+}
+
+type fakeReports struct {
+	repositories.ReportRepository
+	reports map[uint]models.Report
+	next_id uint
+}
+
+func newFakeReports(reports ...models.Report) *fakeReports {
+	f := &fakeReports{reports: map[uint]models.Report{}, next_id: 100}
+	for _, report := range reports {
+		f.reports[report.ID] = report
+	}
+	return f
+}
+
+func (f *fakeReports) FindByID(id uint) (models.Report, error) {
+	report, ok := f.reports[id]
+	if !ok {
+		return models.Report{}, gorm.ErrRecordNotFound
+	}
+	return report, nil
+}
+
+func (f *fakeReports) HasReported(reporter_id uint, target_type string, target_id uint) (bool, error) {
+	for _, report := range f.reports {
+		if report.ReporterID == reporter_id && report.TargetType == target_type && report.TargetID == target_id {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (f *fakeReports) ListOpen(target_type string, target_id uint) ([]models.Report, error) {
+	reports := []models.Report{}
+	for id := uint(0); id <= f.next_id; id++ {
+		report, ok := f.reports[id]
+		if ok && report.TargetType == target_type && report.TargetID == target_id && report.Status == models.REPORT_STATUS_OPEN {
+			reports = append(reports, report)
+		}
+	}
+	return reports, nil
+}
+
+func (f *fakeReports) Create(report *models.Report) error {
+	f.next_id++
+	report.ID = f.next_id
+	f.reports[report.ID] = *report
+	return nil
+}
+
+func (f *fakeReports) Hold(report *models.Report) error {
+	report.HeldByReport = true
+	f.reports[report.ID] = *report
+	return nil
+}
+
+func (f *fakeReports) Resolve(target_type string, target_id uint, status uint, action string, moderator_id uint) error {
+	open, _ := f.ListOpen(target_type, target_id)
+	for _, report := range open {
+		report.Status, report.Action, report.ResolvedByID = status, action, &moderator_id
+		f.reports[report.ID] = report
+	}
+	return nil
+}
+
+type fakeFollows struct {
+	repositories.FollowRepository
+	follows map[[2]uint]bool
+	blocks  map[[2]uint]bool
+}
+
+func newFakeFollows() *fakeFollows {
+	return &fakeFollows{follows: map[[2]uint]bool{}, blocks: map[[2]uint]bool{}}
+}
+
+func (f *fakeFollows) FollowUser(follower_id, following_id uint) (models.UserFollow, bool, error) {
+	key := [2]uint{follower_id, following_id}
+	created := !f.follows[key]
+	f.follows[key] = true
+	return models.UserFollow{FollowerID: follower_id, FollowingID: following_id}, created, nil
+}
+
+func (f *fakeFollows) Block(user_id, blocked_id uint) (models.UserBlock, error) {
+	f.blocks[[2]uint{user_id, blocked_id}] = true
+	delete(f.follows, [2]uint{user_id, blocked_id})
+	delete(f.follows, [2]uint{blocked_id, user_id})
+	return models.UserBlock{UserID: user_id, BlockedID: blocked_id}, nil
+}
+
+func (f *fakeFollows) HasBlockBetween(user_id, other_id uint) (bool, error) {
+	return f.blocks[[2]uint{user_id, other_id}] || f.blocks[[2]uint{other_id, user_id}], nil
+}
+
+// fakeTimeline records whose feed was invalidated.
+type fakeTimeline struct {
+	invalidated []uint
+}
+
+func (f *fakeTimeline) PostIDs(user_id uint, limit, offset int) ([]uint, error) {
+	return nil, nil
+}
+
+func (f *fakeTimeline) Invalidate(user_id uint) {
+	f.invalidated = append(f.invalidated, user_id)
+}
+
+// fakeUnit runs the work on the same fakes, without any rollback. It only
+// counts the work that failed and would have been rolled back.
+type fakeUnit struct {
+	tx          repositories.Tx
+	rolled_back int
+}
+
+func (f *fakeUnit) Do(work func(tx *repositories.Tx) error) error {
+	tx := f.tx
+	if err := work(&tx); err != nil {
+		f.rolled_back++
+		return err
+	}
+	tx.Committed()
+	return nil
+}
+
+// fakeNotifier records the notifications that would be sent, comments
+// fail to publish when err is set.
+type fakeNotifier struct {
+	sent []string
+	err  error
+}
+
+func (f *fakeNotifier) Notify(notification models.Notification) error {
+	f.sent = append(f.sent, fmt.Sprintf("%v %v", notification.Type, notification.UserID))
+	return nil
+}
+
+func (f *fakeNotifier) Mentions(target_type string, target_id uint) error {
+	f.sent = append(f.sent, fmt.Sprintf("mentions %v %v", target_type, target_id))
+	return nil
+}
+
+func (f *fakeNotifier) CommentPublished(comment models.Comment) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.sent = append(f.sent, fmt.Sprintf("comment %v", comment.ID))
+	return nil
+}
+
+func (f *fakeNotifier) Like(actor_id, owner_id uint, target_type string, target_id, post_id uint) error {
+	f.sent = append(f.sent, fmt.Sprintf("like %v %v %v", owner_id, target_type, target_id))
+	return nil
+}
+
+func (f *fakeNotifier) Moderation(user_id, moderator_id uint, action, target_type string, target_id, post_id uint) error {
+	f.sent = append(f.sent, fmt.Sprintf("%v %v %v %v", action, user_id, target_type, target_id))
+	return nil
+}
+
+// fakeSitemap records which posts the sitemap was told about.
+type fakeSitemap struct {
+	changed []uint
+	deleted []uint
+}
+
+func (f *fakeSitemap) PostChanged(post models.Post, author models.User) {
+	f.changed = append(f.changed, post.ID)
+}
+
+func (f *fakeSitemap) PostDeleted(post_id uint) {
+	f.deleted = append(f.deleted, post_id)
+}
+
+func (f *fakeSitemap) CategoryChanged(category models.Category) {}
+
+func (f *fakeSitemap) CategoryDeleted(category_id uint) {}
+
+func (f *fakeSitemap) AuthorChanged(user models.User) {}
+
+func (f *fakeSitemap) AuthorDeleted(user_id uint) {}
+
+// fakeSpam gives every text the same score and records what it learns.
+type fakeSpam struct {
+	score   float64
+	trained bool
+	learned []string
+}
+
+func (f *fakeSpam) Score(text string) (float64, bool, error) {
+	return f.score, f.trained, nil
+}
+
+func (f *fakeSpam) Learn(text string, isSpam bool) error {
+	f.learned = append(f.learned, fmt.Sprintf("learn %v %v", text, isSpam))
+	return nil
+}
+
+func (f *fakeSpam) Unlearn(text string, isSpam bool) error {
+	f.learned = append(f.learned, fmt.Sprintf("unlearn %v %v", text, isSpam))
+	return nil
+}
+
+func testUser(id, role uint) models.User {
+	user := models.User{Name: fmt.Sprintf("user %v", id), Username: fmt.Sprintf("user%v", id), Role: role}
+	user.ID = id
+	return user
+}
+
+func testPost(id, user_id, status uint) models.Post {
+	post := models.Post{UserID: user_id, ArticleTitle: "Title", CategoryID: 1, Status: status}
+	post.ID = id
+	return post
+}
+
+func testComment(id, post_id, user_id, status uint) models.Comment {
+	comment := models.Comment{PostID: post_id, UserID: user_id, CommentContent: "comment", ModerationStatus: status, ThreadID: id}
+	comment.ID = id
+	return comment
+}
+
+// errorCode is the problem code of an error, empty for nil.
+func errorCode(err error) string {
+	if err == nil {
+		return ""
+	}
+	return apperror.From(err).Code
+}
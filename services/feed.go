@@ -0,0 +1,106 @@
+package services
+
+import (
+	"blogspot-project/models"
+	"blogspot-project/repositories"
+	"blogspot-project/utils"
+	"blogspot-project/utils/apperror"
+	"strconv"
+	"time"
+)
+
+// Feed describes a syndication feed, Scope tells feeds apart in their ETag.
+type Feed struct {
+	Scope       string
+	Title       string
+	Link        string
+	Description string
+	Filter      repositories.FeedFilter
+}
+
+// FeedService serves the syndication feeds of the site, a category, an
+// author or a tag.
+type FeedService struct {
+	posts      repositories.PostRepository
+	users      repositories.UserRepository
+	categories repositories.CategoryRepository
+	tags       repositories.TagRepository
+}
+
+func NewFeedService(posts repositories.PostRepository, users repositories.UserRepository, categories repositories.CategoryRepository, tags repositories.TagRepository) *FeedService {
+	return &FeedService{posts: posts, users: users, categories: categories, tags: tags}
+}
+
+func (s *FeedService) Site() Feed {
+	return Feed{Scope: "site", Title: utils.GetEnv("SITE_TITLE", "Blogspot"), Link: utils.SiteURL(), Description: "Latest posts"}
+}
+
+func (s *FeedService) Category(id uint) (Feed, error) {
+	category, err := s.categories.FindByID(id)
+	if err != nil {
+		return Feed{}, apperror.Lookup(err, apperror.CODE_CATEGORY_NOT_FOUND, "Category not found")
+	}
+	return Feed{
+		Scope:       "category:" + strconv.Itoa(int(category.ID)),
+		Title:       category.Name,
+		Link:        utils.CategoryURL(category.ID),
+		Description: "Latest posts in " + category.Name,
+		Filter:      repositories.FeedFilter{CategoryID: category.ID},
+	}, nil
+}
+
+func (s *FeedService) Author(username string) (Feed, error) {
+	user, err := s.users.FindByUsername(username)
+	if err != nil {
+		return Feed{}, apperror.Lookup(err, apperror.CODE_USER_NOT_FOUND, "User not found")
+	}
+	return Feed{
+		Scope:       "author:" + user.Username,
+		Title:       user.Name,
+		Link:        utils.AuthorURL(user.Username),
+		Description: "Latest posts by " + user.Name,
+		Filter:      repositories.FeedFilter{UserID: user.ID},
+	}, nil
+}
+
+func (s *FeedService) Tag(slug string) (Feed, error) {
+	tag, err := s.tags.FindBySlug(slug)
+	if err != nil {
+		return Feed{}, apperror.Lookup(err, apperror.CODE_TAG_NOT_FOUND, "Tag not found")
+	}
+	return Feed{
+		Scope:       "tag:" + tag.Slug,
+		Title:       tag.Name,
+		Link:        utils.TagURL(tag.Slug),
+		Description: "Latest posts tagged " + tag.Name,
+		Filter:      repositories.FeedFilter{TagID: tag.ID},
+	}, nil
+}
+
+// State counts the posts of a feed and returns when it last changed,
+// without loading any post content so polling readers stay cheap.
+func (s *FeedService) State(feed Feed) (int64, time.Time, error) {
+	count, last_modified, err := s.posts.FeedState(feed.Filter)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	return count, last_modified.UTC().Truncate(time.Second), nil
+}
+
+// Posts returns the latest FEED_ITEM_LIMIT posts of a feed with their
+// authors keyed by user id.
+func (s *FeedService) Posts(feed Feed) ([]models.Post, map[uint]models.User, error) {
+	limit, err := strconv.Atoi(utils.GetEnv("FEED_ITEM_LIMIT", "20"))
+	if err != nil {
+		return nil, nil, err
+	}
+	posts, err := s.posts.ListFeed(feed.Filter, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+	authors, err := s.users.Authors(posts)
+	if err != nil {
+		return nil, nil, err
+	}
+	return posts, authors, nil
+}
@@ -0,0 +1,208 @@
+package services
+
+import (
+	"blogspot-project/models"
+	"blogspot-project/repositories"
+	"blogspot-project/utils/apperror"
+)
+
+// FollowService handles who users follow, block and mute, and the
+// personalized feed built from their follows.
+type FollowService struct {
+	follows    repositories.FollowRepository
+	users      repositories.UserRepository
+	categories repositories.CategoryRepository
+	posts      repositories.PostRepository
+	unit       repositories.UnitOfWork
+	timeline   TimelineCache
+	notifier   TxNotifier
+}
+
+func NewFollowService(follows repositories.FollowRepository, users repositories.UserRepository, categories repositories.CategoryRepository, posts repositories.PostRepository, unit repositories.UnitOfWork, cache TimelineCache, notifier TxNotifier) *FollowService {
+	return &FollowService{follows: follows, users: users, categories: categories, posts: posts, unit: unit, timeline: cache, notifier: notifier}
+}
+
+func (s *FollowService) user(username string) (models.User, error) {
+	user, err := s.users.FindByUsername(username)
+	if err != nil {
+		return models.User{}, apperror.Lookup(err, apperror.CODE_USER_NOT_FOUND, "User not found")
+	}
+	return user, nil
+}
+
+// other loads the user someone acts on, who must not be themselves.
+func (s *FollowService) other(user_id uint, username string) (models.User, error) {
+	user, err := s.user(username)
+	if err != nil {
+		return models.User{}, err
+	}
+	if user.ID == user_id {
+		return models.User{}, apperror.Validation("You cannot do this to yourself")
+	}
+	return user, nil
+}
+
+func (s *FollowService) FollowCategory(user_id, category_id uint) (models.CategoryFollow, error) {
+	category, err := s.categories.FindByID(category_id)
+	if err != nil {
+		return models.CategoryFollow{}, apperror.Lookup(err, apperror.CODE_CATEGORY_NOT_FOUND, "Category not found")
+	}
+	follow, err := s.follows.FollowCategory(user_id, category.ID)
+	if err != nil {
+		return models.CategoryFollow{}, err
+	}
+	s.timeline.Invalidate(user_id)
+	return follow, nil
+}
+
+func (s *FollowService) UnfollowCategory(user_id, category_id uint) error {
+	if err := s.follows.UnfollowCategory(user_id, category_id); err != nil {
+		return err
+	}
+	s.timeline.Invalidate(user_id)
+	return nil
+}
+
+func (s *FollowService) FollowedCategories(user_id uint) ([]models.Category, error) {
+	return s.follows.FollowedCategories(user_id)
+}
+
+// FollowUser follows an author, who is notified the first time. Users
+// blocking each other cannot follow each other.
+func (s *FollowService) FollowUser(user_id uint, username string) (models.UserFollow, error) {
+	user, err := s.user(username)
+	if err != nil {
+		return models.UserFollow{}, err
+	}
+	if user.ID == user_id {
+		return models.UserFollow{}, apperror.Validation("You cannot follow yourself")
+	}
+	blocked, err := s.follows.HasBlockBetween(user_id, user.ID)
+	if err != nil {
+		return models.UserFollow{}, err
+	}
+	if blocked {
+		return models.UserFollow{}, apperror.Forbidden("You cannot follow this user")
+	}
+	follow := models.UserFollow{}
+	err = s.unit.Do(func(tx *repositories.Tx) error {
+		var created bool
+		var err error
+		follow, created, err = tx.Follows.FollowUser(user_id, user.ID)
+		if err != nil || !created {
+			return err
+		}
+		return s.notifier(tx).Notify(models.Notification{
+			UserID:     user.ID,
+			ActorID:    user_id,
+			Type:       models.NOTIFICATION_FOLLOW,
+			TargetType: models.TARGET_USER,
+			TargetID:   user.ID,
+		})
+	})
+	if err != nil {
+		return models.UserFollow{}, err
+	}
+	s.timeline.Invalidate(user_id)
+	return follow, nil
+}
+
+func (s *FollowService) UnfollowUser(user_id uint, username string) error {
+	user, err := s.user(username)
+	if err != nil {
+		return err
+	}
+	if err := s.follows.UnfollowUser(user_id, user.ID); err != nil {
+		return err
+	}
+	s.timeline.Invalidate(user_id)
+	return nil
+}
+
+func (s *FollowService) Followers(username string, limit, offset int) ([]models.User, error) {
+	user, err := s.user(username)
+	if err != nil {
+		return nil, err
+	}
+	return s.follows.Followers(user.ID, limit, offset)
+}
+
+func (s *FollowService) Following(username string, limit, offset int) ([]models.User, error) {
+	user, err := s.user(username)
+	if err != nil {
+		return nil, err
+	}
+	return s.follows.Following(user.ID, limit, offset)
+}
+
+// Block blocks a user and removes the follows between them both, so both
+// feeds change.
+func (s *FollowService) Block(user_id uint, username string) (models.UserBlock, error) {
+	user, err := s.other(user_id, username)
+	if err != nil {
+		return models.UserBlock{}, err
+	}
+	block, err := s.follows.Block(user_id, user.ID)
+	if err != nil {
+		return models.UserBlock{}, err
+	}
+	s.timeline.Invalidate(user_id)
+	s.timeline.Invalidate(user.ID)
+	return block, nil
+}
+
+func (s *FollowService) Unblock(user_id uint, username string) error {
+	user, err := s.other(user_id, username)
+	if err != nil {
+		return err
+	}
+	if err := s.follows.Unblock(user_id, user.ID); err != nil {
+		return err
+	}
+	s.timeline.Invalidate(user_id)
+	return nil
+}
+
+func (s *FollowService) Mute(user_id uint, username string) (models.UserMute, error) {
+	user, err := s.other(user_id, username)
+	if err != nil {
+		return models.UserMute{}, err
+	}
+	mute, err := s.follows.Mute(user_id, user.ID)
+	if err != nil {
+		return models.UserMute{}, err
+	}
+	s.timeline.Invalidate(user_id)
+	return mute, nil
+}
+
+func (s *FollowService) Unmute(user_id uint, username string) error {
+	user, err := s.other(user_id, username)
+	if err != nil {
+		return err
+	}
+	if err := s.follows.Unmute(user_id, user.ID); err != nil {
+		return err
+	}
+	s.timeline.Invalidate(user_id)
+	return nil
+}
+
+func (s *FollowService) Blocked(user_id uint) ([]models.User, error) {
+	return s.follows.Blocked(user_id)
+}
+
+func (s *FollowService) Muted(user_id uint) ([]models.User, error) {
+	return s.follows.Muted(user_id)
+}
+
+// Feed returns a page of the personalized feed. Posts unpublished or deleted
+// since the feed was cached are left out, as are posts of blocked or muted
+// authors followed through a category.
+func (s *FollowService) Feed(user_id uint, limit, offset int) ([]models.Post, error) {
+	ids, err := s.timeline.PostIDs(user_id, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return s.posts.ListPublishedByID(ids, user_id)
+}
@@ -0,0 +1,76 @@
+package services
+
+import (
+	"blogspot-project/models"
+	"blogspot-project/repositories"
+	"blogspot-project/utils/apperror"
+	"reflect"
+	"testing"
+)
+
+type followFixture struct {
+	follows  *fakeFollows
+	timeline *fakeTimeline
+	notifier *fakeNotifier
+	service  *FollowService
+}
+
+// newFollowFixture has the members user1, user2 and user3.
+func newFollowFixture() *followFixture {
+	users := newFakeUsers(testUser(1, models.NON_ADMIN_USER_ROLE), testUser(2, models.NON_ADMIN_USER_ROLE), testUser(3, models.NON_ADMIN_USER_ROLE))
+	f := &followFixture{follows: newFakeFollows(), timeline: &fakeTimeline{}, notifier: &fakeNotifier{}}
+	unit := &fakeUnit{tx: repositories.Tx{Users: users, Follows: f.follows}}
+	f.service = NewFollowService(f.follows, users, nil, nil, unit, f.timeline, func(tx *repositories.Tx) Notifier {
+		return f.notifier
+	})
+	return f
+}
+
+func TestFollowServiceFollowUser(t *testing.T) {
+	f := newFollowFixture()
+	f.follows.blocks[[2]uint{3, 1}] = true
+	tests := []struct {
+		name     string
+		username string
+		code     string
+		notified []string
+	}{
+		{"first follow notifies", "user2", "", []string{"follow 2"}},
+		{"following again stays quiet", "user2", "", []string{"follow 2"}},
+		{"yourself", "user1", apperror.CODE_VALIDATION_FAILED, []string{"follow 2"}},
+		{"blocked by them", "user3", apperror.CODE_FORBIDDEN, []string{"follow 2"}},
+		{"unknown user", "nobody", apperror.CODE_USER_NOT_FOUND, []string{"follow 2"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := f.service.FollowUser(1, test.username)
+			if code := errorCode(err); code != test.code {
+				t.Fatalf("expected error code %q, got %q (%v)", test.code, code, err)
+			}
+			if !reflect.DeepEqual(f.notifier.sent, test.notified) {
+				t.Errorf("expected notifications %v, got %v", test.notified, f.notifier.sent)
+			}
+		})
+	}
+	if !reflect.DeepEqual(f.timeline.invalidated, []uint{1, 1}) {
+		t.Errorf("expected the feed of the follower invalidated on each follow, got %v", f.timeline.invalidated)
+	}
+}
+
+func TestFollowServiceBlock(t *testing.T) {
+	f := newFollowFixture()
+	f.follows.follows[[2]uint{1, 2}] = true
+	f.follows.follows[[2]uint{2, 1}] = true
+	if _, err := f.service.Block(1, "user1"); errorCode(err) != apperror.CODE_VALIDATION_FAILED {
+		t.Fatalf("expected blocking yourself to fail, got %v", err)
+	}
+	if _, err := f.service.Block(1, "user2"); err != nil {
+		t.Fatal(err)
+	}
+	if len(f.follows.follows) != 0 {
+		t.Errorf("expected the follows between them removed, got %v", f.follows.follows)
+	}
+	if !reflect.DeepEqual(f.timeline.invalidated, []uint{1, 2}) {
+		t.Errorf("expected both feeds invalidated, got %v", f.timeline.invalidated)
+	}
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"blogspot-project/models"
+	"blogspot-project/repositories"
+	"blogspot-project/utils/apperror"
+	"blogspot-project/utils/realtime"
+)
+
+type LikeService struct {
+	likes    repositories.LikeRepository
+	posts    repositories.PostRepository
+	comments repositories.CommentRepository
+	users    repositories.UserRepository
+	hub      EventPublisher
+	notifier Notifier
+}
+
+func NewLikeService(likes repositories.LikeRepository, posts repositories.PostRepository, comments repositories.CommentRepository, users repositories.UserRepository, hub EventPublisher, notifier Notifier) *LikeService {
+	return &LikeService{likes: likes, posts: posts, comments: comments, users: users, hub: hub, notifier: notifier}
+}
+
+// target loads a post or comment the user can see.
+func (s *LikeService) target(target_type string, id, user_id uint) (repositories.ReactionTarget, error) {
+	if target_type == models.TARGET_POST {
+		post, err := s.posts.FindVisible(id, user_id)
+		if err != nil {
+			return repositories.ReactionTarget{}, apperror.Lookup(err, apperror.CODE_POST_NOT_FOUND, "Post not found")
+		}
+		return repositories.ReactionTarget{TargetType: target_type, TargetID: post.ID, PostID: post.ID, OwnerID: post.UserID}, nil
+	}
+	comment, err := s.comments.FindVisible(id, user_id)
+	if err != nil {
+		return repositories.ReactionTarget{}, apperror.Lookup(err, apperror.CODE_COMMENT_NOT_FOUND, "Comment not found")
+	}
+	return repositories.ReactionTarget{TargetType: target_type, TargetID: comment.ID, PostID: comment.PostID, OwnerID: comment.UserID}, nil
+}
+
+// React sets the reaction of a user on a post or comment, or removes it when
+// reaction is empty, and returns the new reaction counts. A change is
+// published to the readers of the post, and a new reaction other than a
+// dislike notifies the owner of the target.
+func (s *LikeService) React(user_id uint, target_type string, id uint, reaction string) (models.ReactionSummary, error) {
+	if reaction != "" && !models.IsValidReaction(reaction) {
+		return models.ReactionSummary{}, apperror.Validation("Reaction must be one of the reaction types")
+	}
+	target, err := s.target(target_type, id, user_id)
+	if err != nil {
+		return models.ReactionSummary{}, err
+	}
+	changed, err := s.likes.SetReaction(user_id, target, reaction)
+	if err != nil {
+		return models.ReactionSummary{}, err
+	}
+	if changed && reaction != "" && reaction != models.REACTION_DISLIKE {
+		if err := s.notifier.Like(user_id, target.OwnerID, target.TargetType, target.TargetID, target.PostID); err != nil {
+			return models.ReactionSummary{}, err
+		}
+	}
+	summary, err := s.likes.Summary(target, user_id)
+	if err != nil {
+		return models.ReactionSummary{}, err
+	}
+	if changed {
+		s.hub.Publish(realtime.PostTopic(target.PostID), realtime.EVENT_LIKES, map[string]interface{}{
+			"target_type":   target.TargetType,
+			"target_id":     target.TargetID,
+			"like_count":    summary.Counts[models.REACTION_LIKE],
+			"dislike_count": summary.Counts[models.REACTION_DISLIKE],
+			"reactions":     summary.Counts,
+		})
+	}
+	return summary, nil
+}
+
+// List returns the reactions on a post or comment the user can see,
+// optionally only of one type.
+func (s *LikeService) List(user_id uint, target_type string, id uint, reaction string, limit, offset int) ([]models.Reaction, error) {
+	target, err := s.target(target_type, id, user_id)
+	if err != nil {
+		return nil, err
+	}
+	return s.likes.List(target.TargetType, target.TargetID, reaction, user_id, limit, offset)
+}
+
+// Users returns all the reactions of one type on a post or comment the user
+// can see, for the like and dislike lists.
+func (s *LikeService) Users(user_id uint, target_type string, id uint, reaction string) ([]models.Reaction, error) {
+	target, err := s.target(target_type, id, user_id)
+	if err != nil {
+		return nil, err
+	}
+	return s.likes.List(target.TargetType, target.TargetID, reaction, user_id, -1, 0)
+}
+
+// Reactors loads the users behind the reactions keyed by user id, with their
+// avatars keyed by media id.
+func (s *LikeService) Reactors(reactions []models.Reaction) (map[uint]models.User, map[uint]models.Media, error) {
+	ids := make([]uint, len(reactions))
+	for i, reaction := range reactions {
+		ids[i] = reaction.UserID
+	}
+	users, err := s.users.FindManyByID(ids)
+	if err != nil {
+		return nil, nil, err
+	}
+	avatars, err := s.users.Avatars(users)
+	if err != nil {
+		return nil, nil, err
+	}
+	by_id := map[uint]models.User{}
+	for _, user := range users {
+		by_id[user.ID] = user
+	}
+	return by_id, avatars, nil
+}
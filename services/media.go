@@ -0,0 +1,205 @@
+package services
+
+import (
+	"blogspot-project/models"
+	"blogspot-project/repositories"
+	"blogspot-project/utils/apperror"
+	"blogspot-project/utils/media"
+	"blogspot-project/utils/storage"
+	"errors"
+	"fmt"
+	"image"
+	"log"
+	"net/http"
+	"path/filepath"
+
+	"gorm.io/gorm"
+)
+
+// MediaService keeps the files users upload, with the resized and avatar
+// variants of their images.
+type MediaService struct {
+	media repositories.MediaRepository
+	store storage.Storage
+}
+
+func NewMediaService(items repositories.MediaRepository, store storage.Storage) *MediaService {
+	return &MediaService{media: items, store: store}
+}
+
+// Upload stores a file of the user, stripped of its metadata when it is an
+// image. Uploading the same file again returns the existing media, with
+// existing set.
+func (s *MediaService) Upload(user_id uint, file_name string, data []byte, is_avatar bool) (item models.Media, existing bool, err error) {
+	info, err := media.Inspect(data)
+	if err == media.ErrUnsupportedType {
+		return models.Media{}, false, apperror.New(http.StatusUnsupportedMediaType, apperror.CODE_UNSUPPORTED_MEDIA_TYPE, "Unsupported file type").With("allowed_types", media.AllowedTypes())
+	}
+	if err != nil {
+		return models.Media{}, false, apperror.Validation("File could not be read")
+	}
+
+	var img image.Image
+	if info.Width > 0 {
+		if img, err = media.Decode(data); err != nil {
+			return models.Media{}, false, apperror.Validation(err.Error())
+		}
+		if data, err = media.PrepareOriginal(data, info.MimeType); err != nil {
+			return models.Media{}, false, err
+		}
+		if info, err = media.Inspect(data); err != nil {
+			return models.Media{}, false, err
+		}
+	}
+
+	if item, err := s.media.FindByChecksum(user_id, info.Checksum); err == nil {
+		return s.existing(item, is_avatar)
+	}
+
+	key := fmt.Sprintf("%v/%v%v", user_id, info.Checksum, info.Extension)
+	if err := s.store.Put(key, data, info.MimeType); err != nil {
+		return models.Media{}, false, err
+	}
+	item = models.Media{
+		UserID:     user_id,
+		FileName:   filepath.Base(file_name),
+		MimeType:   info.MimeType,
+		Size:       int64(len(data)),
+		Width:      info.Width,
+		Height:     info.Height,
+		Checksum:   info.Checksum,
+		StorageKey: key,
+		Url:        s.store.URL(key),
+	}
+	if err := s.media.Create(&item); err != nil {
+		// the same file uploaded at the same time was saved first, with the
+		// same storage key, so the file stays
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			item, err := s.media.FindByChecksum(user_id, info.Checksum)
+			if err != nil {
+				return models.Media{}, false, apperror.Conflict(apperror.CODE_CONFLICT, "This file is being uploaded already")
+			}
+			return s.existing(item, is_avatar)
+		}
+		s.store.Delete(key)
+		return models.Media{}, false, err
+	}
+	if img != nil {
+		variants, err := media.ResizedVariants(img)
+		if err == nil && is_avatar {
+			var avatars []media.Variant
+			avatars, err = media.AvatarVariants(img)
+			variants = append(variants, avatars...)
+		}
+		if err == nil {
+			err = s.storeVariants(&item, variants)
+		}
+		if err != nil {
+			s.delete(item)
+			return models.Media{}, false, err
+		}
+	}
+	return item, false, nil
+}
+
+// existing returns a media uploaded again, with the avatar sizes when it is
+// uploaded as an avatar.
+func (s *MediaService) existing(item models.Media, is_avatar bool) (models.Media, bool, error) {
+	if is_avatar {
+		if err := s.ensureAvatarVariants(&item); err != nil {
+			return models.Media{}, false, err
+		}
+	}
+	return item, true, nil
+}
+
+func (s *MediaService) List(user_id uint, limit, offset int) ([]models.Media, error) {
+	return s.media.List(user_id, limit, offset)
+}
+
+func (s *MediaService) Delete(user_id, id uint) error {
+	item, err := s.media.FindOwned(id, user_id)
+	if err != nil {
+		return apperror.Lookup(err, apperror.CODE_MEDIA_NOT_FOUND, "Media not found")
+	}
+	return s.delete(item)
+}
+
+// PrepareAvatar loads an image uploaded by the user to use as their avatar,
+// cropping the avatar sizes from it when needed, see AvatarPreparer.
+func (s *MediaService) PrepareAvatar(user_id, media_id uint) (models.Media, error) {
+	item, err := s.media.FindOwnedImage(media_id, user_id)
+	if err != nil {
+		return models.Media{}, apperror.Validation("Avatar must be an image uploaded by you")
+	}
+	if err := s.ensureAvatarVariants(&item); err != nil {
+		return models.Media{}, err
+	}
+	return item, nil
+}
+
+func (s *MediaService) storeVariants(item *models.Media, variants []media.Variant) error {
+	for _, variant := range variants {
+		key := fmt.Sprintf("%v/%v-%v-%v.webp", item.UserID, item.Checksum, variant.Kind, variant.Width)
+		if err := s.store.Put(key, variant.Data, variant.MimeType); err != nil {
+			return err
+		}
+		newVariant := models.MediaVariant{
+			MediaID:    item.ID,
+			Kind:       variant.Kind,
+			Width:      variant.Width,
+			Height:     variant.Height,
+			MimeType:   variant.MimeType,
+			Size:       int64(len(variant.Data)),
+			StorageKey: key,
+			Url:        s.store.URL(key),
+		}
+		if err := s.media.CreateVariant(&newVariant); err != nil {
+			s.store.Delete(key)
+			return err
+		}
+		item.Variants = append(item.Variants, newVariant)
+	}
+	return nil
+}
+
+// ensureAvatarVariants crops avatars from the stored original the first time
+// an image is used as an avatar.
+func (s *MediaService) ensureAvatarVariants(item *models.Media) error {
+	for _, variant := range item.Variants {
+		if variant.Kind == models.MEDIA_VARIANT_AVATAR {
+			return nil
+		}
+	}
+	data, err := s.store.Get(item.StorageKey)
+	if err != nil {
+		return err
+	}
+	img, err := media.Decode(data)
+	if err != nil {
+		return err
+	}
+	variants, err := media.AvatarVariants(img)
+	if err != nil {
+		return err
+	}
+	return s.storeVariants(item, variants)
+}
+
+// delete removes a media, then its files once nothing points to them. Files
+// that cannot be removed are only logged, the media is gone already.
+func (s *MediaService) delete(item models.Media) error {
+	if err := s.media.Delete(item); err != nil {
+		return err
+	}
+	keys := []string{item.StorageKey}
+	for _, variant := range item.Variants {
+		keys = append(keys, variant.StorageKey)
+	}
+	for _, key := range keys {
+		if err := s.store.Delete(key); err != nil {
+			log.Printf("media %v: %v", item.ID, err)
+		}
+	}
+	return nil
+}
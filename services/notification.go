@@ -0,0 +1,140 @@
+package services
+
+import (
+	"blogspot-project/models"
+	"blogspot-project/repositories"
+	"blogspot-project/utils/apperror"
+)
+
+// NOTIFICATION_ACTORS_SHOWN is how many of the people behind an aggregated
+// notification are listed with it.
+const NOTIFICATION_ACTORS_SHOWN = 3
+
+// emailListColumns are the preference columns of each email list.
+var emailListColumns = map[string]string{
+	models.EMAIL_LIST_MENTIONS: "email_mentions",
+	models.EMAIL_LIST_REPLIES:  "email_replies",
+	models.EMAIL_LIST_DIGEST:   "email_digest",
+}
+
+// NotificationPreferenceChanges holds the changes of a user to their
+// notification preferences, nil fields are left unchanged.
+type NotificationPreferenceChanges struct {
+	Mentions   *bool
+	Replies    *bool
+	Comments   *bool
+	Likes      *bool
+	Follows    *bool
+	Moderation *bool
+
+	EmailMentions *bool
+	EmailReplies  *bool
+	EmailDigest   *bool
+}
+
+// NotificationWithActors is a notification with the latest people behind
+// it and their avatars.
+type NotificationWithActors struct {
+	Notification models.Notification
+	Actors       []models.User
+	Avatars      map[uint]models.Media
+}
+
+type NotificationService struct {
+	notifications repositories.NotificationRepository
+	users         repositories.UserRepository
+}
+
+func NewNotificationService(notifications repositories.NotificationRepository, users repositories.UserRepository) *NotificationService {
+	return &NotificationService{notifications: notifications, users: users}
+}
+
+// List returns the notifications of a user with the people behind them,
+// latest activity first.
+func (s *NotificationService) List(user_id uint, unread bool, limit, offset int) ([]NotificationWithActors, error) {
+	notifications, err := s.notifications.List(user_id, unread, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	result := []NotificationWithActors{}
+	for _, item := range notifications {
+		actors, err := s.notifications.Actors(item.ID, NOTIFICATION_ACTORS_SHOWN)
+		if err != nil {
+			return nil, err
+		}
+		avatars, err := s.users.Avatars(actors)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, NotificationWithActors{Notification: item, Actors: actors, Avatars: avatars})
+	}
+	return result, nil
+}
+
+func (s *NotificationService) CountUnread(user_id uint) (int64, error) {
+	return s.notifications.CountUnread(user_id)
+}
+
+func (s *NotificationService) Read(user_id, id uint) (models.Notification, error) {
+	item, err := s.notifications.FindOwned(id, user_id)
+	if err != nil {
+		return models.Notification{}, apperror.Lookup(err, apperror.CODE_NOTIFICATION_NOT_FOUND, "Notification not found")
+	}
+	return s.notifications.MarkRead(item)
+}
+
+// ReadAll returns how many notifications were unread.
+func (s *NotificationService) ReadAll(user_id uint) (int64, error) {
+	return s.notifications.MarkAllRead(user_id)
+}
+
+func (s *NotificationService) Preference(user_id uint) (models.NotificationPreference, error) {
+	return s.notifications.Preference(user_id)
+}
+
+func (s *NotificationService) UpdatePreference(user_id uint, input NotificationPreferenceChanges) (models.NotificationPreference, error) {
+	changes := models.NotificationPreference{}
+	columns := []string{}
+	set := func(value *bool, field *bool, column string) {
+		if value != nil {
+			*field = *value
+			columns = append(columns, column)
+		}
+	}
+	set(input.Mentions, &changes.Mentions, "mentions")
+	set(input.Replies, &changes.Replies, "replies")
+	set(input.Comments, &changes.Comments, "comments")
+	set(input.Likes, &changes.Likes, "likes")
+	set(input.Follows, &changes.Follows, "follows")
+	set(input.Moderation, &changes.Moderation, "moderation")
+	set(input.EmailMentions, &changes.EmailMentions, "email_mentions")
+	set(input.EmailReplies, &changes.EmailReplies, "email_replies")
+	set(input.EmailDigest, &changes.EmailDigest, "email_digest")
+	return s.notifications.UpdatePreference(user_id, changes, columns)
+}
+
+// IsEmailList is true for the lists an unsubscribe link can point to.
+func (s *NotificationService) IsEmailList(list string) bool {
+	_, ok := emailListColumns[list]
+	return ok
+}
+
+// Subscribed is true while the user gets the emails of list.
+func (s *NotificationService) Subscribed(user_id uint, list string) (bool, error) {
+	preference, err := s.notifications.Preference(user_id)
+	if err != nil {
+		return false, err
+	}
+	return preference.AllowsEmail(list), nil
+}
+
+// Unsubscribe turns off an email list of the user.
+func (s *NotificationService) Unsubscribe(user_id uint, list string) error {
+	changes := models.NotificationPreference{}
+	_, err := s.notifications.UpdatePreference(user_id, changes, []string{emailListColumns[list]})
+	return err
+}
+
+func (s *NotificationService) Mentions(user_id uint, limit, offset int) ([]models.Mention, error) {
+	return s.notifications.Mentions(user_id, limit, offset)
+}
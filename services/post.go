@@ -0,0 +1,253 @@
+package services
+
+import (
+	"blogspot-project/models"
+	"blogspot-project/repositories"
+	"blogspot-project/utils/apperror"
+	"blogspot-project/utils/validation"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PostChanges holds the fields of a post written by its author. On update
+// empty fields are left unchanged.
+type PostChanges struct {
+	ArticleTitle       string
+	ArticleDescription string
+	CategoryID         uint
+	ArticleContent     string
+	Status             uint
+	FeaturedImageID    *uint
+	Tags               *[]string
+}
+
+type PostService struct {
+	posts      repositories.PostRepository
+	users      repositories.UserRepository
+	categories repositories.CategoryRepository
+	sitemap    SitemapUpdater
+	notifier   Notifier
+}
+
+func NewPostService(posts repositories.PostRepository, users repositories.UserRepository, categories repositories.CategoryRepository, index SitemapUpdater, notifier Notifier) *PostService {
+	return &PostService{posts: posts, users: users, categories: categories, sitemap: index, notifier: notifier}
+}
+
+// saved keeps the sitemap in line with a created or updated post and
+// notifies the users it mentions once it is public.
+func (s *PostService) saved(post models.Post, author models.User) error {
+	s.sitemap.PostChanged(post, author)
+	if post.Status != models.POST_STATUS_PUBLISHED || post.IsHidden {
+		return nil
+	}
+	return s.notifier.Mentions(models.TARGET_POST, post.ID)
+}
+
+// author loads the admin writing a post, forbidden with the given detail
+// when they are not one.
+func (s *PostService) author(user_id uint, detail string) (models.User, error) {
+	user, err := s.users.FindByID(user_id)
+	if err != nil {
+		return models.User{}, apperror.Lookup(err, apperror.CODE_USER_NOT_FOUND, "User not found")
+	}
+	if user.IsSuspended() {
+		return models.User{}, apperror.New(http.StatusForbidden, apperror.CODE_ACCOUNT_SUSPENDED, "Your account is suspended")
+	}
+	if user.Role != models.ADMIN_USER_ROLE {
+		return models.User{}, apperror.Forbidden(detail)
+	}
+	return user, nil
+}
+
+// tags validates the tag names of a post, dropping those with the same slug
+// as an earlier one.
+func (s *PostService) tags(names []string) ([]string, error) {
+	if len(names) > models.POST_MAX_TAGS {
+		return nil, apperror.Invalid(validation.FieldError{Field: "tags", Rule: "max_items", Param: strconv.Itoa(models.POST_MAX_TAGS)})
+	}
+	result := []string{}
+	slugs := map[string]bool{}
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if len([]rune(name)) > models.TAG_NAME_MAX_LENGTH {
+			return nil, apperror.Invalid(validation.FieldError{Field: "tags", Rule: "max", Param: strconv.Itoa(models.TAG_NAME_MAX_LENGTH)})
+		}
+		slug := models.TagSlug(name)
+		if slug == "" {
+			return nil, apperror.Invalid(validation.FieldError{Field: "tags", Rule: "invalid"})
+		}
+		if !slugs[slug] {
+			slugs[slug] = true
+			result = append(result, name)
+		}
+	}
+	return result, nil
+}
+
+// check validates the category and featured image of a post.
+func (s *PostService) check(user_id uint, input PostChanges) error {
+	if _, err := s.categories.FindByID(input.CategoryID); err != nil {
+		return apperror.Lookup(err, apperror.CODE_CATEGORY_NOT_FOUND, "Category not found")
+	}
+	if input.FeaturedImageID != nil {
+		if _, err := s.users.FindOwnedImage(*input.FeaturedImageID, user_id); err != nil {
+			return apperror.Validation("Featured image must be an image uploaded by you")
+		}
+	}
+	return nil
+}
+
+// Create writes a new post of an admin, published unless a draft status is
+// given.
+func (s *PostService) Create(user_id uint, input PostChanges) (models.Post, error) {
+	user, err := s.author(user_id, "Only Admin can create new post")
+	if err != nil {
+		return models.Post{}, err
+	}
+	if input.Status == 0 {
+		input.Status = models.POST_STATUS_PUBLISHED
+	}
+	if !models.IsValidPostStatus(input.Status) {
+		return models.Post{}, apperror.Validation("Invalid status (1 for draft, 2 for published)")
+	}
+	if err := s.check(user_id, input); err != nil {
+		return models.Post{}, err
+	}
+	tags := []string{}
+	if input.Tags != nil {
+		if tags, err = s.tags(*input.Tags); err != nil {
+			return models.Post{}, err
+		}
+	}
+	post := models.Post{
+		UserID:             user_id,
+		ArticleTitle:       input.ArticleTitle,
+		ArticleDescription: input.ArticleDescription,
+		CategoryID:         input.CategoryID,
+		ArticleContent:     input.ArticleContent,
+		Status:             input.Status,
+		FeaturedImageID:    input.FeaturedImageID,
+	}
+	if input.Status == models.POST_STATUS_PUBLISHED {
+		now := time.Now()
+		post.PublishedAt = &now
+	}
+	if err := s.posts.Create(&post); err != nil {
+		return models.Post{}, err
+	}
+	if len(tags) > 0 {
+		if err := s.posts.SetTags(post, tags); err != nil {
+			return models.Post{}, err
+		}
+	}
+	if err := s.saved(post, user); err != nil {
+		return models.Post{}, err
+	}
+	return post, nil
+}
+
+// Update changes a post of the admin who wrote it.
+func (s *PostService) Update(user_id, id uint, input PostChanges) (models.Post, error) {
+	user, err := s.author(user_id, "Only Admin can update post")
+	if err != nil {
+		return models.Post{}, err
+	}
+	post, err := s.posts.FindByAuthor(id, user_id)
+	if err != nil {
+		return models.Post{}, apperror.Lookup(err, apperror.CODE_POST_NOT_FOUND, "Post not found")
+	}
+	if input.Status != 0 && !models.IsValidPostStatus(input.Status) {
+		return models.Post{}, apperror.Validation("Invalid status (1 for draft, 2 for published)")
+	}
+	if err := s.check(user_id, input); err != nil {
+		return models.Post{}, err
+	}
+	var tags []string
+	if input.Tags != nil {
+		if tags, err = s.tags(*input.Tags); err != nil {
+			return models.Post{}, err
+		}
+	}
+	changes := models.Post{
+		ArticleTitle:       input.ArticleTitle,
+		ArticleContent:     input.ArticleContent,
+		ArticleDescription: input.ArticleDescription,
+		CategoryID:         input.CategoryID,
+		Status:             input.Status,
+		FeaturedImageID:    input.FeaturedImageID,
+	}
+	// first publish keeps its original date when a post is re-published later
+	if input.Status == models.POST_STATUS_PUBLISHED && post.PublishedAt == nil {
+		now := time.Now()
+		changes.PublishedAt = &now
+	}
+	savedPost, err := s.posts.Update(post, changes)
+	if err != nil {
+		return models.Post{}, apperror.Lookup(err, apperror.CODE_POST_NOT_FOUND, "Post not found")
+	}
+	if tags != nil {
+		if err := s.posts.SetTags(savedPost, tags); err != nil {
+			return models.Post{}, err
+		}
+	}
+	if err := s.saved(savedPost, user); err != nil {
+		return models.Post{}, err
+	}
+	return savedPost, nil
+}
+
+func (s *PostService) Delete(user_id, id uint) error {
+	user, err := s.users.FindByID(user_id)
+	if err != nil {
+		return apperror.Lookup(err, apperror.CODE_USER_NOT_FOUND, "User not found")
+	}
+	if user.Role != models.ADMIN_USER_ROLE {
+		return apperror.Forbidden("Only Admin can delete post")
+	}
+	post, err := s.posts.FindByID(id)
+	if err != nil {
+		return apperror.Lookup(err, apperror.CODE_POST_NOT_FOUND, "Post not found")
+	}
+	if err := s.posts.Delete(post); err != nil {
+		return err
+	}
+	s.sitemap.PostDeleted(post.ID)
+	return nil
+}
+
+// Find loads a post the user can read.
+func (s *PostService) Find(user_id, id uint) (models.Post, error) {
+	post, err := s.posts.FindVisible(id, user_id)
+	if err != nil {
+		return models.Post{}, apperror.Lookup(err, apperror.CODE_POST_NOT_FOUND, "Post not found")
+	}
+	return post, nil
+}
+
+// Details loads the featured images, reactions and bookmarks shown with the
+// posts to the user.
+func (s *PostService) Details(posts []models.Post, user_id uint) (models.PostDetails, error) {
+	return s.posts.Details(posts, user_id)
+}
+
+func (s *PostService) List(user_id uint, search string, limit, offset int) ([]models.Post, error) {
+	return s.posts.List(user_id, search, limit, offset)
+}
+
+// ListByAuthor returns the published posts of the user with the given
+// username, newest first.
+func (s *PostService) ListByAuthor(username string, limit, offset int) ([]models.Post, error) {
+	user, err := s.users.FindByUsername(username)
+	if err != nil {
+		return nil, apperror.Lookup(err, apperror.CODE_USER_NOT_FOUND, "User not found")
+	}
+	return s.posts.ListPublishedByAuthor(user.ID, limit, offset)
+}
+
+// VisibleIDs returns which of the posts the user can read, for following
+// them in realtime.
+func (s *PostService) VisibleIDs(user_id uint, ids []uint64) ([]uint, error) {
+	return s.posts.VisibleIDs(ids, user_id)
+}
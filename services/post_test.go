@@ -0,0 +1,205 @@
+package services
+
+import (
+	"blogspot-project/models"
+	"blogspot-project/utils/apperror"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type postFixture struct {
+	posts    *fakePosts
+	users    *fakeUsers
+	sitemap  *fakeSitemap
+	notifier *fakeNotifier
+	service  *PostService
+}
+
+// newPostFixture has an admin (1), a member (2), a suspended admin (3) and
+// an image of the admin (5) in category 1.
+func newPostFixture(posts ...models.Post) postFixture {
+	suspended := testUser(3, models.ADMIN_USER_ROLE)
+	until := time.Now().Add(time.Hour)
+	suspended.SuspendedUntil = &until
+	users := newFakeUsers(testUser(1, models.ADMIN_USER_ROLE), testUser(2, models.NON_ADMIN_USER_ROLE), suspended)
+	image := models.Media{UserID: 1, Width: 10}
+	image.ID = 5
+	users.images[image.ID] = image
+	categories := &fakeCategories{categories: map[uint]models.Category{1: {ID: 1, Name: "News"}}}
+	f := postFixture{posts: newFakePosts(posts...), users: users, sitemap: &fakeSitemap{}, notifier: &fakeNotifier{}}
+	f.service = NewPostService(f.posts, f.users, categories, f.sitemap, f.notifier)
+	return f
+}
+
+func TestPostServiceCreate(t *testing.T) {
+	image_id, other_image := uint(5), uint(6)
+	tests := []struct {
+		name      string
+		user_id   uint
+		input     PostChanges
+		code      string
+		published bool
+		notified  []string
+	}{
+		{"published by default", 1, PostChanges{ArticleTitle: "Hi", CategoryID: 1}, "", true, []string{"mentions post 101"}},
+		{"draft notifies nobody", 1, PostChanges{ArticleTitle: "Hi", CategoryID: 1, Status: models.POST_STATUS_DRAFT}, "", false, nil},
+		{"own featured image", 1, PostChanges{ArticleTitle: "Hi", CategoryID: 1, FeaturedImageID: &image_id}, "", true, []string{"mentions post 101"}},
+		{"member", 2, PostChanges{ArticleTitle: "Hi", CategoryID: 1}, apperror.CODE_FORBIDDEN, false, nil},
+		{"suspended admin", 3, PostChanges{ArticleTitle: "Hi", CategoryID: 1}, apperror.CODE_ACCOUNT_SUSPENDED, false, nil},
+		{"unknown user", 9, PostChanges{ArticleTitle: "Hi", CategoryID: 1}, apperror.CODE_USER_NOT_FOUND, false, nil},
+		{"invalid status", 1, PostChanges{ArticleTitle: "Hi", CategoryID: 1, Status: 7}, apperror.CODE_VALIDATION_FAILED, false, nil},
+		{"unknown category", 1, PostChanges{ArticleTitle: "Hi", CategoryID: 2}, apperror.CODE_CATEGORY_NOT_FOUND, false, nil},
+		{"image of someone else", 1, PostChanges{ArticleTitle: "Hi", CategoryID: 1, FeaturedImageID: &other_image}, apperror.CODE_VALIDATION_FAILED, false, nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f := newPostFixture()
+			post, err := f.service.Create(test.user_id, test.input)
+			if code := errorCode(err); code != test.code {
+				t.Fatalf("expected error code %q, got %q (%v)", test.code, code, err)
+			}
+			if !reflect.DeepEqual(f.notifier.sent, test.notified) {
+				t.Errorf("expected notifications %v, got %v", test.notified, f.notifier.sent)
+			}
+			if test.code != "" {
+				if len(f.posts.posts) != 0 || len(f.sitemap.changed) != 0 {
+					t.Errorf("a rejected post must not be saved, got %v and sitemap %v", f.posts.posts, f.sitemap.changed)
+				}
+				return
+			}
+			if post.UserID != test.user_id || f.posts.posts[post.ID].ArticleTitle != test.input.ArticleTitle {
+				t.Errorf("expected the post to be saved for the user, got %+v", post)
+			}
+			if (post.PublishedAt != nil) != test.published {
+				t.Errorf("expected published %v, got published_at %v", test.published, post.PublishedAt)
+			}
+			if !reflect.DeepEqual(f.sitemap.changed, []uint{post.ID}) {
+				t.Errorf("expected the sitemap to get post %v, got %v", post.ID, f.sitemap.changed)
+			}
+		})
+	}
+}
+
+func TestPostServiceUpdate(t *testing.T) {
+	first := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	published := testPost(10, 1, models.POST_STATUS_PUBLISHED)
+	published.PublishedAt = &first
+	draft := testPost(11, 1, models.POST_STATUS_DRAFT)
+	tests := []struct {
+		name        string
+		user_id     uint
+		id          uint
+		input       PostChanges
+		code        string
+		keeps_date  bool
+		notified    []string
+		sitemap_ids []uint
+	}{
+		{"republish keeps the first date", 1, 10, PostChanges{CategoryID: 1, Status: models.POST_STATUS_PUBLISHED}, "", true, []string{"mentions post 10"}, []uint{10}},
+		{"first publish sets the date", 1, 11, PostChanges{CategoryID: 1, Status: models.POST_STATUS_PUBLISHED}, "", false, []string{"mentions post 11"}, []uint{11}},
+		{"draft stays quiet", 1, 11, PostChanges{ArticleTitle: "New", CategoryID: 1}, "", false, nil, []uint{11}},
+		{"post of another author", 1, 12, PostChanges{CategoryID: 1}, apperror.CODE_POST_NOT_FOUND, false, nil, nil},
+		{"member", 2, 10, PostChanges{CategoryID: 1}, apperror.CODE_FORBIDDEN, false, nil, nil},
+		{"invalid status", 1, 10, PostChanges{CategoryID: 1, Status: 9}, apperror.CODE_VALIDATION_FAILED, false, nil, nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f := newPostFixture(published, draft, testPost(12, 3, models.POST_STATUS_PUBLISHED))
+			post, err := f.service.Update(test.user_id, test.id, test.input)
+			if code := errorCode(err); code != test.code {
+				t.Fatalf("expected error code %q, got %q (%v)", test.code, code, err)
+			}
+			if !reflect.DeepEqual(f.notifier.sent, test.notified) {
+				t.Errorf("expected notifications %v, got %v", test.notified, f.notifier.sent)
+			}
+			if !reflect.DeepEqual(f.sitemap.changed, test.sitemap_ids) {
+				t.Errorf("expected sitemap changes %v, got %v", test.sitemap_ids, f.sitemap.changed)
+			}
+			if test.code != "" || post.Status != models.POST_STATUS_PUBLISHED {
+				return
+			}
+			if post.PublishedAt == nil || post.PublishedAt.Equal(first) != test.keeps_date {
+				t.Errorf("expected the first publish date kept %v, got %v", test.keeps_date, post.PublishedAt)
+			}
+		})
+	}
+}
+
+func TestPostServiceTags(t *testing.T) {
+	too_many := []string{}
+	for i := 0; i <= models.POST_MAX_TAGS; i++ {
+		too_many = append(too_many, fmt.Sprintf("tag %v", i))
+	}
+	tests := []struct {
+		name  string
+		input []string
+		code  string
+		saved []string
+	}{
+		{"same slug once", []string{"Go", " go ", "Web Dev"}, "", []string{"Go", "Web Dev"}},
+		{"no tags", []string{}, "", []string{}},
+		{"too many", too_many, apperror.CODE_VALIDATION_FAILED, nil},
+		{"too long", []string{strings.Repeat("a", models.TAG_NAME_MAX_LENGTH+1)}, apperror.CODE_VALIDATION_FAILED, nil},
+		{"only symbols", []string{"#!?"}, apperror.CODE_VALIDATION_FAILED, nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f := newPostFixture(testPost(10, 1, models.POST_STATUS_PUBLISHED))
+			f.posts.tags[10] = []string{"Old"}
+			_, err := f.service.Update(1, 10, PostChanges{CategoryID: 1, Tags: &test.input})
+			if code := errorCode(err); code != test.code {
+				t.Fatalf("expected error code %q, got %q (%v)", test.code, code, err)
+			}
+			if test.code != "" {
+				test.saved = []string{"Old"}
+			}
+			if !reflect.DeepEqual(f.posts.tags[10], test.saved) {
+				t.Errorf("expected tags %v, got %v", test.saved, f.posts.tags[10])
+			}
+		})
+	}
+
+	t.Run("left out keeps the tags", func(t *testing.T) {
+		f := newPostFixture(testPost(10, 1, models.POST_STATUS_PUBLISHED))
+		f.posts.tags[10] = []string{"Old"}
+		if _, err := f.service.Update(1, 10, PostChanges{ArticleTitle: "New", CategoryID: 1}); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(f.posts.tags[10], []string{"Old"}) {
+			t.Errorf("expected the tags kept, got %v", f.posts.tags[10])
+		}
+	})
+}
+
+func TestPostServiceDelete(t *testing.T) {
+	tests := []struct {
+		name    string
+		user_id uint
+		id      uint
+		code    string
+	}{
+		{"admin", 1, 10, ""},
+		{"admin deleting the post of another admin", 3, 10, ""},
+		{"member", 2, 10, apperror.CODE_FORBIDDEN},
+		{"unknown post", 1, 99, apperror.CODE_POST_NOT_FOUND},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f := newPostFixture(testPost(10, 1, models.POST_STATUS_PUBLISHED))
+			err := f.service.Delete(test.user_id, test.id)
+			if code := errorCode(err); code != test.code {
+				t.Fatalf("expected error code %q, got %q (%v)", test.code, code, err)
+			}
+			_, kept := f.posts.posts[10]
+			if kept == (test.code == "") {
+				t.Errorf("expected the post deleted %v, it is kept %v", test.code == "", kept)
+			}
+			if test.code == "" && !reflect.DeepEqual(f.sitemap.deleted, []uint{10}) {
+				t.Errorf("expected post 10 to leave the sitemap, got %v", f.sitemap.deleted)
+			}
+		})
+	}
+}
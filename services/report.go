@@ -0,0 +1,373 @@
+package services
+
+import (
+	"blogspot-project/models"
+	"blogspot-project/repositories"
+	"blogspot-project/utils/apperror"
+	"blogspot-project/utils/notification"
+	"errors"
+	"net/http"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ReportInput is a reader flagging a post or comment.
+type ReportInput struct {
+	TargetType string
+	TargetID   uint
+	Reason     string
+	Message    string
+}
+
+// ReportHistory is what moderators see of a user: the reports they filed
+// and the reports against their content.
+type ReportHistory struct {
+	User    models.User
+	Filed   []models.Report
+	Against []models.Report
+}
+
+// reportNotificationActions is what the author of reported content is told
+// for each action, dismissed reports are not worth a notification.
+var reportNotificationActions = map[string]string{
+	models.REPORT_ACTION_HIDE:    notification.ACTION_HIDDEN,
+	models.REPORT_ACTION_DELETE:  notification.ACTION_DELETED,
+	models.REPORT_ACTION_WARN:    notification.ACTION_WARNED,
+	models.REPORT_ACTION_SUSPEND: notification.ACTION_SUSPENDED,
+}
+
+type ReportService struct {
+	reports  repositories.ReportRepository
+	posts    repositories.PostRepository
+	comments repositories.CommentRepository
+	users    repositories.UserRepository
+	unit     repositories.UnitOfWork
+	sitemap  SitemapUpdater
+	notifier TxNotifier
+}
+
+func NewReportService(reports repositories.ReportRepository, posts repositories.PostRepository, comments repositories.CommentRepository, users repositories.UserRepository, unit repositories.UnitOfWork, index SitemapUpdater, notifier TxNotifier) *ReportService {
+	return &ReportService{reports: reports, posts: posts, comments: comments, users: users, unit: unit, sitemap: index, notifier: notifier}
+}
+
+func (s *ReportService) user(user_id uint) (models.User, error) {
+	user, err := s.users.FindByID(user_id)
+	if err != nil {
+		return models.User{}, apperror.Lookup(err, apperror.CODE_USER_NOT_FOUND, "User not found")
+	}
+	return user, nil
+}
+
+// moderator loads the user acting as a moderator, forbidden with the given
+// detail otherwise.
+func (s *ReportService) moderator(user_id uint, detail string) (models.User, error) {
+	user, err := s.user(user_id)
+	if err != nil {
+		return models.User{}, err
+	}
+	if !user.IsModerator() {
+		return models.User{}, apperror.Forbidden(detail)
+	}
+	return user, nil
+}
+
+// targetUserID is the author of the reported content, which the reporter
+// must be able to read.
+func (s *ReportService) targetUserID(user_id uint, target_type string, target_id uint) (uint, error) {
+	if target_type == models.TARGET_POST {
+		post, err := s.posts.FindVisible(target_id, user_id)
+		if err != nil {
+			return 0, apperror.Lookup(err, apperror.CODE_POST_NOT_FOUND, "Post not found")
+		}
+		return post.UserID, nil
+	}
+	comment, err := s.comments.FindVisible(target_id, user_id)
+	if err != nil {
+		return 0, apperror.Lookup(err, apperror.CODE_COMMENT_NOT_FOUND, "Comment not found")
+	}
+	return comment.UserID, nil
+}
+
+// Create files a report. Content reaching REPORT_HIDE_THRESHOLD open
+// reports is hidden until a moderator resolves them: a post is hidden and an
+// approved comment goes back to the moderation queue.
+func (s *ReportService) Create(user_id uint, input ReportInput) (models.Report, error) {
+	user, err := s.user(user_id)
+	if err != nil {
+		return models.Report{}, err
+	}
+	if user.IsSuspended() {
+		return models.Report{}, apperror.New(http.StatusForbidden, apperror.CODE_ACCOUNT_SUSPENDED, "Your account is suspended")
+	}
+	if !models.IsValidReportTarget(input.TargetType) {
+		return models.Report{}, apperror.Validation("Target type must be post or comment")
+	}
+	if !models.IsValidReportReason(input.Reason) {
+		return models.Report{}, apperror.Validation("Invalid report reason")
+	}
+	target_user_id, err := s.targetUserID(user_id, input.TargetType, input.TargetID)
+	if err != nil {
+		return models.Report{}, err
+	}
+	if target_user_id == user_id {
+		return models.Report{}, apperror.Validation("You cannot report your own content")
+	}
+	reported, err := s.reports.HasReported(user_id, input.TargetType, input.TargetID)
+	if err != nil {
+		return models.Report{}, err
+	}
+	if reported {
+		return models.Report{}, apperror.Conflict(apperror.CODE_ALREADY_REPORTED, "You already reported this "+input.TargetType)
+	}
+	report := models.Report{
+		ReporterID:   user_id,
+		TargetType:   input.TargetType,
+		TargetID:     input.TargetID,
+		TargetUserID: target_user_id,
+		Reason:       input.Reason,
+		Message:      input.Message,
+		Status:       models.REPORT_STATUS_OPEN,
+	}
+	err = s.unit.Do(func(tx *repositories.Tx) error {
+		if err := tx.Reports.Create(&report); err != nil {
+			return err
+		}
+		threshold := models.ReportHideThreshold()
+		if threshold == 0 {
+			return nil
+		}
+		open, err := tx.Reports.ListOpen(report.TargetType, report.TargetID)
+		if err != nil {
+			return err
+		}
+		if int64(len(open)) < threshold {
+			return nil
+		}
+		return s.hold(tx, &report)
+	})
+	return report, err
+}
+
+// hold hides content that got too many reports, and marks the report that
+// did it so a dismissal can undo it. Content a moderator already hid is left
+// alone. Like every change of the sitemap in a unit of work, it only reaches
+// the sitemap once the work commits.
+func (s *ReportService) hold(tx *repositories.Tx, report *models.Report) error {
+	if report.TargetType == models.TARGET_POST {
+		post, err := tx.Posts.FindByID(report.TargetID)
+		if err != nil {
+			return err
+		}
+		if post.IsHidden {
+			return nil
+		}
+		if _, err := tx.Posts.SetHidden(post, true); err != nil {
+			return err
+		}
+		tx.AfterCommit(func() { s.sitemap.PostDeleted(post.ID) })
+		return tx.Reports.Hold(report)
+	}
+	// the comment goes back to the moderation queue
+	comment, err := tx.Comments.FindByID(report.TargetID)
+	if err != nil {
+		return err
+	}
+	if comment.ModerationStatus != models.COMMENT_STATUS_APPROVED {
+		return nil
+	}
+	if err := tx.Comments.SetStatus(comment, models.COMMENT_STATUS_PENDING, comment.SpamLabel); err != nil {
+		return err
+	}
+	return tx.Reports.Hold(report)
+}
+
+// List returns the reports with a status to moderators, only those on
+// target_type when it is set.
+func (s *ReportService) List(user_id uint, status uint, target_type string, limit, offset int) ([]models.Report, error) {
+	if _, err := s.moderator(user_id, "Only moderators can see reports"); err != nil {
+		return nil, err
+	}
+	if status < models.REPORT_STATUS_OPEN || status > models.REPORT_STATUS_DISMISSED {
+		return nil, apperror.Validation("Invalid report status")
+	}
+	if target_type != "" && !models.IsValidReportTarget(target_type) {
+		return nil, apperror.Validation("Target type must be post or comment")
+	}
+	return s.reports.List(status, target_type, limit, offset)
+}
+
+// Resolve closes a report and every other open report on the same content
+// with the moderator's action, and tells the author and the reporters.
+func (s *ReportService) Resolve(user_id, id uint, action string, suspend_days int) error {
+	if _, err := s.moderator(user_id, "Only moderators can resolve reports"); err != nil {
+		return err
+	}
+	switch action {
+	case models.REPORT_ACTION_DISMISS, models.REPORT_ACTION_HIDE, models.REPORT_ACTION_DELETE, models.REPORT_ACTION_WARN, models.REPORT_ACTION_SUSPEND:
+	default:
+		return apperror.Validation("Action must be dismiss, hide, delete, warn or suspend")
+	}
+	if suspend_days < 0 {
+		return apperror.Validation("Suspend days cannot be negative")
+	}
+	report, err := s.reports.FindByID(id)
+	if err != nil {
+		return apperror.Lookup(err, apperror.CODE_REPORT_NOT_FOUND, "Report not found")
+	}
+	if report.Status != models.REPORT_STATUS_OPEN {
+		return apperror.Conflict(apperror.CODE_REPORT_RESOLVED, "Report is already resolved")
+	}
+	return s.unit.Do(func(tx *repositories.Tx) error {
+		notifier := s.notifier(tx)
+		post_id, err := reportPostID(tx, report)
+		if err != nil {
+			return err
+		}
+		open, err := tx.Reports.ListOpen(report.TargetType, report.TargetID)
+		if err != nil {
+			return err
+		}
+		if err := s.apply(tx, notifier, report, open, action, suspend_days); err != nil {
+			return err
+		}
+		status := uint(models.REPORT_STATUS_RESOLVED)
+		if action == models.REPORT_ACTION_DISMISS {
+			status = models.REPORT_STATUS_DISMISSED
+		}
+		if err := tx.Reports.Resolve(report.TargetType, report.TargetID, status, action, user_id); err != nil {
+			return err
+		}
+		if notified, ok := reportNotificationActions[action]; ok {
+			if err := notifier.Moderation(report.TargetUserID, user_id, notified, report.TargetType, report.TargetID, post_id); err != nil {
+				return err
+			}
+		}
+		for _, item := range open {
+			if err := notifier.Moderation(item.ReporterID, user_id, notification.ACTION_REPORT_REVIEWED, report.TargetType, report.TargetID, post_id); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// reportPostID is the post the reported content belongs to.
+func reportPostID(tx *repositories.Tx, report models.Report) (uint, error) {
+	if report.TargetType == models.TARGET_POST {
+		return report.TargetID, nil
+	}
+	comment, err := tx.Comments.FindByID(report.TargetID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return comment.PostID, nil
+}
+
+// apply carries out the moderator's decision on the reported content and
+// its author.
+func (s *ReportService) apply(tx *repositories.Tx, notifier Notifier, report models.Report, open []models.Report, action string, suspend_days int) error {
+	switch action {
+	case models.REPORT_ACTION_DISMISS:
+		// only undo the hold placed by the reports, not an earlier moderator
+		// decision
+		for _, item := range open {
+			if item.HeldByReport {
+				return s.setHidden(tx, notifier, report, false)
+			}
+		}
+		return nil
+	case models.REPORT_ACTION_DELETE:
+		if report.TargetType == models.TARGET_POST {
+			post, err := tx.Posts.FindByID(report.TargetID)
+			if err != nil {
+				return err
+			}
+			if err := tx.Posts.Delete(post); err != nil {
+				return err
+			}
+			tx.AfterCommit(func() { s.sitemap.PostDeleted(post.ID) })
+			return nil
+		}
+		comment, err := tx.Comments.FindByID(report.TargetID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+		return tx.Comments.Remove(comment)
+	}
+	if err := s.setHidden(tx, notifier, report, true); err != nil {
+		return err
+	}
+	switch action {
+	case models.REPORT_ACTION_WARN:
+		return tx.Users.Warn(report.TargetUserID)
+	case models.REPORT_ACTION_SUSPEND:
+		if suspend_days == 0 {
+			suspend_days = models.ReportSuspendDays()
+		}
+		return tx.Users.Suspend(report.TargetUserID, time.Now().AddDate(0, 0, suspend_days))
+	}
+	return nil
+}
+
+// setHidden hides reported content, or shows it again when the reports
+// turned out to be unfounded. Comments are hidden by rejecting them.
+func (s *ReportService) setHidden(tx *repositories.Tx, notifier Notifier, report models.Report, hidden bool) error {
+	if report.TargetType == models.TARGET_POST {
+		post, err := tx.Posts.FindByID(report.TargetID)
+		if err != nil {
+			return err
+		}
+		post, err = tx.Posts.SetHidden(post, hidden)
+		if err != nil {
+			return err
+		}
+		author, err := tx.Users.FindByID(post.UserID)
+		if err != nil {
+			return err
+		}
+		tx.AfterCommit(func() { s.sitemap.PostChanged(post, author) })
+		return nil
+	}
+	comment, err := tx.Comments.FindByID(report.TargetID)
+	if err != nil {
+		return err
+	}
+	if hidden && comment.ModerationStatus != models.COMMENT_STATUS_REJECTED {
+		return tx.Comments.SetStatus(comment, models.COMMENT_STATUS_REJECTED, comment.SpamLabel)
+	}
+	if !hidden && comment.ModerationStatus == models.COMMENT_STATUS_PENDING {
+		if err := tx.Comments.SetStatus(comment, models.COMMENT_STATUS_APPROVED, comment.SpamLabel); err != nil {
+			return err
+		}
+		return notifier.CommentPublished(comment)
+	}
+	return nil
+}
+
+// History returns the reports filed by a user and those against their
+// content to moderators.
+func (s *ReportService) History(user_id, id uint) (ReportHistory, error) {
+	if _, err := s.moderator(user_id, "Only moderators can see report history"); err != nil {
+		return ReportHistory{}, err
+	}
+	user, err := s.user(id)
+	if err != nil {
+		return ReportHistory{}, err
+	}
+	filed, err := s.reports.ListFiledBy(user.ID)
+	if err != nil {
+		return ReportHistory{}, err
+	}
+	against, err := s.reports.ListAgainst(user.ID)
+	if err != nil {
+		return ReportHistory{}, err
+	}
+	return ReportHistory{User: user, Filed: filed, Against: against}, nil
+}
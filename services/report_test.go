@@ -0,0 +1,199 @@
+package services
+
+import (
+	"blogspot-project/models"
+	"blogspot-project/repositories"
+	"blogspot-project/utils/apperror"
+	"reflect"
+	"testing"
+)
+
+type reportFixture struct {
+	reports  *fakeReports
+	posts    *fakePosts
+	comments *fakeComments
+	users    *fakeUsers
+	sitemap  *fakeSitemap
+	notifier *fakeNotifier
+	service  *ReportService
+}
+
+// newReportFixture has an admin (1) who wrote post 10, a member (2) who
+// wrote comment 20 on it, a moderator (3) and two more members (4, 5).
+func newReportFixture(reports ...models.Report) *reportFixture {
+	f := &reportFixture{
+		reports:  newFakeReports(reports...),
+		posts:    newFakePosts(testPost(10, 1, models.POST_STATUS_PUBLISHED)),
+		comments: newFakeComments(testComment(20, 10, 2, models.COMMENT_STATUS_APPROVED)),
+		users:    newFakeUsers(testUser(1, models.ADMIN_USER_ROLE), testUser(2, models.NON_ADMIN_USER_ROLE), testUser(3, models.MODERATOR_USER_ROLE), testUser(4, models.NON_ADMIN_USER_ROLE), testUser(5, models.NON_ADMIN_USER_ROLE)),
+		sitemap:  &fakeSitemap{},
+		notifier: &fakeNotifier{},
+	}
+	unit := &fakeUnit{tx: repositories.Tx{Users: f.users, Posts: f.posts, Comments: f.comments, Reports: f.reports}}
+	f.service = NewReportService(f.reports, f.posts, f.comments, f.users, unit, f.sitemap, func(tx *repositories.Tx) Notifier {
+		return f.notifier
+	})
+	return f
+}
+
+func testReport(id, reporter_id uint, target_type string, target_id, target_user_id uint) models.Report {
+	report := models.Report{ReporterID: reporter_id, TargetType: target_type, TargetID: target_id, TargetUserID: target_user_id, Reason: "spam", Status: models.REPORT_STATUS_OPEN}
+	report.ID = id
+	return report
+}
+
+func TestReportServiceCreate(t *testing.T) {
+	t.Setenv("REPORT_HIDE_THRESHOLD", "2")
+	earlier_post := testReport(1, 4, models.TARGET_POST, 10, 1)
+	earlier_comment := testReport(2, 4, models.TARGET_COMMENT, 20, 2)
+	tests := []struct {
+		name           string
+		user_id        uint
+		input          ReportInput
+		earlier        []models.Report
+		code           string
+		post_hidden    bool
+		comment_status uint
+	}{
+		{"first report", 5, ReportInput{TargetType: models.TARGET_POST, TargetID: 10, Reason: "spam"}, nil, "", false, models.COMMENT_STATUS_APPROVED},
+		{"threshold hides the post", 5, ReportInput{TargetType: models.TARGET_POST, TargetID: 10, Reason: "spam"}, []models.Report{earlier_post}, "", true, models.COMMENT_STATUS_APPROVED},
+		{"threshold holds the comment", 5, ReportInput{TargetType: models.TARGET_COMMENT, TargetID: 20, Reason: "spam"}, []models.Report{earlier_comment}, "", false, models.COMMENT_STATUS_PENDING},
+		{"reported twice", 4, ReportInput{TargetType: models.TARGET_POST, TargetID: 10, Reason: "spam"}, []models.Report{earlier_post}, apperror.CODE_ALREADY_REPORTED, false, models.COMMENT_STATUS_APPROVED},
+		{"own content", 2, ReportInput{TargetType: models.TARGET_COMMENT, TargetID: 20, Reason: "spam"}, nil, apperror.CODE_VALIDATION_FAILED, false, models.COMMENT_STATUS_APPROVED},
+		{"unknown reason", 5, ReportInput{TargetType: models.TARGET_POST, TargetID: 10, Reason: "boring"}, nil, apperror.CODE_VALIDATION_FAILED, false, models.COMMENT_STATUS_APPROVED},
+		{"unknown post", 5, ReportInput{TargetType: models.TARGET_POST, TargetID: 99, Reason: "spam"}, nil, apperror.CODE_POST_NOT_FOUND, false, models.COMMENT_STATUS_APPROVED},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f := newReportFixture(test.earlier...)
+			report, err := f.service.Create(test.user_id, test.input)
+			if code := errorCode(err); code != test.code {
+				t.Fatalf("expected error code %q, got %q (%v)", test.code, code, err)
+			}
+			held := test.post_hidden || test.comment_status == models.COMMENT_STATUS_PENDING
+			if report.HeldByReport != held || f.reports.reports[report.ID].HeldByReport != held {
+				t.Errorf("expected the report to record the hold %v", held)
+			}
+			if f.posts.posts[10].IsHidden != test.post_hidden {
+				t.Errorf("expected the post hidden %v", test.post_hidden)
+			}
+			if test.post_hidden != reflect.DeepEqual(f.sitemap.deleted, []uint{10}) {
+				t.Errorf("expected the post to leave the sitemap %v, got %v", test.post_hidden, f.sitemap.deleted)
+			}
+			if status := f.comments.comments[20].ModerationStatus; status != test.comment_status {
+				t.Errorf("expected comment status %v, got %v", test.comment_status, status)
+			}
+		})
+	}
+}
+
+func TestReportServiceResolve(t *testing.T) {
+	tests := []struct {
+		name     string
+		user_id  uint
+		report   models.Report
+		action   string
+		code     string
+		status   uint
+		notified []string
+	}{
+		{"hide a comment", 3, testReport(1, 4, models.TARGET_COMMENT, 20, 2), models.REPORT_ACTION_HIDE, "", models.REPORT_STATUS_RESOLVED,
+			[]string{"hidden 2 comment 20", "reviewed 4 comment 20", "reviewed 5 comment 20"}},
+		{"dismiss", 3, testReport(1, 4, models.TARGET_POST, 10, 1), models.REPORT_ACTION_DISMISS, "", models.REPORT_STATUS_DISMISSED,
+			[]string{"reviewed 4 post 10", "reviewed 5 post 10"}},
+		{"delete a comment", 1, testReport(1, 4, models.TARGET_COMMENT, 20, 2), models.REPORT_ACTION_DELETE, "", models.REPORT_STATUS_RESOLVED,
+			[]string{"deleted 2 comment 20", "reviewed 4 comment 20", "reviewed 5 comment 20"}},
+		{"unknown action", 3, testReport(1, 4, models.TARGET_POST, 10, 1), "ignore", apperror.CODE_VALIDATION_FAILED, models.REPORT_STATUS_OPEN, nil},
+		{"member", 2, testReport(1, 4, models.TARGET_POST, 10, 1), models.REPORT_ACTION_HIDE, apperror.CODE_FORBIDDEN, models.REPORT_STATUS_OPEN, nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			other := testReport(2, 5, test.report.TargetType, test.report.TargetID, test.report.TargetUserID)
+			f := newReportFixture(test.report, other)
+			err := f.service.Resolve(test.user_id, 1, test.action, 0)
+			if code := errorCode(err); code != test.code {
+				t.Fatalf("expected error code %q, got %q (%v)", test.code, code, err)
+			}
+			for _, report := range f.reports.reports {
+				if report.Status != test.status {
+					t.Errorf("expected report %v in status %v, got %v", report.ID, test.status, report.Status)
+				}
+			}
+			if !reflect.DeepEqual(f.notifier.sent, test.notified) {
+				t.Errorf("expected notifications %v, got %v", test.notified, f.notifier.sent)
+			}
+		})
+	}
+}
+
+func TestReportServiceDismiss(t *testing.T) {
+	held := testReport(1, 4, models.TARGET_COMMENT, 20, 2)
+	held.HeldByReport = true
+	tests := []struct {
+		name      string
+		report    models.Report
+		status    uint
+		restored  uint
+		published bool
+	}{
+		{"hold of the reports undone", held, models.COMMENT_STATUS_PENDING, models.COMMENT_STATUS_APPROVED, true},
+		{"comment a moderator held stays held", testReport(1, 4, models.TARGET_COMMENT, 20, 2), models.COMMENT_STATUS_PENDING, models.COMMENT_STATUS_PENDING, false},
+		{"rejected comment stays rejected", held, models.COMMENT_STATUS_REJECTED, models.COMMENT_STATUS_REJECTED, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f := newReportFixture(test.report)
+			comment := f.comments.comments[20]
+			comment.ModerationStatus = test.status
+			f.comments.comments[20] = comment
+			if err := f.service.Resolve(3, 1, models.REPORT_ACTION_DISMISS, 0); err != nil {
+				t.Fatal(err)
+			}
+			if status := f.comments.comments[20].ModerationStatus; status != test.restored {
+				t.Errorf("expected status %v, got %v", test.restored, status)
+			}
+			if published := len(f.notifier.sent) > 0 && f.notifier.sent[0] == "comment 20"; published != test.published {
+				t.Errorf("expected the comment published %v, got %v", test.published, f.notifier.sent)
+			}
+		})
+	}
+
+	// a post hidden by a moderator is not shown again by dismissing new
+	// reports
+	f := newReportFixture(testReport(1, 4, models.TARGET_POST, 10, 1))
+	post := f.posts.posts[10]
+	post.IsHidden = true
+	f.posts.posts[10] = post
+	if err := f.service.Resolve(3, 1, models.REPORT_ACTION_DISMISS, 0); err != nil {
+		t.Fatal(err)
+	}
+	if !f.posts.posts[10].IsHidden || len(f.sitemap.changed) != 0 {
+		t.Errorf("expected the post to stay hidden, sitemap got %v", f.sitemap.changed)
+	}
+}
+
+func TestReportServiceResolveAuthor(t *testing.T) {
+	tests := []struct {
+		action    string
+		warnings  uint
+		suspended bool
+	}{
+		{models.REPORT_ACTION_WARN, 1, false},
+		{models.REPORT_ACTION_SUSPEND, 0, true},
+	}
+	for _, test := range tests {
+		t.Run(test.action, func(t *testing.T) {
+			f := newReportFixture(testReport(1, 4, models.TARGET_COMMENT, 20, 2))
+			if err := f.service.Resolve(3, 1, test.action, 0); err != nil {
+				t.Fatal(err)
+			}
+			author := f.users.users[2]
+			if author.WarningCount != test.warnings || author.IsSuspended() != test.suspended {
+				t.Errorf("expected %v warnings and suspended %v, got %v and %v", test.warnings, test.suspended, author.WarningCount, author.SuspendedUntil)
+			}
+			if status := f.comments.comments[20].ModerationStatus; status != models.COMMENT_STATUS_REJECTED {
+				t.Errorf("expected the comment hidden, got status %v", status)
+			}
+		})
+	}
+}
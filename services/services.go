@@ -0,0 +1,63 @@
+package services
+
+import (
+	"blogspot-project/models"
+	"blogspot-project/repositories"
+	"blogspot-project/utils/notification"
+)
+
+// Notifier stores notifications for the users something happened to, see
+// notification.Notifier.
+type Notifier interface {
+	Notify(notification models.Notification) error
+	Mentions(target_type string, target_id uint) error
+	CommentPublished(comment models.Comment) error
+	Like(actor_id, owner_id uint, target_type string, target_id, post_id uint) error
+	Moderation(user_id, moderator_id uint, action, target_type string, target_id, post_id uint) error
+}
+
+// TxNotifier gives a Notifier writing inside a unit of work.
+type TxNotifier func(tx *repositories.Tx) Notifier
+
+// NotifierInTx writes the notifications inside the unit of work and only
+// pushes them to the clients once it commits.
+func NotifierInTx(notifier *notification.Notifier) TxNotifier {
+	return func(tx *repositories.Tx) Notifier {
+		pending := notifier.WithTx(tx.DB())
+		tx.AfterCommit(pending.Flush)
+		return pending
+	}
+}
+
+// SitemapUpdater keeps the sitemap in line with the published posts,
+// categories and authors, see sitemap.Index.
+type SitemapUpdater interface {
+	PostChanged(post models.Post, author models.User)
+	PostDeleted(post_id uint)
+	CategoryChanged(category models.Category)
+	CategoryDeleted(category_id uint)
+	AuthorChanged(user models.User)
+	// AuthorDeleted also drops the posts of the author
+	AuthorDeleted(user_id uint)
+}
+
+// SpamScorer scores new comments and learns from the decisions of
+// moderators, see spam.Classifier.
+type SpamScorer interface {
+	Score(text string) (score float64, trained bool, err error)
+	Learn(text string, isSpam bool) error
+	Unlearn(text string, isSpam bool) error
+}
+
+// TimelineCache keeps the personalized feed of each user, see
+// timeline.Cache.
+type TimelineCache interface {
+	PostIDs(user_id uint, limit, offset int) ([]uint, error)
+	Invalidate(user_id uint)
+}
+
+// EventPublisher pushes an event to the clients following a topic, see
+// realtime.Hub.
+type EventPublisher interface {
+	Publish(topic, event_type string, data interface{})
+}
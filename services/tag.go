@@ -0,0 +1,34 @@
+package services
+
+import (
+	"blogspot-project/models"
+	"blogspot-project/repositories"
+	"blogspot-project/utils/apperror"
+)
+
+type TagService struct {
+	tags  repositories.TagRepository
+	posts repositories.PostRepository
+}
+
+func NewTagService(tags repositories.TagRepository, posts repositories.PostRepository) *TagService {
+	return &TagService{tags: tags, posts: posts}
+}
+
+// Get loads a tag with a page of its published posts, newest first, and
+// their details as anonymous readers see them.
+func (s *TagService) Get(slug string, limit, offset int) (models.Tag, []models.Post, models.PostDetails, error) {
+	tag, err := s.tags.FindBySlug(slug)
+	if err != nil {
+		return models.Tag{}, nil, models.PostDetails{}, apperror.Lookup(err, apperror.CODE_TAG_NOT_FOUND, "Tag not found")
+	}
+	posts, err := s.posts.ListPublishedWithTag(tag.ID, limit, offset)
+	if err != nil {
+		return models.Tag{}, nil, models.PostDetails{}, err
+	}
+	details, err := s.posts.Details(posts, 0)
+	if err != nil {
+		return models.Tag{}, nil, models.PostDetails{}, err
+	}
+	return tag, posts, details, nil
+}
@@ -0,0 +1,211 @@
+package services
+
+import (
+	"blogspot-project/models"
+	"blogspot-project/repositories"
+	"blogspot-project/utils/apperror"
+	"blogspot-project/utils/token"
+	"blogspot-project/utils/validation"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// AvatarPreparer loads an image uploaded by a user, making sure it has the
+// avatar sizes.
+type AvatarPreparer func(user_id, media_id uint) (models.Media, error)
+
+// ProfileUpdate holds the changes of a user to their own account. Empty
+// fields are left unchanged, except bio and social links which are only
+// written when not nil and may be cleared.
+type ProfileUpdate struct {
+	Name          string
+	Username      string
+	Email         string
+	ImageUrl      string
+	AvatarMediaID *uint
+	Bio           *string
+	SocialLinks   *[]models.SocialLink
+}
+
+type UserService struct {
+	users   repositories.UserRepository
+	sitemap SitemapUpdater
+	avatars AvatarPreparer
+}
+
+func NewUserService(users repositories.UserRepository, index SitemapUpdater, avatars AvatarPreparer) *UserService {
+	return &UserService{users: users, sitemap: index, avatars: avatars}
+}
+
+func (s *UserService) Find(id uint) (models.User, error) {
+	user, err := s.users.FindByID(id)
+	if err != nil {
+		return models.User{}, apperror.Lookup(err, apperror.CODE_USER_NOT_FOUND, "User not found")
+	}
+	return user, nil
+}
+
+func (s *UserService) FindByUsername(username string) (models.User, error) {
+	user, err := s.users.FindByUsername(username)
+	if err != nil {
+		return models.User{}, apperror.Lookup(err, apperror.CODE_USER_NOT_FOUND, "User not found")
+	}
+	return user, nil
+}
+
+// admin loads the user acting as an admin, forbidden with the given detail
+// when they are not one.
+func (s *UserService) admin(user_id uint, detail string) (models.User, error) {
+	user, err := s.Find(user_id)
+	if err != nil {
+		return models.User{}, err
+	}
+	if user.Role != models.ADMIN_USER_ROLE {
+		return models.User{}, apperror.Forbidden(detail)
+	}
+	return user, nil
+}
+
+// Register creates a non admin user. Other roles are only given by an admin
+// through UpdateRole.
+func (s *UserService) Register(user models.User) (models.User, error) {
+	user.Role = models.NON_ADMIN_USER_ROLE
+	err := s.users.Create(&user)
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return models.User{}, apperror.Conflict(apperror.CODE_ACCOUNT_EXISTS, "Username or email is already registered")
+	}
+	if err != nil {
+		return models.User{}, err
+	}
+	return user, nil
+}
+
+// Login returns a token for the user with the given credentials.
+func (s *UserService) Login(username, password string) (string, error) {
+	user, err := s.users.FindByUsername(username)
+	if err == nil {
+		err = models.VerifyPassword(password, user.Password)
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return "", apperror.New(http.StatusUnauthorized, apperror.CODE_INVALID_CREDENTIALS, "Username or password is incorrect")
+	}
+	if err != nil {
+		return "", err
+	}
+	return token.GenerateToken(user.ID)
+}
+
+func (s *UserService) UpdatePassword(user_id uint, old_password, new_password string) error {
+	user, err := s.Find(user_id)
+	if err != nil {
+		return err
+	}
+	if err := models.VerifyPassword(old_password, user.Password); err == bcrypt.ErrMismatchedHashAndPassword {
+		return apperror.New(http.StatusUnprocessableEntity, apperror.CODE_INVALID_CREDENTIALS, "Old password is incorrect")
+	}
+	hashedPassword, err := models.PasswordHashing(new_password)
+	if err != nil {
+		return err
+	}
+	_, err = s.users.Update(user, models.User{Password: hashedPassword}, nil)
+	return err
+}
+
+// UpdateProfile applies the changes of a user to their own account. An
+// uploaded avatar replaces the image url.
+func (s *UserService) UpdateProfile(user_id uint, input ProfileUpdate) (models.User, error) {
+	user, err := s.Find(user_id)
+	if err != nil {
+		return models.User{}, err
+	}
+	changes := models.User{
+		Name:     input.Name,
+		Username: input.Username,
+		Email:    input.Email,
+		ImageUrl: input.ImageUrl,
+	}
+	if input.AvatarMediaID != nil {
+		avatar, err := s.avatars(user_id, *input.AvatarMediaID)
+		if err != nil {
+			return models.User{}, err
+		}
+		changes.AvatarMediaID = &avatar.ID
+		changes.ImageUrl = avatar.Image(models.MEDIA_VARIANT_AVATAR).Url
+	}
+	// bio and links may be cleared, so they are written even when empty
+	columns := []string{}
+	if input.Bio != nil {
+		if len([]rune(*input.Bio)) > models.PROFILE_BIO_MAX_LENGTH {
+			return models.User{}, apperror.Invalid(validation.FieldError{Field: "bio", Rule: "max", Param: strconv.Itoa(models.PROFILE_BIO_MAX_LENGTH)})
+		}
+		changes.Bio = *input.Bio
+		columns = append(columns, "bio")
+	}
+	if input.SocialLinks != nil {
+		if len(*input.SocialLinks) > models.PROFILE_MAX_SOCIAL_LINKS {
+			return models.User{}, apperror.Invalid(validation.FieldError{Field: "social_links", Rule: "max_items", Param: strconv.Itoa(models.PROFILE_MAX_SOCIAL_LINKS)})
+		}
+		changes.SocialLinks = *input.SocialLinks
+		columns = append(columns, "social_links")
+	}
+	savedUser, err := s.users.Update(user, changes, columns)
+	if err != nil {
+		return models.User{}, apperror.Lookup(err, apperror.CODE_USER_NOT_FOUND, "User not found")
+	}
+	s.sitemap.AuthorChanged(savedUser)
+	return savedUser, nil
+}
+
+// Avatars loads the avatar media of the users keyed by media id.
+func (s *UserService) Avatars(users ...models.User) (map[uint]models.Media, error) {
+	return s.users.Avatars(users)
+}
+
+// Stats counts the published posts and the follows of a user.
+func (s *UserService) Stats(user_id uint) (models.UserStats, error) {
+	return s.users.Stats(user_id)
+}
+
+// List searches the other users by name, for admins only.
+func (s *UserService) List(user_id uint, search string, limit, offset int) ([]models.User, error) {
+	if _, err := s.admin(user_id, "Only Admin can look list users"); err != nil {
+		return nil, err
+	}
+	return s.users.List(user_id, search, limit, offset)
+}
+
+func (s *UserService) Delete(user_id, id uint) error {
+	if _, err := s.admin(user_id, "Only Admin can delete user"); err != nil {
+		return err
+	}
+	user, err := s.Find(id)
+	if err != nil {
+		return err
+	}
+	if err := s.users.Delete(user); err != nil {
+		return err
+	}
+	s.sitemap.AuthorDeleted(user.ID)
+	return nil
+}
+
+func (s *UserService) UpdateRole(user_id, id, role uint) (models.User, error) {
+	if _, err := s.admin(user_id, "Only Admin can change user roles"); err != nil {
+		return models.User{}, err
+	}
+	if !models.IsValidRole(role) {
+		return models.User{}, apperror.Validation("Invalid role")
+	}
+	user, err := s.Find(id)
+	if err != nil {
+		return models.User{}, err
+	}
+	if err := s.users.UpdateRole(&user, role); err != nil {
+		return models.User{}, err
+	}
+	return user, nil
+}
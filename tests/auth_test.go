@@ -0,0 +1,48 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestRegister(t *testing.T) {
+	s := newServer(t)
+	body := map[string]interface{}{
+		"name":      "Jane",
+		"username":  "jane",
+		"email":     "jane@example.com",
+		"password":  PASSWORD,
+		"image_url": "https://example.com/jane.png",
+	}
+	s.call(http.StatusOK, "POST", "/auth/register", "", body)
+	s.call(http.StatusConflict, "POST", "/auth/register", "", body)
+	s.call(http.StatusUnprocessableEntity, "POST", "/auth/register", "", map[string]interface{}{
+		"name":      "x",
+		"username":  "a b",
+		"email":     "nope",
+		"password":  PASSWORD,
+		"image_url": "nope",
+	})
+	// a role cannot be picked when registering
+	body["name"], body["username"], body["email"], body["role"] = "John", "john", "john@example.com", 3
+	s.call(http.StatusOK, "POST", "/auth/register", "", body)
+}
+
+func TestLogin(t *testing.T) {
+	s := newServer(t)
+	s.createMember("jane")
+	s.call(http.StatusOK, "POST", "/auth/login", "", map[string]string{"username": "jane", "password": PASSWORD})
+	s.call(http.StatusUnauthorized, "POST", "/auth/login", "", map[string]string{"username": "jane", "password": "wrong"})
+	s.call(http.StatusUnauthorized, "POST", "/auth/login", "", map[string]string{"username": "nobody", "password": PASSWORD})
+	s.call(http.StatusUnprocessableEntity, "POST", "/auth/login", "", map[string]string{"username": "jane"})
+}
+
+func TestUpdatePassword(t *testing.T) {
+	s := newServer(t)
+	jane := s.createMember("jane")
+	token := s.login(jane)
+	s.call(http.StatusUnauthorized, "PATCH", "/login/update-password", "", map[string]string{"old_password": PASSWORD, "new_password": "changed"})
+	s.call(http.StatusUnprocessableEntity, "PATCH", "/login/update-password", token, map[string]string{"old_password": "wrong", "new_password": "changed"})
+	s.call(http.StatusOK, "PATCH", "/login/update-password", token, map[string]string{"old_password": PASSWORD, "new_password": "changed"})
+	s.call(http.StatusOK, "POST", "/auth/login", "", map[string]string{"username": "jane", "password": "changed"})
+}
@@ -0,0 +1,64 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestCreateCategory(t *testing.T) {
+	s := newServer(t)
+	admin := s.createAdmin("admin")
+	jane := s.createMember("jane")
+	s.call(http.StatusOK, "POST", "/category/", s.login(admin), map[string]string{"name": "Tech"})
+	s.call(http.StatusOK, "POST", "/category/", s.login(jane), map[string]string{"name": "Life"})
+	s.call(http.StatusUnauthorized, "POST", "/category/", "", map[string]string{"name": "Travel"})
+	s.call(http.StatusUnprocessableEntity, "POST", "/category/", s.login(admin), map[string]string{})
+}
+
+func TestListCategories(t *testing.T) {
+	s := newServer(t)
+	admin := s.createAdmin("admin")
+	tech := s.createCategory("Tech")
+	s.createCategory("Life")
+	s.createPost(admin, tech, "Hello")
+	s.call(http.StatusOK, "GET", "/category/", "", nil)
+	s.call(http.StatusOK, "GET", "/category/?input_search=te", "", nil)
+	s.call(http.StatusOK, "GET", fmt.Sprintf("/category/%v", tech.ID), "", nil)
+	s.call(http.StatusNotFound, "GET", "/category/99", "", nil)
+}
+
+func TestUpdateCategory(t *testing.T) {
+	s := newServer(t)
+	admin := s.createAdmin("admin")
+	jane := s.createMember("jane")
+	category := s.createCategory("Tech")
+	path := fmt.Sprintf("/category/%v", category.ID)
+	s.call(http.StatusOK, "PATCH", path, s.login(admin), map[string]string{"name": "Technology"})
+	s.call(http.StatusOK, "PATCH", path, s.login(jane), map[string]string{"name": "Tech and science"})
+	s.call(http.StatusNotFound, "PATCH", "/category/99", s.login(admin), map[string]string{"name": "Nothing"})
+}
+
+func TestDeleteCategory(t *testing.T) {
+	s := newServer(t)
+	admin := s.createAdmin("admin")
+	jane := s.createMember("jane")
+	category := s.createCategory("Tech")
+	path := fmt.Sprintf("/category/%v", category.ID)
+	s.call(http.StatusUnauthorized, "DELETE", path, "", nil)
+	s.call(http.StatusOK, "DELETE", path, s.login(jane), nil)
+	s.call(http.StatusNotFound, "DELETE", path, s.login(admin), nil)
+}
+
+func TestFollowCategory(t *testing.T) {
+	s := newServer(t)
+	jane := s.createMember("jane")
+	category := s.createCategory("Tech")
+	token := s.login(jane)
+	path := fmt.Sprintf("/category/%v/follow", category.ID)
+	s.call(http.StatusOK, "POST", path, token, nil)
+	s.call(http.StatusOK, "GET", "/user/following/categories", token, nil)
+	s.call(http.StatusOK, "DELETE", path, token, nil)
+	s.call(http.StatusNotFound, "POST", "/category/99/follow", token, nil)
+	s.call(http.StatusOK, "GET", "/user/following/categories", token, nil)
+}
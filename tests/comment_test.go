@@ -0,0 +1,65 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestCreateComment(t *testing.T) {
+	s := newServer(t)
+	admin := s.createAdmin("admin")
+	jane := s.createMember("jane")
+	post := s.createPost(admin, s.createCategory("Tech"), "Hello")
+	parent := s.createComment(admin, post, "First", nil)
+	token := s.login(jane)
+	s.call(http.StatusOK, "POST", "/post/comment", token, map[string]interface{}{"post_id": post.ID, "comment_content": "Nice post @admin"})
+	s.call(http.StatusOK, "POST", "/post/comment", token, map[string]interface{}{"post_id": post.ID, "parent_id": parent.ID, "comment_content": "Agreed"})
+	s.call(http.StatusNotFound, "POST", "/post/comment", token, map[string]interface{}{"post_id": 99, "comment_content": "Lost"})
+	s.call(http.StatusUnprocessableEntity, "POST", "/post/comment", token, map[string]interface{}{"post_id": post.ID})
+	s.call(http.StatusUnauthorized, "POST", "/post/comment", "", map[string]interface{}{"post_id": post.ID, "comment_content": "Anonymous"})
+}
+
+func TestListComments(t *testing.T) {
+	s := newServer(t)
+	admin := s.createAdmin("admin")
+	jane := s.createMember("jane")
+	post := s.createPost(admin, s.createCategory("Tech"), "Hello")
+	first := s.createComment(jane, post, "First", nil)
+	reply := s.createComment(admin, post, "Reply", &first)
+	s.createComment(jane, post, "Nested", &reply)
+	s.createComment(jane, post, "Second", nil)
+	s.call(http.StatusOK, "GET", fmt.Sprintf("/post/%v/comment", post.ID), "", nil)
+	s.call(http.StatusOK, "GET", fmt.Sprintf("/post/%v/comment?input_search=sec", post.ID), "", nil)
+	s.call(http.StatusOK, "GET", fmt.Sprintf("/post/%v/comment/tree", post.ID), "", nil)
+	s.call(http.StatusOK, "GET", fmt.Sprintf("/post/%v/comment/%v/thread", post.ID, first.ID), "", nil)
+	s.call(http.StatusNotFound, "GET", fmt.Sprintf("/post/%v/comment/99/thread", post.ID), "", nil)
+}
+
+func TestUpdateComment(t *testing.T) {
+	s := newServer(t)
+	admin := s.createAdmin("admin")
+	jane := s.createMember("jane")
+	john := s.createMember("john")
+	post := s.createPost(admin, s.createCategory("Tech"), "Hello")
+	comment := s.createComment(jane, post, "Frist", nil)
+	path := fmt.Sprintf("/post/%v/comment/%v", post.ID, comment.ID)
+	s.call(http.StatusOK, "PATCH", path, s.login(jane), map[string]string{"comment_content": "First"})
+	s.call(http.StatusForbidden, "PATCH", path, s.login(john), map[string]string{"comment_content": "Mine"})
+	s.call(http.StatusUnprocessableEntity, "PATCH", path, s.login(jane), map[string]string{})
+	s.call(http.StatusOK, "GET", path+"/history", s.login(admin), nil)
+	s.call(http.StatusForbidden, "GET", path+"/history", s.login(jane), nil)
+}
+
+func TestDeleteComment(t *testing.T) {
+	s := newServer(t)
+	admin := s.createAdmin("admin")
+	jane := s.createMember("jane")
+	john := s.createMember("john")
+	post := s.createPost(admin, s.createCategory("Tech"), "Hello")
+	comment := s.createComment(jane, post, "First", nil)
+	path := fmt.Sprintf("/post/%v/comment/%v", post.ID, comment.ID)
+	s.call(http.StatusForbidden, "DELETE", path, s.login(john), nil)
+	s.call(http.StatusOK, "DELETE", path, s.login(jane), nil)
+	s.call(http.StatusNotFound, "DELETE", path, s.login(jane), nil)
+}
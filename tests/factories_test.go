@@ -0,0 +1,106 @@
+package tests
+
+import (
+	"blogspot-project/models"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// PASSWORD is the password of every user made by the factories.
+const PASSWORD = "secret"
+
+func (s *server) createUser(username string, role uint) models.User {
+	s.t.Helper()
+	user := models.User{
+		Name:     username,
+		Username: username,
+		Email:    username + "@example.com",
+		Password: PASSWORD,
+		ImageUrl: "https://example.com/" + username + ".png",
+		Role:     role,
+	}
+	if _, err := user.CreateUser(s.db); err != nil {
+		s.t.Fatal(err)
+	}
+	return user
+}
+
+func (s *server) createAdmin(username string) models.User {
+	return s.createUser(username, models.ADMIN_USER_ROLE)
+}
+
+func (s *server) createMember(username string) models.User {
+	return s.createUser(username, models.NON_ADMIN_USER_ROLE)
+}
+
+// login returns a token for a user made by the factories.
+func (s *server) login(user models.User) string {
+	s.t.Helper()
+	res := s.do("POST", "/auth/login", "", map[string]string{"username": user.Username, "password": PASSWORD})
+	if res.Code != http.StatusOK {
+		s.t.Fatalf("login of %v failed: %s", user.Username, res.Body)
+	}
+	return res.JSON(s.t)["token"].(string)
+}
+
+func (s *server) createCategory(name string) models.Category {
+	s.t.Helper()
+	category := models.Category{Name: name}
+	if err := s.db.Create(&category).Error; err != nil {
+		s.t.Fatal(err)
+	}
+	return category
+}
+
+// createPost writes a published post, use the api to get mentions rendered.
+func (s *server) createPost(author models.User, category models.Category, title string) models.Post {
+	s.t.Helper()
+	now := time.Now()
+	content := fmt.Sprintf("Content of %v", title)
+	post := models.Post{
+		UserID:             author.ID,
+		ArticleTitle:       title,
+		ArticleDescription: "About " + title,
+		CategoryID:         category.ID,
+		ArticleContent:     content,
+		RenderedContent:    content,
+		Status:             models.POST_STATUS_PUBLISHED,
+		PublishedAt:        &now,
+	}
+	if err := s.db.Create(&post).Error; err != nil {
+		s.t.Fatal(err)
+	}
+	return post
+}
+
+// createComment writes an approved comment, a reply when parent is given.
+func (s *server) createComment(author models.User, post models.Post, content string, parent *models.Comment) models.Comment {
+	s.t.Helper()
+	comment := models.Comment{
+		UserID:           author.ID,
+		PostID:           post.ID,
+		CommentContent:   content,
+		RenderedContent:  content,
+		ModerationStatus: models.COMMENT_STATUS_APPROVED,
+	}
+	if parent != nil {
+		comment.ParentID = &parent.ID
+		comment.ThreadID = parent.ThreadID
+		comment.Depth = parent.Depth + 1
+	}
+	if err := s.db.Create(&comment).Error; err != nil {
+		s.t.Fatal(err)
+	}
+	if parent == nil {
+		comment.ThreadID = comment.ID
+		if err := s.db.Model(&comment).UpdateColumn("thread_id", comment.ID).Error; err != nil {
+			s.t.Fatal(err)
+		}
+		return comment
+	}
+	if err := s.db.Model(parent).UpdateColumn("reply_count", parent.ReplyCount+1).Error; err != nil {
+		s.t.Fatal(err)
+	}
+	return comment
+}
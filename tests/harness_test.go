@@ -0,0 +1,224 @@
+// Package tests runs the whole api from routes.SetupRouter against an
+// in-memory SQLite database and compares the responses with the golden files
+// in testdata. Run go test ./tests -update to rewrite them, and set
+// TEST_DATABASE_DRIVER (mysql or postgres) with TEST_DATABASE_DSN to run the
+// same tests on another database.
+package tests
+
+import (
+	"blogspot-project/config"
+	"blogspot-project/routes"
+	"blogspot-project/utils"
+	"blogspot-project/utils/storage"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// run with -update to write the responses of a test as its golden file
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	gin.DefaultWriter = io.Discard
+	os.Exit(m.Run())
+}
+
+// server is the full api from routes.SetupRouter on a fresh database. Every
+// request made with call is kept and compared with the golden file of the
+// test when it ends.
+type server struct {
+	t          *testing.T
+	db         *gorm.DB
+	handler    http.Handler
+	uploads    string
+	transcript bytes.Buffer
+}
+
+type response struct {
+	Code   int
+	Header http.Header
+	Body   []byte
+}
+
+// JSON decodes the body of a response into a map.
+func (r *response) JSON(t *testing.T) map[string]interface{} {
+	t.Helper()
+	data := map[string]interface{}{}
+	if err := json.Unmarshal(r.Body, &data); err != nil {
+		t.Fatalf("response is not json: %v\n%s", err, r.Body)
+	}
+	return data
+}
+
+// openDatabase opens the database of a test. It is an in-memory SQLite
+// database unless TEST_DATABASE_DRIVER and TEST_DATABASE_DSN point to a
+// MySQL or PostgreSQL database, which is emptied first. Tests never run in
+// parallel, so they can share it.
+func openDatabase(t *testing.T) *gorm.DB {
+	t.Helper()
+	driver := utils.GetEnv("TEST_DATABASE_DRIVER", "sqlite")
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if driver == "sqlite" && dsn == "" {
+		dsn = fmt.Sprintf("file:%v?mode=memory&cache=shared&_foreign_keys=on", strings.ReplaceAll(t.Name(), "/", "_"))
+	}
+	db, err := config.OpenDatabase(driver, dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.Logger = logger.Default.LogMode(logger.Silent)
+	tables, err := db.Migrator().GetTables()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, table := range tables {
+		if err := db.Migrator().DropTable(table); err != nil {
+			t.Fatal(err)
+		}
+	}
+	config.MigrateDatabase(db)
+	return db
+}
+
+func newServer(t *testing.T) *server {
+	t.Helper()
+	db := openDatabase(t)
+	uploads := t.TempDir()
+	s := &server{
+		t:       t,
+		db:      db,
+		handler: routes.SetupRouter(db, storage.NewLocalStorage(uploads, "http://localhost:8080/uploads")),
+		uploads: uploads,
+	}
+	t.Cleanup(func() {
+		if sqlDb, err := db.DB(); err == nil {
+			sqlDb.Close()
+		}
+	})
+	t.Cleanup(s.assertGolden)
+	return s
+}
+
+// do sends a request without recording it. A body that is not a string or
+// a reader is sent as json.
+func (s *server) do(method, path, token string, body interface{}, headers ...string) *response {
+	s.t.Helper()
+	var reader io.Reader
+	switch value := body.(type) {
+	case nil:
+	case string:
+		reader = strings.NewReader(value)
+	case io.Reader:
+		reader = value
+	default:
+		data, err := json.Marshal(value)
+		if err != nil {
+			s.t.Fatal(err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req := httptest.NewRequest(method, path, reader)
+	if reader != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	recorder := httptest.NewRecorder()
+	s.handler.ServeHTTP(recorder, req)
+	return &response{Code: recorder.Code, Header: recorder.Header(), Body: recorder.Body.Bytes()}
+}
+
+// call sends a request, fails the test when the status is not the expected
+// one, and records the response for the golden file.
+func (s *server) call(status int, method, path, token string, body interface{}, headers ...string) *response {
+	s.t.Helper()
+	res := s.do(method, path, token, body, headers...)
+	if res.Code != status {
+		s.t.Errorf("%v %v: expected status %v, got %v\n%s", method, path, status, res.Code, res.Body)
+	}
+	fmt.Fprintf(&s.transcript, "%v %v\n%v %v\n%v\n\n", method, path, res.Code, res.Header.Get("Content-Type"), normalize(res.Body))
+	return res
+}
+
+var volatile = []struct {
+	pattern     *regexp.Regexp
+	replacement string
+}{
+	{regexp.MustCompile(`\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:\d{2})`), "<time>"},
+	{regexp.MustCompile(`[A-Z][a-z]{2}, \d{2} [A-Z][a-z]{2} \d{4} \d{2}:\d{2}:\d{2} ([+-]\d{4}|[A-Z]+)`), "<time>"},
+	{regexp.MustCompile(`\d{4}-\d{2}-\d{2}`), "<date>"},
+	{regexp.MustCompile(`eyJ[\w-]+\.[\w-]+\.[\w-]+`), "<token>"},
+}
+
+// normalize indents json bodies and hides the values that change between
+// runs, such as times and tokens.
+func normalize(body []byte) string {
+	text := string(body)
+	var indented bytes.Buffer
+	if json.Indent(&indented, body, "", "  ") == nil {
+		text = indented.String()
+	}
+	for _, item := range volatile {
+		text = item.pattern.ReplaceAllString(text, item.replacement)
+	}
+	return text
+}
+
+func (s *server) assertGolden() {
+	if s.t.Failed() || s.transcript.Len() == 0 {
+		return
+	}
+	path := filepath.Join("testdata", strings.ReplaceAll(s.t.Name(), "/", "_")+".golden")
+	if *update {
+		if err := os.MkdirAll("testdata", 0755); err != nil {
+			s.t.Fatal(err)
+		}
+		if err := os.WriteFile(path, s.transcript.Bytes(), 0644); err != nil {
+			s.t.Fatal(err)
+		}
+		return
+	}
+	expected, err := os.ReadFile(path)
+	if err != nil {
+		s.t.Fatalf("missing golden file, run the tests with -update: %v", err)
+	}
+	if !bytes.Equal(expected, s.transcript.Bytes()) {
+		s.t.Errorf("responses differ from %v, run the tests with -update if the change is expected\n%v", path, diff(string(expected), s.transcript.String()))
+	}
+}
+
+// diff shows the first lines where two transcripts differ.
+func diff(expected, actual string) string {
+	expected_lines := strings.Split(expected, "\n")
+	actual_lines := strings.Split(actual, "\n")
+	for i := 0; i < len(expected_lines) || i < len(actual_lines); i++ {
+		want, got := "", ""
+		if i < len(expected_lines) {
+			want = expected_lines[i]
+		}
+		if i < len(actual_lines) {
+			got = actual_lines[i]
+		}
+		if want != got {
+			return fmt.Sprintf("line %v:\n- %v\n+ %v", i+1, want, got)
+		}
+	}
+	return ""
+}
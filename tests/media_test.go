@@ -0,0 +1,132 @@
+package tests
+
+import (
+	"blogspot-project/config"
+	"blogspot-project/models"
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// pngImage draws a small png of the given size and color.
+func pngImage(t *testing.T, width, height int, fill color.Color) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			img.Set(x, y, fill)
+		}
+	}
+	var data bytes.Buffer
+	if err := png.Encode(&data, img); err != nil {
+		t.Fatal(err)
+	}
+	return data.Bytes()
+}
+
+// upload posts a file to /media as a multipart form.
+func (s *server) upload(status int, token, file_name string, data []byte, purpose string) *response {
+	s.t.Helper()
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", file_name)
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	part.Write(data)
+	if purpose != "" {
+		writer.WriteField("purpose", purpose)
+	}
+	writer.Close()
+	return s.call(status, "POST", "/media/", token, &body, "Content-Type", writer.FormDataContentType())
+}
+
+func TestUploadMedia(t *testing.T) {
+	s := newServer(t)
+	jane := s.createMember("jane")
+	token := s.login(jane)
+	red := pngImage(t, 40, 20, color.RGBA{255, 0, 0, 255})
+	created := s.upload(http.StatusOK, token, "red.png", red, "")
+	url := created.JSON(t)["data"].(map[string]interface{})["url"].(string)
+	if res := s.do("GET", strings.TrimPrefix(url, "http://localhost:8080"), "", nil); res.Code != http.StatusOK || !bytes.Equal(res.Body, red) {
+		t.Errorf("uploaded file is not served at %v", url)
+	}
+	// the same file is only stored once
+	s.upload(http.StatusOK, token, "again.png", red, "")
+	s.upload(http.StatusOK, token, "avatar.png", pngImage(t, 64, 64, color.RGBA{0, 0, 255, 255}), "avatar")
+	s.upload(http.StatusUnsupportedMediaType, token, "notes.txt", []byte("just some text"), "")
+	s.upload(http.StatusUnauthorized, "", "red.png", red, "")
+}
+
+func TestListAndDeleteMedia(t *testing.T) {
+	s := newServer(t)
+	admin := s.createAdmin("admin")
+	jane := s.createMember("jane")
+	john := s.createMember("john")
+	token := s.login(jane)
+	created := s.upload(http.StatusOK, token, "red.png", pngImage(t, 40, 20, color.RGBA{255, 0, 0, 255}), "")
+	id := created.JSON(t)["data"].(map[string]interface{})["ID"]
+	s.call(http.StatusOK, "GET", "/media/", token, nil)
+	s.call(http.StatusOK, "GET", "/media/", s.login(john), nil)
+
+	// a featured image must belong to the author
+	category := s.createCategory("Tech")
+	post := map[string]interface{}{
+		"article_title":       "Hello",
+		"article_description": "With an image",
+		"category_id":         category.ID,
+		"article_content":     "Look",
+		"featured_image_id":   id,
+	}
+	s.call(http.StatusUnprocessableEntity, "POST", "/post/", s.login(admin), post)
+
+	s.call(http.StatusNotFound, "DELETE", fmt.Sprintf("/media/%v", id), s.login(john), nil)
+	s.call(http.StatusOK, "DELETE", fmt.Sprintf("/media/%v", id), token, nil)
+	s.call(http.StatusOK, "GET", "/media/", token, nil)
+}
+
+func TestMigrateDuplicateMedia(t *testing.T) {
+	db := openDatabase(t)
+	if err := db.Migrator().DropIndex(&models.Media{}, "idx_media_user_checksum"); err != nil {
+		t.Fatal(err)
+	}
+	jane := models.User{Name: "Jane", Username: "jane", Email: "jane@example.com", Password: "secret"}
+	if err := db.Create(&jane).Error; err != nil {
+		t.Fatal(err)
+	}
+	// the same file uploaded twice, with the avatar pointing to the copy
+	uploads := []models.Media{}
+	for i := 0; i < 2; i++ {
+		item := models.Media{UserID: jane.ID, FileName: "red.png", MimeType: "image/png", Checksum: "abc", StorageKey: "1/abc.png", Url: "/uploads/1/abc.png"}
+		if err := db.Create(&item).Error; err != nil {
+			t.Fatal(err)
+		}
+		if err := db.Create(&models.MediaVariant{MediaID: item.ID, Kind: models.MEDIA_VARIANT_AVATAR, StorageKey: "1/abc-avatar.webp"}).Error; err != nil {
+			t.Fatal(err)
+		}
+		uploads = append(uploads, item)
+	}
+	if err := db.Model(&jane).Update("avatar_media_id", uploads[1].ID).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	config.MigrateDatabase(db)
+	var ids []uint
+	db.Model(&models.Media{}).Pluck("id", &ids)
+	var variants int64
+	db.Model(&models.MediaVariant{}).Count(&variants)
+	db.Take(&jane, jane.ID)
+	if !reflect.DeepEqual(ids, []uint{uploads[0].ID}) || variants != 1 || jane.AvatarMediaID == nil || *jane.AvatarMediaID != uploads[0].ID {
+		t.Errorf("expected only the first upload kept and used, got media %v, %v variants and avatar %v", ids, variants, jane.AvatarMediaID)
+	}
+	if !db.Migrator().HasIndex(&models.Media{}, "idx_media_user_checksum") {
+		t.Errorf("expected the unique index to be created")
+	}
+}
@@ -0,0 +1,177 @@
+package tests
+
+import (
+	"blogspot-project/models"
+	"blogspot-project/utils/spam"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestNotifications(t *testing.T) {
+	s := newServer(t)
+	admin := s.createAdmin("admin")
+	jane := s.createMember("jane")
+	post := s.createPost(admin, s.createCategory("Tech"), "Hello")
+	token := s.login(admin)
+	s.call(http.StatusOK, "PATCH", "/moderation/policy", token, map[string]interface{}{"hold_first_comment": false})
+	s.call(http.StatusOK, "POST", "/author/admin/follow", s.login(jane), nil)
+	s.call(http.StatusOK, "POST", "/post/comment", s.login(jane), map[string]interface{}{"post_id": post.ID, "comment_content": "Nice post @admin"})
+	s.call(http.StatusOK, "GET", "/notifications", token, nil)
+	s.call(http.StatusOK, "GET", "/notifications/unread-count", token, nil)
+	s.call(http.StatusOK, "POST", "/notifications/1/read", token, nil)
+	s.call(http.StatusNotFound, "POST", "/notifications/99/read", token, nil)
+	s.call(http.StatusOK, "GET", "/notifications?unread=true", token, nil)
+	s.call(http.StatusOK, "POST", "/notifications/read-all", token, nil)
+	s.call(http.StatusOK, "GET", "/notifications/unread-count", token, nil)
+	s.call(http.StatusUnauthorized, "GET", "/notifications", "", nil)
+}
+
+func TestNotificationPreferences(t *testing.T) {
+	s := newServer(t)
+	admin := s.createAdmin("admin")
+	jane := s.createMember("jane")
+	post := s.createPost(admin, s.createCategory("Tech"), "Hello")
+	token := s.login(admin)
+	s.call(http.StatusOK, "PATCH", "/moderation/policy", token, map[string]interface{}{"hold_first_comment": false})
+	s.call(http.StatusOK, "GET", "/notifications/preferences", token, nil)
+	s.call(http.StatusOK, "PATCH", "/notifications/preferences", token, map[string]bool{"comments": false, "email_digest": true})
+	// comments are no longer notified
+	s.call(http.StatusOK, "POST", "/post/comment", s.login(jane), map[string]interface{}{"post_id": post.ID, "comment_content": "Nice post"})
+	s.call(http.StatusOK, "GET", "/notifications/unread-count", token, nil)
+}
+
+func TestModerationPolicy(t *testing.T) {
+	s := newServer(t)
+	admin := s.createAdmin("admin")
+	jane := s.createMember("jane")
+	s.call(http.StatusOK, "GET", "/moderation/policy", s.login(admin), nil)
+	s.call(http.StatusOK, "PATCH", "/moderation/policy", s.login(admin), map[string]interface{}{"hold_first_comment": true, "spam_threshold": 0.8})
+	s.call(http.StatusUnprocessableEntity, "PATCH", "/moderation/policy", s.login(admin), map[string]interface{}{"spam_threshold": 2})
+	s.call(http.StatusForbidden, "GET", "/moderation/policy", s.login(jane), nil)
+}
+
+func TestModerationQueue(t *testing.T) {
+	s := newServer(t)
+	admin := s.createAdmin("admin")
+	moderator := s.createUser("moderator", models.MODERATOR_USER_ROLE)
+	jane := s.createMember("jane")
+	john := s.createMember("john")
+	post := s.createPost(admin, s.createCategory("Tech"), "Hello")
+	token := s.login(moderator)
+	s.call(http.StatusOK, "PATCH", "/moderation/policy", s.login(admin), map[string]interface{}{"hold_first_comment": true})
+	s.call(http.StatusOK, "POST", "/post/comment", s.login(jane), map[string]interface{}{"post_id": post.ID, "comment_content": "Hello from jane"})
+	s.call(http.StatusOK, "POST", "/post/comment", s.login(john), map[string]interface{}{"post_id": post.ID, "comment_content": "Hello from john"})
+	s.call(http.StatusOK, "GET", fmt.Sprintf("/post/%v/comment", post.ID), "", nil)
+	s.call(http.StatusOK, "GET", "/moderation/comments", token, nil)
+	s.call(http.StatusOK, "POST", "/moderation/comments", token, map[string]interface{}{"comment_ids": []uint{1}, "action": "approve"})
+	s.call(http.StatusOK, "POST", "/moderation/comments", token, map[string]interface{}{"comment_ids": []uint{2}, "action": "spam"})
+	s.call(http.StatusUnprocessableEntity, "POST", "/moderation/comments", token, map[string]interface{}{"comment_ids": []uint{2}, "action": "ignore"})
+	s.call(http.StatusOK, "GET", fmt.Sprintf("/moderation/comments?status=%v", models.COMMENT_STATUS_SPAM), token, nil)
+	s.call(http.StatusOK, "GET", fmt.Sprintf("/post/%v/comment", post.ID), "", nil)
+	s.call(http.StatusForbidden, "GET", "/moderation/comments", s.login(jane), nil)
+}
+
+func TestReports(t *testing.T) {
+	s := newServer(t)
+	admin := s.createAdmin("admin")
+	jane := s.createMember("jane")
+	john := s.createMember("john")
+	post := s.createPost(admin, s.createCategory("Tech"), "Hello")
+	comment := s.createComment(john, post, "Buy now", nil)
+	token := s.login(jane)
+	s.call(http.StatusOK, "POST", "/report/", token, map[string]interface{}{"target_type": "comment", "target_id": comment.ID, "reason": "spam", "message": "Advert"})
+	s.call(http.StatusConflict, "POST", "/report/", token, map[string]interface{}{"target_type": "comment", "target_id": comment.ID, "reason": "spam"})
+	s.call(http.StatusOK, "POST", "/report/", token, map[string]interface{}{"target_type": "post", "target_id": post.ID, "reason": "misinformation"})
+	s.call(http.StatusUnprocessableEntity, "POST", "/report/", token, map[string]interface{}{"target_type": "user", "target_id": 1, "reason": "spam"})
+	s.call(http.StatusUnprocessableEntity, "POST", "/report/", token, map[string]interface{}{"target_type": "post", "target_id": post.ID, "reason": "boring"})
+	s.call(http.StatusNotFound, "POST", "/report/", token, map[string]interface{}{"target_type": "post", "target_id": 99, "reason": "spam"})
+
+	moderator := s.login(admin)
+	s.call(http.StatusOK, "GET", "/moderation/reports", moderator, nil)
+	s.call(http.StatusOK, "GET", "/moderation/reports?target_type=comment", moderator, nil)
+	s.call(http.StatusOK, "POST", "/moderation/reports/1/resolve", moderator, map[string]interface{}{"action": "suspend", "suspend_days": 3})
+	s.call(http.StatusOK, "POST", "/moderation/reports/2/resolve", moderator, map[string]interface{}{"action": "dismiss"})
+	s.call(http.StatusUnprocessableEntity, "POST", "/moderation/reports/2/resolve", moderator, map[string]interface{}{"action": "ignore"})
+	s.call(http.StatusNotFound, "POST", "/moderation/reports/99/resolve", moderator, map[string]interface{}{"action": "dismiss"})
+	s.call(http.StatusOK, "GET", fmt.Sprintf("/moderation/users/%v/reports", john.ID), moderator, nil)
+	s.call(http.StatusForbidden, "GET", "/moderation/reports", token, nil)
+
+	// suspended users cannot comment
+	s.call(http.StatusForbidden, "POST", "/post/comment", s.login(john), map[string]interface{}{"post_id": post.ID, "comment_content": "Again"})
+}
+
+func TestReportDeletePost(t *testing.T) {
+	s := newServer(t)
+	admin := s.createAdmin("admin")
+	jane := s.createMember("jane")
+	post := s.createPost(admin, s.createCategory("Tech"), "Hello")
+	token := s.login(jane)
+	s.call(http.StatusOK, "POST", fmt.Sprintf("/post/%v/bookmark", post.ID), token, nil)
+	s.call(http.StatusOK, "POST", "/report/", token, map[string]interface{}{"target_type": "post", "target_id": post.ID, "reason": "spam"})
+	s.call(http.StatusOK, "POST", "/moderation/reports/1/resolve", s.login(admin), map[string]interface{}{"action": "delete"})
+
+	// the post leaves the bookmarks like when its author deletes it
+	s.call(http.StatusOK, "GET", "/bookmarks", token, nil)
+	s.call(http.StatusOK, "GET", "/notifications", token, nil)
+}
+
+func TestSpamClassifier(t *testing.T) {
+	db := openDatabase(t)
+	classifier := spam.NewClassifier(db)
+	spammy := "Buy cheap pills now at http://pills.example, best price"
+	hammy := "Great post about generics in Go, thanks for the examples"
+	learn := func(count int) {
+		for i := 0; i < count; i++ {
+			if err := classifier.Learn(fmt.Sprintf("%v offer %v", spammy, i), true); err != nil {
+				t.Fatal(err)
+			}
+			if err := classifier.Learn(fmt.Sprintf("%v part %v", hammy, i), false); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	learn(spam.MIN_TRAINING_DOCS - 1)
+	if _, trained, err := classifier.Score(spammy); err != nil || trained {
+		t.Fatalf("expected no trusted score before %v examples of each kind, got %v (%v)", spam.MIN_TRAINING_DOCS, trained, err)
+	}
+	learn(1)
+	tests := []struct {
+		text string
+		spam bool
+	}{
+		{"cheap pills, buy at http://pills.example", true},
+		{"Visit www.pills.example for the best price", true},
+		{"Thanks, the examples about generics helped", false},
+		{"Great post", false},
+	}
+	for _, test := range tests {
+		score, trained, err := classifier.Score(test.text)
+		if err != nil || !trained {
+			t.Fatalf("expected a trusted score, got %v (%v)", trained, err)
+		}
+		if (score >= 0.9) != test.spam || (score <= 0.1) == test.spam {
+			t.Errorf("expected %q to be spam %v, got score %v", test.text, test.spam, score)
+		}
+	}
+
+	// unlearning a spam example takes the classifier under the minimum again
+	if err := classifier.Unlearn(spammy+" offer 0", true); err != nil {
+		t.Fatal(err)
+	}
+	if _, trained, err := classifier.Score(spammy); err != nil || trained {
+		t.Errorf("expected the score untrusted after unlearning, got %v (%v)", trained, err)
+	}
+	for i := 0; i < spam.MIN_TRAINING_DOCS; i++ {
+		if i > 0 {
+			classifier.Unlearn(fmt.Sprintf("%v offer %v", spammy, i), true)
+		}
+		classifier.Unlearn(fmt.Sprintf("%v part %v", hammy, i), false)
+	}
+	var tokens int64
+	db.Model(&models.SpamToken{}).Count(&tokens)
+	if tokens != 0 {
+		t.Errorf("expected no tokens left once everything is unlearned, got %v", tokens)
+	}
+}
@@ -0,0 +1,85 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestCreatePost(t *testing.T) {
+	s := newServer(t)
+	admin := s.createAdmin("admin")
+	jane := s.createMember("jane")
+	category := s.createCategory("Tech")
+	body := map[string]interface{}{
+		"article_title":       "Hello",
+		"article_description": "First post",
+		"category_id":         category.ID,
+		"article_content":     "Hello @jane, welcome",
+	}
+	s.call(http.StatusOK, "POST", "/post/", s.login(admin), body)
+	s.call(http.StatusForbidden, "POST", "/post/", s.login(jane), body)
+	s.call(http.StatusUnauthorized, "POST", "/post/", "", body)
+	s.call(http.StatusUnprocessableEntity, "POST", "/post/", s.login(admin), map[string]interface{}{"article_title": "Hello"})
+	body["category_id"] = 99
+	s.call(http.StatusNotFound, "POST", "/post/", s.login(admin), body)
+	body["category_id"], body["status"] = category.ID, 7
+	s.call(http.StatusUnprocessableEntity, "POST", "/post/", s.login(admin), body)
+}
+
+func TestListPosts(t *testing.T) {
+	s := newServer(t)
+	admin := s.createAdmin("admin")
+	category := s.createCategory("Tech")
+	s.createPost(admin, category, "Go tips")
+	s.createPost(admin, category, "Rust tips")
+	s.call(http.StatusOK, "POST", "/post/", s.login(admin), map[string]interface{}{
+		"article_title":       "Draft",
+		"article_description": "Not yet",
+		"category_id":         category.ID,
+		"article_content":     "Later",
+		"status":              1,
+	})
+	s.call(http.StatusOK, "GET", "/post/", "", nil)
+	s.call(http.StatusOK, "GET", "/post/", s.login(admin), nil)
+	s.call(http.StatusOK, "GET", "/post/?input_search=go", "", nil)
+	s.call(http.StatusOK, "GET", "/post/?page_size=1&current_page=2", "", nil)
+}
+
+func TestDetailPost(t *testing.T) {
+	s := newServer(t)
+	admin := s.createAdmin("admin")
+	category := s.createCategory("Tech")
+	post := s.createPost(admin, category, "Hello")
+	s.call(http.StatusOK, "GET", fmt.Sprintf("/post/%v", post.ID), "", nil)
+	s.call(http.StatusNotFound, "GET", "/post/99", "", nil)
+}
+
+func TestUpdatePost(t *testing.T) {
+	s := newServer(t)
+	admin := s.createAdmin("admin")
+	other := s.createAdmin("other")
+	category := s.createCategory("Tech")
+	post := s.createPost(admin, category, "Hello")
+	path := fmt.Sprintf("/post/%v", post.ID)
+	s.call(http.StatusOK, "PATCH", path, s.login(admin), map[string]interface{}{"article_title": "Hello again", "category_id": category.ID})
+	s.call(http.StatusNotFound, "PATCH", path, s.login(admin), map[string]interface{}{"article_title": "No category"})
+	// only the author can update a post
+	s.call(http.StatusNotFound, "PATCH", path, s.login(other), map[string]interface{}{"article_title": "Mine now", "category_id": category.ID})
+	s.call(http.StatusForbidden, "PATCH", path, s.login(s.createMember("jane")), map[string]interface{}{"article_title": "Mine now", "category_id": category.ID})
+	s.call(http.StatusOK, "GET", path, "", nil)
+}
+
+func TestDeletePost(t *testing.T) {
+	s := newServer(t)
+	admin := s.createAdmin("admin")
+	other := s.createAdmin("other")
+	category := s.createCategory("Tech")
+	post := s.createPost(admin, category, "Hello")
+	path := fmt.Sprintf("/post/%v", post.ID)
+	s.call(http.StatusForbidden, "DELETE", path, s.login(s.createMember("jane")), nil)
+	// any admin can delete a post
+	s.call(http.StatusOK, "DELETE", path, s.login(other), nil)
+	s.call(http.StatusNotFound, "DELETE", path, s.login(admin), nil)
+	s.call(http.StatusNotFound, "GET", path, "", nil)
+}
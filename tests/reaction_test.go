@@ -0,0 +1,178 @@
+package tests
+
+import (
+	"blogspot-project/models"
+	"blogspot-project/repositories"
+	"blogspot-project/utils/counters"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+func TestReactionTypes(t *testing.T) {
+	s := newServer(t)
+	s.call(http.StatusOK, "GET", "/reactions", "", nil)
+}
+
+func TestPostReactions(t *testing.T) {
+	s := newServer(t)
+	admin := s.createAdmin("admin")
+	jane := s.createMember("jane")
+	post := s.createPost(admin, s.createCategory("Tech"), "Hello")
+	path := fmt.Sprintf("/post/%v/reaction", post.ID)
+	s.call(http.StatusOK, "PUT", path, s.login(jane), map[string]string{"type": "love"})
+	s.call(http.StatusOK, "PUT", path, s.login(admin), map[string]string{"type": "like"})
+	s.call(http.StatusUnprocessableEntity, "PUT", path, s.login(jane), map[string]string{"type": "angry"})
+	s.call(http.StatusNotFound, "PUT", "/post/99/reaction", s.login(jane), map[string]string{"type": "like"})
+	s.call(http.StatusOK, "GET", fmt.Sprintf("/post/%v/reactions", post.ID), "", nil)
+	s.call(http.StatusOK, "GET", fmt.Sprintf("/post/%v/reactions?type=love", post.ID), "", nil)
+	s.call(http.StatusOK, "DELETE", path, s.login(jane), nil)
+	s.call(http.StatusOK, "GET", fmt.Sprintf("/post/%v", post.ID), "", nil)
+}
+
+func TestCommentReactions(t *testing.T) {
+	s := newServer(t)
+	admin := s.createAdmin("admin")
+	jane := s.createMember("jane")
+	comment := s.createComment(admin, s.createPost(admin, s.createCategory("Tech"), "Hello"), "First", nil)
+	path := fmt.Sprintf("/post/comment/%v/reaction", comment.ID)
+	s.call(http.StatusOK, "PUT", path, s.login(jane), map[string]string{"type": "insightful"})
+	s.call(http.StatusNotFound, "PUT", "/post/comment/99/reaction", s.login(jane), map[string]string{"type": "like"})
+	s.call(http.StatusOK, "GET", fmt.Sprintf("/post/comment/%v/reactions", comment.ID), "", nil)
+	s.call(http.StatusOK, "DELETE", path, s.login(jane), nil)
+	s.call(http.StatusOK, "GET", fmt.Sprintf("/post/comment/%v/reactions", comment.ID), "", nil)
+}
+
+func TestLikePost(t *testing.T) {
+	s := newServer(t)
+	admin := s.createAdmin("admin")
+	jane := s.createMember("jane")
+	john := s.createMember("john")
+	post := s.createPost(admin, s.createCategory("Tech"), "Hello")
+	s.call(http.StatusOK, "POST", fmt.Sprintf("/post/%v/like/1", post.ID), s.login(jane), nil)
+	s.call(http.StatusOK, "POST", fmt.Sprintf("/post/%v/like/0", post.ID), s.login(john), nil)
+	s.call(http.StatusUnprocessableEntity, "POST", fmt.Sprintf("/post/%v/like/5", post.ID), s.login(john), nil)
+	s.call(http.StatusOK, "GET", fmt.Sprintf("/post/%v/user-likes/", post.ID), s.login(jane), nil)
+	s.call(http.StatusOK, "GET", fmt.Sprintf("/post/%v/user-dislikes/", post.ID), s.login(jane), nil)
+}
+
+func TestLikeComment(t *testing.T) {
+	s := newServer(t)
+	admin := s.createAdmin("admin")
+	jane := s.createMember("jane")
+	john := s.createMember("john")
+	comment := s.createComment(admin, s.createPost(admin, s.createCategory("Tech"), "Hello"), "First", nil)
+	s.call(http.StatusOK, "POST", fmt.Sprintf("/post/comment/%v/like/1", comment.ID), s.login(jane), nil)
+	s.call(http.StatusOK, "POST", fmt.Sprintf("/post/comment/%v/like/0", comment.ID), s.login(john), nil)
+	s.call(http.StatusOK, "GET", fmt.Sprintf("/post/comment/%v/user-likes/", comment.ID), s.login(jane), nil)
+	s.call(http.StatusOK, "GET", fmt.Sprintf("/post/comment/%v/user-dislikes/", comment.ID), s.login(jane), nil)
+}
+
+// reactors are only listed on posts and comments the user can see
+func TestReactionUsersOfHiddenTargets(t *testing.T) {
+	s := newServer(t)
+	admin := s.createAdmin("admin")
+	jane := s.createMember("jane")
+	category := s.createCategory("Tech")
+	draft := s.createPost(admin, category, "Draft")
+	hidden := s.createPost(admin, category, "Hidden")
+	pending := s.createComment(jane, s.createPost(admin, category, "Open"), "Held", nil)
+	for _, path := range []string{
+		fmt.Sprintf("/post/%v/like/1", draft.ID),
+		fmt.Sprintf("/post/%v/like/1", hidden.ID),
+		fmt.Sprintf("/post/comment/%v/like/1", pending.ID),
+	} {
+		s.do("POST", path, s.login(admin), nil)
+	}
+	s.db.Model(&draft).Update("status", models.POST_STATUS_DRAFT)
+	s.db.Model(&hidden).Update("is_hidden", true)
+	s.db.Model(&pending).Update("moderation_status", models.COMMENT_STATUS_PENDING)
+
+	john := s.login(s.createMember("john"))
+	s.call(http.StatusNotFound, "GET", fmt.Sprintf("/post/%v/user-likes/", draft.ID), john, nil)
+	s.call(http.StatusNotFound, "GET", fmt.Sprintf("/post/%v/user-dislikes/", draft.ID), john, nil)
+	s.call(http.StatusNotFound, "GET", fmt.Sprintf("/post/%v/user-likes/", hidden.ID), john, nil)
+	s.call(http.StatusNotFound, "GET", fmt.Sprintf("/post/comment/%v/user-likes/", pending.ID), john, nil)
+	s.call(http.StatusNotFound, "GET", "/post/99/user-likes/", john, nil)
+	// the author still sees who reacted to their draft
+	s.call(http.StatusOK, "GET", fmt.Sprintf("/post/%v/user-likes/", draft.ID), s.login(admin), nil)
+}
+
+func TestReconcileCounters(t *testing.T) {
+	s := newServer(t)
+	admin := s.createAdmin("admin")
+	post := s.createPost(admin, s.createCategory("Tech"), "Hello")
+	if s.db.Dialector.Name() == "sqlite" {
+		// in-memory sqlite fails concurrent writers instead of making them
+		// wait, the other databases run the reactions truly concurrently
+		if sqlDb, err := s.db.DB(); err == nil {
+			sqlDb.SetMaxOpenConns(1)
+		}
+	}
+	users := []models.User{}
+	for i := 0; i < 20; i++ {
+		users = append(users, s.createMember(fmt.Sprintf("member%v", i)))
+	}
+
+	likes := repositories.NewLikeRepository(s.db)
+	target := repositories.ReactionTarget{TargetType: models.TARGET_POST, TargetID: post.ID, PostID: post.ID, OwnerID: admin.ID}
+	errs := make(chan error, len(users)+1)
+	var wait sync.WaitGroup
+	done := make(chan bool)
+	go func() {
+		// reconciling while reactions are made must not lose any of them
+		for {
+			select {
+			case <-done:
+				errs <- nil
+				return
+			default:
+				if _, err := counters.Reconcile(s.db); err != nil {
+					errs <- err
+					return
+				}
+			}
+		}
+	}()
+	for _, user := range users {
+		wait.Add(1)
+		go func(user models.User) {
+			defer wait.Done()
+			_, err := likes.SetReaction(user.ID, target, models.REACTION_LIKE)
+			errs <- err
+		}(user)
+	}
+	wait.Wait()
+	close(done)
+	for i := 0; i < len(users)+1; i++ {
+		if err := <-errs; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	counts := func() (int64, uint) {
+		var total int64
+		s.db.Model(&models.ReactionCount{}).Where("target_type = ? AND target_id = ? AND type = ?", models.TARGET_POST, post.ID, models.REACTION_LIKE).
+			Select("total").Scan(&total)
+		var saved models.Post
+		s.db.Take(&saved, post.ID)
+		return total, saved.PostLikeCount
+	}
+	if total, like_count := counts(); total != 20 || like_count != 20 {
+		t.Fatalf("expected 20 likes counted, got %v and a like count of %v", total, like_count)
+	}
+	if fixed, err := counters.Reconcile(s.db); err != nil || fixed != 0 {
+		t.Errorf("expected nothing to repair, got %v (%v)", fixed, err)
+	}
+
+	// a lost count row and a drifted counter are both repaired
+	s.db.Where("target_type = ? AND target_id = ?", models.TARGET_POST, post.ID).Delete(&models.ReactionCount{})
+	s.db.Model(&models.Post{}).Where("id = ?", post.ID).UpdateColumn("post_like_count", 3)
+	if fixed, err := counters.Reconcile(s.db); err != nil || fixed != 2 {
+		t.Errorf("expected 2 repaired rows, got %v (%v)", fixed, err)
+	}
+	if total, like_count := counts(); total != 20 || like_count != 20 {
+		t.Errorf("expected 20 likes after reconciling, got %v and a like count of %v", total, like_count)
+	}
+}
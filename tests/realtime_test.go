@@ -0,0 +1,124 @@
+package tests
+
+import (
+	"blogspot-project/utils/realtime"
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// streams are long lived, so they are checked by content instead of golden
+// files
+func TestRealtimeEvents(t *testing.T) {
+	s := newServer(t)
+	admin := s.createAdmin("admin")
+	jane := s.createMember("jane")
+	post := s.createPost(admin, s.createCategory("Tech"), "Hello")
+	token := s.login(jane)
+	s.do("PATCH", "/moderation/policy", s.login(admin), map[string]interface{}{"hold_first_comment": false})
+	live := httptest.NewServer(s.handler)
+	defer live.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%v/realtime/events?token=%v&posts=%v", live.URL, token, post.ID), nil)
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK || res.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("expected an event stream, got %v %v", res.StatusCode, res.Header.Get("Content-Type"))
+	}
+	reader := bufio.NewReader(res.Body)
+	if line, _ := reader.ReadString('\n'); !strings.HasPrefix(line, "retry:") {
+		t.Fatalf("expected the retry line first, got %q", line)
+	}
+
+	if res := s.do("POST", "/post/comment", token, map[string]interface{}{"post_id": post.ID, "comment_content": "Live"}); res.Code != http.StatusOK {
+		t.Fatalf("comment failed: %s", res.Body)
+	}
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("stream ended before the comment event: %v", err)
+		}
+		if line == "event: "+realtime.EVENT_COMMENT+"\n" {
+			data, _ := reader.ReadString('\n')
+			if !strings.Contains(data, `"comment_content":"Live"`) {
+				t.Errorf("unexpected comment event %q", data)
+			}
+			return
+		}
+	}
+}
+
+func TestRealtimeEventsRequireToken(t *testing.T) {
+	s := newServer(t)
+	s.call(http.StatusUnauthorized, "GET", "/realtime/events", "", nil)
+	s.call(http.StatusUnauthorized, "GET", "/realtime/ws", "", nil)
+}
+
+func TestRealtimeSocket(t *testing.T) {
+	s := newServer(t)
+	admin := s.createAdmin("admin")
+	jane := s.createMember("jane")
+	post := s.createPost(admin, s.createCategory("Tech"), "Hello")
+	live := httptest.NewServer(s.handler)
+	defer live.Close()
+
+	url := fmt.Sprintf("ws%v/realtime/ws?token=%v", strings.TrimPrefix(live.URL, "http"), s.login(admin))
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	// admin is told about the reaction on their post
+	if res := s.do("PUT", fmt.Sprintf("/post/%v/reaction", post.ID), s.login(jane), map[string]string{"type": "like"}); res.Code != http.StatusOK {
+		t.Fatalf("reaction failed: %s", res.Body)
+	}
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var event realtime.Event
+	if err := conn.ReadJSON(&event); err != nil {
+		t.Fatal(err)
+	}
+	if event.Type != realtime.EVENT_NOTIFICATION || event.Topic != realtime.UserTopic(admin.ID) {
+		t.Errorf("unexpected event %+v", event)
+	}
+}
+
+func TestRealtimeSocketOrigin(t *testing.T) {
+	s := newServer(t)
+	jane := s.createMember("jane")
+	live := httptest.NewServer(s.handler)
+	defer live.Close()
+	t.Setenv("ALLOWED_ORIGINS", "https://app.example.com, https://admin.example.com/")
+
+	url := fmt.Sprintf("ws%v/realtime/ws?token=%v", strings.TrimPrefix(live.URL, "http"), s.login(jane))
+	for origin, allowed := range map[string]bool{
+		"http://localhost:8080":     true,
+		"https://admin.example.com": true,
+		live.URL:                    true,
+		"https://evil.example.com":  false,
+		"null":                      false,
+	} {
+		conn, res, err := websocket.DefaultDialer.Dial(url, http.Header{"Origin": {origin}})
+		if allowed && err != nil {
+			t.Errorf("%v: expected the connection to be accepted, got %v", origin, err)
+		}
+		if !allowed && (err == nil || res == nil || res.StatusCode != http.StatusForbidden) {
+			t.Errorf("%v: expected the connection to be refused", origin)
+		}
+		if conn != nil {
+			conn.Close()
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package tests
+
+import (
+	"blogspot-project/models"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestBookmarks(t *testing.T) {
+	s := newServer(t)
+	admin := s.createAdmin("admin")
+	jane := s.createMember("jane")
+	category := s.createCategory("Tech")
+	first := s.createPost(admin, category, "First")
+	s.createPost(admin, category, "Second")
+	token := s.login(jane)
+	s.call(http.StatusOK, "POST", fmt.Sprintf("/post/%v/bookmark", first.ID), token, nil)
+	s.call(http.StatusNotFound, "POST", "/post/99/bookmark", token, nil)
+	s.call(http.StatusOK, "GET", "/bookmarks", token, nil)
+	s.call(http.StatusOK, "DELETE", fmt.Sprintf("/post/%v/bookmark", first.ID), token, nil)
+	s.call(http.StatusOK, "GET", "/bookmarks", token, nil)
+	s.call(http.StatusUnauthorized, "GET", "/bookmarks", "", nil)
+}
+
+func TestReadingLists(t *testing.T) {
+	s := newServer(t)
+	admin := s.createAdmin("admin")
+	jane := s.createMember("jane")
+	john := s.createMember("john")
+	category := s.createCategory("Tech")
+	first := s.createPost(admin, category, "First")
+	second := s.createPost(admin, category, "Second")
+	token := s.login(jane)
+
+	created := s.call(http.StatusOK, "POST", "/reading-lists", token, map[string]interface{}{"name": "Weekend", "description": "To read", "is_public": false})
+	id := created.JSON(t)["data"].(map[string]interface{})["id"]
+	path := fmt.Sprintf("/reading-lists/%v", id)
+	s.call(http.StatusUnprocessableEntity, "POST", "/reading-lists", token, map[string]interface{}{"description": "No name"})
+	s.call(http.StatusOK, "POST", path+"/posts", token, map[string]interface{}{"post_id": first.ID, "note": "Start here"})
+	s.call(http.StatusOK, "POST", path+"/posts", token, map[string]interface{}{"post_id": second.ID})
+	s.call(http.StatusNotFound, "POST", path+"/posts", token, map[string]interface{}{"post_id": 99})
+	s.call(http.StatusOK, "PATCH", fmt.Sprintf("%v/posts/%v", path, second.ID), token, map[string]interface{}{"note": "Then this"})
+	s.call(http.StatusOK, "PUT", path+"/order", token, map[string]interface{}{"post_ids": []uint{second.ID, first.ID}})
+	s.call(http.StatusOK, "GET", path, token, nil)
+	s.call(http.StatusOK, "GET", "/reading-lists", token, nil)
+
+	// private lists are hidden from everyone else
+	s.call(http.StatusNotFound, "GET", path, s.login(john), nil)
+	s.call(http.StatusOK, "GET", "/author/jane/reading-lists", "", nil)
+	s.call(http.StatusOK, "PATCH", path, token, map[string]interface{}{"is_public": true})
+	s.call(http.StatusOK, "GET", path, "", nil)
+	s.call(http.StatusOK, "GET", "/author/jane/reading-lists", "", nil)
+	s.call(http.StatusNotFound, "PATCH", path, s.login(john), map[string]interface{}{"name": "Mine"})
+
+	s.call(http.StatusOK, "DELETE", fmt.Sprintf("%v/posts/%v", path, first.ID), token, nil)
+	s.call(http.StatusOK, "DELETE", path, token, nil)
+	s.call(http.StatusNotFound, "GET", path, token, nil)
+}
+
+func TestReadingListPositions(t *testing.T) {
+	s := newServer(t)
+	admin := s.createAdmin("admin")
+	jane := s.createMember("jane")
+	category := s.createCategory("Tech")
+	a := s.createPost(admin, category, "A")
+	b := s.createPost(admin, category, "B")
+	c := s.createPost(admin, category, "C")
+	d := s.createPost(admin, category, "D")
+	token := s.login(jane)
+	id := s.do("POST", "/reading-lists", token, map[string]interface{}{"name": "Weekend"}).JSON(t)["data"].(map[string]interface{})["id"]
+	path := fmt.Sprintf("/reading-lists/%v", id)
+
+	// expect checks the list holds the posts in order at positions 1 to n
+	expect := func(step string, posts ...models.Post) {
+		t.Helper()
+		var items []models.ReadingListItem
+		if err := s.db.Where("reading_list_id = ?", id).Order("position").Find(&items).Error; err != nil {
+			t.Fatal(err)
+		}
+		got, want := []string{}, []string{}
+		for i, item := range items {
+			got = append(got, fmt.Sprintf("%v@%v", item.PostID, item.Position))
+			if i < len(posts) {
+				want = append(want, fmt.Sprintf("%v@%v", posts[i].ID, i+1))
+			}
+		}
+		if len(items) != len(posts) || !reflect.DeepEqual(got, want) {
+			t.Errorf("%v: expected post@position %v, got %v", step, want, got)
+		}
+	}
+	send := func(status int, method, path string, body interface{}) {
+		t.Helper()
+		if res := s.do(method, path, token, body); res.Code != status {
+			t.Fatalf("%v %v: expected %v, got %v %s", method, path, status, res.Code, res.Body)
+		}
+	}
+	item := func(post models.Post) string {
+		return fmt.Sprintf("%v/posts/%v", path, post.ID)
+	}
+
+	for _, post := range []models.Post{a, b, c} {
+		send(http.StatusOK, "POST", path+"/posts", map[string]interface{}{"post_id": post.ID})
+	}
+	expect("appended", a, b, c)
+	send(http.StatusOK, "POST", path+"/posts", map[string]interface{}{"post_id": d.ID, "position": 2})
+	expect("inserted", a, d, b, c)
+	send(http.StatusOK, "PATCH", item(c), map[string]interface{}{"position": 1})
+	expect("moved up", c, a, d, b)
+	send(http.StatusOK, "PATCH", item(c), map[string]interface{}{"position": 4})
+	expect("moved down", a, d, b, c)
+	send(http.StatusOK, "PATCH", item(d), map[string]interface{}{"note": "Only a note"})
+	expect("note changed", a, d, b, c)
+	send(http.StatusUnprocessableEntity, "PATCH", item(d), map[string]interface{}{"position": 0})
+	send(http.StatusUnprocessableEntity, "PATCH", item(d), map[string]interface{}{"position": 5})
+	expect("invalid moves", a, d, b, c)
+
+	send(http.StatusOK, "PUT", path+"/order", map[string]interface{}{"post_ids": []uint{b.ID, c.ID, a.ID, d.ID}})
+	expect("ordered", b, c, a, d)
+	send(http.StatusUnprocessableEntity, "PUT", path+"/order", map[string]interface{}{"post_ids": []uint{a.ID, b.ID, c.ID}})
+	send(http.StatusUnprocessableEntity, "PUT", path+"/order", map[string]interface{}{"post_ids": []uint{a.ID, a.ID, b.ID, c.ID}})
+	expect("invalid orders", b, c, a, d)
+
+	send(http.StatusOK, "DELETE", item(c), nil)
+	expect("removed", b, a, d)
+	if res := s.do("DELETE", fmt.Sprintf("/post/%v", a.ID), s.login(admin), nil); res.Code != http.StatusOK {
+		t.Fatalf("deleting the post failed: %s", res.Body)
+	}
+	expect("post deleted", b, d)
+}
+
+func TestFollowAuthor(t *testing.T) {
+	s := newServer(t)
+	s.createAdmin("admin")
+	jane := s.createMember("jane")
+	token := s.login(jane)
+	s.call(http.StatusOK, "POST", "/author/admin/follow", token, nil)
+	s.call(http.StatusNotFound, "POST", "/author/nobody/follow", token, nil)
+	s.call(http.StatusUnprocessableEntity, "POST", "/author/jane/follow", token, nil)
+	s.call(http.StatusOK, "GET", "/author/admin/followers", "", nil)
+	s.call(http.StatusOK, "GET", "/author/jane/following", "", nil)
+	s.call(http.StatusOK, "GET", "/author/admin", "", nil)
+	s.call(http.StatusOK, "DELETE", "/author/admin/follow", token, nil)
+	s.call(http.StatusOK, "GET", "/author/admin/followers", "", nil)
+}
+
+func TestFeed(t *testing.T) {
+	s := newServer(t)
+	admin := s.createAdmin("admin")
+	other := s.createAdmin("other")
+	jane := s.createMember("jane")
+	tech := s.createCategory("Tech")
+	life := s.createCategory("Life")
+	s.createPost(admin, life, "From a followed author")
+	s.createPost(other, tech, "From a followed category")
+	s.createPost(other, life, "From nobody followed")
+	token := s.login(jane)
+	s.call(http.StatusOK, "GET", "/feed", token, nil)
+	s.call(http.StatusOK, "POST", "/author/admin/follow", token, nil)
+	s.call(http.StatusOK, "POST", fmt.Sprintf("/category/%v/follow", tech.ID), token, nil)
+	s.call(http.StatusOK, "GET", "/feed", token, nil)
+	s.call(http.StatusOK, "GET", "/feed?page_size=1", token, nil)
+	s.call(http.StatusUnauthorized, "GET", "/feed", "", nil)
+}
+
+func TestBlockAndMute(t *testing.T) {
+	s := newServer(t)
+	admin := s.createAdmin("admin")
+	jane := s.createMember("jane")
+	john := s.createMember("john")
+	post := s.createPost(admin, s.createCategory("Tech"), "Hello")
+	s.createComment(john, post, "From john", nil)
+	token := s.login(jane)
+
+	s.call(http.StatusOK, "POST", "/author/john/mute", token, nil)
+	s.call(http.StatusOK, "GET", "/user/mutes", token, nil)
+	s.call(http.StatusOK, "GET", fmt.Sprintf("/post/%v/comment", post.ID), token, nil)
+	s.call(http.StatusOK, "DELETE", "/author/john/mute", token, nil)
+	s.call(http.StatusOK, "GET", "/user/mutes", token, nil)
+
+	s.call(http.StatusOK, "POST", "/author/john/block", token, nil)
+	s.call(http.StatusUnprocessableEntity, "POST", "/author/jane/block", token, nil)
+	s.call(http.StatusNotFound, "POST", "/author/nobody/block", token, nil)
+	s.call(http.StatusOK, "GET", "/user/blocks", token, nil)
+	// a blocked user cannot follow the one who blocked them
+	s.call(http.StatusForbidden, "POST", "/author/jane/follow", s.login(john), nil)
+	s.call(http.StatusOK, "DELETE", "/author/john/block", token, nil)
+	s.call(http.StatusOK, "GET", "/user/blocks", token, nil)
+}
+
+func TestMentions(t *testing.T) {
+	s := newServer(t)
+	admin := s.createAdmin("admin")
+	jane := s.createMember("jane")
+	category := s.createCategory("Tech")
+	s.call(http.StatusOK, "POST", "/post/", s.login(admin), map[string]interface{}{
+		"article_title":       "Hello",
+		"article_description": "Mentions",
+		"category_id":         category.ID,
+		"article_content":     "Thanks @jane and @nobody",
+	})
+	post := s.createPost(admin, category, "Another")
+	s.call(http.StatusOK, "POST", "/post/comment", s.login(admin), map[string]interface{}{"post_id": post.ID, "comment_content": "What do you think @jane?"})
+	s.call(http.StatusOK, "GET", "/user/mentions", s.login(jane), nil)
+	s.call(http.StatusOK, "GET", "/user/mentions", s.login(admin), nil)
+}
@@ -0,0 +1,21 @@
+package tests
+
+import (
+	_ "blogspot-project/docs"
+	"net/http"
+	"testing"
+)
+
+func TestSwagger(t *testing.T) {
+	s := newServer(t)
+	res := s.do("GET", "/swagger/doc.json", "", nil)
+	if res.Code != http.StatusOK {
+		t.Fatalf("expected the swagger document, got %v", res.Code)
+	}
+	paths := res.JSON(t)["paths"].(map[string]interface{})
+	for _, path := range []string{"/auth/login", "/post", "/realtime/events"} {
+		if _, ok := paths[path]; !ok {
+			t.Errorf("swagger document is missing %v", path)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package tests
+
+import (
+	"blogspot-project/models"
+	"blogspot-project/utils/mailer"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestSiteFeeds(t *testing.T) {
+	s := newServer(t)
+	admin := s.createAdmin("admin")
+	category := s.createCategory("Tech")
+	s.createPost(admin, category, "First")
+	s.createPost(admin, category, "Second")
+	s.call(http.StatusOK, "GET", "/feed.xml", "", nil)
+	s.call(http.StatusOK, "GET", "/atom.xml", "", nil)
+	s.call(http.StatusOK, "GET", "/feed.json", "", nil)
+	s.call(http.StatusOK, "GET", "/feed.json?mode=summary", "", nil)
+}
+
+func TestCategoryAndAuthorFeeds(t *testing.T) {
+	s := newServer(t)
+	admin := s.createAdmin("admin")
+	other := s.createAdmin("other")
+	tech := s.createCategory("Tech")
+	life := s.createCategory("Life")
+	s.createPost(admin, tech, "About tech")
+	s.createPost(other, life, "About life")
+	s.call(http.StatusOK, "GET", fmt.Sprintf("/category/%v/feed.xml", tech.ID), "", nil)
+	s.call(http.StatusOK, "GET", fmt.Sprintf("/category/%v/atom.xml", life.ID), "", nil)
+	s.call(http.StatusOK, "GET", fmt.Sprintf("/category/%v/feed.json", tech.ID), "", nil)
+	s.call(http.StatusNotFound, "GET", "/category/99/feed.xml", "", nil)
+	s.call(http.StatusOK, "GET", "/author/other/feed.xml", "", nil)
+	s.call(http.StatusOK, "GET", "/author/admin/atom.xml", "", nil)
+	s.call(http.StatusOK, "GET", "/author/admin/feed.json", "", nil)
+	s.call(http.StatusNotFound, "GET", "/author/nobody/feed.json", "", nil)
+}
+
+func TestTagFeeds(t *testing.T) {
+	s := newServer(t)
+	admin := s.createAdmin("admin")
+	category := s.createCategory("Tech")
+	s.createPost(admin, category, "Untagged")
+	s.call(http.StatusOK, "POST", "/post/", s.login(admin), map[string]interface{}{
+		"article_title":       "Tagged",
+		"article_description": "With tags",
+		"category_id":         category.ID,
+		"article_content":     "Hello",
+		"tags":                []string{"Go", "go ", "Web Dev"},
+	})
+	s.call(http.StatusOK, "GET", "/tag/go", "", nil)
+	s.call(http.StatusOK, "GET", "/tag/go/feed.xml", "", nil)
+	s.call(http.StatusOK, "GET", "/tag/web-dev/atom.xml", "", nil)
+	s.call(http.StatusOK, "GET", "/tag/go/feed.json", "", nil)
+	s.call(http.StatusNotFound, "GET", "/tag/nothing/feed.xml", "", nil)
+}
+
+func TestFeedConditionalRequest(t *testing.T) {
+	s := newServer(t)
+	admin := s.createAdmin("admin")
+	s.createPost(admin, s.createCategory("Tech"), "First")
+	res := s.call(http.StatusOK, "GET", "/feed.json", "", nil)
+	etag := res.Header.Get("ETag")
+	s.call(http.StatusNotModified, "GET", "/feed.json", "", nil, "If-None-Match", etag)
+	s.call(http.StatusNotModified, "GET", "/feed.json", "", nil, "If-None-Match", "W/"+etag)
+	s.call(http.StatusNotModified, "GET", "/feed.json", "", nil, "If-None-Match", `"stale", `+etag)
+	s.call(http.StatusNotModified, "GET", "/feed.json", "", nil, "If-None-Match", `W/"stale" ,W/`+etag+` `)
+	s.call(http.StatusNotModified, "GET", "/feed.json", "", nil, "If-None-Match", "*")
+	s.call(http.StatusOK, "GET", "/feed.json", "", nil, "If-None-Match", `"stale", W/"older"`)
+}
+
+func TestSitemap(t *testing.T) {
+	s := newServer(t)
+	admin := s.createAdmin("admin")
+	category := s.createCategory("Tech")
+	s.call(http.StatusOK, "POST", "/post/", s.login(admin), map[string]interface{}{
+		"article_title":       "Hello",
+		"article_description": "In the sitemap",
+		"category_id":         category.ID,
+		"article_content":     "Hello",
+	})
+	s.call(http.StatusOK, "GET", "/sitemap.xml", "", nil)
+	// small sitemaps are not split into pages
+	s.call(http.StatusNotFound, "GET", "/sitemap/1.xml", "", nil)
+	s.call(http.StatusOK, "GET", "/robots.txt", "", nil)
+}
+
+func TestSitemapUserDeleted(t *testing.T) {
+	s := newServer(t)
+	admin := s.createAdmin("admin")
+	author := s.createMember("author")
+	category := s.createCategory("Tech")
+	s.createPost(admin, category, "Stays")
+	s.createPost(author, category, "Goes")
+	s.call(http.StatusOK, "GET", "/sitemap.xml", "", nil)
+	// the author page and the posts of a deleted user leave the sitemap
+	s.call(http.StatusOK, "DELETE", fmt.Sprintf("/user/%v", author.ID), s.login(admin), nil)
+	s.call(http.StatusOK, "GET", "/sitemap.xml", "", nil)
+}
+
+func TestEmailUnsubscribe(t *testing.T) {
+	s := newServer(t)
+	jane := s.createMember("jane")
+	token := mailer.UnsubscribeToken(jane.ID, models.EMAIL_LIST_DIGEST)
+	s.call(http.StatusOK, "GET", "/email/unsubscribe?token="+token, "", nil)
+	s.call(http.StatusOK, "POST", "/email/unsubscribe?token="+token, "", nil)
+	s.call(http.StatusOK, "GET", "/email/unsubscribe?token="+token, "", nil)
+	s.call(http.StatusOK, "GET", "/notifications/preferences", s.login(jane), nil)
+	s.call(http.StatusBadRequest, "POST", "/email/unsubscribe?token=forged", "", nil)
+}
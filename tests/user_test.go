@@ -0,0 +1,96 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestCurrentUserProfile(t *testing.T) {
+	s := newServer(t)
+	jane := s.createMember("jane")
+	token := s.login(jane)
+	s.call(http.StatusOK, "GET", "/user/profile", token, nil)
+	s.call(http.StatusUnauthorized, "GET", "/user/profile", "", nil)
+	s.call(http.StatusUnauthorized, "GET", "/user/profile", "not-a-token", nil)
+}
+
+func TestUpdateCurrentUser(t *testing.T) {
+	s := newServer(t)
+	jane := s.createMember("jane")
+	token := s.login(jane)
+	s.call(http.StatusOK, "PATCH", "/login/update-current-user", token, map[string]interface{}{
+		"name":         "Jane Doe",
+		"email":        "jane@example.com",
+		"image_url":    "https://example.com/new.png",
+		"bio":          "Writer",
+		"social_links": []map[string]string{{"label": "site", "url": "https://jane.example.com"}},
+	})
+	s.call(http.StatusUnprocessableEntity, "PATCH", "/login/update-current-user", token, map[string]interface{}{
+		"email":     "jane@example.com",
+		"image_url": "https://example.com/new.png",
+		"bio":       strings.Repeat("b", 501),
+	})
+	s.call(http.StatusUnprocessableEntity, "PATCH", "/login/update-current-user", token, map[string]interface{}{
+		"email":        "jane@example.com",
+		"image_url":    "",
+		"social_links": []map[string]string{{"label": "", "url": "nope"}},
+	})
+	// an empty bio clears it
+	s.call(http.StatusOK, "PATCH", "/login/update-current-user", token, map[string]interface{}{
+		"email":     "jane@example.com",
+		"image_url": "https://example.com/new.png",
+		"bio":       "",
+	})
+}
+
+func TestListUsers(t *testing.T) {
+	s := newServer(t)
+	admin := s.createAdmin("admin")
+	jane := s.createMember("jane")
+	s.createMember("john")
+	s.call(http.StatusOK, "GET", "/user/", s.login(admin), nil)
+	s.call(http.StatusOK, "GET", "/user/?input_search=JA&page_size=1", s.login(admin), nil)
+	// wildcards are searched as they are
+	s.call(http.StatusOK, "GET", "/user/?input_search=_", s.login(admin), nil)
+	s.call(http.StatusBadRequest, "GET", "/user/?page_size=many", s.login(admin), nil)
+	s.call(http.StatusUnprocessableEntity, "GET", "/user/?page_size=0", s.login(admin), nil)
+	s.call(http.StatusUnprocessableEntity, "GET", "/user/?current_page=0", s.login(admin), nil, "Accept-Language", "id")
+	// pages larger than the maximum are cut down to it
+	s.call(http.StatusOK, "GET", "/user/?page_size=1000", s.login(admin), nil)
+	s.call(http.StatusForbidden, "GET", "/user/", s.login(jane), nil)
+}
+
+func TestDeleteUser(t *testing.T) {
+	s := newServer(t)
+	admin := s.createAdmin("admin")
+	jane := s.createMember("jane")
+	s.call(http.StatusForbidden, "DELETE", fmt.Sprintf("/user/%v", admin.ID), s.login(jane), nil)
+	s.call(http.StatusOK, "DELETE", fmt.Sprintf("/user/%v", jane.ID), s.login(admin), nil)
+	s.call(http.StatusNotFound, "DELETE", fmt.Sprintf("/user/%v", jane.ID), s.login(admin), nil)
+}
+
+func TestUpdateUserRole(t *testing.T) {
+	s := newServer(t)
+	admin := s.createAdmin("admin")
+	jane := s.createMember("jane")
+	path := fmt.Sprintf("/user/%v/role", jane.ID)
+	s.call(http.StatusForbidden, "PATCH", path, s.login(jane), map[string]uint{"role": 1})
+	s.call(http.StatusUnprocessableEntity, "PATCH", path, s.login(admin), map[string]uint{"role": 9})
+	s.call(http.StatusOK, "PATCH", path, s.login(admin), map[string]uint{"role": 3})
+	s.call(http.StatusNotFound, "PATCH", "/user/99/role", s.login(admin), map[string]uint{"role": 3})
+}
+
+func TestAuthorProfile(t *testing.T) {
+	s := newServer(t)
+	jane := s.createAdmin("jane")
+	category := s.createCategory("Tech")
+	s.createPost(jane, category, "First")
+	s.createPost(jane, category, "Second")
+	s.call(http.StatusOK, "GET", "/author/jane", "", nil)
+	s.call(http.StatusNotFound, "GET", "/author/nobody", "", nil)
+	s.call(http.StatusOK, "GET", "/author/jane/posts", "", nil)
+	s.call(http.StatusOK, "GET", "/author/jane/posts?page_size=1&current_page=2", "", nil)
+	s.call(http.StatusNotFound, "GET", "/author/nobody/posts", "", nil)
+}
@@ -0,0 +1,224 @@
+package apperror
+
+import (
+	"blogspot-project/utils/validation"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// Codes are stable, machine-readable names of errors. Clients should switch
+// on them rather than on the human readable detail.
+const CODE_BAD_REQUEST = "bad_request"
+const CODE_VALIDATION_FAILED = "validation_failed"
+const CODE_UNAUTHORIZED = "unauthorized"
+const CODE_INVALID_CREDENTIALS = "invalid_credentials"
+const CODE_FORBIDDEN = "forbidden"
+const CODE_ACCOUNT_SUSPENDED = "account_suspended"
+const CODE_NOT_FOUND = "not_found"
+const CODE_CONFLICT = "conflict"
+const CODE_PAYLOAD_TOO_LARGE = "payload_too_large"
+const CODE_UNSUPPORTED_MEDIA_TYPE = "unsupported_media_type"
+const CODE_INTERNAL = "internal_error"
+
+const CODE_USER_NOT_FOUND = "user_not_found"
+const CODE_POST_NOT_FOUND = "post_not_found"
+const CODE_COMMENT_NOT_FOUND = "comment_not_found"
+const CODE_CATEGORY_NOT_FOUND = "category_not_found"
+const CODE_TAG_NOT_FOUND = "tag_not_found"
+const CODE_MEDIA_NOT_FOUND = "media_not_found"
+const CODE_REPORT_NOT_FOUND = "report_not_found"
+const CODE_NOTIFICATION_NOT_FOUND = "notification_not_found"
+const CODE_READING_LIST_NOT_FOUND = "reading_list_not_found"
+const CODE_SITEMAP_NOT_FOUND = "sitemap_not_found"
+
+const CODE_ACCOUNT_EXISTS = "account_exists"
+const CODE_COMMENT_DELETED = "comment_deleted"
+const CODE_COMMENT_NOT_APPROVED = "comment_not_approved"
+const CODE_REPORT_RESOLVED = "report_resolved"
+const CODE_ALREADY_REPORTED = "already_reported"
+const CODE_ALREADY_IN_READING_LIST = "already_in_reading_list"
+
+// PROBLEM_CONTENT_TYPE is the media type of RFC 7807 responses.
+const PROBLEM_CONTENT_TYPE = "application/problem+json"
+
+// Error is an error meant for the client, with the status to answer with. Its
+// detail is shown as is, so it must never hold database or library messages.
+type Error struct {
+	Status int
+	Code   string
+	Detail string
+	// Extra is added to the problem as extension members
+	Extra map[string]interface{}
+	// Fields are listed in the errors member, in the language of the client
+	Fields []validation.FieldError
+	cause  error
+}
+
+func (e *Error) Error() string {
+	if e.cause != nil {
+		return fmt.Sprintf("%v: %v", e.Code, e.cause)
+	}
+	return e.Code + ": " + e.Detail
+}
+
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// With adds an extension member to the problem.
+func (e *Error) With(key string, value interface{}) *Error {
+	if e.Extra == nil {
+		e.Extra = map[string]interface{}{}
+	}
+	e.Extra[key] = value
+	return e
+}
+
+func New(status int, code, detail string) *Error {
+	return &Error{Status: status, Code: code, Detail: detail}
+}
+
+func BadRequest(code, detail string) *Error {
+	return New(http.StatusBadRequest, code, detail)
+}
+
+func Unauthorized(detail string) *Error {
+	return New(http.StatusUnauthorized, CODE_UNAUTHORIZED, detail)
+}
+
+func Forbidden(detail string) *Error {
+	return New(http.StatusForbidden, CODE_FORBIDDEN, detail)
+}
+
+func NotFound(code, detail string) *Error {
+	return New(http.StatusNotFound, code, detail)
+}
+
+func Conflict(code, detail string) *Error {
+	return New(http.StatusConflict, code, detail)
+}
+
+// Validation is a request that is well formed but holds invalid values.
+func Validation(detail string) *Error {
+	return New(http.StatusUnprocessableEntity, CODE_VALIDATION_FAILED, detail)
+}
+
+// Invalid is a request with invalid fields, for rules that are checked by
+// the handler rather than by the binding tags.
+func Invalid(fields ...validation.FieldError) *Error {
+	return &Error{Status: http.StatusUnprocessableEntity, Code: CODE_VALIDATION_FAILED, Detail: "Request has invalid fields", Fields: fields}
+}
+
+// InvalidToken is the error of a missing, malformed or expired token.
+func InvalidToken(err error) *Error {
+	return &Error{Status: http.StatusUnauthorized, Code: CODE_UNAUTHORIZED, Detail: "Missing, invalid or expired token", cause: err}
+}
+
+// Binding is the error of a request body or query that could not be read
+// into its input struct.
+func Binding(err error) *Error {
+	var syntaxError *json.SyntaxError
+	var typeError *json.UnmarshalTypeError
+	switch {
+	case errors.Is(err, io.EOF):
+		return &Error{Status: http.StatusBadRequest, Code: CODE_BAD_REQUEST, Detail: "Request body is empty", cause: err}
+	case errors.As(err, &syntaxError), errors.Is(err, io.ErrUnexpectedEOF):
+		return &Error{Status: http.StatusBadRequest, Code: CODE_BAD_REQUEST, Detail: "Request body is not valid JSON", cause: err}
+	case errors.As(err, &typeError):
+		invalid := Invalid(validation.FieldError{Field: jsonPath(typeError.Field), Rule: "type", Param: typeError.Type.String()})
+		invalid.cause = err
+		return invalid
+	}
+	invalid := Invalid(validation.Fields(err)...)
+	invalid.cause = err
+	return invalid
+}
+
+// jsonPath writes the list indexes of a json decoding path, like
+// social_links.1.url, the way validation paths are, social_links[1].url.
+func jsonPath(field string) string {
+	parts := strings.Split(field, ".")
+	path := parts[0]
+	for _, part := range parts[1:] {
+		if _, err := strconv.Atoi(part); err == nil {
+			path += "[" + part + "]"
+		} else {
+			path += "." + part
+		}
+	}
+	return path
+}
+
+// Lookup is the error of loading a single record, a missing record is a not
+// found with the given code.
+func Lookup(err error, code, detail string) *Error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &Error{Status: http.StatusNotFound, Code: code, Detail: detail, cause: err}
+	}
+	return From(err)
+}
+
+// From turns any error into an Error. Errors that are not known are internal
+// errors, their message is logged but never sent.
+func From(err error) *Error {
+	var appError *Error
+	if errors.As(err, &appError) {
+		return appError
+	}
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return &Error{Status: http.StatusNotFound, Code: CODE_NOT_FOUND, Detail: "Resource not found", cause: err}
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return &Error{Status: http.StatusConflict, Code: CODE_CONFLICT, Detail: "Resource already exists", cause: err}
+	case errors.Is(err, bcrypt.ErrMismatchedHashAndPassword):
+		return &Error{Status: http.StatusUnauthorized, Code: CODE_INVALID_CREDENTIALS, Detail: "Username or password is incorrect", cause: err}
+	}
+	return &Error{Status: http.StatusInternalServerError, Code: CODE_INTERNAL, Detail: "Something went wrong, please try again later", cause: err}
+}
+
+// Problem is the RFC 7807 body of an error response. Error repeats the
+// detail for clients written before problems were introduced, errors lists
+// the invalid fields of a validation error.
+func Problem(ctx *gin.Context, err *Error) gin.H {
+	problem := gin.H{}
+	for key, value := range err.Extra {
+		problem[key] = value
+	}
+	problem["type"] = "/problems/" + err.Code
+	problem["title"] = http.StatusText(err.Status)
+	problem["status"] = err.Status
+	problem["detail"] = err.Detail
+	problem["instance"] = ctx.Request.URL.Path
+	problem["code"] = err.Code
+	problem["error"] = err.Detail
+	if len(err.Fields) > 0 {
+		locale := validation.Locale(ctx.GetHeader("Accept-Language"))
+		fields := make([]gin.H, len(err.Fields))
+		for i, field := range err.Fields {
+			fields[i] = gin.H{"field": field.Field, "rule": field.Rule, "message": validation.Message(locale, field)}
+		}
+		problem["errors"] = fields
+	}
+	return problem
+}
+
+// Respond writes the error as a problem. Internal errors are logged with
+// their cause.
+func Respond(ctx *gin.Context, err error) {
+	appError := From(err)
+	if appError.Status >= http.StatusInternalServerError {
+		log.Printf("%v %v: %v", ctx.Request.Method, ctx.Request.URL.Path, err)
+	}
+	ctx.Header("Content-Type", PROBLEM_CONTENT_TYPE)
+	ctx.JSON(appError.Status, Problem(ctx, appError))
+}
@@ -0,0 +1,83 @@
+package apperror
+
+import (
+	"blogspot-project/utils/validation"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin/binding"
+	"gorm.io/gorm"
+)
+
+func TestBinding(t *testing.T) {
+	validation.Register()
+	type link struct {
+		URL string `json:"url" binding:"required,url"`
+	}
+	type input struct {
+		Name  string `json:"name" binding:"required,max=5"`
+		Links []link `json:"links" binding:"max=1,dive"`
+	}
+	tests := []struct {
+		name   string
+		body   string
+		status int
+		code   string
+		fields []validation.FieldError
+	}{
+		{"empty body", ``, http.StatusBadRequest, CODE_BAD_REQUEST, nil},
+		{"syntax error", `{"name" "jane"}`, http.StatusBadRequest, CODE_BAD_REQUEST, nil},
+		{"cut short", `{"name": "ja`, http.StatusBadRequest, CODE_BAD_REQUEST, nil},
+		{"wrong type", `{"name": 5}`, http.StatusUnprocessableEntity, CODE_VALIDATION_FAILED, []validation.FieldError{{Field: "name", Rule: "type", Param: "string"}}},
+		{"wrong nested type", `{"name": "jane", "links": [{"url": true}]}`, http.StatusUnprocessableEntity, CODE_VALIDATION_FAILED, []validation.FieldError{{Field: "links[0].url", Rule: "type", Param: "string"}}},
+		{"missing field", `{}`, http.StatusUnprocessableEntity, CODE_VALIDATION_FAILED, []validation.FieldError{{Field: "name", Rule: "required"}}},
+		{"broken rules", `{"name": "jennifer", "links": [{"url": "/a"}, {"url": "b"}]}`, http.StatusUnprocessableEntity, CODE_VALIDATION_FAILED, []validation.FieldError{
+			{Field: "name", Rule: "max", Param: "5"},
+			{Field: "links", Rule: "max_items", Param: "1"},
+		}},
+		{"broken nested rule", `{"name": "jane", "links": [{"url": "b"}]}`, http.StatusUnprocessableEntity, CODE_VALIDATION_FAILED, []validation.FieldError{{Field: "links[0].url", Rule: "url"}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := binding.JSON.BindBody([]byte(test.body), &input{})
+			if err == nil {
+				t.Fatal("expected the body to be rejected")
+			}
+			got := Binding(err)
+			if got.Status != test.status || got.Code != test.code || !reflect.DeepEqual(got.Fields, test.fields) {
+				t.Errorf("expected %v %v %v, got %v %v %v", test.status, test.code, test.fields, got.Status, got.Code, got.Fields)
+			}
+			if !reflect.DeepEqual(got.Unwrap(), err) {
+				t.Errorf("expected the binding error to be kept as the cause")
+			}
+		})
+	}
+}
+
+func TestFrom(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+	}{
+		{"app error", Forbidden("No"), http.StatusForbidden, CODE_FORBIDDEN},
+		{"wrapped app error", errors.Join(errors.New("saving"), Conflict(CODE_ALREADY_REPORTED, "Again")), http.StatusConflict, CODE_ALREADY_REPORTED},
+		{"missing record", gorm.ErrRecordNotFound, http.StatusNotFound, CODE_NOT_FOUND},
+		{"duplicate", gorm.ErrDuplicatedKey, http.StatusConflict, CODE_CONFLICT},
+		{"anything else", errors.New("connection refused"), http.StatusInternalServerError, CODE_INTERNAL},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := From(test.err)
+			if got.Status != test.status || got.Code != test.code {
+				t.Errorf("expected %v %v, got %v %v", test.status, test.code, got.Status, got.Code)
+			}
+		})
+	}
+	if detail := From(errors.New("secret dsn")).Detail; detail != "Something went wrong, please try again later" {
+		t.Errorf("expected internal errors to be hidden, got %q", detail)
+	}
+}
@@ -0,0 +1,79 @@
+package counters
+
+import (
+	"blogspot-project/models"
+	"blogspot-project/utils"
+	"log"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// likeCounters are the denormalized like and dislike columns, by table.
+var likeCounters = []struct {
+	table      string
+	targetType string
+	column     string
+	reaction   string
+}{
+	{"posts", models.TARGET_POST, "post_like_count", models.REACTION_LIKE},
+	{"posts", models.TARGET_POST, "post_dislike_count", models.REACTION_DISLIKE},
+	{"comments", models.TARGET_COMMENT, "comment_like_count", models.REACTION_LIKE},
+	{"comments", models.TARGET_COMMENT, "comment_dislike_count", models.REACTION_DISLIKE},
+}
+
+// Reconcile recounts the reactions and repairs every reaction count and like
+// or dislike counter that drifted from them. It returns how many rows were
+// repaired. Each row is recounted in a single statement, so a reaction made
+// meanwhile is never lost.
+func Reconcile(db *gorm.DB) (int64, error) {
+	var fixed int64 = 0
+	recount := `(SELECT COUNT(*) FROM reactions r WHERE r.target_type = reaction_counts.target_type
+		AND r.target_id = reaction_counts.target_id AND r.type = reaction_counts.type)`
+	result := db.Exec(`UPDATE reaction_counts SET total = ` + recount + ` WHERE total <> ` + recount)
+	if result.Error != nil {
+		return fixed, result.Error
+	}
+	fixed += result.RowsAffected
+
+	result = db.Exec(`INSERT INTO reaction_counts (target_type, target_id, type, total)
+		SELECT r.target_type, r.target_id, r.type, COUNT(*) FROM reactions r
+		WHERE NOT EXISTS (SELECT 1 FROM reaction_counts rc WHERE rc.target_type = r.target_type AND rc.target_id = r.target_id AND rc.type = r.type)
+		GROUP BY r.target_type, r.target_id, r.type`)
+	if result.Error != nil {
+		return fixed, result.Error
+	}
+	fixed += result.RowsAffected
+
+	for _, counter := range likeCounters {
+		total := `COALESCE((SELECT rc.total FROM reaction_counts rc WHERE rc.target_type = ? AND rc.target_id = ` + counter.table + `.id AND rc.type = ?), 0)`
+		result := db.Exec(`UPDATE `+counter.table+` SET `+counter.column+` = `+total+` WHERE `+counter.column+` <> `+total,
+			counter.targetType, counter.reaction, counter.targetType, counter.reaction)
+		if result.Error != nil {
+			return fixed, result.Error
+		}
+		fixed += result.RowsAffected
+	}
+	return fixed, nil
+}
+
+// Run reconciles the counters at start and then every
+// COUNTER_RECONCILE_MINUTES, for as long as the process lives.
+func Run(db *gorm.DB) {
+	minutes, err := strconv.Atoi(utils.GetEnv("COUNTER_RECONCILE_MINUTES", "60"))
+	if err != nil || minutes <= 0 {
+		minutes = 60
+	}
+	ticker := time.NewTicker(time.Duration(minutes) * time.Minute)
+	defer ticker.Stop()
+	for {
+		fixed, err := Reconcile(db)
+		if err != nil {
+			log.Println("counters:", err)
+		} else if fixed > 0 {
+			log.Printf("counters: repaired %v drifted counters", fixed)
+		}
+		<-ticker.C
+	}
+}
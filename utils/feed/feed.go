@@ -0,0 +1,107 @@
+package feed
+
+import (
+	"blogspot-project/models"
+	"blogspot-project/utils"
+	"crypto/sha1"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gorilla/feeds"
+)
+
+const FORMAT_RSS = "rss"
+const FORMAT_ATOM = "atom"
+const FORMAT_JSON = "json"
+
+const MODE_FULL = "full"
+const MODE_SUMMARY = "summary"
+
+// FormatFromPath picks the output format from the route the request matched,
+// so one handler can serve /feed.xml, /atom.xml and /feed.json.
+func FormatFromPath(path string) string {
+	switch {
+	case strings.HasSuffix(path, "atom.xml"):
+		return FORMAT_ATOM
+	case strings.HasSuffix(path, ".json"):
+		return FORMAT_JSON
+	default:
+		return FORMAT_RSS
+	}
+}
+
+func ContentType(format string) string {
+	switch format {
+	case FORMAT_ATOM:
+		return "application/atom+xml; charset=utf-8"
+	case FORMAT_JSON:
+		return "application/feed+json; charset=utf-8"
+	default:
+		return "application/rss+xml; charset=utf-8"
+	}
+}
+
+// Mode returns the requested content mode, falling back to FEED_MODE.
+func Mode(requested string) string {
+	if requested == MODE_FULL || requested == MODE_SUMMARY {
+		return requested
+	}
+	if utils.GetEnv("FEED_MODE", MODE_SUMMARY) == MODE_FULL {
+		return MODE_FULL
+	}
+	return MODE_SUMMARY
+}
+
+// ETag identifies a feed by everything that changes its output: the newest
+// modification, the number of posts, and how the feed is rendered.
+func ETag(lastModified time.Time, count int64, format, mode, scope string) string {
+	sum := sha1.Sum([]byte(fmt.Sprintf("%v|%v|%v|%v|%v", lastModified.UnixNano(), count, format, mode, scope)))
+	return fmt.Sprintf(`"%x"`, sum[:10])
+}
+
+// Build converts posts into a feed. The authors map is keyed by user id and
+// is used for the author element of each entry.
+func Build(title, link, description string, posts []models.Post, authors map[uint]models.User, mode string, updated time.Time) *feeds.Feed {
+	f := &feeds.Feed{
+		Title:       title,
+		Link:        &feeds.Link{Href: link},
+		Description: description,
+		Id:          link,
+		Updated:     updated,
+		Created:     updated,
+	}
+	for _, post := range posts {
+		created := post.CreatedAt
+		if post.PublishedAt != nil {
+			created = *post.PublishedAt
+		}
+		item := &feeds.Item{
+			Title:       post.ArticleTitle,
+			Link:        &feeds.Link{Href: utils.PostURL(post.ID)},
+			Id:          utils.PostURL(post.ID),
+			Description: post.ArticleDescription,
+			Created:     created,
+			Updated:     post.UpdatedAt,
+		}
+		if author, ok := authors[post.UserID]; ok {
+			item.Author = &feeds.Author{Name: author.Name}
+		}
+		if mode == MODE_FULL {
+			item.Content = post.ArticleContent
+		}
+		f.Items = append(f.Items, item)
+	}
+	return f
+}
+
+func Render(f *feeds.Feed, format string) (string, error) {
+	switch format {
+	case FORMAT_ATOM:
+		return f.ToAtom()
+	case FORMAT_JSON:
+		return f.ToJSON()
+	default:
+		return f.ToRss()
+	}
+}
@@ -1,10 +1,12 @@
 package utils
 
 import (
-	"net/mail"
-	"net/url"
+	"blogspot-project/utils/apperror"
+	"blogspot-project/utils/validation"
+	"fmt"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -16,6 +18,48 @@ func GetEnv(key, fallback string) string {
 	return fallback
 }
 
+func SiteURL() string {
+	return strings.TrimRight(GetEnv("SITE_URL", "http://localhost:8080"), "/")
+}
+
+// AllowedOrigin reports whether a browser page from origin may open
+// connections to the api: the site itself or one of the comma separated
+// ALLOWED_ORIGINS.
+func AllowedOrigin(origin string) bool {
+	origin = strings.TrimRight(origin, "/")
+	if strings.EqualFold(origin, SiteURL()) {
+		return true
+	}
+	for _, allowed := range strings.Split(GetEnv("ALLOWED_ORIGINS", ""), ",") {
+		allowed = strings.TrimRight(strings.TrimSpace(allowed), "/")
+		if allowed != "" && strings.EqualFold(origin, allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+func PostURL(post_id uint) string {
+	return fmt.Sprintf("%v/post/%v", SiteURL(), post_id)
+}
+
+func CategoryURL(category_id uint) string {
+	return fmt.Sprintf("%v/category/%v", SiteURL(), category_id)
+}
+
+func TagURL(slug string) string {
+	return fmt.Sprintf("%v/tag/%v", SiteURL(), slug)
+}
+
+func AuthorURL(username string) string {
+	return fmt.Sprintf("%v/author/%v", SiteURL(), username)
+}
+
+// MAX_PAGE_SIZE caps page_size, larger pages are cut down to it.
+const MAX_PAGE_SIZE = 100
+
+// GetPagination returns the limit and offset of the page_size and
+// current_page query params. Both start at 1.
 func GetPagination(ctx *gin.Context) (int, int, error) {
 	current_page := ctx.Query("current_page")
 	page_size := ctx.Query("page_size")
@@ -24,34 +68,37 @@ func GetPagination(ctx *gin.Context) (int, int, error) {
 	if page_size != "" {
 		page_size_int, err := strconv.Atoi(page_size)
 		if err != nil {
-			return 0, 0, err
+			return 0, 0, apperror.BadRequest(apperror.CODE_BAD_REQUEST, "page_size must be a number")
+		}
+		if page_size_int < 1 {
+			return 0, 0, apperror.Invalid(validation.FieldError{Field: "page_size", Rule: "gte", Param: "1"})
 		}
 		limit = page_size_int
+		if limit > MAX_PAGE_SIZE {
+			limit = MAX_PAGE_SIZE
+		}
 	}
 
 	offset := 0
 	if current_page != "" {
 		current_page_int, err := strconv.Atoi(current_page)
 		if err != nil {
-			return 0, 0, err
+			return 0, 0, apperror.BadRequest(apperror.CODE_BAD_REQUEST, "current_page must be a number")
+		}
+		if current_page_int < 1 {
+			return 0, 0, apperror.Invalid(validation.FieldError{Field: "current_page", Rule: "gte", Param: "1"})
 		}
 		offset = (current_page_int - 1) * limit
 	}
 	return limit, offset, nil
 }
 
-func IsValidUrl(str string) bool {
-	_, err := url.ParseRequestURI(str)
-	if err != nil {
-		return false
-	}
-	return true
-}
-
-func IsValidEmail(str string) bool {
-	_, err := mail.ParseAddress(str)
+// GetParamID reads a numeric id path param. An invalid id is 0, which no
+// record has, so lookups by it end as not found.
+func GetParamID(ctx *gin.Context, name string) uint {
+	id, err := strconv.ParseUint(ctx.Param(name), 10, 64)
 	if err != nil {
-		return false
+		return 0
 	}
-	return true
+	return uint(id)
 }
@@ -0,0 +1,78 @@
+package mailer
+
+import (
+	"blogspot-project/models"
+	"blogspot-project/utils"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DIGEST_MAX_POSTS caps how many posts one digest lists.
+const DIGEST_MAX_POSTS = 20
+
+// SendDigests queues a digest of the new posts in the categories a user
+// follows, for every user whose last digest is MAIL_DIGEST_DAYS old. Users
+// without new posts get nothing but still wait for the next period.
+func SendDigests(db *gorm.DB, now time.Time) error {
+	period := time.Duration(envInt("MAIL_DIGEST_DAYS", 7)) * 24 * time.Hour
+	var user_ids []uint
+	if err := db.Model(&models.CategoryFollow{}).Distinct("user_id").Pluck("user_id", &user_ids).Error; err != nil {
+		return err
+	}
+	for _, user_id := range user_ids {
+		preference, err := models.GetNotificationPreference(db, user_id)
+		if err != nil {
+			return err
+		}
+		if !preference.EmailDigest {
+			continue
+		}
+		since := now.Add(-period)
+		if preference.DigestSentAt != nil {
+			if preference.DigestSentAt.After(since) {
+				continue
+			}
+			since = *preference.DigestSentAt
+		}
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			return sendDigest(tx, user_id, since, now)
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func sendDigest(tx *gorm.DB, user_id uint, since, now time.Time) error {
+	var user models.User
+	if err := tx.Where("id = ?", user_id).Take(&user).Error; err != nil {
+		return err
+	}
+	var posts []models.Post
+	if err := tx.Scopes(models.PublishedPosts).Preload("Category").
+		Where("category_id IN (?)", tx.Model(&models.CategoryFollow{}).Select("category_id").Where("user_id = ?", user_id)).
+		Where("published_at > ? AND published_at <= ? AND user_id <> ?", since, now, user_id).
+		Order("published_at desc").Limit(DIGEST_MAX_POSTS).Find(&posts).Error; err != nil {
+		return err
+	}
+	if len(posts) > 0 {
+		content := Content{}
+		for _, post := range posts {
+			content.Posts = append(content.Posts, DigestPost{
+				Title:       post.ArticleTitle,
+				Description: Excerpt(post.ArticleDescription, 200),
+				Category:    post.Category.Name,
+				URL:         utils.PostURL(post.ID),
+			})
+		}
+		if err := Enqueue(tx, user, models.EMAIL_LIST_DIGEST, TEMPLATE_DIGEST, content); err != nil {
+			return err
+		}
+	}
+	preference := models.NotificationPreference{}
+	if err := tx.Where(models.NotificationPreference{UserID: user_id}).Attrs(models.DefaultNotificationPreference(user_id)).FirstOrCreate(&preference).Error; err != nil {
+		return err
+	}
+	return tx.Model(&preference).Update("digest_sent_at", now).Error
+}
@@ -0,0 +1,30 @@
+package mailer
+
+import (
+	"log"
+	"strings"
+)
+
+// Message is one email, with a plain text and an HTML version of the body.
+type Message struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+	Headers map[string]string
+}
+
+// Mailer sends emails. It is only used by the outbox worker, handlers queue
+// emails with Enqueue instead of sending them.
+type Mailer interface {
+	Send(message Message) error
+}
+
+// LogMailer writes emails to the log instead of sending them, for
+// development.
+type LogMailer struct{}
+
+func (LogMailer) Send(message Message) error {
+	log.Printf("mail to %v: %v\n%v", message.To, message.Subject, strings.TrimSpace(message.Text))
+	return nil
+}
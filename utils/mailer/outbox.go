@@ -0,0 +1,125 @@
+package mailer
+
+import (
+	"blogspot-project/models"
+	"blogspot-project/utils"
+	"encoding/json"
+	"log"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MAX_BACKOFF caps the wait between two attempts of the same email.
+const MAX_BACKOFF = 6 * time.Hour
+
+func envInt(key string, fallback int) int {
+	value, err := strconv.Atoi(utils.GetEnv(key, strconv.Itoa(fallback)))
+	if err != nil || value <= 0 {
+		return fallback
+	}
+	return value
+}
+
+// Enqueue renders an email for a user and stores it in the outbox. Pass the
+// transaction that caused it, so the email is only sent if it commits.
+func Enqueue(db *gorm.DB, user models.User, list, template string, content Content) error {
+	content.Name = user.Name
+	content.UnsubscribeURL = UnsubscribeURL(user.ID, list)
+	subject, text, html, err := render(template, content)
+	if err != nil {
+		return err
+	}
+	headers, err := json.Marshal(unsubscribeHeaders(user.ID, list))
+	if err != nil {
+		return err
+	}
+	return db.Create(&models.EmailOutbox{
+		UserID:        user.ID,
+		List:          list,
+		ToAddress:     user.Email,
+		Subject:       subject,
+		TextBody:      text,
+		HTMLBody:      html,
+		Headers:       string(headers),
+		Status:        models.EMAIL_STATUS_PENDING,
+		NextAttemptAt: time.Now(),
+	}).Error
+}
+
+// backoff doubles the wait after every failed attempt, starting at a minute.
+func backoff(attempts uint) time.Duration {
+	wait := time.Minute
+	for i := uint(1); i < attempts && wait < MAX_BACKOFF; i++ {
+		wait *= 2
+	}
+	if wait > MAX_BACKOFF {
+		return MAX_BACKOFF
+	}
+	return wait
+}
+
+// Deliver sends the emails that are due, up to MAIL_BATCH_SIZE of them, and
+// returns how many were sent. Each email is claimed before sending, so
+// several instances of the api can share the outbox. Emails that failed
+// MAIL_MAX_ATTEMPTS times are given up.
+func Deliver(db *gorm.DB, mailer Mailer) (int, error) {
+	max_attempts := uint(envInt("MAIL_MAX_ATTEMPTS", 5))
+	var emails []models.EmailOutbox
+	if err := db.Where("status = ? AND next_attempt_at <= ?", models.EMAIL_STATUS_PENDING, time.Now()).
+		Order("id").Limit(envInt("MAIL_BATCH_SIZE", 50)).Find(&emails).Error; err != nil {
+		return 0, err
+	}
+	sent := 0
+	for _, email := range emails {
+		// a crash while sending retries the email once the claim runs out
+		claim := db.Model(&models.EmailOutbox{}).Where("id = ? AND status = ? AND attempts = ?", email.ID, models.EMAIL_STATUS_PENDING, email.Attempts).
+			Updates(map[string]interface{}{"attempts": email.Attempts + 1, "next_attempt_at": time.Now().Add(backoff(email.Attempts + 1))})
+		if claim.Error != nil {
+			return sent, claim.Error
+		}
+		if claim.RowsAffected == 0 {
+			continue
+		}
+		email.Attempts++
+		headers := map[string]string{}
+		if email.Headers != "" {
+			if err := json.Unmarshal([]byte(email.Headers), &headers); err != nil {
+				return sent, err
+			}
+		}
+		err := mailer.Send(Message{To: email.ToAddress, Subject: email.Subject, Text: email.TextBody, HTML: email.HTMLBody, Headers: headers})
+		updates := map[string]interface{}{}
+		if err == nil {
+			updates["status"] = models.EMAIL_STATUS_SENT
+			updates["sent_at"] = time.Now()
+			updates["last_error"] = ""
+			sent++
+		} else {
+			updates["last_error"] = err.Error()
+			if email.Attempts >= max_attempts {
+				updates["status"] = models.EMAIL_STATUS_FAILED
+			}
+		}
+		if err := db.Model(&email).Updates(updates).Error; err != nil {
+			return sent, err
+		}
+	}
+	return sent, nil
+}
+
+// Run delivers the outbox and queues the digests every MAIL_POLL_SECONDS,
+// for as long as the process lives.
+func Run(db *gorm.DB, mailer Mailer) {
+	ticker := time.NewTicker(time.Duration(envInt("MAIL_POLL_SECONDS", 30)) * time.Second)
+	defer ticker.Stop()
+	for range ticker.C {
+		if err := SendDigests(db, time.Now()); err != nil {
+			log.Println("mail digest:", err)
+		}
+		if _, err := Deliver(db, mailer); err != nil {
+			log.Println("mail outbox:", err)
+		}
+	}
+}
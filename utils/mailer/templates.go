@@ -0,0 +1,71 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+)
+
+const TEMPLATE_REPLY = "reply"
+const TEMPLATE_MENTION = "mention"
+const TEMPLATE_DIGEST = "digest"
+
+//go:embed templates
+var templateFiles embed.FS
+
+// Content is what the templates can show. Name and UnsubscribeURL are
+// filled in by Enqueue.
+type Content struct {
+	Name           string
+	Actor          string
+	PostTitle      string
+	URL            string
+	Excerpt        string
+	Posts          []DigestPost
+	UnsubscribeURL string
+}
+
+type DigestPost struct {
+	Title       string
+	Description string
+	Category    string
+	URL         string
+}
+
+var textTemplates = map[string]*texttemplate.Template{}
+var htmlTemplates = map[string]*htmltemplate.Template{}
+
+func init() {
+	for _, name := range []string{TEMPLATE_REPLY, TEMPLATE_MENTION, TEMPLATE_DIGEST} {
+		textTemplates[name] = texttemplate.Must(texttemplate.ParseFS(templateFiles, "templates/layout.txt", "templates/"+name+".txt"))
+		htmlTemplates[name] = htmltemplate.Must(htmltemplate.ParseFS(templateFiles, "templates/layout.html", "templates/"+name+".html"))
+	}
+}
+
+// render returns the subject, text body and HTML body of an email. The
+// subject is defined in the text template.
+func render(name string, content Content) (string, string, string, error) {
+	var subject, text, html bytes.Buffer
+	if err := textTemplates[name].ExecuteTemplate(&subject, "subject", content); err != nil {
+		return "", "", "", err
+	}
+	if err := textTemplates[name].ExecuteTemplate(&text, "layout", content); err != nil {
+		return "", "", "", err
+	}
+	if err := htmlTemplates[name].ExecuteTemplate(&html, "layout", content); err != nil {
+		return "", "", "", err
+	}
+	return strings.TrimSpace(subject.String()), text.String(), html.String(), nil
+}
+
+// Excerpt shortens text for quoting it in an email.
+func Excerpt(text string, length int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	runes := []rune(text)
+	if len(runes) <= length {
+		return text
+	}
+	return strings.TrimSpace(string(runes[:length])) + "..."
+}
@@ -0,0 +1,66 @@
+package mailer
+
+import (
+	"blogspot-project/utils"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidUnsubscribeToken = errors.New("invalid unsubscribe token")
+
+// unsubscribe tokens do not expire, so they are signed with their own secret
+// (MAIL_UNSUBSCRIBE_SECRET) rather than the one for login tokens
+func unsubscribeSecret() []byte {
+	return []byte(utils.GetEnv("MAIL_UNSUBSCRIBE_SECRET", utils.GetEnv("API_SECRET", "rahasiasekali")))
+}
+
+func sign(payload string) string {
+	mac := hmac.New(sha256.New, unsubscribeSecret())
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// UnsubscribeToken identifies a user and an email list without needing a
+// login, so unsubscribe links work straight from the email.
+func UnsubscribeToken(user_id uint, list string) string {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%v:%v", user_id, list)))
+	return payload + "." + sign(payload)
+}
+
+func ParseUnsubscribeToken(token string) (uint, string, error) {
+	payload, signature, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(sign(payload))) {
+		return 0, "", ErrInvalidUnsubscribeToken
+	}
+	decoded, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return 0, "", ErrInvalidUnsubscribeToken
+	}
+	id, list, ok := strings.Cut(string(decoded), ":")
+	if !ok {
+		return 0, "", ErrInvalidUnsubscribeToken
+	}
+	user_id, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		return 0, "", ErrInvalidUnsubscribeToken
+	}
+	return uint(user_id), list, nil
+}
+
+func UnsubscribeURL(user_id uint, list string) string {
+	return fmt.Sprintf("%v/email/unsubscribe?token=%v", utils.SiteURL(), UnsubscribeToken(user_id, list))
+}
+
+// unsubscribeHeaders lets mail clients offer one-click unsubscribe
+// (RFC 8058).
+func unsubscribeHeaders(user_id uint, list string) map[string]string {
+	return map[string]string{
+		"List-Unsubscribe":      "<" + UnsubscribeURL(user_id, list) + ">",
+		"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+	}
+}
@@ -0,0 +1,83 @@
+package media
+
+import (
+	"blogspot-project/utils"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"net/http"
+	"strconv"
+	"strings"
+
+	_ "golang.org/x/image/webp"
+)
+
+var ErrUnsupportedType = errors.New("unsupported file type")
+var ErrCorruptImage = errors.New("file looks like an image but could not be decoded")
+
+var extensions = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/gif":       ".gif",
+	"image/webp":      ".webp",
+	"application/pdf": ".pdf",
+}
+
+type Info struct {
+	MimeType  string
+	Extension string
+	Width     int
+	Height    int
+	Checksum  string
+}
+
+// MaxUploadSize is the largest accepted upload in bytes (MEDIA_MAX_UPLOAD_SIZE).
+func MaxUploadSize() int64 {
+	size, err := strconv.ParseInt(utils.GetEnv("MEDIA_MAX_UPLOAD_SIZE", "10485760"), 10, 64)
+	if err != nil || size <= 0 {
+		return 10485760
+	}
+	return size
+}
+
+func AllowedTypes() []string {
+	var types []string
+	for _, t := range strings.Split(utils.GetEnv("MEDIA_ALLOWED_TYPES", "image/jpeg,image/png,image/gif,image/webp"), ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			types = append(types, t)
+		}
+	}
+	return types
+}
+
+// Inspect sniffs the real content type from the bytes instead of trusting
+// the client supplied header, and reads image dimensions when it can.
+func Inspect(data []byte) (Info, error) {
+	mimeType := strings.Split(http.DetectContentType(data), ";")[0]
+	allowed := false
+	for _, t := range AllowedTypes() {
+		if t == mimeType {
+			allowed = true
+		}
+	}
+	ext, known := extensions[mimeType]
+	if !allowed || !known {
+		return Info{}, ErrUnsupportedType
+	}
+	sum := sha256.Sum256(data)
+	info := Info{MimeType: mimeType, Extension: ext, Checksum: hex.EncodeToString(sum[:])}
+	if strings.HasPrefix(mimeType, "image/") {
+		config, _, err := image.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			return Info{}, ErrCorruptImage
+		}
+		info.Width = config.Width
+		info.Height = config.Height
+	}
+	return info, nil
+}
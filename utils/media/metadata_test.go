@@ -0,0 +1,230 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"testing"
+)
+
+// exifTIFF builds a little or big endian EXIF block with an orientation tag
+// and a GPS IFD holding a latitude.
+func exifTIFF(order binary.ByteOrder, orientation uint16) []byte {
+	var tiff bytes.Buffer
+	if order == binary.LittleEndian {
+		tiff.WriteString("II")
+	} else {
+		tiff.WriteString("MM")
+	}
+	binary.Write(&tiff, order, uint16(42))
+	binary.Write(&tiff, order, uint32(8))
+	// IFD0: orientation and the GPS IFD pointer
+	binary.Write(&tiff, order, uint16(2))
+	binary.Write(&tiff, order, []uint16{0x0112, 3})
+	binary.Write(&tiff, order, uint32(1))
+	binary.Write(&tiff, order, []uint16{orientation, 0})
+	binary.Write(&tiff, order, []uint16{0x8825, 4})
+	binary.Write(&tiff, order, []uint32{1, 38})
+	binary.Write(&tiff, order, uint32(0))
+	// GPS IFD: latitude 48° 51' 29.59"
+	binary.Write(&tiff, order, uint16(1))
+	binary.Write(&tiff, order, []uint16{0x0002, 5})
+	binary.Write(&tiff, order, []uint32{3, 56})
+	binary.Write(&tiff, order, uint32(0))
+	binary.Write(&tiff, order, []uint32{48, 1, 51, 1, 2959, 100})
+	return tiff.Bytes()
+}
+
+func jpegSegment(marker byte, payload []byte) []byte {
+	segment := []byte{0xFF, marker, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	return append(segment, payload...)
+}
+
+// testJPEG is a JPEG skeleton with JFIF, EXIF (with GPS), XMP, IPTC and a
+// comment before the scan.
+func testJPEG(tiff []byte) []byte {
+	var data bytes.Buffer
+	data.Write([]byte{0xFF, 0xD8})
+	data.Write(jpegSegment(0xE0, []byte("JFIF\x00\x01\x01\x00\x00\x01\x00\x01\x00\x00")))
+	data.Write(jpegSegment(0xE1, append([]byte("Exif\x00\x00"), tiff...)))
+	data.Write(jpegSegment(0xE1, []byte("http://ns.adobe.com/xap/1.0/\x00<x:xmpmeta/>")))
+	data.Write(jpegSegment(0xED, []byte("Photoshop 3.0\x008BIM")))
+	data.Write(jpegSegment(0xFE, []byte("shot by jane")))
+	data.Write(jpegSegment(0xDB, bytes.Repeat([]byte{1}, 65)))
+	data.Write(jpegSegment(0xDA, []byte{1, 1, 0, 0, 0x3F, 0}))
+	data.Write([]byte{0x12, 0x34, 0xFF, 0x00, 0x56, 0xFF, 0xD9})
+	return data.Bytes()
+}
+
+func TestStripJPEG(t *testing.T) {
+	tiff := exifTIFF(binary.LittleEndian, 6)
+	data := testJPEG(tiff)
+	if orientation := JPEGOrientation(data); orientation != 6 {
+		t.Fatalf("expected orientation 6 before stripping, got %v", orientation)
+	}
+	out, err := StripMetadata(data, "image/jpeg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, metadata := range [][]byte{tiff, []byte("Exif"), []byte("xmpmeta"), []byte("8BIM"), []byte("shot by jane")} {
+		if bytes.Contains(out, metadata) {
+			t.Errorf("%q is still in the stripped JPEG", metadata)
+		}
+	}
+	// everything else is kept as it is
+	for _, kept := range [][]byte{data[2:20], jpegSegment(0xDB, bytes.Repeat([]byte{1}, 65)), data[len(data)-20:]} {
+		if !bytes.Contains(out, kept) {
+			t.Errorf("% x is missing from the stripped JPEG", kept)
+		}
+	}
+	if orientation := JPEGOrientation(out); orientation != 1 {
+		t.Errorf("expected no orientation after stripping, got %v", orientation)
+	}
+}
+
+func TestStripJPEGMalformed(t *testing.T) {
+	data := testJPEG(exifTIFF(binary.LittleEndian, 1))
+	for name, malformed := range map[string][]byte{
+		"not a jpeg":     []byte("GIF89a"),
+		"truncated":      data[:30],
+		"no scan":        data[:len(data)-30],
+		"short segment":  {0xFF, 0xD8, 0xFF, 0xE1, 0x00, 0x01, 0xFF, 0xDA},
+		"missing marker": {0xFF, 0xD8, 0x00, 0xE1, 0x00, 0x02},
+	} {
+		if _, err := stripJPEG(malformed); err != errMalformed {
+			t.Errorf("%v: expected errMalformed, got %v", name, err)
+		}
+	}
+}
+
+func TestJPEGOrientationMalformed(t *testing.T) {
+	for name, data := range map[string][]byte{
+		"empty length":   {0xFF, 0xD8, 0xFF, 0xE1, 0x00, 0x00, 0xFF, 0xDA},
+		"one byte":       {0xFF, 0xD8, 0xFF, 0xE1, 0x00, 0x01, 0xFF, 0xDA},
+		"past the end":   {0xFF, 0xD8, 0xFF, 0xE1, 0x01, 0x00, 0xFF, 0xDA},
+		"not a jpeg":     []byte("\x89PNG\r\n\x1a\n"),
+		"short exif":     append([]byte{0xFF, 0xD8}, jpegSegment(0xE1, []byte("Exif\x00\x00II"))...),
+		"only the start": {0xFF, 0xD8},
+	} {
+		if orientation := JPEGOrientation(data); orientation != 1 {
+			t.Errorf("%v: expected 1, got %v", name, orientation)
+		}
+	}
+}
+
+func TestExifOrientation(t *testing.T) {
+	if orientation := exifOrientation(exifTIFF(binary.LittleEndian, 8)); orientation != 8 {
+		t.Errorf("little endian: expected 8, got %v", orientation)
+	}
+	if orientation := exifOrientation(exifTIFF(binary.BigEndian, 3)); orientation != 3 {
+		t.Errorf("big endian: expected 3, got %v", orientation)
+	}
+	out_of_range := exifTIFF(binary.BigEndian, 9)
+	no_tag := exifTIFF(binary.LittleEndian, 6)
+	// rename the orientation tag
+	no_tag[10] = 0x13
+	bad_offset := exifTIFF(binary.LittleEndian, 6)
+	binary.LittleEndian.PutUint32(bad_offset[4:], 4000)
+	truncated := exifTIFF(binary.LittleEndian, 6)[:20]
+	for name, tiff := range map[string][]byte{
+		"out of range": out_of_range,
+		"no tag":       no_tag,
+		"bad offset":   bad_offset,
+		"truncated":    truncated,
+		"bad order":    append([]byte("XX"), no_tag[2:]...),
+		"short":        []byte("II*"),
+	} {
+		if orientation := exifOrientation(tiff); orientation != 1 {
+			t.Errorf("%v: expected 1, got %v", name, orientation)
+		}
+	}
+}
+
+func pngChunk(kind string, payload []byte) []byte {
+	chunk := binary.BigEndian.AppendUint32(nil, uint32(len(payload)))
+	chunk = append(chunk, kind...)
+	chunk = append(chunk, payload...)
+	return binary.BigEndian.AppendUint32(chunk, crc32.ChecksumIEEE(chunk[4:]))
+}
+
+func TestStripPNG(t *testing.T) {
+	var encoded bytes.Buffer
+	if err := png.Encode(&encoded, image.NewGray(image.Rect(0, 0, 4, 4))); err != nil {
+		t.Fatal(err)
+	}
+	plain := encoded.Bytes()
+	// metadata goes right after IHDR, which is 8+25 bytes in
+	var data []byte
+	data = append(data, plain[:33]...)
+	data = append(data, pngChunk("tEXt", []byte("Author\x00jane"))...)
+	data = append(data, pngChunk("iTXt", []byte("Comment\x00\x00\x00\x00\x00secret"))...)
+	data = append(data, pngChunk("zTXt", []byte("Title\x00\x00x"))...)
+	data = append(data, pngChunk("tIME", []byte{0x07, 0xE8, 1, 2, 3, 4, 5})...)
+	data = append(data, pngChunk("eXIf", exifTIFF(binary.BigEndian, 6))...)
+	data = append(data, plain[33:]...)
+
+	out, err := StripMetadata(data, "image/png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(out, plain) {
+		t.Errorf("expected only the metadata chunks to be removed\n got % x\nwant % x", out, plain)
+	}
+	if _, err := png.Decode(bytes.NewReader(out)); err != nil {
+		t.Errorf("stripped PNG does not decode: %v", err)
+	}
+	if _, err := stripPNG(data[:50]); err != errMalformed {
+		t.Errorf("expected errMalformed for a truncated PNG, got %v", err)
+	}
+}
+
+func webpChunk(fourcc string, payload []byte) []byte {
+	chunk := append([]byte(fourcc), binary.LittleEndian.AppendUint32(nil, uint32(len(payload)))...)
+	chunk = append(chunk, payload...)
+	if len(payload)%2 == 1 {
+		chunk = append(chunk, 0)
+	}
+	return chunk
+}
+
+func riff(chunks ...[]byte) []byte {
+	body := []byte("WEBP")
+	for _, chunk := range chunks {
+		body = append(body, chunk...)
+	}
+	return append(append([]byte("RIFF"), binary.LittleEndian.AppendUint32(nil, uint32(len(body)))...), body...)
+}
+
+func TestStripWebP(t *testing.T) {
+	// VP8X with the EXIF and XMP flags set on a 1x1 canvas
+	header := []byte{0x08 | 0x04 | 0x10, 0, 0, 0, 0, 0, 0, 0, 0, 0}
+	image_data := webpChunk("VP8L", []byte{0x2F, 0, 0, 0, 0x10, 0x07, 0x10, 0x11, 0x11, 0x88, 0x88, 0x00})
+	data := riff(
+		webpChunk("VP8X", header),
+		image_data,
+		webpChunk("EXIF", exifTIFF(binary.LittleEndian, 6)),
+		webpChunk("XMP ", []byte("<x:xmpmeta/>x")),
+	)
+	out, err := StripMetadata(data, "image/webp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cleared := append([]byte{0x10}, header[1:]...)
+	expected := riff(webpChunk("VP8X", cleared), image_data)
+	if !bytes.Equal(out, expected) {
+		t.Errorf("unexpected stripped WebP\n got % x\nwant % x", out, expected)
+	}
+
+	for name, malformed := range map[string][]byte{
+		"not riff":   []byte("RIFX\x00\x00\x00\x00WEBP"),
+		"truncated":  data[:len(data)-4],
+		"empty VP8X": riff(webpChunk("VP8X", nil)),
+	} {
+		if _, err := stripWebP(malformed); err != errMalformed {
+			t.Errorf("%v: expected errMalformed, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package media
+
+import (
+	"blogspot-project/models"
+	"blogspot-project/utils"
+	"bytes"
+	"errors"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/HugoSmits86/nativewebp"
+	xdraw "golang.org/x/image/draw"
+)
+
+var ErrImageTooLarge = errors.New("image has too many pixels to process")
+
+type Variant struct {
+	Kind     string
+	Width    int
+	Height   int
+	MimeType string
+	Data     []byte
+}
+
+func intList(key, fallback string) []int {
+	var list []int
+	for _, item := range strings.Split(utils.GetEnv(key, fallback), ",") {
+		if n, err := strconv.Atoi(strings.TrimSpace(item)); err == nil && n > 0 {
+			list = append(list, n)
+		}
+	}
+	sort.Ints(list)
+	return list
+}
+
+// ImageWidths are the widths generated for featured images and other
+// uploads (MEDIA_IMAGE_WIDTHS).
+func ImageWidths() []int {
+	return intList("MEDIA_IMAGE_WIDTHS", "320,640,1024,1600")
+}
+
+// AvatarSizes are the square sizes generated for avatars (MEDIA_AVATAR_SIZES).
+func AvatarSizes() []int {
+	return intList("MEDIA_AVATAR_SIZES", "64,128,256")
+}
+
+func maxPixels() int {
+	n, err := strconv.Atoi(utils.GetEnv("MEDIA_MAX_PIXELS", "40000000"))
+	if err != nil || n <= 0 {
+		return 40000000
+	}
+	return n
+}
+
+// Decode decodes an uploaded image and applies its EXIF orientation, since
+// the derivatives are written without metadata. The pixel count is checked
+// before decoding so a small file cannot expand into gigabytes of memory.
+func Decode(data []byte) (image.Image, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrCorruptImage
+	}
+	if config.Width*config.Height > maxPixels() {
+		return nil, ErrImageTooLarge
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrCorruptImage
+	}
+	return orient(img, JPEGOrientation(data)), nil
+}
+
+// PrepareOriginal strips metadata from the stored original. JPEGs that rely
+// on an EXIF rotation are re-encoded upright, because removing the tag
+// would otherwise show them sideways.
+func PrepareOriginal(data []byte, mimeType string) ([]byte, error) {
+	if mimeType == "image/jpeg" && JPEGOrientation(data) != 1 {
+		img, err := Decode(data)
+		if err != nil {
+			return nil, err
+		}
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 92}); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+	return StripMetadata(data, mimeType)
+}
+
+// ResizedVariants scales the image down to every configured width smaller
+// than the original. Images are never scaled up.
+func ResizedVariants(img image.Image) ([]Variant, error) {
+	bounds := img.Bounds()
+	var variants []Variant
+	for _, width := range ImageWidths() {
+		if width >= bounds.Dx() {
+			continue
+		}
+		height := bounds.Dy() * width / bounds.Dx()
+		if height < 1 {
+			height = 1
+		}
+		variant, err := encodeVariant(models.MEDIA_VARIANT_RESIZED, scale(img, width, height))
+		if err != nil {
+			return nil, err
+		}
+		variants = append(variants, variant)
+	}
+	return variants, nil
+}
+
+// AvatarVariants crops the centre square of the image and scales it to
+// every configured avatar size.
+func AvatarVariants(img image.Image) ([]Variant, error) {
+	square := cropSquare(img)
+	var variants []Variant
+	for _, size := range AvatarSizes() {
+		if size > square.Bounds().Dx() {
+			size = square.Bounds().Dx()
+		}
+		variant, err := encodeVariant(models.MEDIA_VARIANT_AVATAR, scale(square, size, size))
+		if err != nil {
+			return nil, err
+		}
+		variants = append(variants, variant)
+	}
+	return variants, nil
+}
+
+func encodeVariant(kind string, img image.Image) (Variant, error) {
+	var buf bytes.Buffer
+	if err := nativewebp.Encode(&buf, img, nil); err != nil {
+		return Variant{}, err
+	}
+	return Variant{
+		Kind:     kind,
+		Width:    img.Bounds().Dx(),
+		Height:   img.Bounds().Dy(),
+		MimeType: "image/webp",
+		Data:     buf.Bytes(),
+	}, nil
+}
+
+func scale(img image.Image, width, height int) image.Image {
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	xdraw.CatmullRom.Scale(dst, dst.Bounds(), img, img.Bounds(), xdraw.Src, nil)
+	return dst
+}
+
+func cropSquare(img image.Image) image.Image {
+	bounds := img.Bounds()
+	size := bounds.Dx()
+	if bounds.Dy() < size {
+		size = bounds.Dy()
+	}
+	x := bounds.Min.X + (bounds.Dx()-size)/2
+	y := bounds.Min.Y + (bounds.Dy()-size)/2
+	dst := image.NewNRGBA(image.Rect(0, 0, size, size))
+	draw.Draw(dst, dst.Bounds(), img, image.Pt(x, y), draw.Src)
+	return dst
+}
+
+// orient applies one of the 8 EXIF orientations.
+func orient(img image.Image, orientation int) image.Image {
+	if orientation <= 1 || orientation > 8 {
+		return img
+	}
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2:
+				dx, dy = w-1-x, y
+			case 3:
+				dx, dy = w-1-x, h-1-y
+			case 4:
+				dx, dy = x, h-1-y
+			case 5:
+				dx, dy = y, x
+			case 6:
+				dx, dy = h-1-y, x
+			case 7:
+				dx, dy = h-1-y, w-1-x
+			case 8:
+				dx, dy = y, w-1-x
+			}
+			dst.Set(dx, dy, img.At(bounds.Min.X+x, bounds.Min.Y+y))
+		}
+	}
+	return dst
+}
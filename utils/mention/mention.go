@@ -0,0 +1,55 @@
+package mention
+
+import (
+	"blogspot-project/utils"
+	"html"
+	"regexp"
+	"strings"
+)
+
+// MAX_MENTIONS caps how many users one post or comment can mention, so a
+// single comment cannot be used to notify the whole site.
+const MAX_MENTIONS = 20
+
+// a mention starts the text or follows a character that cannot be part of
+// an email address or a url, so "mail@example.com" is not a mention
+var mentionPattern = regexp.MustCompile(`(^|[^\w@/.])@([A-Za-z0-9_](?:[A-Za-z0-9_.-]*[A-Za-z0-9_])?)`)
+
+// Parse returns the distinct usernames mentioned in a text, in order of
+// first appearance. Usernames are compared case insensitively.
+func Parse(text string) []string {
+	seen := map[string]bool{}
+	names := []string{}
+	for _, match := range mentionPattern.FindAllStringSubmatch(text, -1) {
+		name := match[2]
+		if seen[strings.ToLower(name)] {
+			continue
+		}
+		seen[strings.ToLower(name)] = true
+		names = append(names, name)
+		if len(names) == MAX_MENTIONS {
+			break
+		}
+	}
+	return names
+}
+
+// Render turns every mention of a known user into a link to their profile.
+// known maps lower cased usernames to the username as stored. Plain text
+// (comments) is HTML escaped first, HTML (posts) is kept as is.
+func Render(content string, known map[string]string, escape bool) string {
+	if escape {
+		content = html.EscapeString(content)
+	}
+	if len(known) == 0 {
+		return content
+	}
+	return mentionPattern.ReplaceAllStringFunc(content, func(match string) string {
+		parts := mentionPattern.FindStringSubmatch(match)
+		username, ok := known[strings.ToLower(parts[2])]
+		if !ok {
+			return match
+		}
+		return parts[1] + `<a href="` + html.EscapeString(utils.AuthorURL(username)) + `" class="mention">@` + html.EscapeString(username) + `</a>`
+	})
+}
@@ -0,0 +1,334 @@
+package notification
+
+import (
+	"blogspot-project/models"
+	"blogspot-project/utils"
+	"blogspot-project/utils/mailer"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// EMAIL_EXCERPT_LENGTH is how much of a comment or post an email quotes.
+const EMAIL_EXCERPT_LENGTH = 300
+
+// Moderation outcomes, stored in Notification.Action.
+const ACTION_APPROVED = "approved"
+const ACTION_REJECTED = "rejected"
+const ACTION_HIDDEN = "hidden"
+const ACTION_DELETED = "deleted"
+const ACTION_WARNED = "warned"
+const ACTION_SUSPENDED = "suspended"
+const ACTION_REPORT_REVIEWED = "reviewed"
+
+// Notifier stores notifications and hands every new or joined one to its
+// listener, which pushes them to connected clients.
+type Notifier struct {
+	db       *gorm.DB
+	listener func(models.Notification)
+	// a notifier from WithTx holds its events until Flush
+	inTx    bool
+	pending []models.Notification
+}
+
+// NewNotifier returns a notifier writing to db. listener may be nil.
+func NewNotifier(db *gorm.DB, listener func(models.Notification)) *Notifier {
+	return &Notifier{db: db, listener: listener}
+}
+
+// WithTx returns a notifier writing within tx, so the notifications are
+// only kept when the rest of the transaction is. Its events wait until
+// Flush, which is called once tx has committed, so clients never hear about
+// a notification that was rolled back.
+func (n *Notifier) WithTx(tx *gorm.DB) *Notifier {
+	return &Notifier{db: tx, listener: n.listener, inTx: true}
+}
+
+// Flush hands the events held by a notifier from WithTx to the listener.
+func (n *Notifier) Flush() {
+	pending := n.pending
+	n.pending = nil
+	for _, notification := range pending {
+		n.emitNow(notification)
+	}
+}
+
+func (n *Notifier) emit(notification models.Notification) {
+	if n.inTx {
+		n.pending = append(n.pending, notification)
+		return
+	}
+	n.emitNow(notification)
+}
+
+func (n *Notifier) emitNow(notification models.Notification) {
+	if n.listener != nil {
+		n.listener(notification)
+	}
+}
+
+// aggregated types are folded into one unread entry per target
+func aggregated(notification_type string) bool {
+	switch notification_type {
+	case models.NOTIFICATION_LIKE, models.NOTIFICATION_COMMENT, models.NOTIFICATION_REPLY, models.NOTIFICATION_FOLLOW:
+		return true
+	}
+	return false
+}
+
+// Notify stores a notification unless it would go to the user who caused it,
+// the recipient blocked or muted that user or turned that type off. Likes,
+// comments, replies and follows join the unread entry for the same target
+// when there is one. Moderation decisions are sent whoever the moderator is.
+func (n *Notifier) Notify(notification models.Notification) error {
+	if notification.UserID == 0 || notification.UserID == notification.ActorID {
+		return nil
+	}
+	if notification.ActorID != 0 && notification.Type != models.NOTIFICATION_MODERATION {
+		hidden, err := models.HasHidden(n.db, notification.UserID, notification.ActorID)
+		if err != nil || hidden {
+			return err
+		}
+	}
+	preference, err := models.GetNotificationPreference(n.db, notification.UserID)
+	if err != nil {
+		return err
+	}
+	if !preference.Allows(notification.Type) {
+		return nil
+	}
+	if aggregated(notification.Type) {
+		var existing models.Notification
+		err := n.db.Where("user_id = ? AND type = ? AND target_type = ? AND target_id = ? AND read_at IS NULL",
+			notification.UserID, notification.Type, notification.TargetType, notification.TargetID).Take(&existing).Error
+		if err == nil {
+			return n.addActor(existing, notification.ActorID)
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+	}
+	notification.ActorCount = 1
+	if err := n.db.Create(&notification).Error; err != nil {
+		return err
+	}
+	if notification.ActorID != 0 {
+		if err := n.db.Create(&models.NotificationActor{NotificationID: notification.ID, ActorID: notification.ActorID}).Error; err != nil {
+			return err
+		}
+	}
+	n.emit(notification)
+	if list, ok := emailLists[notification.Type]; ok && preference.AllowsEmail(list) {
+		return email(n.db, notification, list)
+	}
+	return nil
+}
+
+// emailLists are the notification types also sent by email. Only new
+// notifications are, more replies joining an unread one send nothing.
+var emailLists = map[string]string{
+	models.NOTIFICATION_MENTION: models.EMAIL_LIST_MENTIONS,
+	models.NOTIFICATION_REPLY:   models.EMAIL_LIST_REPLIES,
+}
+
+// email queues the email for a mention or a reply, quoting what was written.
+func email(db *gorm.DB, notification models.Notification, list string) error {
+	var user, actor models.User
+	if err := db.Where("id = ?", notification.UserID).Take(&user).Error; err != nil {
+		return err
+	}
+	if err := db.Where("id = ?", notification.ActorID).Take(&actor).Error; err != nil {
+		return err
+	}
+	var post models.Post
+	if err := db.Where("id = ?", notification.PostID).Take(&post).Error; err != nil {
+		return err
+	}
+	content := mailer.Content{
+		Actor:     actor.Name,
+		PostTitle: post.ArticleTitle,
+		URL:       utils.PostURL(post.ID),
+		Excerpt:   mailer.Excerpt(post.ArticleDescription, EMAIL_EXCERPT_LENGTH),
+	}
+	// a reply notification points at the parent, quote the latest reply of
+	// the actor to it
+	var comment models.Comment
+	query := db.Where("id = ?", notification.TargetID)
+	if notification.Type == models.NOTIFICATION_REPLY {
+		query = db.Where("parent_id = ? AND user_id = ?", notification.TargetID, notification.ActorID).Order("id desc")
+	}
+	if notification.Type == models.NOTIFICATION_REPLY || notification.TargetType == models.TARGET_COMMENT {
+		if err := query.Take(&comment).Error; err != nil {
+			return err
+		}
+		content.Excerpt = mailer.Excerpt(comment.CommentContent, EMAIL_EXCERPT_LENGTH)
+	}
+	template := mailer.TEMPLATE_MENTION
+	if notification.Type == models.NOTIFICATION_REPLY {
+		template = mailer.TEMPLATE_REPLY
+	}
+	return mailer.Enqueue(db, user, list, template, content)
+}
+
+func (n *Notifier) addActor(notification models.Notification, actor_id uint) error {
+	var count int64 = 0
+	if err := n.db.Model(&models.NotificationActor{}).Where("notification_id = ? AND actor_id = ?", notification.ID, actor_id).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	if err := n.db.Create(&models.NotificationActor{NotificationID: notification.ID, ActorID: actor_id}).Error; err != nil {
+		return err
+	}
+	now := time.Now()
+	if err := n.db.Model(&notification).Updates(map[string]interface{}{
+		"actor_id":    actor_id,
+		"actor_count": gorm.Expr("actor_count + 1"),
+		"updated_at":  now,
+	}).Error; err != nil {
+		return err
+	}
+	notification.ActorID = actor_id
+	notification.ActorCount++
+	notification.UpdatedAt = now
+	n.emit(notification)
+	return nil
+}
+
+// NewComment tells the author of the parent comment about a reply, and the
+// author of the post about a comment on it. Someone who is both only gets
+// the reply.
+func (n *Notifier) NewComment(comment models.Comment) error {
+	var post models.Post
+	if err := n.db.Select("id", "user_id").Where("id = ?", comment.PostID).Take(&post).Error; err != nil {
+		return err
+	}
+	var parent_author uint
+	if comment.ParentID != nil {
+		var parent models.Comment
+		if err := n.db.Select("id", "user_id").Where("id = ?", *comment.ParentID).Take(&parent).Error; err != nil {
+			return err
+		}
+		parent_author = parent.UserID
+		if err := n.Notify(models.Notification{
+			UserID:     parent.UserID,
+			ActorID:    comment.UserID,
+			Type:       models.NOTIFICATION_REPLY,
+			TargetType: models.TARGET_COMMENT,
+			TargetID:   parent.ID,
+			PostID:     post.ID,
+		}); err != nil {
+			return err
+		}
+	}
+	if post.UserID == parent_author {
+		return nil
+	}
+	return n.Notify(models.Notification{
+		UserID:     post.UserID,
+		ActorID:    comment.UserID,
+		Type:       models.NOTIFICATION_COMMENT,
+		TargetType: models.TARGET_POST,
+		TargetID:   post.ID,
+		PostID:     post.ID,
+	})
+}
+
+// Mentions tells mentioned users about a post or comment that has become
+// visible. Each mention is notified only once.
+func (n *Notifier) Mentions(target_type string, target_id uint) error {
+	var mentions []models.Mention
+	if err := n.db.Where("target_type = ? AND target_id = ? AND notified = ?", target_type, target_id, false).Find(&mentions).Error; err != nil {
+		return err
+	}
+	for _, item := range mentions {
+		if err := n.Notify(models.Notification{
+			UserID:     item.UserID,
+			ActorID:    item.MentionerID,
+			Type:       models.NOTIFICATION_MENTION,
+			TargetType: item.TargetType,
+			TargetID:   item.TargetID,
+			PostID:     item.PostID,
+		}); err != nil {
+			return err
+		}
+		if err := n.db.Model(&item).Update("notified", true).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CommentPublished tells everyone a comment that just became visible
+// concerns: the users it mentions and the authors of its parent and post.
+func (n *Notifier) CommentPublished(comment models.Comment) error {
+	if err := n.Mentions(models.TARGET_COMMENT, comment.ID); err != nil {
+		return err
+	}
+	return n.NewComment(comment)
+}
+
+// Like tells the owner of a post or comment that someone reacted to it.
+func (n *Notifier) Like(actor_id, owner_id uint, target_type string, target_id, post_id uint) error {
+	return n.Notify(models.Notification{
+		UserID:     owner_id,
+		ActorID:    actor_id,
+		Type:       models.NOTIFICATION_LIKE,
+		TargetType: target_type,
+		TargetID:   target_id,
+		PostID:     post_id,
+	})
+}
+
+// Moderation tells a user what a moderator decided about their content,
+// their account or a report they filed.
+func (n *Notifier) Moderation(user_id, moderator_id uint, action, target_type string, target_id, post_id uint) error {
+	return n.Notify(models.Notification{
+		UserID:     user_id,
+		ActorID:    moderator_id,
+		Type:       models.NOTIFICATION_MODERATION,
+		TargetType: target_type,
+		TargetID:   target_id,
+		PostID:     post_id,
+		Action:     action,
+	})
+}
+
+// Message is the text shown for a notification, actor being the name of the
+// latest person behind it.
+func Message(notification models.Notification, actor string) string {
+	if notification.ActorCount > 1 {
+		others := "others"
+		if notification.ActorCount == 2 {
+			others = "other"
+		}
+		actor = fmt.Sprintf("%v and %v %v", actor, notification.ActorCount-1, others)
+	}
+	target := notification.TargetType
+	switch notification.Type {
+	case models.NOTIFICATION_MENTION:
+		return fmt.Sprintf("%v mentioned you in a %v", actor, target)
+	case models.NOTIFICATION_REPLY:
+		return fmt.Sprintf("%v replied to your comment", actor)
+	case models.NOTIFICATION_COMMENT:
+		return fmt.Sprintf("%v commented on your post", actor)
+	case models.NOTIFICATION_LIKE:
+		return fmt.Sprintf("%v reacted to your %v", actor, target)
+	case models.NOTIFICATION_FOLLOW:
+		return fmt.Sprintf("%v started following you", actor)
+	case models.NOTIFICATION_MODERATION:
+		switch notification.Action {
+		case ACTION_WARNED:
+			return fmt.Sprintf("You received a warning because of your %v", target)
+		case ACTION_SUSPENDED:
+			return fmt.Sprintf("Your account was suspended because of your %v", target)
+		case ACTION_REPORT_REVIEWED:
+			return fmt.Sprintf("A moderator reviewed the %v you reported", target)
+		}
+		return fmt.Sprintf("Your %v was %v by a moderator", target, notification.Action)
+	}
+	return ""
+}
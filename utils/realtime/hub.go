@@ -0,0 +1,160 @@
+package realtime
+
+import (
+	"blogspot-project/utils"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const EVENT_COMMENT = "comment"
+const EVENT_LIKES = "likes"
+const EVENT_NOTIFICATION = "notification"
+
+// EVENT_RESET tells a reconnecting client that events it missed are no longer
+// kept, so it has to fetch the current state again.
+const EVENT_RESET = "reset"
+
+// Event is one update pushed to the clients subscribed to its topic. IDs
+// grow by one for every published event and restart with the process.
+type Event struct {
+	ID    uint64      `json:"id"`
+	Topic string      `json:"topic"`
+	Type  string      `json:"type"`
+	Data  interface{} `json:"data"`
+}
+
+func PostTopic(post_id uint) string {
+	return fmt.Sprintf("post:%v", post_id)
+}
+
+func UserTopic(user_id uint) string {
+	return fmt.Sprintf("user:%v", user_id)
+}
+
+// Hub is an in-process pub/sub. It keeps the latest events so clients that
+// reconnect with the id of the last event they saw get what they missed.
+type Hub struct {
+	mu          sync.Mutex
+	lastID      uint64
+	history     []Event
+	historySize int
+	bufferSize  int
+	subscribers map[*Subscriber]bool
+}
+
+// NewHub keeps REALTIME_HISTORY_SIZE events for replay and queues up to
+// REALTIME_BUFFER_SIZE events per connection.
+func NewHub() *Hub {
+	return &Hub{
+		historySize: envInt("REALTIME_HISTORY_SIZE", 1000),
+		bufferSize:  envInt("REALTIME_BUFFER_SIZE", 64),
+		subscribers: map[*Subscriber]bool{},
+	}
+}
+
+// Heartbeat is how often idle connections are pinged
+// (REALTIME_HEARTBEAT_SECONDS), so proxies keep them open and dead clients
+// are noticed.
+func Heartbeat() time.Duration {
+	return time.Duration(envInt("REALTIME_HEARTBEAT_SECONDS", 25)) * time.Second
+}
+
+func envInt(key string, fallback int) int {
+	value, err := strconv.Atoi(utils.GetEnv(key, strconv.Itoa(fallback)))
+	if err != nil || value <= 0 {
+		return fallback
+	}
+	return value
+}
+
+// Publish sends an event to every subscriber of the topic. A subscriber whose
+// queue is full is disconnected instead of slowing down the publisher, it can
+// catch up by reconnecting with its last event id.
+func (h *Hub) Publish(topic, event_type string, data interface{}) {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.lastID++
+	event := Event{ID: h.lastID, Topic: topic, Type: event_type, Data: data}
+	h.history = append(h.history, event)
+	if len(h.history) > h.historySize {
+		h.history = h.history[len(h.history)-h.historySize:]
+	}
+	for subscriber := range h.subscribers {
+		if !subscriber.topics[topic] {
+			continue
+		}
+		select {
+		case subscriber.events <- event:
+		default:
+			h.remove(subscriber)
+		}
+	}
+}
+
+// Subscribe registers a subscriber for the topics and returns the events
+// after last_event_id it has to be sent first. When some of those events are
+// no longer kept, that is a single EVENT_RESET carrying the latest id.
+func (h *Hub) Subscribe(topics []string, last_event_id uint64) (*Subscriber, []Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	subscriber := &Subscriber{
+		hub:    h,
+		topics: map[string]bool{},
+		events: make(chan Event, h.bufferSize),
+		done:   make(chan struct{}),
+	}
+	for _, topic := range topics {
+		subscriber.topics[topic] = true
+	}
+	h.subscribers[subscriber] = true
+
+	if last_event_id == 0 {
+		return subscriber, nil
+	}
+	if last_event_id > h.lastID || (len(h.history) > 0 && h.history[0].ID > last_event_id+1) {
+		return subscriber, []Event{{ID: h.lastID, Type: EVENT_RESET}}
+	}
+	replay := []Event{}
+	for _, event := range h.history {
+		if event.ID > last_event_id && subscriber.topics[event.Topic] {
+			replay = append(replay, event)
+		}
+	}
+	return subscriber, replay
+}
+
+func (h *Hub) remove(subscriber *Subscriber) {
+	if !h.subscribers[subscriber] {
+		return
+	}
+	delete(h.subscribers, subscriber)
+	close(subscriber.done)
+}
+
+// Subscriber is one client connection.
+type Subscriber struct {
+	hub    *Hub
+	topics map[string]bool
+	events chan Event
+	done   chan struct{}
+}
+
+func (s *Subscriber) Events() <-chan Event {
+	return s.events
+}
+
+// Done is closed when the subscriber is closed or fell too far behind.
+func (s *Subscriber) Done() <-chan struct{} {
+	return s.done
+}
+
+func (s *Subscriber) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	s.hub.remove(s)
+}
@@ -0,0 +1,326 @@
+package sitemap
+
+import (
+	"blogspot-project/models"
+	"blogspot-project/utils"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MAX_URLS_PER_SITEMAP is the limit of the sitemap protocol for one file.
+const MAX_URLS_PER_SITEMAP = 50000
+
+const KIND_POST = "post"
+const KIND_CATEGORY = "category"
+const KIND_AUTHOR = "author"
+
+type entry struct {
+	kind    string
+	id      uint
+	loc     string
+	lastMod time.Time
+	// the author and category of a post, to update them when it goes
+	authorID   uint
+	categoryID uint
+}
+
+type urlSet struct {
+	XMLName xml.Name `xml:"urlset"`
+	Xmlns   string   `xml:"xmlns,attr"`
+	URLs    []url    `xml:"url"`
+}
+
+type url struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name `xml:"sitemapindex"`
+	Xmlns    string   `xml:"xmlns,attr"`
+	Sitemaps []url    `xml:"sitemap"`
+}
+
+// Index keeps every sitemap URL in memory. It is filled from the database on
+// first use and afterwards kept current by the handlers that change posts,
+// categories and users, so serving the sitemap never scans the posts table.
+type Index struct {
+	db *gorm.DB
+
+	mu       sync.Mutex
+	loaded   bool
+	entries  map[string]*entry
+	counts   map[string]int
+	rendered map[int][]byte
+}
+
+func NewIndex(db *gorm.DB) *Index {
+	return &Index{db: db}
+}
+
+func key(kind string, id uint) string {
+	return fmt.Sprintf("%v:%v", kind, id)
+}
+
+func (i *Index) ensureLoaded() error {
+	if i.loaded {
+		return nil
+	}
+	i.entries = map[string]*entry{}
+	i.counts = map[string]int{}
+	i.rendered = map[int][]byte{}
+
+	var posts []models.Post
+	if err := i.db.Select("id", "user_id", "category_id", "updated_at").Scopes(models.PublishedPosts).Find(&posts).Error; err != nil {
+		return err
+	}
+	var categories []models.Category
+	if err := i.db.Find(&categories).Error; err != nil {
+		return err
+	}
+	author_ids := []uint{}
+	for _, post := range posts {
+		author_ids = append(author_ids, post.UserID)
+	}
+	var authors []models.User
+	if len(author_ids) > 0 {
+		if err := i.db.Where("id IN ?", author_ids).Find(&authors).Error; err != nil {
+			return err
+		}
+	}
+	for _, category := range categories {
+		i.entries[key(KIND_CATEGORY, category.ID)] = &entry{kind: KIND_CATEGORY, id: category.ID, loc: utils.CategoryURL(category.ID)}
+	}
+	for _, author := range authors {
+		i.entries[key(KIND_AUTHOR, author.ID)] = &entry{kind: KIND_AUTHOR, id: author.ID, loc: utils.AuthorURL(author.Username), lastMod: author.UpdatedAt}
+	}
+	// posts of deleted authors are left out with them
+	for _, post := range posts {
+		if _, ok := i.entries[key(KIND_AUTHOR, post.UserID)]; ok {
+			i.addPost(post)
+		}
+	}
+	i.loaded = true
+	return nil
+}
+
+func (i *Index) addPost(post models.Post) {
+	i.entries[key(KIND_POST, post.ID)] = &entry{kind: KIND_POST, id: post.ID, loc: utils.PostURL(post.ID), lastMod: post.UpdatedAt, authorID: post.UserID, categoryID: post.CategoryID}
+	i.counts[key(KIND_AUTHOR, post.UserID)]++
+	i.touch(key(KIND_CATEGORY, post.CategoryID), post.UpdatedAt)
+	i.touch(key(KIND_AUTHOR, post.UserID), post.UpdatedAt)
+}
+
+func (i *Index) touch(k string, t time.Time) {
+	if e, ok := i.entries[k]; ok && t.After(e.lastMod) {
+		e.lastMod = t
+	}
+}
+
+// PostChanged records a created or updated post. Drafts are removed, since
+// only published posts belong in the sitemap.
+func (i *Index) PostChanged(post models.Post, author models.User) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if !i.loaded {
+		return
+	}
+	i.removePost(post.ID)
+	if post.Status != models.POST_STATUS_PUBLISHED || post.IsHidden {
+		i.rendered = map[int][]byte{}
+		return
+	}
+	authorKey := key(KIND_AUTHOR, author.ID)
+	if _, ok := i.entries[authorKey]; !ok {
+		i.entries[authorKey] = &entry{kind: KIND_AUTHOR, id: author.ID, loc: utils.AuthorURL(author.Username), lastMod: author.UpdatedAt}
+	}
+	i.addPost(post)
+	i.rendered = map[int][]byte{}
+}
+
+func (i *Index) PostDeleted(post_id uint) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if !i.loaded {
+		return
+	}
+	i.removePost(post_id)
+	i.rendered = map[int][]byte{}
+}
+
+// removePost drops a post and, when it was the last published post of its
+// author, the author page as well.
+func (i *Index) removePost(post_id uint) {
+	k := key(KIND_POST, post_id)
+	post, ok := i.entries[k]
+	if !ok {
+		return
+	}
+	delete(i.entries, k)
+	now := time.Now()
+	authorKey := key(KIND_AUTHOR, post.authorID)
+	i.counts[authorKey]--
+	if i.counts[authorKey] <= 0 {
+		delete(i.counts, authorKey)
+		delete(i.entries, authorKey)
+	} else {
+		i.touch(authorKey, now)
+	}
+	i.touch(key(KIND_CATEGORY, post.categoryID), now)
+}
+
+func (i *Index) CategoryChanged(category models.Category) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if !i.loaded {
+		return
+	}
+	k := key(KIND_CATEGORY, category.ID)
+	if _, ok := i.entries[k]; !ok {
+		i.entries[k] = &entry{kind: KIND_CATEGORY, id: category.ID, loc: utils.CategoryURL(category.ID), lastMod: time.Now()}
+	}
+	i.rendered = map[int][]byte{}
+}
+
+func (i *Index) CategoryDeleted(category_id uint) {
+	i.remove(key(KIND_CATEGORY, category_id))
+}
+
+// AuthorChanged refreshes the profile URL of an author, whose username may
+// have changed. Users without published posts are not listed.
+func (i *Index) AuthorChanged(user models.User) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if !i.loaded {
+		return
+	}
+	if e, ok := i.entries[key(KIND_AUTHOR, user.ID)]; ok {
+		e.loc = utils.AuthorURL(user.Username)
+		e.lastMod = user.UpdatedAt
+		i.rendered = map[int][]byte{}
+	}
+}
+
+// AuthorDeleted drops the author page with all the posts of the author,
+// whose pages are gone with them.
+func (i *Index) AuthorDeleted(user_id uint) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if !i.loaded {
+		return
+	}
+	for _, e := range i.entries {
+		if e.kind == KIND_POST && e.authorID == user_id {
+			i.removePost(e.id)
+		}
+	}
+	authorKey := key(KIND_AUTHOR, user_id)
+	delete(i.entries, authorKey)
+	delete(i.counts, authorKey)
+	i.rendered = map[int][]byte{}
+}
+
+func (i *Index) remove(k string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if !i.loaded {
+		return
+	}
+	delete(i.entries, k)
+	delete(i.counts, k)
+	i.rendered = map[int][]byte{}
+}
+
+// sorted lists entries in a stable order (categories, authors, then posts by
+// id) so a page keeps the same URLs between renders.
+func (i *Index) sorted() []*entry {
+	order := map[string]int{KIND_CATEGORY: 0, KIND_AUTHOR: 1, KIND_POST: 2}
+	list := make([]*entry, 0, len(i.entries))
+	for _, e := range i.entries {
+		list = append(list, e)
+	}
+	sort.Slice(list, func(a, b int) bool {
+		if list[a].kind != list[b].kind {
+			return order[list[a].kind] < order[list[b].kind]
+		}
+		return list[a].id < list[b].id
+	})
+	return list
+}
+
+// PageCount is the number of sitemap files, 1 while all URLs fit in one.
+func (i *Index) PageCount() (int, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if err := i.ensureLoaded(); err != nil {
+		return 0, err
+	}
+	return i.pageCount(), nil
+}
+
+func (i *Index) pageCount() int {
+	pages := (len(i.entries) + MAX_URLS_PER_SITEMAP - 1) / MAX_URLS_PER_SITEMAP
+	if pages == 0 {
+		return 1
+	}
+	return pages
+}
+
+// Render returns /sitemap.xml for page 0, which is a urlset while every URL
+// fits in one file and a sitemap index otherwise, or the numbered page.
+func (i *Index) Render(page int) ([]byte, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if err := i.ensureLoaded(); err != nil {
+		return nil, err
+	}
+	if body, ok := i.rendered[page]; ok {
+		return body, nil
+	}
+	pages := i.pageCount()
+	if page < 0 || page > pages || (page > 0 && pages == 1) {
+		return nil, fmt.Errorf("sitemap page %v not found", page)
+	}
+
+	var doc interface{}
+	if page == 0 && pages > 1 {
+		index := sitemapIndex{Xmlns: "http://www.sitemaps.org/schemas/sitemap/0.9"}
+		for n := 1; n <= pages; n++ {
+			index.Sitemaps = append(index.Sitemaps, url{Loc: fmt.Sprintf("%v/sitemap/%v.xml", utils.SiteURL(), n)})
+		}
+		doc = index
+	} else {
+		list := i.sorted()
+		start := 0
+		if page > 0 {
+			start = (page - 1) * MAX_URLS_PER_SITEMAP
+		}
+		end := start + MAX_URLS_PER_SITEMAP
+		if end > len(list) {
+			end = len(list)
+		}
+		set := urlSet{Xmlns: "http://www.sitemaps.org/schemas/sitemap/0.9"}
+		for _, e := range list[start:end] {
+			u := url{Loc: e.loc}
+			if !e.lastMod.IsZero() {
+				u.LastMod = e.lastMod.UTC().Format(time.RFC3339)
+			}
+			set.URLs = append(set.URLs, u)
+		}
+		doc = set
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	if err := xml.NewEncoder(&buf).Encode(doc); err != nil {
+		return nil, err
+	}
+	i.rendered[page] = buf.Bytes()
+	return i.rendered[page], nil
+}
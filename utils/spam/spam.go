@@ -0,0 +1,194 @@
+package spam
+
+import (
+	"blogspot-project/models"
+	"math"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MIN_TRAINING_DOCS is how many spam and how many ham comments the
+// classifier must have seen before its scores are trusted.
+const MIN_TRAINING_DOCS = 5
+
+// MAX_TOKENS_PER_DOC bounds the work done for a single long comment.
+const MAX_TOKENS_PER_DOC = 300
+
+var linkPattern = regexp.MustCompile(`(?i)\b(?:https?://|www\.)[^\s<>"']+`)
+
+// CountLinks returns how many links a text contains.
+func CountLinks(text string) int {
+	return len(linkPattern.FindAllStringIndex(text, -1))
+}
+
+// Tokenize splits a text into the distinct features the classifier counts:
+// lower cased words plus a marker and the host of every link, since spam is
+// mostly recognised by where it points to.
+func Tokenize(text string) []string {
+	seen := map[string]bool{}
+	tokens := []string{}
+	add := func(token string) {
+		if len(tokens) >= MAX_TOKENS_PER_DOC || len(token) > 64 || seen[token] {
+			return
+		}
+		seen[token] = true
+		tokens = append(tokens, token)
+	}
+	for _, link := range linkPattern.FindAllString(text, -1) {
+		add("__link__")
+		if !strings.Contains(link, "://") {
+			link = "http://" + link
+		}
+		if parsed, err := url.Parse(link); err == nil && parsed.Hostname() != "" {
+			add("host:" + strings.TrimPrefix(strings.ToLower(parsed.Hostname()), "www."))
+		}
+	}
+	words := strings.FieldsFunc(strings.ToLower(linkPattern.ReplaceAllString(text, " ")), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		if len(word) < 2 || len(word) > 32 {
+			continue
+		}
+		add(word)
+	}
+	return tokens
+}
+
+func loadCorpus(db *gorm.DB) (models.SpamCorpus, error) {
+	corpus := models.SpamCorpus{ID: 1}
+	err := db.Where(models.SpamCorpus{ID: 1}).FirstOrCreate(&corpus).Error
+	return corpus, err
+}
+
+// Score returns the probability that a text is spam. trained is false while
+// the classifier has seen too few examples of either kind, callers should
+// ignore the score then.
+func Score(db *gorm.DB, text string) (score float64, trained bool, err error) {
+	corpus, err := loadCorpus(db)
+	if err != nil {
+		return 0, false, err
+	}
+	if corpus.SpamDocs < MIN_TRAINING_DOCS || corpus.HamDocs < MIN_TRAINING_DOCS {
+		return 0, false, nil
+	}
+	tokens := Tokenize(text)
+	var vocabulary int64 = 0
+	if err := db.Model(&models.SpamToken{}).Count(&vocabulary).Error; err != nil {
+		return 0, false, err
+	}
+	var known []models.SpamToken
+	if len(tokens) > 0 {
+		if err := db.Where("token IN ?", tokens).Find(&known).Error; err != nil {
+			return 0, false, err
+		}
+	}
+	counts := make(map[string]models.SpamToken, len(known))
+	for _, token := range known {
+		counts[token.Token] = token
+	}
+
+	// multinomial naive Bayes with Laplace smoothing, summed as log odds
+	total := float64(corpus.SpamDocs + corpus.HamDocs)
+	logOdds := math.Log(float64(corpus.SpamDocs)/total) - math.Log(float64(corpus.HamDocs)/total)
+	spamDenominator := float64(corpus.SpamTokens) + float64(vocabulary)
+	hamDenominator := float64(corpus.HamTokens) + float64(vocabulary)
+	for _, token := range tokens {
+		count := counts[token]
+		logOdds += math.Log((float64(count.SpamCount)+1)/spamDenominator) - math.Log((float64(count.HamCount)+1)/hamDenominator)
+	}
+	return 1 / (1 + math.Exp(-logOdds)), true, nil
+}
+
+// Classifier scores and trains on the data of one database, for callers
+// that do not hold the database themselves.
+type Classifier struct {
+	db *gorm.DB
+}
+
+func NewClassifier(db *gorm.DB) *Classifier {
+	return &Classifier{db: db}
+}
+
+func (c *Classifier) Score(text string) (float64, bool, error) {
+	return Score(c.db, text)
+}
+
+func (c *Classifier) Learn(text string, isSpam bool) error {
+	return Learn(c.db, text, isSpam)
+}
+
+func (c *Classifier) Unlearn(text string, isSpam bool) error {
+	return Unlearn(c.db, text, isSpam)
+}
+
+// Learn adds a text to the spam or ham side of the training data.
+func Learn(db *gorm.DB, text string, isSpam bool) error {
+	return train(db, text, isSpam, 1)
+}
+
+// Unlearn takes back an earlier Learn with the same arguments.
+func Unlearn(db *gorm.DB, text string, isSpam bool) error {
+	return train(db, text, isSpam, -1)
+}
+
+func train(db *gorm.DB, text string, isSpam bool, delta int) error {
+	docsColumn, tokensColumn, countColumn := "ham_docs", "ham_tokens", "ham_count"
+	if isSpam {
+		docsColumn, tokensColumn, countColumn = "spam_docs", "spam_tokens", "spam_count"
+	}
+	tokens := Tokenize(text)
+	return db.Transaction(func(tx *gorm.DB) error {
+		if _, err := loadCorpus(tx); err != nil {
+			return err
+		}
+		if delta < 0 {
+			if err := tx.Model(&models.SpamCorpus{}).Where("id = 1 AND "+docsColumn+" > 0").
+				UpdateColumn(docsColumn, gorm.Expr(docsColumn+" - 1")).Error; err != nil {
+				return err
+			}
+			for _, token := range tokens {
+				result := tx.Model(&models.SpamToken{}).Where("token = ? AND "+countColumn+" > 0", token).
+					UpdateColumn(countColumn, gorm.Expr(countColumn+" - 1"))
+				if result.Error != nil {
+					return result.Error
+				}
+				if result.RowsAffected > 0 {
+					if err := tx.Model(&models.SpamCorpus{}).Where("id = 1 AND "+tokensColumn+" > 0").
+						UpdateColumn(tokensColumn, gorm.Expr(tokensColumn+" - 1")).Error; err != nil {
+						return err
+					}
+				}
+			}
+			return tx.Where("spam_count = 0 AND ham_count = 0").Delete(&models.SpamToken{}).Error
+		}
+		if err := tx.Model(&models.SpamCorpus{}).Where("id = 1").Updates(map[string]interface{}{
+			docsColumn:   gorm.Expr(docsColumn + " + 1"),
+			tokensColumn: gorm.Expr(tokensColumn+" + ?", len(tokens)),
+		}).Error; err != nil {
+			return err
+		}
+		for _, token := range tokens {
+			newToken := models.SpamToken{Token: token}
+			if isSpam {
+				newToken.SpamCount = 1
+			} else {
+				newToken.HamCount = 1
+			}
+			// a token seen before is counted once more, in one statement so
+			// concurrent training cannot insert it twice
+			if err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "token"}},
+				DoUpdates: clause.Assignments(map[string]interface{}{countColumn: gorm.Expr("spam_tokens." + countColumn + " + 1")}),
+			}).Create(&newToken).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,41 @@
+package spam
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		tokens []string
+	}{
+		{"words once, lower cased", "Great post, great POST!", []string{"great", "post"}},
+		{"short words left out", "a b go", []string{"go"}},
+		{"links give a marker and their host", "see https://www.Pills.example/buy and www.pills.example", []string{"__link__", "host:pills.example", "see", "and"}},
+		{"letters of any script", "très bien, terima kasih", []string{"très", "bien", "terima", "kasih"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if tokens := Tokenize(test.text); !reflect.DeepEqual(tokens, test.tokens) {
+				t.Errorf("expected %q, got %q", test.tokens, tokens)
+			}
+		})
+	}
+
+	words := []string{}
+	for i := 0; i < MAX_TOKENS_PER_DOC+10; i++ {
+		words = append(words, "word"+strings.Repeat("x", i%20)+string(rune('a'+i/20)))
+	}
+	if tokens := Tokenize(strings.Join(words, " ")); len(tokens) != MAX_TOKENS_PER_DOC {
+		t.Errorf("expected at most %v tokens, got %v", MAX_TOKENS_PER_DOC, len(tokens))
+	}
+}
+
+func TestCountLinks(t *testing.T) {
+	if count := CountLinks("http://a.example, https://b.example/x?y=1 and www.c.example but not c.example"); count != 3 {
+		t.Errorf("expected 3 links, got %v", count)
+	}
+}
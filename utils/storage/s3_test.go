@@ -0,0 +1,167 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	ACCESS_KEY = "AKIDEXAMPLE"
+	SECRET_KEY = "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"
+)
+
+// fakeS3 is an in memory bucket that only accepts requests carrying a valid
+// Signature Version 4 for ACCESS_KEY and SECRET_KEY.
+type fakeS3 struct {
+	mutex    sync.Mutex
+	objects  map[string][]byte
+	types    map[string]string
+	rejected []error
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if err := f.verify(r, body); err != nil {
+		f.rejected = append(f.rejected, err)
+		http.Error(w, "SignatureDoesNotMatch", http.StatusForbidden)
+		return
+	}
+	switch r.Method {
+	case http.MethodPut:
+		f.objects[r.URL.EscapedPath()] = body
+		f.types[r.URL.EscapedPath()] = r.Header.Get("Content-Type")
+	case http.MethodGet:
+		data, ok := f.objects[r.URL.EscapedPath()]
+		if !ok {
+			http.Error(w, "NoSuchKey", http.StatusNotFound)
+			return
+		}
+		w.Write(data)
+	case http.MethodDelete:
+		delete(f.objects, r.URL.EscapedPath())
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// verify checks the signing headers the way S3 does: the payload hash must
+// match the body, the date must be recent and the signature must be the one
+// computed from the canonical request.
+func (f *fakeS3) verify(r *http.Request, body []byte) error {
+	payloadHash := r.Header.Get("x-amz-content-sha256")
+	if payloadHash != sha256Hex(body) {
+		return fmt.Errorf("x-amz-content-sha256 %q does not match the body", payloadHash)
+	}
+	amzDate := r.Header.Get("x-amz-date")
+	signedAt, err := time.Parse("20060102T150405Z", amzDate)
+	if err != nil {
+		return fmt.Errorf("x-amz-date %q is not an ISO 8601 basic timestamp", amzDate)
+	}
+	if time.Since(signedAt).Abs() > 5*time.Minute {
+		return fmt.Errorf("x-amz-date %q is not current", amzDate)
+	}
+
+	date := amzDate[:8]
+	scope := date + "/us-east-1/s3/aws4_request"
+	canonicalRequest := r.Method + "\n" + r.URL.EscapedPath() + "\n\n" +
+		"host:" + r.Host + "\nx-amz-content-sha256:" + payloadHash + "\nx-amz-date:" + amzDate + "\n\n" +
+		"host;x-amz-content-sha256;x-amz-date\n" + payloadHash
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+	key := hmacSHA256([]byte("AWS4"+SECRET_KEY), date)
+	key = hmacSHA256(key, "us-east-1")
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	expected := fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%v/%v, SignedHeaders=host;x-amz-content-sha256;x-amz-date, Signature=%v",
+		ACCESS_KEY, scope, hex.EncodeToString(hmacSHA256(key, stringToSign)))
+	if authorization := r.Header.Get("Authorization"); authorization != expected {
+		return fmt.Errorf("Authorization %q, expected %q", authorization, expected)
+	}
+	return nil
+}
+
+func (f *fakeS3) contentType(path string) string {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.types[path]
+}
+
+func (f *fakeS3) refused() []error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return append([]error(nil), f.rejected...)
+}
+
+func newFakeS3(t *testing.T) (*fakeS3, *S3Storage) {
+	fake := &fakeS3{objects: map[string][]byte{}, types: map[string]string{}}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	return fake, NewS3Storage(server.URL+"/", "us-east-1", "uploads", ACCESS_KEY, SECRET_KEY, "")
+}
+
+func TestS3RoundTrip(t *testing.T) {
+	fake, s3 := newFakeS3(t)
+	data := []byte("\x89PNG not really")
+	key := "3/a picture+1.png"
+	if err := s3.Put(key, data, "image/png"); err != nil {
+		t.Fatal(err, fake.refused())
+	}
+	if content_type := fake.contentType("/uploads/3/a%20picture%2B1.png"); content_type != "image/png" {
+		t.Errorf("expected the object under its escaped path, got %q", content_type)
+	}
+
+	got, err := s3.Get(key)
+	if err != nil {
+		t.Fatal(err, fake.refused())
+	}
+	if !bytes.Equal(got, data) {
+		t.Errorf("Get returned %q, expected %q", got, data)
+	}
+
+	if err := s3.Delete(key); err != nil {
+		t.Fatal(err, fake.refused())
+	}
+	if _, err := s3.Get(key); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("expected a 404 after Delete, got %v", err)
+	}
+	// deleting a missing object is not an error
+	if err := s3.Delete(key); err != nil {
+		t.Errorf("Delete of a missing key failed: %v", err)
+	}
+	if refused := fake.refused(); len(refused) > 0 {
+		t.Errorf("requests were refused: %v", refused)
+	}
+}
+
+func TestS3RejectsWrongSecret(t *testing.T) {
+	fake, s3 := newFakeS3(t)
+	s3.SecretKey = "wrong"
+	if err := s3.Put("3/file.png", []byte("data"), "image/png"); err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("expected the bad signature to be refused, got %v", err)
+	}
+	if refused := fake.refused(); len(refused) != 1 || !strings.Contains(refused[0].Error(), "Authorization") {
+		t.Errorf("expected the Authorization header to be refused, got %v", refused)
+	}
+}
+
+func TestS3URL(t *testing.T) {
+	s3 := NewS3Storage("https://s3.example.com/", "us-east-1", "uploads", ACCESS_KEY, SECRET_KEY, "")
+	if url := s3.URL("3/a b.png"); url != "https://s3.example.com/uploads/3/a%20b.png" {
+		t.Errorf("unexpected url %v", url)
+	}
+	s3 = NewS3Storage("https://s3.example.com", "us-east-1", "uploads", ACCESS_KEY, SECRET_KEY, "https://cdn.example.com/")
+	if url := s3.URL("3/a.png"); url != "https://cdn.example.com/3/a.png" {
+		t.Errorf("unexpected url %v", url)
+	}
+	if err := s3.Put("../a.png", nil, ""); err != ErrInvalidKey {
+		t.Errorf("expected ErrInvalidKey, got %v", err)
+	}
+}
@@ -0,0 +1,122 @@
+package timeline
+
+import (
+	"blogspot-project/models"
+	"blogspot-project/utils"
+	"strconv"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Cache builds the personalized feed of a user when it is read, from the
+// authors and categories the user follows, and keeps the newest post ids of
+// each feed for FEED_CACHE_SECONDS. Following, blocking or muting drops the
+// cached feed, new posts show up when it expires.
+type Cache struct {
+	db   *gorm.DB
+	ttl  time.Duration
+	size int
+
+	mu      sync.Mutex
+	entries map[uint]*entry
+}
+
+type entry struct {
+	postIDs []uint
+	expires time.Time
+}
+
+// NewCache keeps the FEED_CACHE_SIZE newest posts of each feed, pages further
+// back are read from the database.
+func NewCache(db *gorm.DB) *Cache {
+	return &Cache{
+		db:      db,
+		ttl:     time.Duration(envInt("FEED_CACHE_SECONDS", 60)) * time.Second,
+		size:    envInt("FEED_CACHE_SIZE", 500),
+		entries: map[uint]*entry{},
+	}
+}
+
+func envInt(key string, fallback int) int {
+	value, err := strconv.Atoi(utils.GetEnv(key, strconv.Itoa(fallback)))
+	if err != nil || value <= 0 {
+		return fallback
+	}
+	return value
+}
+
+// PostIDs returns the ids of a page of the feed of the user, newest first.
+func (c *Cache) PostIDs(user_id uint, limit, offset int) ([]uint, error) {
+	ids, err := c.cached(user_id)
+	if err != nil {
+		return nil, err
+	}
+	if offset+limit <= len(ids) || len(ids) < c.size {
+		if offset >= len(ids) {
+			return []uint{}, nil
+		}
+		end := offset + limit
+		if end > len(ids) {
+			end = len(ids)
+		}
+		return ids[offset:end], nil
+	}
+	return c.query(user_id, limit, offset)
+}
+
+// Invalidate drops the cached feed of the user, after a follow changed.
+func (c *Cache) Invalidate(user_id uint) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, user_id)
+}
+
+func (c *Cache) cached(user_id uint) ([]uint, error) {
+	now := time.Now()
+	c.mu.Lock()
+	if item, ok := c.entries[user_id]; ok && item.expires.After(now) {
+		c.mu.Unlock()
+		return item.postIDs, nil
+	}
+	c.mu.Unlock()
+
+	ids, err := c.query(user_id, c.size, 0)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for id, item := range c.entries {
+		if !item.expires.After(now) {
+			delete(c.entries, id)
+		}
+	}
+	c.entries[user_id] = &entry{postIDs: ids, expires: now.Add(c.ttl)}
+	return ids, nil
+}
+
+func (c *Cache) query(user_id uint, limit, offset int) ([]uint, error) {
+	var author_ids, category_ids []uint
+	if err := c.db.Model(&models.UserFollow{}).Where("follower_id = ?", user_id).Pluck("following_id", &author_ids).Error; err != nil {
+		return nil, err
+	}
+	if err := c.db.Model(&models.CategoryFollow{}).Where("user_id = ?", user_id).Pluck("category_id", &category_ids).Error; err != nil {
+		return nil, err
+	}
+	ids := []uint{}
+	if len(author_ids) == 0 && len(category_ids) == 0 {
+		return ids, nil
+	}
+	sources := c.db.Where("posts.user_id IN ?", author_ids)
+	if len(author_ids) == 0 {
+		sources = c.db.Where("posts.category_id IN ?", category_ids)
+	} else if len(category_ids) > 0 {
+		sources = sources.Or("posts.category_id IN ?", category_ids)
+	}
+	err := c.db.Model(&models.Post{}).Scopes(models.PublishedPosts, models.WithoutHiddenUsers("posts.user_id", user_id)).Where(sources).
+		Order("posts.published_at desc").Order("posts.id desc").
+		Limit(limit).Offset(offset).Pluck("posts.id", &ids).Error
+	return ids, err
+}
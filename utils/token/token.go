@@ -74,3 +74,12 @@ func ExtractTokenID(c *gin.Context) (uint, error) {
 	}
 	return 0, nil
 }
+
+// ExtractOptionalTokenID returns 0 without an error when the request carries
+// no token, so public endpoints can personalize responses for logged in users.
+func ExtractOptionalTokenID(c *gin.Context) (uint, error) {
+	if GetTokenString(c) == "" {
+		return 0, nil
+	}
+	return ExtractTokenID(c)
+}
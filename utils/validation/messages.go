@@ -0,0 +1,66 @@
+package validation
+
+import (
+	"strings"
+)
+
+const DEFAULT_LOCALE = "en"
+
+// messages are the texts of each rule per locale. {field} and {param} are
+// replaced by the field path and the argument of the rule.
+var messages = map[string]map[string]string{
+	"en": {
+		"required":  "{field} is required",
+		"min":       "{field} must be at least {param} characters",
+		"max":       "{field} must be at most {param} characters",
+		"min_items": "{field} must have at least {param} items",
+		"max_items": "{field} cannot have more than {param} items",
+		"gte":       "{field} must be {param} or more",
+		"username":  "{field} must be 3 to 30 letters, digits, dots, dashes or underscores, starting and ending with a letter, digit or underscore",
+		"url":       "{field} must be a valid url",
+		"email":     "{field} must be a valid email address",
+		"type":      "{field} must be a {param}",
+		"invalid":   "{field} is invalid",
+	},
+	"id": {
+		"required":  "{field} wajib diisi",
+		"min":       "{field} minimal {param} karakter",
+		"max":       "{field} maksimal {param} karakter",
+		"min_items": "{field} minimal berisi {param} item",
+		"max_items": "{field} tidak boleh lebih dari {param} item",
+		"gte":       "{field} minimal {param}",
+		"username":  "{field} harus 3 sampai 30 huruf, angka, titik, tanda hubung atau garis bawah, diawali dan diakhiri huruf, angka atau garis bawah",
+		"url":       "{field} harus berupa url yang valid",
+		"email":     "{field} harus berupa alamat email yang valid",
+		"type":      "{field} harus bertipe {param}",
+		"invalid":   "{field} tidak valid",
+	},
+}
+
+// Locale picks the first language of an Accept-Language header that has
+// messages, or DEFAULT_LOCALE. Quality values are not weighed, clients list
+// their preferred language first.
+func Locale(accept_language string) string {
+	for _, language := range strings.Split(accept_language, ",") {
+		tag, _, _ := strings.Cut(strings.TrimSpace(language), ";")
+		primary, _, _ := strings.Cut(strings.ToLower(tag), "-")
+		if _, ok := messages[primary]; ok {
+			return primary
+		}
+	}
+	return DEFAULT_LOCALE
+}
+
+// Message is the text of a field error in the given locale. Rules without a
+// text of their own are reported as invalid.
+func Message(locale string, field FieldError) string {
+	catalog, ok := messages[locale]
+	if !ok {
+		catalog = messages[DEFAULT_LOCALE]
+	}
+	message, ok := catalog[field.Rule]
+	if !ok {
+		message = catalog["invalid"]
+	}
+	return strings.NewReplacer("{field}", field.Field, "{param}", field.Param).Replace(message)
+}
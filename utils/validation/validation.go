@@ -0,0 +1,121 @@
+package validation
+
+import (
+	"errors"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// usernamePattern keeps usernames mentionable: they can be written after an
+// @ and end where a mention ends.
+var usernamePattern = regexp.MustCompile(`^[A-Za-z0-9_](?:[A-Za-z0-9_.-]*[A-Za-z0-9_])?$`)
+
+const USERNAME_MIN_LENGTH = 3
+const USERNAME_MAX_LENGTH = 30
+
+// FieldError is one invalid field of a request. Field is its json path, like
+// social_links[1].url, Rule the name of the broken rule and Param its
+// argument, like the maximum length.
+type FieldError struct {
+	Field string
+	Rule  string
+	Param string
+}
+
+var registerOnce sync.Once
+
+// Register adds the rules of this package to the validator of gin, and makes
+// it name fields by their json key. Besides the rules of the validator:
+//
+//	username  3 to 30 letters, digits, dots, dashes or underscores
+//	url       an absolute url or an absolute path
+//	email     an address as written in a mail header
+//
+// url and email replace the rules of the validator so the api accepts the
+// same values it always did.
+func Register() {
+	registerOnce.Do(func() {
+		engine, ok := binding.Validator.Engine().(*validator.Validate)
+		if !ok {
+			return
+		}
+		engine.RegisterTagNameFunc(func(field reflect.StructField) string {
+			name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+			if name == "-" {
+				return ""
+			}
+			if name == "" {
+				return field.Name
+			}
+			return name
+		})
+		engine.RegisterValidation("username", func(fl validator.FieldLevel) bool {
+			return IsUsername(fl.Field().String())
+		})
+		engine.RegisterValidation("url", func(fl validator.FieldLevel) bool {
+			return IsURL(fl.Field().String())
+		})
+		engine.RegisterValidation("email", func(fl validator.FieldLevel) bool {
+			return IsEmail(fl.Field().String())
+		})
+	})
+}
+
+func IsUsername(str string) bool {
+	length := len([]rune(str))
+	return length >= USERNAME_MIN_LENGTH && length <= USERNAME_MAX_LENGTH && usernamePattern.MatchString(str)
+}
+
+func IsURL(str string) bool {
+	_, err := url.ParseRequestURI(str)
+	return err == nil
+}
+
+func IsEmail(str string) bool {
+	_, err := mail.ParseAddress(str)
+	return err == nil
+}
+
+// Fields lists the invalid fields of a failed validation, or nil when err is
+// not one.
+func Fields(err error) []FieldError {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return nil
+	}
+	fields := make([]FieldError, len(validationErrors))
+	for i, fieldError := range validationErrors {
+		fields[i] = FieldError{Field: fieldPath(fieldError.Namespace()), Rule: rule(fieldError), Param: fieldError.Param()}
+	}
+	return fields
+}
+
+// fieldPath drops the name of the input struct from a namespace.
+func fieldPath(namespace string) string {
+	if _, path, ok := strings.Cut(namespace, "."); ok {
+		return path
+	}
+	return namespace
+}
+
+// rule tells apart the length of a text from the number of items of a list.
+func rule(fieldError validator.FieldError) string {
+	rule := fieldError.Tag()
+	switch fieldError.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		if rule == "min" || rule == "max" {
+			return rule + "_items"
+		}
+	}
+	if rule == "required_without" {
+		return "required"
+	}
+	return rule
+}
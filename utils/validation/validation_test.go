@@ -0,0 +1,180 @@
+package validation
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin/binding"
+)
+
+var bindingTagPattern = regexp.MustCompile("binding:\"([^\"]*)\"")
+var rulePattern = regexp.MustCompile(`Rule:\s*"([^"]+)"`)
+
+// rulesInUse lists the rules of the binding tags and of the field errors
+// built by hand across the repository, as Fields names them.
+func rulesInUse(t *testing.T) []string {
+	t.Helper()
+	rules := map[string]bool{"min_items": true, "max_items": true, "type": true, "invalid": true}
+	root := filepath.Join("..", "..")
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() && path != root && (entry.Name() == "docs" || strings.HasPrefix(entry.Name(), ".")) {
+			return filepath.SkipDir
+		}
+		if entry.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+		source, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		for _, match := range bindingTagPattern.FindAllStringSubmatch(string(source), -1) {
+			for _, tag := range strings.Split(match[1], ",") {
+				name, _, _ := strings.Cut(tag, "=")
+				switch name {
+				case "omitempty", "dive":
+				case "required_without":
+					rules["required"] = true
+				default:
+					rules[name] = true
+				}
+			}
+		}
+		for _, match := range rulePattern.FindAllStringSubmatch(string(source), -1) {
+			rules[match[1]] = true
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := []string{}
+	for name := range rules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestMessagesCoverEveryRule(t *testing.T) {
+	rules := rulesInUse(t)
+	for _, custom := range []string{"username", "url", "email"} {
+		if !strings.Contains(strings.Join(rules, " "), custom) {
+			t.Errorf("expected the custom rule %v to be found in use", custom)
+		}
+	}
+	for _, locale := range []string{"en", "id"} {
+		for _, rule := range rules {
+			if _, ok := messages[locale][rule]; !ok {
+				t.Errorf("rule %v has no %v message", rule, locale)
+			}
+		}
+	}
+	for locale, catalog := range messages {
+		for rule := range catalog {
+			if _, ok := messages[DEFAULT_LOCALE][rule]; !ok {
+				t.Errorf("rule %v has a %v message but none in %v", rule, locale, DEFAULT_LOCALE)
+			}
+		}
+	}
+}
+
+func TestLocale(t *testing.T) {
+	tests := []struct {
+		accept_language string
+		locale          string
+	}{
+		{"", "en"},
+		{"id", "id"},
+		{"id-ID,id;q=0.9,en;q=0.8", "id"},
+		{"ID-id", "id"},
+		{"fr-FR, id;q=0.5", "id"},
+		{"fr-FR,de;q=0.9", "en"},
+		{"en-GB;q=0.8, id;q=0.9", "en"},
+		{"*", "en"},
+	}
+	for _, test := range tests {
+		if locale := Locale(test.accept_language); locale != test.locale {
+			t.Errorf("Locale(%q): expected %v, got %v", test.accept_language, test.locale, locale)
+		}
+	}
+}
+
+func TestMessage(t *testing.T) {
+	field := FieldError{Field: "social_links[1].url", Rule: "max", Param: "255"}
+	tests := []struct {
+		locale  string
+		field   FieldError
+		message string
+	}{
+		{"en", field, "social_links[1].url must be at most 255 characters"},
+		{"id", field, "social_links[1].url maksimal 255 karakter"},
+		{"fr", field, "social_links[1].url must be at most 255 characters"},
+		{"id", FieldError{Field: "bio", Rule: "unknown"}, "bio tidak valid"},
+	}
+	for _, test := range tests {
+		if message := Message(test.locale, test.field); message != test.message {
+			t.Errorf("Message(%v, %+v): expected %q, got %q", test.locale, test.field, test.message, message)
+		}
+	}
+}
+
+func TestFields(t *testing.T) {
+	Register()
+	type link struct {
+		URL string `json:"url" binding:"required,url"`
+	}
+	type input struct {
+		Username string   `json:"username" binding:"required,username"`
+		Email    string   `json:"email" binding:"required,email"`
+		Avatar   string   `json:"avatar" binding:"required_without=Website"`
+		Website  string   `json:"-"`
+		Tags     []string `json:"tags" binding:"max=1"`
+		Links    []link   `json:"links" binding:"dive"`
+	}
+	err := binding.Validator.ValidateStruct(&input{
+		Username: "a.",
+		Email:    "jane",
+		Tags:     []string{"go", "web"},
+		Links:    []link{{URL: "https://example.com"}, {URL: "example"}},
+	})
+	expected := []FieldError{
+		{Field: "username", Rule: "username"},
+		{Field: "email", Rule: "email"},
+		{Field: "avatar", Rule: "required", Param: "Website"},
+		{Field: "tags", Rule: "max_items", Param: "1"},
+		{Field: "links[1].url", Rule: "url"},
+	}
+	if fields := Fields(err); !reflect.DeepEqual(fields, expected) {
+		t.Errorf("expected %+v, got %+v", expected, fields)
+	}
+	if Fields(os.ErrNotExist) != nil {
+		t.Errorf("expected no fields for other errors")
+	}
+}
+
+func TestIsUsername(t *testing.T) {
+	for username, valid := range map[string]bool{
+		"jane":                                   true,
+		"jane.doe-99":                            true,
+		"_jane_":                                 true,
+		"ja":                                     false,
+		"jane.":                                  false,
+		"-jane":                                  false,
+		"jane doe":                               false,
+		strings.Repeat("j", USERNAME_MAX_LENGTH): true,
+		strings.Repeat("j", USERNAME_MAX_LENGTH+1): false,
+	} {
+		if IsUsername(username) != valid {
+			t.Errorf("IsUsername(%q): expected %v", username, valid)
+		}
+	}
+}